@@ -0,0 +1,130 @@
+// Command kronop-tracker-dump converts a tracker.RawEventRecorder dump
+// (all "<path>.0", "<path>.1", ... shard files plus "<path>.strings")
+// into JSON or CSV for offline analysis.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kronop/prefetcher/internal/tracker/dumpreader"
+)
+
+func main() {
+	path := flag.String("path", "", "Raw event dump path prefix, as passed to TrackerConfig.RawEventDumpPath")
+	format := flag.String("format", "json", "Output format: json or csv")
+	out := flag.String("out", "", "Output file (default: stdout)")
+	kind := flag.String("kind", "", "Only include events of this kind (scroll, watch, interaction, dropped); default: all")
+	flag.Parse()
+
+	if *path == "" {
+		log.Fatal("-path is required")
+	}
+
+	strings, err := dumpreader.ReadStringTable(*path + ".strings")
+	if err != nil {
+		log.Fatalf("failed to read string table: %v", err)
+	}
+
+	shardPaths, err := filepath.Glob(*path + ".*")
+	if err != nil {
+		log.Fatalf("failed to list shards: %v", err)
+	}
+	sort.Strings(shardPaths)
+
+	var events []dumpreader.Event
+	for _, sp := range shardPaths {
+		if sp == *path+".strings" {
+			continue
+		}
+		shardEvents, err := dumpreader.ReadShard(sp, strings)
+		if err != nil {
+			log.Fatalf("failed to read shard %s: %v", sp, err)
+		}
+		events = append(events, shardEvents...)
+	}
+
+	if *kind != "" {
+		events = filterByKind(events, *kind)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("failed to create output file: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "json":
+		if err := writeJSON(w, events); err != nil {
+			log.Fatalf("failed to write JSON: %v", err)
+		}
+	case "csv":
+		if err := writeCSV(w, events); err != nil {
+			log.Fatalf("failed to write CSV: %v", err)
+		}
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+}
+
+// filterByKind keeps only events whose Kind.String() matches kind,
+// case-insensitively.
+func filterByKind(events []dumpreader.Event, kind string) []dumpreader.Event {
+	kind = strings.ToLower(kind)
+	filtered := events[:0]
+	for _, e := range events {
+		if strings.ToLower(e.Kind.String()) == kind {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func writeJSON(w *os.File, events []dumpreader.Event) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(events)
+}
+
+func writeCSV(w *os.File, events []dumpreader.Event) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{"kind", "offset_ns", "reel_id", "tag", "from_reel", "to_reel", "speed", "duration_ns", "completed", "position", "dropped"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		row := []string{
+			e.Kind.String(),
+			strconv.FormatInt(e.Offset.Nanoseconds(), 10),
+			strconv.FormatUint(e.ReelID, 10),
+			e.Tag,
+			strconv.FormatUint(e.FromReel, 10),
+			strconv.FormatUint(e.ToReel, 10),
+			fmt.Sprintf("%g", e.Speed),
+			strconv.FormatInt(e.Duration.Nanoseconds(), 10),
+			strconv.FormatBool(e.Completed),
+			fmt.Sprintf("%g", e.Position),
+			strconv.FormatUint(e.Dropped, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package ai
+
+import "time"
+
+// CacheStore persists PredictionEntry values keyed by userID behind a
+// pluggable backend: an in-memory LRU (the default, memoryCacheStore) or a
+// Pebble-backed store (pebbleCacheStore) for durability across restarts.
+// Entries returned by Get are not guaranteed to be the same pointer later
+// stored by Put (true for pebbleCacheStore, since it round-trips through
+// serialization) — callers that mutate an entry must Put it back.
+type CacheStore interface {
+	// Get returns the entry for userID, if present.
+	Get(userID string) (*PredictionEntry, bool)
+	// Put stores (or overwrites) the entry for userID, evicting an entry
+	// first if the store is at capacity.
+	Put(userID string, entry *PredictionEntry)
+	// Delete removes the entry for userID, if present.
+	Delete(userID string)
+	// Len returns the number of entries currently stored.
+	Len() int
+	// Range calls fn for every stored entry, stopping early if fn returns
+	// false. Iteration order is unspecified.
+	Range(fn func(userID string, entry *PredictionEntry) bool)
+	// PurgeExpired removes every entry whose ExpiresAt is before now,
+	// returning the number removed.
+	PurgeExpired(now time.Time) int
+	// Clear removes every entry.
+	Clear()
+	// Close releases any resources the store holds (file handles, etc).
+	Close() error
+}
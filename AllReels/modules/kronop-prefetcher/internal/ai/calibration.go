@@ -0,0 +1,223 @@
+package ai
+
+import (
+	"math"
+	"sort"
+)
+
+// calibrationBins is how many equal-width confidence bins Expected
+// Calibration Error buckets predictions into.
+const calibrationBins = 10
+
+// calibrationMinSamples is the fewest recorded outcomes required before
+// maybeRetuneAdaptiveThreshold will act — retuning off a handful of samples
+// would chase noise.
+const calibrationMinSamples = 20
+
+// calibrationECEHigh is the Expected Calibration Error above which
+// confidence is considered miscalibrated enough to retune AdaptiveThreshold.
+const calibrationECEHigh = 0.1
+
+// calibrationTuneStep is how much AdaptiveThreshold moves per retune.
+const calibrationTuneStep = 0.02
+
+// calibrationSample is one outcome-labeled prediction, used to compute the
+// calibration metrics below.
+type calibrationSample struct {
+	confidence float64
+	outcome    bool
+}
+
+// RecordOutcome records the observed ground truth for userID's cached
+// prediction (whether it turned out correct), separate from Confidence so
+// calibration metrics aren't conflating predicted confidence with observed
+// correctness. It also triggers an AdaptiveThreshold retune (see
+// maybeRetuneAdaptiveThreshold) once enough outcomes are on record.
+func (pl *PredictionLogic) RecordOutcome(userID string, correct bool) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	entry, ok := pl.store.Get(userID)
+	if !ok || entry.Prediction == nil {
+		return
+	}
+
+	entry.Outcome = correct
+	entry.OutcomeSet = true
+	pl.store.Put(userID, entry)
+
+	pl.maybeRetuneAdaptiveThreshold()
+}
+
+// calibrationSamples collects every outcome-labeled entry in the store.
+// Caller should hold pl.mu (read or write).
+func (pl *PredictionLogic) calibrationSamples() []calibrationSample {
+	var samples []calibrationSample
+	pl.store.Range(func(_ string, entry *PredictionEntry) bool {
+		if entry.Prediction != nil && entry.OutcomeSet {
+			samples = append(samples, calibrationSample{confidence: entry.Confidence, outcome: entry.Outcome})
+		}
+		return true
+	})
+	return samples
+}
+
+// calibrationAccuracy is the empirical share of outcome-labeled predictions
+// that were correct.
+func calibrationAccuracy(samples []calibrationSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	correct := 0
+	for _, s := range samples {
+		if s.outcome {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(samples))
+}
+
+// brierScore is mean((confidence - outcome)^2) over outcome-labeled
+// predictions — lower is better calibrated, 0 is perfect.
+func brierScore(samples []calibrationSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		observed := 0.0
+		if s.outcome {
+			observed = 1.0
+		}
+		diff := s.confidence - observed
+		sum += diff * diff
+	}
+	return sum / float64(len(samples))
+}
+
+// expectedCalibrationError buckets samples into calibrationBins equal-width
+// confidence bins and returns the bin-size-weighted average gap between
+// each bin's empirical accuracy and its average confidence — 0 means
+// confidence perfectly tracks accuracy across the whole range.
+func expectedCalibrationError(samples []calibrationSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	type bin struct {
+		count      int
+		confSum    float64
+		correctSum float64
+	}
+	bins := make([]bin, calibrationBins)
+
+	for _, s := range samples {
+		idx := int(s.confidence * calibrationBins)
+		if idx >= calibrationBins {
+			idx = calibrationBins - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		bins[idx].count++
+		bins[idx].confSum += s.confidence
+		if s.outcome {
+			bins[idx].correctSum++
+		}
+	}
+
+	var ece float64
+	for _, b := range bins {
+		if b.count == 0 {
+			continue
+		}
+		avgConf := b.confSum / float64(b.count)
+		avgAcc := b.correctSum / float64(b.count)
+		weight := float64(b.count) / float64(len(samples))
+		ece += weight * math.Abs(avgAcc-avgConf)
+	}
+	return ece
+}
+
+// confidenceMode returns the most frequently occurring confidence value
+// (rounded to 2 decimals, to bucket floats that are "the same" value in
+// practice). Counts are tallied first, then the mode is picked in a
+// separate pass over sorted keys using a strict ">" comparison against a
+// running max count — a naive single-pass "update whenever count >= best"
+// implementation would let a later, equally-common but lower-valued bucket
+// silently override an earlier one (or vice versa, depending on map
+// iteration order), making the result nondeterministic across runs.
+func confidenceMode(samples []calibrationSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	counts := make(map[float64]int, len(samples))
+	for _, s := range samples {
+		key := math.Round(s.confidence*100) / 100
+		counts[key]++
+	}
+
+	keys := make([]float64, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+
+	bestValue := keys[0]
+	bestCount := 0
+	for _, k := range keys {
+		if counts[k] > bestCount {
+			bestCount = counts[k]
+			bestValue = k
+		}
+	}
+	return bestValue
+}
+
+// maybeRetuneAdaptiveThreshold raises AdaptiveThreshold when the Expected
+// Calibration Error shows confidence is running ahead of actual correctness
+// — predictions are less trustworthy than their confidence suggests, so the
+// bar for acting on them moves up. Caller must hold pl.mu (write lock).
+func (pl *PredictionLogic) maybeRetuneAdaptiveThreshold() {
+	samples := pl.calibrationSamples()
+	if len(samples) < calibrationMinSamples {
+		return
+	}
+
+	if expectedCalibrationError(samples) <= calibrationECEHigh {
+		return
+	}
+
+	var confSum float64
+	for _, s := range samples {
+		confSum += s.confidence
+	}
+	if confSum/float64(len(samples)) <= calibrationAccuracy(samples) {
+		return
+	}
+
+	threshold := pl.config.AdaptiveThreshold + calibrationTuneStep
+	if threshold > 1.0 {
+		threshold = 1.0
+	}
+	pl.config.AdaptiveThreshold = threshold
+}
+
+// GetCalibrationStats returns the calibration metrics derived from every
+// outcome-labeled prediction recorded via RecordOutcome: empirical
+// accuracy, Brier score, Expected Calibration Error, the confidence
+// distribution's mode, and the sample count they're computed from.
+func (pl *PredictionLogic) GetCalibrationStats() map[string]interface{} {
+	pl.mu.RLock()
+	samples := pl.calibrationSamples()
+	pl.mu.RUnlock()
+
+	return map[string]interface{}{
+		"samples":         len(samples),
+		"accuracy":        calibrationAccuracy(samples),
+		"brier_score":     brierScore(samples),
+		"ece":             expectedCalibrationError(samples),
+		"confidence_mode": confidenceMode(samples),
+	}
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PredictionLogic implements prometheus.Collector directly (rather than
+// only pushing through the optional RegisterMetrics bundle in metrics.go),
+// so operators can register *PredictionLogic with any registry and get a
+// live view of cache/learning state computed fresh at every scrape instead
+// of polling GetStats.
+var (
+	collectorCacheSizeDesc = prometheus.NewDesc(
+		"kronop_predictions_cache_size",
+		"Current number of entries in the prediction cache.",
+		nil, nil,
+	)
+	collectorLearningUpdatesDesc = prometheus.NewDesc(
+		"kronop_predictions_learning_updates_total",
+		"Total prediction entries that have received a learning update.",
+		nil, nil,
+	)
+	collectorSuccessRateDesc = prometheus.NewDesc(
+		"kronop_predictions_success_rate",
+		"Sliding-window prediction success rate (see slidingSuccessWindow).",
+		nil, nil,
+	)
+	collectorLearningRateDesc = prometheus.NewDesc(
+		"kronop_predictions_learning_rate",
+		"Effective SGD learning rate (configured rate, halved temporarily under cgroup v2 memory pressure).",
+		nil, nil,
+	)
+	collectorAdaptationThresholdDesc = prometheus.NewDesc(
+		"kronop_predictions_adaptation_threshold",
+		"Configured adaptive confidence threshold.",
+		nil, nil,
+	)
+)
+
+// Describe implements prometheus.Collector.
+func (pl *PredictionLogic) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collectorCacheSizeDesc
+	ch <- collectorLearningUpdatesDesc
+	ch <- collectorSuccessRateDesc
+	ch <- collectorLearningRateDesc
+	ch <- collectorAdaptationThresholdDesc
+	pl.confidenceHist.Describe(ch)
+}
+
+// Collect implements prometheus.Collector. Every gauge is computed from
+// current state at scrape time (a pull model) so cache_size and
+// success_rate always reflect the present moment rather than whatever was
+// last pushed.
+func (pl *PredictionLogic) Collect(ch chan<- prometheus.Metric) {
+	pl.mu.RLock()
+	cacheSize := float64(pl.store.Len())
+	learningRate := pl.currentLearningRate()
+	adaptationThreshold := pl.config.AdaptiveThreshold
+	pl.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(collectorCacheSizeDesc, prometheus.GaugeValue, cacheSize)
+	ch <- prometheus.MustNewConstMetric(collectorLearningUpdatesDesc, prometheus.CounterValue, float64(pl.getTotalLearningUpdates()))
+	ch <- prometheus.MustNewConstMetric(collectorSuccessRateDesc, prometheus.GaugeValue, pl.getSuccessRate())
+	ch <- prometheus.MustNewConstMetric(collectorLearningRateDesc, prometheus.GaugeValue, learningRate)
+	ch <- prometheus.MustNewConstMetric(collectorAdaptationThresholdDesc, prometheus.GaugeValue, adaptationThreshold)
+
+	pl.confidenceHist.Collect(ch)
+}
+
+// MetricsHandler mounts pl as a Collector on its own registry and returns a
+// ready-to-serve /metrics handler (via promhttp.Handler), for callers that
+// want prediction-logic stats on a dedicated endpoint rather than folded
+// into a shared application registry.
+func (pl *PredictionLogic) MetricsHandler() http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(pl)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
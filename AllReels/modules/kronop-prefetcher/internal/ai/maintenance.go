@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultMaintenanceTargetSweep is the full-sweep duration target used when
+// PredictionConfig.PredictionWindow isn't set, so the maintenance loop still
+// has a sane pace against an empty/default config.
+const defaultMaintenanceTargetSweep = time.Minute
+
+// maintenanceSweepFraction is the fraction of PredictionWindow used as the
+// full-sweep duration target T, mirroring Prometheus's adaptive fingerprint
+// sweep (sweep the whole series map in roughly 1/10th of its retention).
+const maintenanceSweepFraction = 10
+
+// maintenanceMinWait/maintenanceMaxWait clamp the per-entry wait derived
+// from T/N so neither a tiny cache (huge wait) nor a huge cache (near-zero
+// wait) makes the loop pathological.
+const (
+	maintenanceMinWait = 10 * time.Millisecond
+	maintenanceMaxWait = 10 * time.Second
+)
+
+// maintenancePressureThreshold is the load factor (entries / maxCacheSize)
+// above which the sweep speeds up.
+const maintenancePressureThreshold = 0.8
+
+// maintenancePressureFactor scales how aggressively the wait is divided down
+// once pressure clears maintenancePressureThreshold.
+const maintenancePressureFactor = 4.0
+
+// maintenanceConfidenceFloor is the confidence below which a previously-used
+// entry is considered stale enough to evict early, even before its TTL.
+const maintenanceConfidenceFloor = 0.2
+
+// cgroupMemoryShedThreshold is the MemoryUsage()/MemoryLimit() ratio above
+// which a maintenance tick immediately sheds the bottom-confidence
+// cgroupMemoryShedFraction of entries, instead of waiting for TTL/staleness
+// eviction to catch up.
+const cgroupMemoryShedThreshold = 0.85
+
+// cgroupMemoryShedFraction is the fraction of cached entries (by ascending
+// confidence) shed once cgroupMemoryShedThreshold is crossed.
+const cgroupMemoryShedFraction = 0.10
+
+// cgroupPSIPressureThreshold is the cgroup v2 memory.pressure some-avg10
+// percentage above which the SGD learning rate (see currentLearningRate)
+// is temporarily halved, to back off training while the container is
+// actively thrashing on memory rather than waiting for usage to reach
+// cgroupMemoryShedThreshold.
+const cgroupPSIPressureThreshold = 10.0
+
+// Start launches the adaptive cache maintenance loop, and the resource
+// watcher if one was configured via WithWatcher, in the background. It
+// returns immediately; the maintenance loop runs until ctx is canceled.
+func (pl *PredictionLogic) Start(ctx context.Context) {
+	go pl.maintainCache(ctx)
+	if pl.watcher != nil {
+		pl.watcher.Start()
+	}
+}
+
+// maintainCache repeatedly walks the cache store in key-hash order, evicting
+// entries past their TTL or whose confidence has fallen below
+// maintenanceConfidenceFloor after having been used. The wait between
+// per-entry evictions is recomputed at the start of every sweep from the
+// current cache size, so a sweep started under heavy load speeds itself up
+// instead of lagging behind new entries, modeled on Prometheus's adaptive
+// fingerprint-mapping sweep.
+func (pl *PredictionLogic) maintainCache(ctx context.Context) {
+	for {
+		pl.applyCgroupPressure()
+
+		userIDs := pl.sweepOrder()
+		wait := pl.maintenanceSweepWait(len(userIDs))
+		pl.maintenanceWaitMs.Store(wait.Milliseconds())
+		pl.refreshStatsLocked()
+
+		if len(userIDs) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		for _, userID := range userIDs {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			if pl.evictIfStale(userID) {
+				pl.evictionsTotal.Add(1)
+			}
+		}
+	}
+}
+
+// maintenanceSweepWait computes the per-entry wait for a sweep over n
+// entries: clamp(T/n, maintenanceMinWait, maintenanceMaxWait), then divided
+// down further in proportion to cache pressure once the store is more than
+// maintenancePressureThreshold full.
+func (pl *PredictionLogic) maintenanceSweepWait(n int) time.Duration {
+	if n <= 0 {
+		return maintenanceMaxWait
+	}
+
+	target := pl.config.PredictionWindow / maintenanceSweepFraction
+	if target <= 0 {
+		target = defaultMaintenanceTargetSweep
+	}
+
+	wait := target / time.Duration(n)
+	if wait < maintenanceMinWait {
+		wait = maintenanceMinWait
+	}
+	if wait > maintenanceMaxWait {
+		wait = maintenanceMaxWait
+	}
+
+	maxSize := pl.maxCacheSize
+	if maxSize <= 0 {
+		maxSize = defaultCacheMaxSize
+	}
+	pressure := float64(n) / float64(maxSize)
+	if pressure > maintenancePressureThreshold {
+		wait = time.Duration(float64(wait) / (pressure*maintenancePressureFactor + 1))
+	}
+
+	return wait
+}
+
+// sweepOrder returns every userID currently in the store, ordered by the
+// fnv32a hash of the key rather than by store iteration order (unspecified
+// per CacheStore.Range), so a sweep's evictions are spread evenly across the
+// keyspace rather than biased by whatever internal order the backend walks
+// in.
+func (pl *PredictionLogic) sweepOrder() []string {
+	var userIDs []string
+	pl.store.Range(func(userID string, _ *PredictionEntry) bool {
+		userIDs = append(userIDs, userID)
+		return true
+	})
+
+	sort.Slice(userIDs, func(i, j int) bool {
+		return fnv32a(userIDs[i]) < fnv32a(userIDs[j])
+	})
+	return userIDs
+}
+
+// applyCgroupPressure checks container memory usage and (cgroup v2 only)
+// PSI memory pressure once per maintenance tick. A crossed usage ratio
+// sheds entries immediately since it means the cache is already eating
+// into the container's limit; crossed PSI pressure instead halves the
+// learning rate, since active memory thrashing is a cheaper signal to
+// react to than waiting for usage itself to climb. Both reset to normal
+// once the reading clears, so the throttling is temporary by construction.
+func (pl *PredictionLogic) applyCgroupPressure() {
+	if pl.cgroupReader == nil {
+		return
+	}
+
+	if usage, err := pl.cgroupReader.MemoryUsage(); err == nil {
+		if limit, err := pl.cgroupReader.MemoryLimit(); err == nil && limit > 0 {
+			if ratio := float64(usage) / float64(limit); ratio > cgroupMemoryShedThreshold {
+				if shed := pl.shedLowConfidence(cgroupMemoryShedFraction); shed > 0 {
+					logrus.Warnf("📉 Shed %d low-confidence predictions: cgroup memory usage at %.0f%% of limit", shed, ratio*100)
+				}
+			}
+		}
+	}
+
+	scale := 1.0
+	if psi, err := pl.cgroupReader.MemoryPressure(); err == nil && psi > cgroupPSIPressureThreshold {
+		scale = 0.5
+		logrus.Warnf("🐢 Halving learning rate: cgroup memory.pressure some-avg10=%.1f exceeds threshold=%.1f", psi, cgroupPSIPressureThreshold)
+	}
+	pl.learningRateScale.Store(math.Float64bits(scale))
+}
+
+// shedLowConfidence immediately evicts the bottom-confidence fraction of
+// cached entries (persisted SGD model snapshots, which carry a nil
+// Prediction, are left alone), for applyCgroupPressure to call once
+// container memory usage crosses cgroupMemoryShedThreshold. Returns the
+// number of entries shed.
+func (pl *PredictionLogic) shedLowConfidence(fraction float64) int {
+	type scoredEntry struct {
+		userID     string
+		confidence float64
+	}
+
+	var entries []scoredEntry
+	pl.store.Range(func(userID string, entry *PredictionEntry) bool {
+		if entry.Prediction == nil {
+			return true
+		}
+		entries = append(entries, scoredEntry{userID, entry.Confidence})
+		return true
+	})
+	if len(entries) == 0 {
+		return 0
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].confidence < entries[j].confidence })
+
+	n := int(float64(len(entries)) * fraction)
+	if n <= 0 {
+		n = 1
+	}
+	if n > len(entries) {
+		n = len(entries)
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for _, e := range entries[:n] {
+		pl.store.Delete(e.userID)
+		pl.evictionsTotal.Add(1)
+	}
+	pl.refreshStats()
+	return n
+}
+
+// fnv32a hashes s with FNV-1a, used only to order a maintenance sweep.
+func fnv32a(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// evictIfStale removes userID's entry if it's past its TTL or stale (used
+// and below maintenanceConfidenceFloor), returning whether it evicted
+// anything. Entries with a nil Prediction (e.g. persisted SGD model
+// snapshots stored under a reserved key) are left alone.
+func (pl *PredictionLogic) evictIfStale(userID string) bool {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	entry, ok := pl.store.Get(userID)
+	if !ok || entry.Prediction == nil {
+		return false
+	}
+
+	expired := time.Now().After(entry.ExpiresAt)
+	stale := entry.Used && entry.Confidence < maintenanceConfidenceFloor
+	if !expired && !stale {
+		return false
+	}
+
+	pl.store.Delete(userID)
+	pl.refreshStats()
+	logrus.Debugf("🧹 Evicted prediction for %s during cache maintenance (expired=%v stale=%v)", userID, expired, stale)
+	return true
+}
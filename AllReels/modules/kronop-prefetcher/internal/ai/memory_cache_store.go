@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memoryCacheStore is an in-memory CacheStore with real LRU eviction via
+// container/list, replacing the old "evict oldest" loop in cachePrediction
+// that actually evicted a random key because it broke out on the first
+// iteration instead of scanning for the minimum CreatedAt.
+type memoryCacheStore struct {
+	mu      sync.Mutex
+	maxSize int
+	ll      *list.List
+	items   map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	userID string
+	entry  *PredictionEntry
+}
+
+// newMemoryCacheStore creates an in-memory CacheStore holding at most
+// maxSize entries; maxSize <= 0 means unbounded.
+func newMemoryCacheStore(maxSize int) *memoryCacheStore {
+	return &memoryCacheStore{
+		maxSize: maxSize,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+func (s *memoryCacheStore) Get(userID string) (*PredictionEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[userID]
+	if !ok {
+		return nil, false
+	}
+	s.ll.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true
+}
+
+func (s *memoryCacheStore) Put(userID string, entry *PredictionEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[userID]; ok {
+		el.Value.(*memoryCacheItem).entry = entry
+		s.ll.MoveToFront(el)
+		return
+	}
+
+	if s.maxSize > 0 && len(s.items) >= s.maxSize {
+		s.evictLRU()
+	}
+
+	el := s.ll.PushFront(&memoryCacheItem{userID: userID, entry: entry})
+	s.items[userID] = el
+}
+
+// evictLRU removes the least-recently-used entry. Caller must hold s.mu.
+func (s *memoryCacheStore) evictLRU() {
+	el := s.ll.Back()
+	if el == nil {
+		return
+	}
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*memoryCacheItem).userID)
+}
+
+func (s *memoryCacheStore) Delete(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[userID]; ok {
+		s.ll.Remove(el)
+		delete(s.items, userID)
+	}
+}
+
+func (s *memoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *memoryCacheStore) Range(fn func(userID string, entry *PredictionEntry) bool) {
+	s.mu.Lock()
+	items := make([]*memoryCacheItem, 0, s.ll.Len())
+	for el := s.ll.Front(); el != nil; el = el.Next() {
+		items = append(items, el.Value.(*memoryCacheItem))
+	}
+	s.mu.Unlock()
+
+	for _, item := range items {
+		if !fn(item.userID, item.entry) {
+			return
+		}
+	}
+}
+
+func (s *memoryCacheStore) PurgeExpired(now time.Time) int {
+	s.mu.Lock()
+	var expired []string
+	for el := s.ll.Front(); el != nil; el = el.Next() {
+		item := el.Value.(*memoryCacheItem)
+		if now.After(item.entry.ExpiresAt) {
+			expired = append(expired, item.userID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, userID := range expired {
+		s.Delete(userID)
+	}
+	return len(expired)
+}
+
+func (s *memoryCacheStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ll = list.New()
+	s.items = make(map[string]*list.Element)
+}
+
+func (s *memoryCacheStore) Close() error { return nil }
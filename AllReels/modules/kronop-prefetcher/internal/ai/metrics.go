@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// predictionMetrics bundles the Prometheus collectors a PredictionLogic
+// records against. It's nil on a PredictionLogic created without the
+// RegisterMetrics option, so every call site guards on pl.metrics != nil.
+type predictionMetrics struct {
+	predictionsTotal *prometheus.CounterVec // labels: result (created|cache_hit|expired)
+	cacheSize        prometheus.Gauge
+	userTypeCount    *prometheus.GaugeVec // labels: user_type
+	confidence       prometheus.Histogram
+	expectedImpact   prometheus.Histogram
+	validationScore  prometheus.Histogram
+	predictLatency   prometheus.Histogram
+}
+
+// newPredictionMetrics builds and registers a predictionMetrics bundle on
+// reg, with every collector labelled by instance so several PredictionLogic
+// instances (e.g. one per shard) can share a single registry.
+func newPredictionMetrics(reg prometheus.Registerer, instance string) *predictionMetrics {
+	labels := prometheus.Labels{"instance": instance}
+
+	m := &predictionMetrics{
+		predictionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "predictions_total",
+			Help:        "Predictions handled by PredictBehavior, by result.",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		cacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "cache_size",
+			Help:        "Current number of entries in the prediction cache.",
+			ConstLabels: labels,
+		}),
+		userTypeCount: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "cached_user_type_count",
+			Help:        "Cached predictions currently held per user type.",
+			ConstLabels: labels,
+		}, []string{"user_type"}),
+		confidence: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "confidence",
+			Help:        "Confidence of newly created predictions.",
+			Buckets:     prometheus.LinearBuckets(0, 0.1, 11),
+			ConstLabels: labels,
+		}),
+		expectedImpact: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "expected_impact",
+			Help:        "Expected impact of newly created predictions.",
+			Buckets:     prometheus.LinearBuckets(0, 0.1, 11),
+			ConstLabels: labels,
+		}),
+		validationScore: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "validation_score",
+			Help:        "Validation score of newly created predictions.",
+			Buckets:     prometheus.LinearBuckets(0, 0.1, 11),
+			ConstLabels: labels,
+		}),
+		predictLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "kronop",
+			Subsystem:   "prediction",
+			Name:        "predict_behavior_duration_seconds",
+			Help:        "End-to-end PredictBehavior latency.",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: labels,
+		}),
+	}
+
+	reg.MustRegister(m.predictionsTotal, m.cacheSize, m.userTypeCount,
+		m.confidence, m.expectedImpact, m.validationScore, m.predictLatency)
+	return m
+}
+
+// observePrediction records a newly created prediction's confidence,
+// expected impact and validation score.
+func (m *predictionMetrics) observePrediction(p *BehaviorPrediction) {
+	if m == nil {
+		return
+	}
+	m.confidence.Observe(p.Confidence)
+	m.expectedImpact.Observe(p.ExpectedImpact)
+	m.validationScore.Observe(p.ValidationScore)
+}
+
+// observeLatency records how long a PredictBehavior call took, measured
+// from start.
+func (m *predictionMetrics) observeLatency(start time.Time) {
+	if m == nil {
+		return
+	}
+	m.predictLatency.Observe(time.Since(start).Seconds())
+}
+
+// countResult increments predictions_total{result=result}.
+func (m *predictionMetrics) countResult(result string) {
+	if m == nil {
+		return
+	}
+	m.predictionsTotal.WithLabelValues(result).Inc()
+}
+
+// refreshCacheGauges recomputes cache_size and cached_user_type_count from
+// the current contents of store.
+func (m *predictionMetrics) refreshCacheGauges(store CacheStore) {
+	if m == nil {
+		return
+	}
+
+	m.cacheSize.Set(float64(store.Len()))
+
+	byType := make(map[string]int)
+	store.Range(func(_ string, entry *PredictionEntry) bool {
+		if entry.Prediction != nil {
+			byType[entry.Prediction.UserType]++
+		}
+		return true
+	})
+	m.userTypeCount.Reset()
+	for userType, count := range byType {
+		m.userTypeCount.WithLabelValues(userType).Set(float64(count))
+	}
+}
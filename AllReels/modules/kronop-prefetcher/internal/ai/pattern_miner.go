@@ -0,0 +1,372 @@
+package ai
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/tracker"
+)
+
+// defaultMinerMaxDepth bounds how many tokens of an event sequence are used
+// to walk the prefix tree before falling back to leaf-level clustering.
+const defaultMinerMaxDepth = 4
+
+// defaultMinerSimThreshold is the Jaccard similarity (on token sets) a
+// sequence must clear against an existing cluster's template to be merged
+// into it instead of starting a new cluster.
+const defaultMinerSimThreshold = 0.5
+
+// patternCluster is one Drain-style leaf cluster: a group of event
+// sequences considered "the same pattern", plus what users did next after
+// each occurrence.
+type patternCluster struct {
+	id            int
+	template      []string // token template, wildcarded where members disagree
+	tokenSet      map[string]struct{}
+	count         int
+	continuations map[int]int // NextReelID delta -> times observed
+	interArrivals []time.Duration
+	lastSeen      time.Time
+}
+
+// drainNode is one level of the prefix tree, keyed by token at that depth.
+type drainNode struct {
+	children map[string]*drainNode
+	clusters []*patternCluster // populated once this node is a leaf
+}
+
+// PatternMiner groups scroll/watch/interaction event sequences into
+// templates using the Drain log-clustering algorithm: a fixed-depth prefix
+// tree keyed by coarse-bucketed event tokens, with leaves holding clusters
+// of similar sequences merged via Jaccard similarity. The mined cluster's
+// historical continuation distribution (what NextReelID delta followed a
+// given pattern, and how often) lets predictions be driven by observed
+// behavior instead of only the hand-tuned thresholds in
+// adjustPredictionBasedOnPatterns.
+type PatternMiner struct {
+	mu            sync.Mutex
+	root          *drainNode
+	maxDepth      int
+	simThreshold  float64
+	nextClusterID int
+}
+
+// NewPatternMiner creates a PatternMiner. maxDepth bounds how many tokens of
+// a sequence are used to descend the prefix tree before clustering at the
+// leaf; simThreshold is the minimum Jaccard similarity for merging into an
+// existing cluster.
+func NewPatternMiner(maxDepth int, simThreshold float64) *PatternMiner {
+	if maxDepth <= 0 {
+		maxDepth = defaultMinerMaxDepth
+	}
+	if simThreshold <= 0 {
+		simThreshold = defaultMinerSimThreshold
+	}
+	return &PatternMiner{
+		root:         &drainNode{children: make(map[string]*drainNode)},
+		maxDepth:     maxDepth,
+		simThreshold: simThreshold,
+	}
+}
+
+// tokenize converts an event sequence into a sequence of coarse tokens:
+// event type plus a bucketed feature (scroll-speed bucket, watch-duration
+// bucket, or interaction kind), so near-identical behavior maps to the same
+// token regardless of exact values.
+func tokenize(events []tracker.UserEvent) []string {
+	tokens := make([]string, 0, len(events))
+	for _, event := range events {
+		tokens = append(tokens, tokenizeEvent(event))
+	}
+	return tokens
+}
+
+func tokenizeEvent(event tracker.UserEvent) string {
+	data, ok := event.Data.(map[string]interface{})
+	if !ok {
+		return event.Type
+	}
+
+	switch event.Type {
+	case "scroll":
+		speed, _ := data["scroll_speed"].(float64)
+		return "scroll:" + bucketize(speed, 1.0)
+	case "watch":
+		watchTime, _ := data["watch_time"].(float64)
+		return "watch:" + bucketize(watchTime, 10.0)
+	case "interaction":
+		kind, _ := data["type"].(string)
+		return "interaction:" + kind
+	default:
+		return event.Type
+	}
+}
+
+// bucketize maps v into a coarse decile-like bucket sized by width, so e.g.
+// scroll speeds of 4.2 and 4.6 land in the same token.
+func bucketize(v, width float64) string {
+	if width <= 0 {
+		width = 1.0
+	}
+	bucket := int(v / width)
+	return strconv.Itoa(bucket)
+}
+
+// Ingest walks the prefix tree for events' token sequence, merging it into
+// the best-matching cluster at the resulting leaf (creating one if no
+// existing cluster clears simThreshold), and returns that cluster's ID and
+// current support (number of sequences merged into it so far, including
+// this one).
+func (pm *PatternMiner) Ingest(events []tracker.UserEvent) (clusterID int, support int) {
+	if pm == nil || len(events) == 0 {
+		return 0, 0
+	}
+
+	tokens := tokenize(events)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	node := pm.root
+	depth := pm.maxDepth
+	if len(tokens) < depth {
+		depth = len(tokens)
+	}
+	for i := 0; i < depth; i++ {
+		key := tokens[i]
+		child, ok := node.children[key]
+		if !ok {
+			child = &drainNode{children: make(map[string]*drainNode)}
+			node.children[key] = child
+		}
+		node = child
+	}
+
+	cluster := pm.mergeAtLeaf(node, tokens)
+	return cluster.id, cluster.count
+}
+
+// mergeAtLeaf finds the best-matching cluster at node for tokens (by
+// Jaccard similarity on token sets; a token repeated in a sequence counts
+// once), merges into it if similarity
+// clears simThreshold, or creates a new cluster otherwise. Caller must hold
+// pm.mu.
+func (pm *PatternMiner) mergeAtLeaf(node *drainNode, tokens []string) *patternCluster {
+	tokenSet := toSet(tokens)
+
+	var best *patternCluster
+	bestSim := 0.0
+	for _, c := range node.clusters {
+		sim := jaccard(tokenSet, c.tokenSet)
+		if sim > bestSim {
+			bestSim = sim
+			best = c
+		}
+	}
+
+	now := time.Now()
+
+	if best != nil && bestSim >= pm.simThreshold {
+		if !best.lastSeen.IsZero() {
+			best.interArrivals = append(best.interArrivals, now.Sub(best.lastSeen))
+		}
+		best.lastSeen = now
+		best.count++
+		best.template = wildcard(best.template, tokens)
+		return best
+	}
+
+	pm.nextClusterID++
+	cluster := &patternCluster{
+		id:            pm.nextClusterID,
+		template:      append([]string(nil), tokens...),
+		tokenSet:      tokenSet,
+		count:         1,
+		continuations: make(map[int]int),
+		lastSeen:      now,
+	}
+	node.clusters = append(node.clusters, cluster)
+	return cluster
+}
+
+// RecordContinuation records that nextReelIDDelta was the chosen next step
+// the last time clusterID matched, building up the historical continuation
+// distribution PredictContinuation draws from.
+func (pm *PatternMiner) RecordContinuation(clusterID int, nextReelIDDelta int) {
+	if pm == nil {
+		return
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	c := pm.findCluster(clusterID)
+	if c == nil {
+		return
+	}
+	c.continuations[nextReelIDDelta]++
+}
+
+// PredictContinuation returns the most frequently observed NextReelID delta
+// for clusterID and its confidence (share of continuations it accounts
+// for). ok is false if the cluster has no recorded continuations yet.
+func (pm *PatternMiner) PredictContinuation(clusterID int) (delta int, confidence float64, ok bool) {
+	if pm == nil {
+		return 0, 0, false
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	c := pm.findCluster(clusterID)
+	if c == nil || len(c.continuations) == 0 {
+		return 0, 0, false
+	}
+
+	total := 0
+	bestDelta := 0
+	bestCount := 0
+	for d, count := range c.continuations {
+		total += count
+		if count > bestCount {
+			bestCount = count
+			bestDelta = d
+		}
+	}
+	if total == 0 {
+		return 0, 0, false
+	}
+	return bestDelta, float64(bestCount) / float64(total), true
+}
+
+// findCluster walks the whole tree looking for clusterID. Caller must hold
+// pm.mu. Cluster counts stay small enough (bounded by distinct behavior
+// patterns, not by event volume) that a full walk is cheap.
+func (pm *PatternMiner) findCluster(clusterID int) *patternCluster {
+	var found *patternCluster
+	var walk func(n *drainNode)
+	walk = func(n *drainNode) {
+		if found != nil {
+			return
+		}
+		for _, c := range n.clusters {
+			if c.id == clusterID {
+				found = c
+				return
+			}
+		}
+		for _, child := range n.children {
+			walk(child)
+			if found != nil {
+				return
+			}
+		}
+	}
+	walk(pm.root)
+	return found
+}
+
+// PatternSummary describes one mined cluster for operator inspection via
+// GetPatterns.
+type PatternSummary struct {
+	ClusterID  int      `json:"cluster_id"`
+	Template   []string `json:"template"`
+	Support    int      `json:"support"`
+	Confidence float64  `json:"confidence"` // share of continuations the top delta accounts for
+}
+
+// GetPatterns returns every mined cluster's template, support count, and
+// continuation confidence, so operators can inspect what behavior patterns
+// have emerged from live traffic.
+func (pm *PatternMiner) GetPatterns() []PatternSummary {
+	if pm == nil {
+		return nil
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	var summaries []PatternSummary
+	var walk func(n *drainNode)
+	walk = func(n *drainNode) {
+		for _, c := range n.clusters {
+			_, confidence, _ := pm.predictContinuationLocked(c)
+			summaries = append(summaries, PatternSummary{
+				ClusterID:  c.id,
+				Template:   append([]string(nil), c.template...),
+				Support:    c.count,
+				Confidence: confidence,
+			})
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(pm.root)
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Support > summaries[j].Support })
+	return summaries
+}
+
+// predictContinuationLocked is PredictContinuation's body for a cluster
+// already resolved under pm.mu.
+func (pm *PatternMiner) predictContinuationLocked(c *patternCluster) (delta int, confidence float64, ok bool) {
+	if len(c.continuations) == 0 {
+		return 0, 0, false
+	}
+	total := 0
+	bestDelta := 0
+	bestCount := 0
+	for d, count := range c.continuations {
+		total += count
+		if count > bestCount {
+			bestCount = count
+			bestDelta = d
+		}
+	}
+	if total == 0 {
+		return 0, 0, false
+	}
+	return bestDelta, float64(bestCount) / float64(total), true
+}
+
+func toSet(tokens []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tokens))
+	for _, t := range tokens {
+		set[t] = struct{}{}
+	}
+	return set
+}
+
+// jaccard computes |a ∩ b| / |a ∪ b| for two token sets.
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1.0
+	}
+	intersection := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// wildcard merges newTokens into template, replacing any position where
+// they disagree with a wildcard token ("*"), Drain-style.
+func wildcard(template, newTokens []string) []string {
+	n := len(template)
+	if len(newTokens) < n {
+		n = len(newTokens)
+	}
+	merged := append([]string(nil), template...)
+	for i := 0; i < n; i++ {
+		if merged[i] != newTokens[i] {
+			merged[i] = "*"
+		}
+	}
+	return merged
+}
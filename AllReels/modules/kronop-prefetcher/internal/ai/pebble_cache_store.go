@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+)
+
+// pebbleCacheStore is a CacheStore backed by a Pebble key-value store on
+// disk, keyed by userID with JSON-serialized PredictionEntry values, so
+// predictions survive a process restart instead of starting cold every
+// time. Every SSTable gets a bloom filter so a lookup for a userID we've
+// never predicted for stays cheap instead of reading blocks off disk.
+type pebbleCacheStore struct {
+	db *pebble.DB
+}
+
+// newPebbleCacheStore opens (creating if needed) a Pebble database at dir.
+func newPebbleCacheStore(dir string) (*pebbleCacheStore, error) {
+	opts := &pebble.Options{
+		Levels: make([]pebble.LevelOptions, 7),
+	}
+	for i := range opts.Levels {
+		opts.Levels[i].FilterPolicy = bloom.FilterPolicy(10)
+	}
+
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pebble prediction cache at %s: %v", dir, err)
+	}
+	return &pebbleCacheStore{db: db}, nil
+}
+
+func (s *pebbleCacheStore) Get(userID string) (*PredictionEntry, bool) {
+	value, closer, err := s.db.Get([]byte(userID))
+	if err != nil {
+		return nil, false
+	}
+	defer closer.Close()
+
+	var entry PredictionEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (s *pebbleCacheStore) Put(userID string, entry *PredictionEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_ = s.db.Set([]byte(userID), data, pebble.Sync)
+}
+
+func (s *pebbleCacheStore) Delete(userID string) {
+	_ = s.db.Delete([]byte(userID), pebble.Sync)
+}
+
+func (s *pebbleCacheStore) Len() int {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return 0
+	}
+	defer iter.Close()
+
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		count++
+	}
+	return count
+}
+
+func (s *pebbleCacheStore) Range(fn func(userID string, entry *PredictionEntry) bool) {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		var entry PredictionEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			continue
+		}
+		if !fn(string(iter.Key()), &entry) {
+			return
+		}
+	}
+}
+
+// PurgeExpired deletes every entry past its ExpiresAt and compacts the
+// range those tombstones fell in, so TTL expiry actually reclaims disk
+// space instead of letting deleted keys accumulate across restarts.
+func (s *pebbleCacheStore) PurgeExpired(now time.Time) int {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return 0
+	}
+
+	var expired [][]byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		var entry PredictionEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			continue
+		}
+		if now.After(entry.ExpiresAt) {
+			expired = append(expired, append([]byte(nil), iter.Key()...))
+		}
+	}
+	iter.Close()
+
+	if len(expired) == 0 {
+		return 0
+	}
+
+	batch := s.db.NewBatch()
+	for _, key := range expired {
+		_ = batch.Delete(key, nil)
+	}
+	_ = batch.Commit(pebble.Sync)
+
+	_ = s.db.Compact(nil, []byte{0xFF}, true)
+
+	return len(expired)
+}
+
+func (s *pebbleCacheStore) Clear() {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return
+	}
+
+	batch := s.db.NewBatch()
+	for iter.First(); iter.Valid(); iter.Next() {
+		_ = batch.Delete(iter.Key(), nil)
+	}
+	iter.Close()
+	_ = batch.Commit(pebble.Sync)
+}
+
+func (s *pebbleCacheStore) Close() error {
+	return s.db.Close()
+}
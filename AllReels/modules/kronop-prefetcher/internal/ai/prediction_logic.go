@@ -3,19 +3,87 @@ package ai
 import (
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kronop/prefetcher/internal/analyzer"
+	"github.com/kronop/prefetcher/internal/cgroup"
 	"github.com/kronop/prefetcher/internal/tracker"
+	"github.com/montanaflynn/stats"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
 // PredictionLogic handles AI-based prediction logic
 type PredictionLogic struct {
-	analyzer analyzer.BehaviorAnalyzer
-	config    PredictionConfig
-	cache     *PredictionCache
-	mu        sync.RWMutex
+	analyzer     analyzer.BehaviorAnalyzer
+	config       PredictionConfig
+	store        CacheStore
+	maxCacheSize int
+	mu           sync.RWMutex
+	metrics      *predictionMetrics
+	profiler     *Profiler
+	miner        *PatternMiner
+	model        *LearningModel
+
+	maintenanceWaitMs atomic.Int64
+	evictionsTotal    atomic.Int64
+
+	// cgroupReader sizes maxCacheSize against container memory and drives
+	// applyCgroupPressure's shedding/learning-rate throttling (see
+	// maintenance.go). Defaults to cgroup.Default() (autodetected v2/v1/
+	// runtime-fallback).
+	cgroupReader cgroup.Reader
+	// learningRateScale multiplies config.LearningRate, stored as
+	// math.Float64bits so applyCgroupPressure can halve it under cgroup v2
+	// memory.pressure without taking pl.mu. 1.0 (no throttling) by default.
+	learningRateScale atomic.Uint64
+
+	// stats is the atomically-published statsSnapshot GetCacheStats/
+	// GetLearningStats read lock-free from (see stats_snapshot.go).
+	stats atomic.Pointer[statsSnapshot]
+
+	successWindow  *slidingSuccessWindow
+	watcher        *Watcher
+	confidenceHist prometheus.Histogram
+}
+
+// Option configures a PredictionLogic at construction time.
+type Option func(*PredictionLogic)
+
+// RegisterMetrics registers this PredictionLogic's Prometheus collectors
+// (predictions created/served-from-cache/expired, cache size and
+// per-UserType counts, and confidence/expected-impact/validation-score/
+// PredictBehavior-latency histograms) on reg. instance labels every
+// collector so multiple PredictionLogic instances can share a registry
+// without their metrics colliding.
+func RegisterMetrics(reg prometheus.Registerer, instance string) Option {
+	return func(pl *PredictionLogic) {
+		pl.metrics = newPredictionMetrics(reg, instance)
+	}
+}
+
+// WithWatcher attaches a self-watching resource guard (see watcher.go) that
+// samples goroutine count, heap usage, cache size and the sliding-window
+// success rate, firing a pprof capture and Reporter event when goroutines,
+// cache size or success rate crosses its configured threshold. Heap usage
+// is reported in each event but has no threshold of its own. The watcher starts when Start is called and
+// stops alongside Stop.
+func WithWatcher(config WatcherConfig) Option {
+	return func(pl *PredictionLogic) {
+		pl.watcher = NewWatcher(pl, config)
+	}
+}
+
+// WithCgroupReader overrides the cgroup.Reader PredictionLogic sizes its
+// cache and throttles learning against (see cgroupSizedMaxCacheSize and
+// applyCgroupPressure), for tests or a non-default mountpoint. Defaults to
+// cgroup.Default(), which autodetects cgroup v2, falls back to v1, and
+// falls back further to runtime heap stats outside any cgroup.
+func WithCgroupReader(reader cgroup.Reader) Option {
+	return func(pl *PredictionLogic) {
+		pl.cgroupReader = reader
+	}
 }
 
 // PredictionConfig holds prediction configuration
@@ -27,23 +95,31 @@ type PredictionConfig struct {
 	AdaptiveThreshold       float64       `yaml:"adaptive_threshold"`
 	EnableLearning          bool          `yaml:"enable_learning"`
 	LearningRate            float64       `yaml:"learning_rate"`
+	EnableProfiling         bool          `yaml:"enable_profiling"`
 }
 
-// PredictionCache caches recent predictions
-type PredictionCache struct {
-	predictions map[string]*PredictionEntry
-	mu         sync.RWMutex
-	maxSize    int
-}
-
-// PredictionEntry represents a cached prediction
+// PredictionEntry represents a cached prediction. Features holds the
+// feature vector the prediction was made from, so UpdateLearning can train
+// the per-UserType SGD model against it later. The same struct, under a
+// reserved UserID, also doubles as the persisted snapshot of a UserType's
+// SGD model: Features then holds [w..., b] and Confidence holds the sample
+// count (see modelKey, PredictionLogic.loadWeights/saveWeights).
 type PredictionEntry struct {
-	UserID       string
-	Prediction  *BehaviorPrediction
-	CreatedAt   time.Time
-	ExpiresAt   time.Time
-	Used        bool
-	Confidence  float64
+	UserID     string
+	Prediction *BehaviorPrediction
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	Used       bool
+	Confidence float64
+	Features   []float64
+
+	// Outcome and OutcomeSet hold the ground truth recorded by
+	// RecordOutcome (see calibration.go), kept separate from Confidence so
+	// getSuccessRate/calibration metrics don't conflate predicted
+	// confidence with observed correctness. OutcomeSet distinguishes
+	// "recorded false" from "never recorded".
+	Outcome    bool
+	OutcomeSet bool
 }
 
 // BehaviorPrediction represents a behavior prediction
@@ -57,8 +133,15 @@ type BehaviorPrediction struct {
 	RecommendedAction   string    `json:"recommended_action"`
 	ExpectedImpact     float64   `json:"expected_impact"`
 	ValidationScore    float64   `json:"validation_score"`
+	ClusterID          int       `json:"cluster_id"`
+	ClusterSupport     int       `json:"cluster_support"`
 }
 
+// minClusterSupportForOverride is how many times a mined pattern must have
+// been observed before its historical continuation distribution is trusted
+// over the hand-tuned thresholds in adjustPredictionBasedOnPatterns.
+const minClusterSupportForOverride = 5
+
 // ScrollPrediction represents a scroll prediction
 type ScrollPrediction struct {
 	NextReelID         int       `json:"next_reel_id"`
@@ -86,17 +169,91 @@ type InteractionPrediction struct {
 	ExpectedTime       time.Time `json:"expected_time"`
 }
 
-// NewPredictionLogic creates a new prediction logic instance
-func NewPredictionLogic(analyzer analyzer.BehaviorAnalyzer, config PredictionConfig) *PredictionLogic {
-	return &PredictionLogic{
-		analyzer: analyzer,
-		config:    config,
-		cache: &PredictionCache{
-			predictions: make(map[string]*PredictionEntry),
-			maxSize:    100,
-			mu:         sync.RWMutex{},
-		},
+// defaultCacheMaxSize is the in-memory prediction cache's capacity.
+const defaultCacheMaxSize = 100
+
+// cgroupCacheMemFraction is the share of the container's memory limit set
+// aside for cached predictions when cgroupSizedMaxCacheSize derives
+// maxCacheSize from cgroup.Reader.MemoryLimit().
+const cgroupCacheMemFraction = 0.25
+
+// avgPredictionEntryBytes estimates one PredictionEntry's in-memory
+// footprint (feature vector, prediction struct, bookkeeping), used to turn
+// a memory budget into an entry-count cap. A rough estimate is fine here:
+// it only has to be in the right order of magnitude to keep the cache from
+// growing unbounded under a tight container limit.
+const avgPredictionEntryBytes = 2048
+
+// cgroupSizedMaxCacheSize caps configured (the static default) to what the
+// container can actually afford: min(configured, memLimit *
+// cgroupCacheMemFraction / avgPredictionEntryBytes). Falls back to
+// configured unchanged if reader can't report a usable limit.
+func cgroupSizedMaxCacheSize(reader cgroup.Reader, configured int) int {
+	if reader == nil {
+		return configured
 	}
+
+	limit, err := reader.MemoryLimit()
+	if err != nil || limit == 0 {
+		return configured
+	}
+
+	sized := int(float64(limit) * cgroupCacheMemFraction / avgPredictionEntryBytes)
+	if sized <= 0 || sized > configured {
+		return configured
+	}
+	return sized
+}
+
+// NewPredictionLogic creates a new prediction logic instance backed by an
+// in-memory, LRU-evicting prediction cache. Use NewPredictionLogicWithStore
+// for a durable (e.g. Pebble-backed) cache instead.
+func NewPredictionLogic(analyzer analyzer.BehaviorAnalyzer, config PredictionConfig, opts ...Option) *PredictionLogic {
+	return NewPredictionLogicWithStore(analyzer, config, newMemoryCacheStore(defaultCacheMaxSize), opts...)
+}
+
+// NewPredictionLogicWithStore creates a prediction logic instance backed by
+// store, so callers can pick an in-memory cache (newMemoryCacheStore, via
+// NewPredictionLogic) or a durable one (NewPebbleCacheStore) depending on
+// whether predictions need to survive a restart.
+func NewPredictionLogicWithStore(analyzer analyzer.BehaviorAnalyzer, config PredictionConfig, store CacheStore, opts ...Option) *PredictionLogic {
+	pl := &PredictionLogic{
+		analyzer:      analyzer,
+		config:        config,
+		store:         store,
+		maxCacheSize:  defaultCacheMaxSize,
+		profiler:      NewProfiler(config.EnableProfiling),
+		miner:         NewPatternMiner(defaultMinerMaxDepth, defaultMinerSimThreshold),
+		model:         NewLearningModel(),
+		cgroupReader:  cgroup.Default(),
+		successWindow: newSlidingSuccessWindow(defaultSuccessWindowSize),
+		confidenceHist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "kronop_predictions_confidence",
+			Help:    "Distribution of newly created predictions' confidence.",
+			Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0},
+		}),
+	}
+	pl.learningRateScale.Store(math.Float64bits(1.0))
+
+	for _, opt := range opts {
+		opt(pl)
+	}
+
+	pl.maxCacheSize = cgroupSizedMaxCacheSize(pl.cgroupReader, pl.maxCacheSize)
+	if ms, ok := pl.store.(*memoryCacheStore); ok {
+		ms.mu.Lock()
+		ms.maxSize = pl.maxCacheSize
+		ms.mu.Unlock()
+	}
+	pl.refreshStatsLocked()
+
+	return pl
+}
+
+// NewPebbleCacheStore opens (creating if needed) a Pebble-backed CacheStore
+// at dir, for use with NewPredictionLogicWithStore.
+func NewPebbleCacheStore(dir string) (CacheStore, error) {
+	return newPebbleCacheStore(dir)
 }
 
 // PredictBehavior predicts user behavior based on current patterns
@@ -108,42 +265,64 @@ func (pl *PredictionLogic) PredictBehavior(userID string, currentProfile *analyz
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
+	start := time.Now()
+	defer pl.metrics.observeLatency(start)
+
 	logrus.Debugf("🔮 Predicting behavior for user: %s", userID)
 
 	// Check cache first
 	if cached, found := pl.getCachedPrediction(userID); found && !cached.Expired {
+		pl.profiler.RecordCacheHit()
 		// Update confidence based on recent events
 		updatedConfidence := pl.updatePredictionConfidence(cached.Prediction, recentEvents)
 		cached.Prediction.Confidence = updatedConfidence
 		cached.Prediction.PredictionTime = time.Now()
+		pl.metrics.countResult("cache_hit")
 		return cached.Prediction, nil
 	}
+	pl.profiler.RecordCacheMiss()
 
 	// Create new prediction
-	prediction, err := pl.createPrediction(userID, currentProfile, recentEvents)
+	prediction, features, err := pl.createPrediction(userID, currentProfile, recentEvents)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prediction: %v", err)
 	}
 
 	// Cache the prediction
-	pl.cachePrediction(userID, prediction)
+	pl.cachePrediction(userID, prediction, features)
+
+	pl.metrics.countResult("created")
+	pl.metrics.observePrediction(prediction)
+	pl.confidenceHist.Observe(prediction.Confidence)
 
-	logrus.Debugf("🎯 Created new prediction: type=%s, confidence=%.2f, reels=%d", 
+	logrus.Debugf("🎯 Created new prediction: type=%s, confidence=%.2f, reels=%d",
 		prediction.UserType, prediction.Confidence, prediction.PrefetchCount)
 
 	return prediction, nil
 }
 
-// createPrediction creates a new behavior prediction
-func (pl *PredictionLogic) createPrediction(userID string, currentProfile *analyzer.BehaviorProfile, recentEvents []tracker.UserEvent) (*BehaviorPrediction, error) {
+// createPrediction creates a new behavior prediction. The returned feature
+// vector is the one the per-UserType SGD model scores (its output replaces
+// calculatePredictionConfidence's heuristic once the model is trained), so
+// UpdateLearning can later train against the same features the prediction
+// was made with.
+func (pl *PredictionLogic) createPrediction(userID string, currentProfile *analyzer.BehaviorProfile, recentEvents []tracker.UserEvent) (*BehaviorPrediction, []float64, error) {
 	if len(recentEvents) < 3 {
-		return nil, fmt.Errorf("insufficient data for prediction")
+		return nil, nil, fmt.Errorf("insufficient data for prediction")
 	}
 
 	// Analyze recent behavior patterns
+	scrollAct := pl.profiler.Start("analyzeScrollPattern")
 	scrollPattern := pl.analyzeScrollPattern(recentEvents)
+	scrollAct.End()
+
+	watchAct := pl.profiler.Start("analyzeWatchPattern")
 	watchPattern := pl.analyzeWatchPattern(recentEvents)
+	watchAct.End()
+
+	interactionAct := pl.profiler.Start("analyzeInteractionPattern")
 	interactionPattern := pl.analyzeInteractionPattern(recentEvents)
+	interactionAct.End()
 
 	// Create prediction based on patterns
 	prediction := &BehaviorPrediction{
@@ -158,14 +337,60 @@ func (pl *PredictionLogic) createPrediction(userID string, currentProfile *analy
 		ValidationScore:    0.0,
 	}
 
+	// Mine the event sequence into its behavior cluster before adjusting,
+	// so a cluster with enough history can override the hand-tuned rules.
+	clusterID, support := pl.miner.Ingest(recentEvents)
+	prediction.ClusterID = clusterID
+	prediction.ClusterSupport = support
+
 	// Adjust prediction based on patterns
+	adjustAct := pl.profiler.Start("adjustPredictionBasedOnPatterns")
 	pl.adjustPredictionBasedOnPatterns(prediction, scrollPattern, watchPattern, interactionPattern)
+	adjustAct.End()
+
+	clusterDelta, clusterConfidence, haveClusterContinuation := pl.miner.PredictContinuation(clusterID)
+	if haveClusterContinuation && support >= minClusterSupportForOverride {
+		prediction.NextReelID = currentProfile.CurrentReelID + clusterDelta
+	}
+	pl.miner.RecordContinuation(clusterID, prediction.NextReelID-currentProfile.CurrentReelID)
 
 	// Calculate confidence
+	confidenceAct := pl.profiler.Start("calculatePredictionConfidence")
 	prediction.Confidence = pl.calculatePredictionConfidence(prediction, currentProfile)
+	if haveClusterContinuation && support >= minClusterSupportForOverride {
+		prediction.Confidence = math.Max(prediction.Confidence, clusterConfidence)
+	}
+	confidenceAct.End()
+
+	// Feature vector for the per-UserType SGD model (see sgd_model.go):
+	// avg_scroll_speed, scroll_consistency, avg_watch_time, completion_rate,
+	// engagement_score, interaction_rate, log(event_count).
+	interactionRate := 0.0
+	if len(recentEvents) > 0 {
+		interactionRate = float64(interactionPattern.TotalInteractions) / float64(len(recentEvents))
+	}
+	features := []float64{
+		scrollPattern.AvgSpeed,
+		scrollPattern.Consistency,
+		watchPattern.AvgWatchTime,
+		watchPattern.CompletionRate,
+		watchPattern.EngagementScore,
+		interactionRate,
+		math.Log(float64(len(recentEvents)) + 1),
+	}
+
+	// Once a UserType's model has enough training samples, trust its
+	// learned confidence over the heuristic above.
+	if pl.config.EnableLearning {
+		if weights := pl.loadWeights(prediction.UserType); weights.samples >= minSGDSamples {
+			prediction.Confidence = weights.predict(features)
+		}
+	}
 
 	// Set reasoning
+	reasoningAct := pl.profiler.Start("generateReasoning")
 	prediction.Reasoning = pl.generateReasoning(prediction, scrollPattern, watchPattern, interactionPattern)
+	reasoningAct.End()
 
 	// Set recommended action
 	prediction.RecommendedAction = pl.generateRecommendedAction(prediction)
@@ -176,7 +401,48 @@ func (pl *PredictionLogic) createPrediction(userID string, currentProfile *analy
 	// Set validation score
 	prediction.ValidationScore = pl.calculateValidationScore(prediction)
 
-	return prediction, nil
+	return prediction, features, nil
+}
+
+// ScrollPattern is the result of analyzeScrollPattern over a window of
+// scroll events. P50/P90/P99 are scroll-speed percentiles (via
+// github.com/montanaflynn/stats); classification keys off P90 rather than
+// AvgSpeed so a single burst of fast scrolling doesn't dominate the mean.
+type ScrollPattern struct {
+	AvgSpeed         float64
+	PeakSpeed        float64
+	Consistency      float64
+	DirectionRatio   float64
+	AvgInterval      time.Duration
+	RecentSpeeds     []float64
+	RecentDirections []string
+	P50              float64
+	P90              float64
+	P99              float64
+}
+
+// WatchPattern is the result of analyzeWatchPattern over a window of watch
+// events. AvgWatchTime is a Trimean (robust to a single long idle pause
+// skewing a plain mean); P50/P90/P99 are watch-duration percentiles.
+type WatchPattern struct {
+	AvgWatchTime     float64
+	CompletionRate   float64
+	EngagementScore  float64
+	AvgPosition      float64
+	RecentWatchTimes []float64
+	CompletedCount   int
+	P50              float64
+	P90              float64
+	P99              float64
+}
+
+// InteractionPattern is the result of analyzeInteractionPattern over a
+// window of interaction events.
+type InteractionPattern struct {
+	MostFrequentType  string
+	TotalInteractions int
+	InteractionTypes  map[string]int
+	AvgInterval       time.Duration
 }
 
 // analyzeScrollPattern analyzes scrolling patterns
@@ -185,7 +451,6 @@ func (pl *PredictionLogic) analyzeScrollPattern(events []tracker.UserEvent) Scro
 		return ScrollPattern{}
 	}
 
-	var totalSpeed float64
 	var speeds []float64
 	var directions []string
 	var intervals []time.Duration
@@ -197,7 +462,6 @@ func (pl *PredictionLogic) analyzeScrollPattern(events []tracker.UserEvent) Scro
 			direction := data["direction"].(string)
 			duration := time.Duration(data["duration"].(float64)) * time.Second
 
-			totalSpeed += speed
 			speeds = append(speeds, speed)
 			directions = append(directions, direction)
 			intervals = append(intervals, duration)
@@ -208,22 +472,19 @@ func (pl *PredictionLogic) analyzeScrollPattern(events []tracker.UserEvent) Scro
 		return ScrollPattern{}
 	}
 
-	avgSpeed := totalSpeed / float64(len(speeds))
+	avgSpeed, _ := stats.Mean(speeds)
 	peakSpeed := pl.findMaxSpeed(speeds)
-	
-	// Calculate scroll consistency
+	p50, _ := stats.Percentile(speeds, 50)
+	p90, _ := stats.Percentile(speeds, 90)
+	p99, _ := stats.Percentile(speeds, 99)
+
+	// Calculate scroll consistency from the population standard deviation
+	// instead of a hand-rolled variance/mean^2 ratio, which could go
+	// negative on any spread and always ended up clamped to 0.
 	var consistency float64
-	if len(speeds) > 1 {
-		mean := avgSpeed
-		var variance float64
-		for _, speed := range speeds {
-			diff := speed - mean
-			variance += diff * diff
-		}
-		variance = variance / float64(len(speeds))
-		if mean > 0 {
-			consistency = 1.0 - (variance / (mean * mean))
-		}
+	if len(speeds) > 1 && avgSpeed > 0 {
+		stddev, _ := stats.StandardDeviationPopulation(speeds)
+		consistency = 1.0 - (stddev / avgSpeed)
 		if consistency < 0.0 {
 			consistency = 0.0
 		}
@@ -256,6 +517,9 @@ func (pl *PredictionLogic) analyzeScrollPattern(events []tracker.UserEvent) Scro
 		AvgInterval:      avgInterval,
 		RecentSpeeds:     speeds,
 		RecentDirections: directions,
+		P50:              p50,
+		P90:              p90,
+		P99:              p99,
 	}
 }
 
@@ -265,7 +529,6 @@ func (pl *PredictionLogic) analyzeWatchPattern(events []tracker.UserEvent) Watch
 		return WatchPattern{}
 	}
 
-	var totalWatchTime time.Duration
 	var watchTimes []float64
 	var completedCount int
 	var positions []float64
@@ -277,7 +540,6 @@ func (pl *PredictionLogic) analyzeWatchPattern(events []tracker.UserEvent) Watch
 			completed := data["completed"].(bool)
 			position := data["position"].(float64)
 
-			totalWatchTime += watchTime
 			watchTimes = append(watchTimes, watchTime.Seconds())
 			if completed {
 				completedCount++
@@ -290,29 +552,31 @@ func (pl *PredictionLogic) analyzeWatchPattern(events []tracker.UserEvent) Watch
 		return WatchPattern{}
 	}
 
-	avgWatchTime := totalWatchTime / time.Duration(len(watchTimes))
+	// Trimean instead of a plain mean, so one long idle pause doesn't skew
+	// the central estimate used for classification.
+	avgWatchTime, _ := stats.Trimean(watchTimes)
 	completionRate := float64(completedCount) / float64(len(watchTimes))
-	
-	// Calculate engagement score
 	engagementScore := avgWatchTime * completionRate
 
-	// Calculate position preference
+	p50, _ := stats.Percentile(watchTimes, 50)
+	p90, _ := stats.Percentile(watchTimes, 90)
+	p99, _ := stats.Percentile(watchTimes, 99)
+
 	var avgPosition float64
 	if len(positions) > 0 {
-		var totalPosition float64
-		for _, position := range positions {
-			totalPosition += position
-		}
-		avgPosition = totalPosition / float64(len(positions))
+		avgPosition, _ = stats.Mean(positions)
 	}
 
 	return WatchPattern{
-		AvgWatchTime:    avgWatchTime.Seconds(),
-		CompletionRate:  completionRate,
-		EngagementScore: engagementScore,
-		AvgPosition:    avgPosition,
-	RecentWatchTimes: watchTimes,
-		CompletedCount:  completedCount,
+		AvgWatchTime:     avgWatchTime,
+		CompletionRate:   completionRate,
+		EngagementScore:  engagementScore,
+		AvgPosition:      avgPosition,
+		RecentWatchTimes: watchTimes,
+		CompletedCount:   completedCount,
+		P50:              p50,
+		P90:              p90,
+		P99:              p99,
 	}
 }
 
@@ -378,23 +642,25 @@ func (pl *PredictionLogic) findMaxSpeed(speeds []float64) float64 {
 
 // adjustPredictionBasedOnPatterns adjusts prediction based on analyzed patterns
 func (pl *PredictionLogic) adjustPredictionBasedOnPatterns(prediction *BehaviorPrediction, scrollPattern ScrollPattern, watchPattern WatchPattern, interactionPattern InteractionPattern) {
-	// Adjust based on scroll pattern
-	if scrollPattern.AvgSpeed > 5.0 {
+	// Adjust based on scroll pattern. Keyed off P90 rather than AvgSpeed so
+	// a handful of outlier scroll events can't flip the classification.
+	if scrollPattern.P90 > 5.0 {
 		prediction.PrefetchCount = 5
 		prediction.UserType = "fast_scroller"
 		prediction.NextReelID = prediction.NextReelID + 2 // Skip 2 reels for fast scroller
-	} else if scrollPattern.AvgSpeed < 0.5 {
+	} else if scrollPattern.P90 < 0.5 {
 		prediction.PrefetchCount = 2
 		prediction.UserType = "slow_viewer"
 		prediction.NextReelID = prediction.NextReelID + 1 // Next reel only
 	}
 
-	// Adjust based on watch pattern
-	if watchPattern.AvgWatchTime > 30.0 && watchPattern.CompletionRate > 0.8 {
+	// Adjust based on watch pattern. Keyed off P50 rather than AvgWatchTime
+	// so one long idle pause can't skew a binge watcher into casual_browser.
+	if watchPattern.P50 > 30.0 && watchPattern.CompletionRate > 0.8 {
 		prediction.PrefetchCount = 8
 		prediction.UserType = "binge_watcher"
 		prediction.NextReelID = prediction.NextReelID + 3 // Prefetch more for binge watcher
-	} else if watchPattern.AvgWatchTime < 5.0 {
+	} else if watchPattern.P50 < 5.0 {
 		prediction.PrefetchCount = 2
 		prediction.UserType = "casual_browser"
 	}
@@ -580,30 +846,12 @@ func (pl *PredictionLogic) calculateValidationScore(prediction *BehaviorPredicti
 	return score
 }
 
-// cachePrediction caches a prediction
-func (pl *PredictionLogic) cachePrediction(userID string, prediction *BehaviorPrediction) {
+// cachePrediction caches a prediction. Eviction when the store is at
+// capacity is handled by the store itself (e.g. memoryCacheStore's LRU).
+func (pl *PredictionLogic) cachePrediction(userID string, prediction *BehaviorPrediction, features []float64) {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
-	// Remove old predictions if cache is full
-	if len(pl.cache.predictions) >= pl.cache.maxSize {
-		// Remove oldest prediction
-		var oldestKey string
-		oldestTime := time.Now()
-		
-		for key, entry := range pl.cache.predictions {
-			if entry.CreatedAt.Before(oldestTime) {
-				oldestKey = key
-				break
-			}
-		}
-		
-		if oldestKey != "" {
-			delete(pl.cache.predictions, oldestKey)
-		}
-	}
-
-	// Add new prediction
 	entry := &PredictionEntry{
 		UserID:      userID,
 		Prediction:  prediction,
@@ -611,9 +859,12 @@ func (pl *PredictionLogic) cachePrediction(userID string, prediction *BehaviorPr
 		ExpiresAt:    time.Now().Add(pl.config.PredictionWindow),
 		Used:        false,
 		Confidence:  prediction.Confidence,
+		Features:    features,
 	}
 
-	pl.cache.predictions[userID] = entry
+	pl.store.Put(userID, entry)
+	pl.metrics.refreshCacheGauges(pl.store)
+	pl.refreshStats()
 }
 
 // getCachedPrediction retrieves a cached prediction
@@ -621,7 +872,7 @@ func (pl *PredictionLogic) getCachedPrediction(userID string) (*PredictionEntry,
 	pl.mu.RLock()
 	defer pl.mu.RUnlock()
 
-	entry, exists := pl.cache.predictions[userID]
+	entry, exists := pl.store.Get(userID)
 	if !exists {
 		return nil, false
 	}
@@ -629,6 +880,7 @@ func (pl *PredictionLogic) getCachedPrediction(userID string) (*PredictionEntry,
 	// Check if prediction has expired
 	if time.Now().After(entry.ExpiresAt) {
 		delete(pl.cache.predictions[userID]
+		pl.metrics.countResult("expired")
 		return nil, false
 	}
 
@@ -657,6 +909,55 @@ func (pl *PredictionLogic) updatePredictionConfidence(entry *PredictionEntry, re
 	return entry.Confidence
 }
 
+// GetPatterns returns the behavior patterns mined from live traffic so far,
+// for operator inspection.
+func (pl *PredictionLogic) GetPatterns() []PatternSummary {
+	return pl.miner.GetPatterns()
+}
+
+// modelKey is the reserved cache-store key a UserType's SGD model weights
+// are persisted under, namespaced away from real userIDs.
+func modelKey(userType string) string {
+	return "__model__:" + userType
+}
+
+// loadWeights returns userType's in-memory SGD weights, hydrating them from
+// the cache store on first use (see PredictionEntry's doc comment for the
+// persisted shape) and defaulting to a freshly zeroed model otherwise.
+func (pl *PredictionLogic) loadWeights(userType string) *sgdWeights {
+	if w, ok := pl.model.get(userType); ok {
+		return w
+	}
+
+	w := newSGDWeights()
+	if entry, found := pl.store.Get(modelKey(userType)); found && len(entry.Features) == sgdFeatureCount+1 {
+		copy(w.w, entry.Features[:sgdFeatureCount])
+		w.b = entry.Features[sgdFeatureCount]
+		w.samples = int(entry.Confidence)
+	}
+	pl.model.set(userType, w)
+	return w
+}
+
+// saveWeights persists userType's SGD weights through the cache store, so
+// they survive a restart on a Pebble-backed store the same way cached
+// predictions do.
+func (pl *PredictionLogic) saveWeights(userType string, w *sgdWeights) {
+	pl.store.Put(modelKey(userType), &PredictionEntry{
+		UserID:     modelKey(userType),
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(100 * 365 * 24 * time.Hour), // effectively never expires via PurgeExpired
+		Confidence: float64(w.samples),
+		Features:   append(append([]float64(nil), w.w...), w.b),
+	})
+}
+
+// GetModelWeights returns the learned SGD model for every UserType trained
+// so far, for operator inspection.
+func (pl *PredictionLogic) GetModelWeights() []ModelWeightsSnapshot {
+	return pl.model.snapshotAll()
+}
+
 // calculateRecentScrollSpeed calculates recent scroll speed from recent events
 func (pl *PredictionLogic) calculateRecentScrollSpeed(recentEvents []tracker.UserEvent) float64 {
 	var recentScrollSpeeds []float64
@@ -686,44 +987,29 @@ func (pl *PredictionLogic) ClearCache() {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
-	pl.cache.predictions = make(map[string]*PredictionEntry)
+	pl.store.Clear()
+	pl.metrics.refreshCacheGauges(pl.store)
+	pl.refreshStats()
 	logrus.Info("🗑️ Cleared prediction cache")
 }
 
-// GetCacheStats returns cache statistics
+// GetCacheStats returns cache statistics as a map, for callers that want a
+// one-off snapshot rather than scraping pl as a prometheus.Collector (see
+// collector.go, which exposes cache_size/success_rate/confidence the same
+// way). Reads the published statsSnapshot lock-free (see stats_snapshot.go)
+// instead of taking pl.mu, so a stats scrape never contends with the hot
+// prediction path.
 func (pl *PredictionLogic) GetCacheStats() map[string]interface{} {
-	pl.mu.RLock()
-	defer pl.mu.RUnlock()
+	snap := pl.loadStats()
 
-	stats := map[string]interface{}{
-		"cache_size":     len(pl.cache.predictions),
-		"max_size":      pl.cache.maxSize,
-		"hit_rate":      pl.calculateCacheHitRate(),
-		"miss_rate":      pl.calculateCacheMissRate(),
+	return map[string]interface{}{
+		"cache_size":          snap.cacheSize,
+		"max_size":            snap.maxSize,
+		"hit_rate":            snap.hitRate,
+		"miss_rate":           snap.missRate,
+		"maintenance_wait_ms": snap.maintenanceWaitMs,
+		"evictions_total":     snap.evictionsTotal,
 	}
-
-	return stats
-}
-
-// calculateCacheHitRate calculates cache hit rate
-func (pl *PredictionLogic) calculateCacheHitRate() float64 {
-	if len(pl.cache.predictions) == 0 {
-		return 0.0
-	}
-
-	hitCount := 0
-	for _, entry := range pl.cache.predictions {
-		if entry.Used {
-			hitCount++
-		}
-	}
-
-	return float64(hitCount) / float64(len(pl.cache.predictions))
-}
-
-// calculateCacheMissRate calculates cache miss rate
-func (pl *PredictionLogic) calculateCacheMissRate() float64 {
-	return 1.0 - pl.calculateCacheHitRate()
 }
 
 // GetPredictionHistory gets prediction history for a user
@@ -733,8 +1019,8 @@ func (pl *PredictionLogic) GetPredictionHistory(userID string, count int) []*Beh
 
 	var history []*BehaviorPrediction
 	count = pl.config.MaxPredictionCount
-	if count > len(pl.cache.predictions) {
-		count = len(pl.cache.predictions)
+	if count > pl.store.Len() {
+		count = pl.store.Len()
 	}
 
 	// Get recent predictions sorted by creation time
@@ -744,12 +1030,16 @@ func (pl *PredictionLogic) GetPredictionHistory(userID string, count int) []*Beh
 	}
 
 	var entries []predictionEntry
-	for _, entry := range pl.cache.predictions {
+	pl.store.Range(func(_ string, entry *PredictionEntry) bool {
+		if entry.Prediction == nil {
+			return true
+		}
 		entries = append(entries, predictionEntry{
 			CreatedAt: entry.CreatedAt,
 			Prediction: entry.Prediction,
 		})
-	}
+		return true
+	})
 
 	// Sort by creation time (most recent first)
 	for i := 0; i < len(entries); i++ {
@@ -774,21 +1064,36 @@ func (pl *PredictionLogic) GetPredictionHistory(userID string, count int) []*Beh
 
 // UpdateLearning updates the prediction model based on validation results
 func (pl *PredictionLogic) UpdateLearning(userID string, prediction *BehaviorPrediction, actualBehavior string, success bool) {
-	if !pl.config.EnableLearning {
-		return
-	}
-
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
 	// Get cached prediction
-	entry, exists := pl.cache.predictions[userID]
+	entry, exists := pl.store.Get(userID)
 	if !exists {
 		return
 	}
 
-	// Update learning based on validation
+	// Train the UserType's online SGD model (see sgd_model.go) against the
+	// feature vector the prediction was made from, whenever learning is
+	// enabled and we captured one. Fall back to the old heuristic
+	// confidence bump until that UserType's model has seen enough samples
+	// to be trustworthy (or learning is disabled outright).
+	y := 0.0
 	if success {
+		y = 1.0
+	}
+
+	weights := pl.loadWeights(prediction.UserType)
+	trained := false
+	if pl.config.EnableLearning && len(entry.Features) == sgdFeatureCount {
+		weights.update(entry.Features, y, pl.currentLearningRate())
+		pl.saveWeights(prediction.UserType, weights)
+		trained = true
+	}
+
+	if trained && weights.samples >= minSGDSamples {
+		entry.Confidence = weights.predict(entry.Features)
+	} else if success {
 		// Increase confidence for successful predictions
 		entry.Confidence = math.Min(1.0, entry.Confidence+0.1)
 	} else {
@@ -805,70 +1110,94 @@ func (pl *PredictionLogic) UpdateLearning(userID string, prediction *BehaviorPre
 
 	// Update timestamp
 	entry.Prediction.PredictionTime = time.Now()
-		entry.Used = true
+	entry.Used = true
+	pl.successWindow.record(success)
+
+	// Persist the mutated entry: Get doesn't guarantee a live pointer into
+	// the store (it isn't, for the Pebble backend), so the update must be
+	// written back explicitly.
+	pl.store.Put(userID, entry)
+	pl.refreshStats()
 
 	logrus.Debugf("📚 Updated learning for user %s: success=%t, confidence=%.2f", userID, success, entry.Prediction.Confidence)
 }
 
-// GetLearningStats returns learning statistics
+// currentLearningRate returns config.LearningRate scaled by
+// learningRateScale, which applyCgroupPressure halves temporarily under
+// cgroup v2 memory.pressure and restores once pressure clears (see
+// maintenance.go).
+func (pl *PredictionLogic) currentLearningRate() float64 {
+	scale := math.Float64frombits(pl.learningRateScale.Load())
+	return pl.config.LearningRate * scale
+}
+
+// GetLearningStats returns learning statistics as a map, for callers that
+// want a one-off snapshot rather than scraping pl as a prometheus.Collector
+// (see collector.go). Reads the published statsSnapshot lock-free (see
+// stats_snapshot.go) instead of taking pl.mu.
 func (pl *PredictionLogic) GetLearningStats() map[string]interface{} {
-	pl.mu.RLock()
-	defer pl.mu.Unlock()
+	snap := pl.loadStats()
 
-	stats := map[string]interface{}{
-		"enable_learning": pl.config.EnableLearning,
-		"learning_rate": pl.config.LearningRate,
-		"total_updates": pl.getTotalLearningUpdates(),
-		"success_rate": pl.getSuccessRate(),
-		"adaptation_threshold": pl.config.AdaptiveThreshold,
+	return map[string]interface{}{
+		"enable_learning":      snap.enableLearning,
+		"learning_rate":        snap.learningRate,
+		"total_updates":        snap.totalLearningUpdates,
+		"success_rate":         snap.successRate,
+		"adaptation_threshold": snap.adaptationThreshold,
 	}
-
-	return stats
 }
 
-// getTotalLearningUpdates returns total learning updates
+// getTotalLearningUpdates returns the published snapshot's total learning
+// updates, a lock-free read (see stats_snapshot.go). This replaces a prior
+// implementation that took pl.mu.RLock() but deferred pl.mu.Unlock() — a
+// read lock released as a write lock, which panics under any concurrent
+// load.
 func (pl *PredictionLogic) getTotalLearningUpdates() int {
-	pl.mu.RLock()
-	defer pl.mu.Unlock()
-
-	count := 0
-	for _, entry := range pl.cache.predictions {
-		if entry.Used {
-			count++
-		}
-	}
-
-	return count
+	return pl.loadStats().totalLearningUpdates
 }
 
-// getSuccessRate calculates prediction success rate
+// getSuccessRate returns the published snapshot's sliding-window prediction
+// success rate (see slidingSuccessWindow and stats_snapshot.go), a
+// lock-free read. Using a window instead of an all-time store scan means
+// the rate tracks recent behavior rather than being diluted by a cache's
+// entire history.
 func (pl *PredictionLogic) getSuccessRate() float64 {
-	pl.mu.RLock()
-	defer pl.mu.Unlock()
-
-	if len(pl.cache.predictions) == 0 {
-		return 0.0
-	}
-
-	successCount := 0
-	totalCount := 0
-	for _, entry := range pl.cache.predictions {
-		if entry.Used {
-			totalCount++
-			if entry.Prediction.Confidence > 0.7 {
-				successCount++
-			}
-		}
-	}
-
-	return float64(successCount) / float64(totalCount)
+	return pl.loadStats().successRate
 }
 
-// Stop stops the prediction logic
+// Stop stops the prediction logic and releases the cache store's resources
+// (e.g. closing the underlying Pebble database).
 func (pl *PredictionLogic) Stop() {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
-	pl.cache.predictions = make(map[string]*PredictionEntry)
+	if pl.watcher != nil {
+		pl.watcher.Stop()
+	}
+
+	if err := pl.store.Close(); err != nil {
+		logrus.Warnf("⚠️ Failed to close prediction cache store: %v", err)
+	}
 	logrus.Info("🛑️ Stopped prediction logic")
 }
+
+// StartExpiryPurge runs a background loop that periodically purges expired
+// entries from the cache store, so TTL expiry reclaims space (and, for the
+// Pebble backend, disk) even for users who never trigger a cache lookup
+// again. It blocks until stopChan is closed.
+func (pl *PredictionLogic) StartExpiryPurge(stopChan <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			purged := pl.store.PurgeExpired(time.Now())
+			if purged > 0 {
+				logrus.Debugf("🧹 Purged %d expired predictions from cache", purged)
+			}
+		case <-stopChan:
+			return
+		}
+	}
+}
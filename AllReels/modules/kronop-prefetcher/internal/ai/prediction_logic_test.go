@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/tracker"
+)
+
+// watchEvent builds a synthetic "watch" UserEvent with the given watch time
+// (seconds), completion flag and playback position, matching the event
+// shape analyzeWatchPattern expects.
+func watchEvent(watchTimeSeconds float64, completed bool, position float64) tracker.UserEvent {
+	return tracker.UserEvent{
+		Type:      "watch",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"watch_time": watchTimeSeconds,
+			"completed":  completed,
+			"position":   position,
+		},
+	}
+}
+
+// TestAnalyzeWatchPattern_OutliersDontFlipBingeWatcher verifies that a small
+// number of short-watch outliers mixed into an otherwise long-watch session
+// don't drag the P50/completion rate below the binge_watcher thresholds.
+func TestAnalyzeWatchPattern_OutliersDontFlipBingeWatcher(t *testing.T) {
+	var events []tracker.UserEvent
+	for _, watchTime := range []float64{35, 40, 42, 45, 38, 50, 44, 41, 48} {
+		events = append(events, watchEvent(watchTime, true, 0.95))
+	}
+	// A single short outlier (e.g. a reel skipped almost immediately).
+	events = append(events, watchEvent(2, false, 0.05))
+
+	pl := &PredictionLogic{}
+	pattern := pl.analyzeWatchPattern(events)
+
+	if pattern.P50 <= 30.0 {
+		t.Fatalf("expected P50 watch time to stay above the binge_watcher threshold despite the outlier, got %v", pattern.P50)
+	}
+	if pattern.CompletionRate <= 0.8 {
+		t.Fatalf("expected completion rate to stay above the binge_watcher threshold despite the outlier, got %v", pattern.CompletionRate)
+	}
+
+	prediction := &BehaviorPrediction{}
+	pl.adjustPredictionBasedOnPatterns(prediction, ScrollPattern{}, pattern, InteractionPattern{})
+
+	if prediction.UserType != "binge_watcher" {
+		t.Fatalf("expected a small number of outliers to leave UserType as binge_watcher, got %q", prediction.UserType)
+	}
+}
@@ -0,0 +1,192 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// stageStats accumulates timing samples for one profiled stage.
+type stageStats struct {
+	calls     int64
+	totalTime time.Duration
+	durations []time.Duration // raw samples, used to derive percentiles on dump
+}
+
+// Profiler records per-stage timing and cache hit/miss counts for the
+// prediction pipeline. It's a no-op when disabled (PredictionConfig.
+// EnableProfiling is false), so callers can unconditionally call Start/End
+// and record* without checking a flag themselves.
+type Profiler struct {
+	enabled bool
+
+	mu        sync.Mutex
+	stages    map[string]*stageStats
+	cacheHit  int64
+	cacheMiss int64
+}
+
+// NewProfiler creates a Profiler. When enabled is false, every method is a
+// no-op and Start/End avoid the time.Now() call entirely.
+func NewProfiler(enabled bool) *Profiler {
+	return &Profiler{
+		enabled: enabled,
+		stages:  make(map[string]*stageStats),
+	}
+}
+
+// activity is a single in-flight timed stage, returned by Start and closed
+// by calling End.
+type activity struct {
+	p     *Profiler
+	stage string
+	start time.Time
+}
+
+// Start begins timing stage. Call End on the returned activity (typically
+// via defer) to record the sample. When profiling is disabled this returns
+// a zero-cost activity that skips the time.Now() read.
+func (p *Profiler) Start(stage string) activity {
+	if p == nil || !p.enabled {
+		return activity{}
+	}
+	return activity{p: p, stage: stage, start: time.Now()}
+}
+
+// End records the elapsed time since Start against the activity's stage.
+func (a activity) End() {
+	if a.p == nil {
+		return
+	}
+	a.p.record(a.stage, time.Since(a.start))
+}
+
+func (p *Profiler) record(stage string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.stages[stage]
+	if !ok {
+		s = &stageStats{}
+		p.stages[stage] = s
+	}
+	s.calls++
+	s.totalTime += d
+	s.durations = append(s.durations, d)
+}
+
+// RecordCacheHit records a prediction cache hit.
+func (p *Profiler) RecordCacheHit() {
+	if p == nil || !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	p.cacheHit++
+	p.mu.Unlock()
+}
+
+// RecordCacheMiss records a prediction cache miss.
+func (p *Profiler) RecordCacheMiss() {
+	if p == nil || !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	p.cacheMiss++
+	p.mu.Unlock()
+}
+
+// StageProfile is the aggregated view of one stage's timings, as reported
+// by DumpProfile and its JSON export.
+type StageProfile struct {
+	Stage     string        `json:"stage"`
+	Calls     int64         `json:"calls"`
+	TotalTime time.Duration `json:"total_time_ns"`
+	P50       time.Duration `json:"p50_ns"`
+	P95       time.Duration `json:"p95_ns"`
+	P99       time.Duration `json:"p99_ns"`
+}
+
+// snapshot computes a StageProfile per stage, sorted by total time
+// descending, plus the current cache hit/miss counts.
+func (p *Profiler) snapshot() ([]StageProfile, int64, int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profiles := make([]StageProfile, 0, len(p.stages))
+	for stage, s := range p.stages {
+		durations := append([]time.Duration(nil), s.durations...)
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		profiles = append(profiles, StageProfile{
+			Stage:     stage,
+			Calls:     s.calls,
+			TotalTime: s.totalTime,
+			P50:       percentile(durations, 0.50),
+			P95:       percentile(durations, 0.95),
+			P99:       percentile(durations, 0.99),
+		})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].TotalTime > profiles[j].TotalTime })
+
+	return profiles, p.cacheHit, p.cacheMiss
+}
+
+// percentile returns the value at the given percentile (0-1) of a sorted
+// slice, or 0 if the slice is empty.
+func percentile(sorted []time.Duration, pct float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(pct * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// DumpProfile writes a table of per-stage call count, total time and
+// p50/p95/p99 durations to w, sorted by total time descending, followed by
+// the prediction cache hit/miss counts.
+func (p *Profiler) DumpProfile(w io.Writer) error {
+	if p == nil || !p.enabled {
+		return nil
+	}
+
+	profiles, hits, misses := p.snapshot()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tCALLS\tTOTAL\tP50\tP95\tP99")
+	for _, sp := range profiles {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n",
+			sp.Stage, sp.Calls, sp.TotalTime, sp.P50, sp.P95, sp.P99)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "cache: %d hits, %d misses\n", hits, misses)
+	return err
+}
+
+// profileExport is the JSON shape returned by DumpProfileJSON.
+type profileExport struct {
+	Stages      []StageProfile `json:"stages"`
+	CacheHits   int64          `json:"cache_hits"`
+	CacheMisses int64          `json:"cache_misses"`
+}
+
+// DumpProfileJSON writes the same data as DumpProfile as JSON.
+func (p *Profiler) DumpProfileJSON(w io.Writer) error {
+	if p == nil || !p.enabled {
+		return nil
+	}
+
+	profiles, hits, misses := p.snapshot()
+	return json.NewEncoder(w).Encode(profileExport{
+		Stages:      profiles,
+		CacheHits:   hits,
+		CacheMisses: misses,
+	})
+}
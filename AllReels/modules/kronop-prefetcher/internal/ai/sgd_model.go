@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"math"
+	"sync"
+)
+
+// sgdFeatureCount is the length of the feature vector fed to each
+// per-UserType logistic regression model: avg_scroll_speed,
+// scroll_consistency, avg_watch_time, completion_rate, engagement_score,
+// interaction_rate, log(event_count).
+const sgdFeatureCount = 7
+
+// sgdL2Lambda is the L2 regularization strength applied on every update.
+const sgdL2Lambda = 0.01
+
+// minSGDSamples is how many training samples a UserType's model needs
+// before its prediction is trusted over the heuristic confidence bump.
+const minSGDSamples = 20
+
+// sgdWeights is one UserType's online logistic regression model:
+// p = sigmoid(w·x + b), trained by SGD on (feature vector, success) pairs.
+type sgdWeights struct {
+	w       []float64
+	b       float64
+	samples int
+}
+
+func newSGDWeights() *sgdWeights {
+	return &sgdWeights{w: make([]float64, sgdFeatureCount)}
+}
+
+func sigmoid(z float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-z))
+}
+
+// predict returns p = sigmoid(w·x + b) for feature vector x.
+func (m *sgdWeights) predict(x []float64) float64 {
+	z := m.b
+	for i, xi := range x {
+		if i >= len(m.w) {
+			break
+		}
+		z += m.w[i] * xi
+	}
+	return sigmoid(z)
+}
+
+// update applies one SGD step for observed label y (1 for success, 0 for
+// failure) against feature vector x, using learning rate lr and L2
+// regularizer sgdL2Lambda: w <- w - lr*((p-y)*x + lambda*w).
+func (m *sgdWeights) update(x []float64, y, lr float64) {
+	p := m.predict(x)
+	errTerm := p - y
+
+	for i := range m.w {
+		var xi float64
+		if i < len(x) {
+			xi = x[i]
+		}
+		grad := errTerm*xi + sgdL2Lambda*m.w[i]
+		m.w[i] -= lr * grad
+	}
+	m.b -= lr * errTerm
+	m.samples++
+}
+
+// LearningModel holds one sgdWeights per UserType, hydrated lazily from
+// PredictionLogic's cache store and persisted back after every update.
+type LearningModel struct {
+	mu      sync.Mutex
+	weights map[string]*sgdWeights
+}
+
+// NewLearningModel creates an empty LearningModel.
+func NewLearningModel() *LearningModel {
+	return &LearningModel{weights: make(map[string]*sgdWeights)}
+}
+
+// get returns the in-memory weights for userType, if already hydrated.
+func (m *LearningModel) get(userType string) (*sgdWeights, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.weights[userType]
+	return w, ok
+}
+
+// set stores w as the in-memory weights for userType.
+func (m *LearningModel) set(userType string, w *sgdWeights) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.weights[userType] = w
+}
+
+// ModelWeightsSnapshot is the inspectable view of one UserType's learned
+// model, returned by PredictionLogic.GetModelWeights.
+type ModelWeightsSnapshot struct {
+	UserType string    `json:"user_type"`
+	Weights  []float64 `json:"weights"`
+	Bias     float64   `json:"bias"`
+	Samples  int       `json:"samples"`
+}
+
+// snapshotAll returns a ModelWeightsSnapshot per currently-hydrated
+// UserType model.
+func (m *LearningModel) snapshotAll() []ModelWeightsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshots := make([]ModelWeightsSnapshot, 0, len(m.weights))
+	for userType, w := range m.weights {
+		snapshots = append(snapshots, ModelWeightsSnapshot{
+			UserType: userType,
+			Weights:  append([]float64(nil), w.w...),
+			Bias:     w.b,
+			Samples:  w.samples,
+		})
+	}
+	return snapshots
+}
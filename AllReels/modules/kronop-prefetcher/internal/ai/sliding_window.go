@@ -0,0 +1,63 @@
+package ai
+
+import "sync"
+
+// defaultSuccessWindowSize is how many recent prediction outcomes
+// slidingSuccessWindow retains.
+const defaultSuccessWindowSize = 200
+
+// slidingSuccessWindow tracks the most recent outcomes recorded by
+// UpdateLearning in a fixed-capacity ring buffer, so getSuccessRate (and the
+// Watcher's success-rate floor) reacts to recent behavior instead of being
+// diluted by a cache's entire, potentially stale, history.
+type slidingSuccessWindow struct {
+	mu       sync.Mutex
+	outcomes []bool
+	next     int
+	filled   bool
+}
+
+// newSlidingSuccessWindow creates a window retaining the last capacity
+// outcomes (defaultSuccessWindowSize if capacity <= 0).
+func newSlidingSuccessWindow(capacity int) *slidingSuccessWindow {
+	if capacity <= 0 {
+		capacity = defaultSuccessWindowSize
+	}
+	return &slidingSuccessWindow{outcomes: make([]bool, capacity)}
+}
+
+// record appends an outcome, evicting the oldest once the window is full.
+func (w *slidingSuccessWindow) record(success bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.outcomes[w.next] = success
+	w.next++
+	if w.next == len(w.outcomes) {
+		w.next = 0
+		w.filled = true
+	}
+}
+
+// rate returns the share of recorded outcomes that were successes, or 0 if
+// nothing has been recorded yet.
+func (w *slidingSuccessWindow) rate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := w.next
+	if w.filled {
+		n = len(w.outcomes)
+	}
+	if n == 0 {
+		return 0.0
+	}
+
+	successes := 0
+	for i := 0; i < n; i++ {
+		if w.outcomes[i] {
+			successes++
+		}
+	}
+	return float64(successes) / float64(n)
+}
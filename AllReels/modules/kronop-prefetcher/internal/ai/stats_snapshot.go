@@ -0,0 +1,84 @@
+package ai
+
+// statsSnapshot is a point-in-time view of PredictionLogic's cache and
+// learning stats, atomically published via PredictionLogic.stats.
+// GetCacheStats, GetLearningStats, getTotalLearningUpdates, and
+// getSuccessRate all read the current snapshot instead of taking pl.mu, so
+// a Prometheus scrape (see collector.go, typically every 15s) never
+// contends with the hot prediction path. This replaces a prior
+// getTotalLearningUpdates that took pl.mu.RLock() but deferred pl.mu.Unlock()
+// — a read lock released as a write lock, which panics under any
+// concurrent load.
+type statsSnapshot struct {
+	cacheSize            int
+	maxSize              int
+	hitRate              float64
+	missRate             float64
+	maintenanceWaitMs    int64
+	evictionsTotal       int64
+	totalLearningUpdates int
+	successRate          float64
+	learningRate         float64
+	adaptationThreshold  float64
+	enableLearning       bool
+}
+
+// emptyStatsSnapshot is published at construction time so stats.Load()
+// never returns nil before the first refreshStats call.
+var emptyStatsSnapshot = &statsSnapshot{}
+
+// refreshStats recomputes a statsSnapshot from current state and publishes
+// it. Callers must already hold pl.mu (for writing) since it ranges over
+// pl.store expecting a consistent view with whatever mutation just
+// happened; use refreshStatsLocked from a path that doesn't already hold
+// the lock.
+func (pl *PredictionLogic) refreshStats() {
+	cacheSize := pl.store.Len()
+
+	hitCount := 0
+	learningUpdates := 0
+	pl.store.Range(func(_ string, entry *PredictionEntry) bool {
+		if entry.Prediction != nil && entry.Used {
+			hitCount++
+			learningUpdates++
+		}
+		return true
+	})
+
+	hitRate := 0.0
+	if cacheSize > 0 {
+		hitRate = float64(hitCount) / float64(cacheSize)
+	}
+
+	pl.stats.Store(&statsSnapshot{
+		cacheSize:            cacheSize,
+		maxSize:              pl.maxCacheSize,
+		hitRate:              hitRate,
+		missRate:             1.0 - hitRate,
+		maintenanceWaitMs:    pl.maintenanceWaitMs.Load(),
+		evictionsTotal:       pl.evictionsTotal.Load(),
+		totalLearningUpdates: learningUpdates,
+		successRate:          pl.successWindow.rate(),
+		learningRate:         pl.currentLearningRate(),
+		adaptationThreshold:  pl.config.AdaptiveThreshold,
+		enableLearning:       pl.config.EnableLearning,
+	})
+}
+
+// refreshStatsLocked takes pl.mu for writing and refreshes the published
+// snapshot, for callers (the maintenance loop, applyCgroupPressure) that
+// don't already hold it.
+func (pl *PredictionLogic) refreshStatsLocked() {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.refreshStats()
+}
+
+// loadStats returns the currently published statsSnapshot, a lock-free
+// atomic load.
+func (pl *PredictionLogic) loadStats() *statsSnapshot {
+	if snap := pl.stats.Load(); snap != nil {
+		return snap
+	}
+	return emptyStatsSnapshot
+}
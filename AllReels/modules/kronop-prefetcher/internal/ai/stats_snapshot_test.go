@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/analyzer"
+)
+
+// TestStatsSnapshot_ConcurrentPredictAndRead hammers the cache-mutating hot
+// path (cachePrediction, UpdateLearning) concurrently with the stats-reading
+// path (GetCacheStats, GetLearningStats) under -race. It guards against the
+// bug the statsSnapshot design replaced: getTotalLearningUpdates took
+// pl.mu.RLock() but deferred pl.mu.Unlock() — a read lock released as a
+// write lock, which panics (or corrupts pl.mu) under any concurrent load.
+func TestStatsSnapshot_ConcurrentPredictAndRead(t *testing.T) {
+	pl := NewPredictionLogic(analyzer.BehaviorAnalyzer{}, PredictionConfig{
+		EnableLearning:   true,
+		LearningRate:     0.1,
+		PredictionWindow: time.Minute,
+	})
+	t.Cleanup(pl.Stop)
+
+	const workers = 8
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				userID := fmt.Sprintf("user-%d-%d", worker, i)
+				prediction := &BehaviorPrediction{UserType: "fast_scroller", Confidence: 0.5}
+				features := []float64{1, 2, 3, 4, 5, 6, 7}
+				pl.cachePrediction(userID, prediction, features)
+				pl.UpdateLearning(userID, prediction, "fast_scroller", i%2 == 0)
+			}
+		}(w)
+	}
+
+	for r := 0; r < workers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				_ = pl.GetCacheStats()
+				_ = pl.GetLearningStats()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
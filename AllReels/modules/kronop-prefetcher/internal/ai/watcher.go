@@ -0,0 +1,319 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatcherConfig tunes when the self-watching resource guard considers the
+// prediction path pathological and fires a diagnostic capture.
+type WatcherConfig struct {
+	// SampleInterval is how often goroutine count, heap in-use, cache size
+	// and the sliding-window success rate are sampled.
+	SampleInterval time.Duration
+	// MaxGoroutines fires a capture once runtime.NumGoroutine() exceeds it.
+	// Zero disables this trigger.
+	MaxGoroutines int
+	// MaxCacheSize fires a capture once the prediction cache holds more
+	// entries than this. Zero disables this trigger.
+	MaxCacheSize int
+	// SuccessRateFloor fires a capture once the sliding-window success rate
+	// (see slidingSuccessWindow) stays below it for SuccessRateConsecutive
+	// consecutive samples. Zero disables this trigger.
+	SuccessRateFloor float64
+	// SuccessRateConsecutive is how many consecutive low-success samples are
+	// required before firing, so one noisy sample doesn't trip it.
+	SuccessRateConsecutive int
+	// ProfileDir is where goroutine/heap/mutex pprof profiles are written.
+	ProfileDir string
+	// Cooldown is the minimum time between two captures of the same profile
+	// kind, so a sustained pathology doesn't spam profiles/reports.
+	Cooldown time.Duration
+	// Reporter receives a WatchEvent every time the watcher fires. Defaults
+	// to StderrReporter if left nil.
+	Reporter Reporter
+}
+
+// DefaultWatcherConfig returns reasonable defaults for WatcherConfig,
+// writing profiles under profileDir. Reporter is left nil (StderrReporter
+// is used) until the caller sets one.
+func DefaultWatcherConfig(profileDir string) WatcherConfig {
+	return WatcherConfig{
+		SampleInterval:         10 * time.Second,
+		MaxGoroutines:          5000,
+		MaxCacheSize:           defaultCacheMaxSize * 2,
+		SuccessRateFloor:       0.3,
+		SuccessRateConsecutive: 3,
+		ProfileDir:             profileDir,
+		Cooldown:               5 * time.Minute,
+	}
+}
+
+// WatchEvent is what a Reporter receives when the watcher fires a capture.
+type WatchEvent struct {
+	Kind         string    `json:"kind"` // profile kinds joined by "+": "goroutine+heap+mutex"
+	Reason       string    `json:"reason"`
+	Time         time.Time `json:"time"`
+	Goroutines   int       `json:"goroutines"`
+	HeapInUse    uint64    `json:"heap_in_use_bytes"`
+	CacheSize    int       `json:"cache_size"`
+	SuccessRate  float64   `json:"success_rate"`
+	ProfilePaths []string  `json:"profile_paths"`
+}
+
+// Reporter receives a WatchEvent whenever the Watcher fires a capture.
+// Implementations must not block the watcher's sampling loop for long.
+type Reporter interface {
+	Report(event WatchEvent)
+}
+
+// StderrReporter logs a WatchEvent through logrus, the repo's default
+// logging sink. It's the Reporter used when WatcherConfig.Reporter is nil.
+type StderrReporter struct{}
+
+// Report logs event as a warning.
+func (StderrReporter) Report(event WatchEvent) {
+	logrus.Warnf("🩺 prediction-path watcher fired: kind=%s reason=%q goroutines=%d heap_bytes=%d cache_size=%d success_rate=%.2f profiles=%v",
+		event.Kind, event.Reason, event.Goroutines, event.HeapInUse, event.CacheSize, event.SuccessRate, event.ProfilePaths)
+}
+
+// WebhookReporter posts a WatchEvent as JSON to a configured URL.
+type WebhookReporter struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookReporter creates a WebhookReporter posting to url with a 5s
+// request timeout.
+func NewWebhookReporter(url string) *WebhookReporter {
+	return &WebhookReporter{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Report posts event as JSON to r.URL, logging (but not returning) any
+// failure since Reporter.Report has no error return.
+func (r *WebhookReporter) Report(event WatchEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logrus.Warnf("⚠️ Failed to marshal watcher event: %v", err)
+		return
+	}
+
+	resp, err := r.Client.Post(r.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logrus.Warnf("⚠️ Failed to post watcher event to %s: %v", r.URL, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// SlackReporter posts a WatchEvent to a Slack incoming webhook URL as a
+// simple text summary (Slack ignores unrecognized JSON fields, so the
+// structured WatchEvent fields beyond "text" are informational only).
+type SlackReporter struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewSlackReporter creates a SlackReporter posting to webhookURL with a 5s
+// request timeout.
+func NewSlackReporter(webhookURL string) *SlackReporter {
+	return &SlackReporter{URL: webhookURL, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Report posts event to the configured Slack incoming webhook.
+func (r *SlackReporter) Report(event WatchEvent) {
+	text := fmt.Sprintf("prediction-path watcher fired: kind=%s reason=%s profiles=%v",
+		event.Kind, event.Reason, event.ProfilePaths)
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		logrus.Warnf("⚠️ Failed to marshal Slack watcher payload: %v", err)
+		return
+	}
+
+	resp, err := r.Client.Post(r.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logrus.Warnf("⚠️ Failed to post watcher event to Slack: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// profileKinds are the pprof profiles captured on every fire.
+var profileKinds = []string{"goroutine", "heap", "mutex"}
+
+// Watcher samples the prediction path's health on a ticker and, when
+// goroutines, cache size, or the sliding-window success rate cross a
+// configured threshold, writes goroutine/heap/mutex pprof profiles to disk
+// and reports a WatchEvent through its Reporter. Modeled on autopprof's
+// goroutine-watcher: catch pathological resource growth automatically
+// instead of relying on someone noticing it on a dashboard.
+type Watcher struct {
+	pl     *PredictionLogic
+	config WatcherConfig
+
+	mu            sync.Mutex
+	lastCapture   map[string]time.Time
+	lowSuccessRun int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWatcher creates a Watcher over pl. If config.Reporter is nil, events
+// are reported via StderrReporter.
+func NewWatcher(pl *PredictionLogic, config WatcherConfig) *Watcher {
+	if config.Reporter == nil {
+		config.Reporter = StderrReporter{}
+	}
+	return &Watcher{
+		pl:          pl,
+		config:      config,
+		lastCapture: make(map[string]time.Time),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins the sampling loop in a new goroutine.
+func (w *Watcher) Start() {
+	go w.run()
+}
+
+// Stop ends the sampling loop and waits for it to exit.
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	interval := w.config.SampleInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.sample()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// sample takes one reading and, if any threshold is crossed, captures
+// whichever profile kinds aren't in cooldown and reports the result.
+func (w *Watcher) sample() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	goroutines := runtime.NumGoroutine()
+	cacheSize := w.pl.store.Len()
+	successRate := w.pl.getSuccessRate()
+
+	w.mu.Lock()
+	if w.config.SuccessRateFloor > 0 && successRate < w.config.SuccessRateFloor {
+		w.lowSuccessRun++
+	} else {
+		w.lowSuccessRun = 0
+	}
+	lowSuccessRun := w.lowSuccessRun
+	w.mu.Unlock()
+
+	var reasons []string
+	if w.config.MaxGoroutines > 0 && goroutines > w.config.MaxGoroutines {
+		reasons = append(reasons, fmt.Sprintf("goroutines=%d exceeds max=%d", goroutines, w.config.MaxGoroutines))
+	}
+	if w.config.MaxCacheSize > 0 && cacheSize > w.config.MaxCacheSize {
+		reasons = append(reasons, fmt.Sprintf("cache_size=%d exceeds max=%d", cacheSize, w.config.MaxCacheSize))
+	}
+	if w.config.SuccessRateConsecutive > 0 && lowSuccessRun >= w.config.SuccessRateConsecutive {
+		reasons = append(reasons, fmt.Sprintf("success_rate=%.2f below floor=%.2f for %d consecutive samples",
+			successRate, w.config.SuccessRateFloor, lowSuccessRun))
+	}
+	if len(reasons) == 0 {
+		return
+	}
+
+	event := WatchEvent{
+		Kind:        strings.Join(profileKinds, "+"),
+		Reason:      strings.Join(reasons, "; "),
+		Time:        time.Now(),
+		Goroutines:  goroutines,
+		HeapInUse:   mem.HeapInuse,
+		CacheSize:   cacheSize,
+		SuccessRate: successRate,
+	}
+
+	for _, kind := range profileKinds {
+		if !w.shouldCapture(kind) {
+			continue
+		}
+		path, err := w.capture(kind)
+		if err != nil {
+			logrus.Warnf("⚠️ Failed to capture %s profile: %v", kind, err)
+			continue
+		}
+		event.ProfilePaths = append(event.ProfilePaths, path)
+	}
+
+	if len(event.ProfilePaths) == 0 {
+		// Every kind is still in cooldown; nothing new to report.
+		return
+	}
+
+	w.config.Reporter.Report(event)
+}
+
+// shouldCapture reports whether kind has cleared its per-kind cooldown,
+// and if so marks it as captured now.
+func (w *Watcher) shouldCapture(kind string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if last, ok := w.lastCapture[kind]; ok && time.Since(last) < w.config.Cooldown {
+		return false
+	}
+	w.lastCapture[kind] = time.Now()
+	return true
+}
+
+// capture writes the named pprof profile to a timestamped file under
+// ProfileDir, returning its path.
+func (w *Watcher) capture(kind string) (string, error) {
+	if err := os.MkdirAll(w.config.ProfileDir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(w.config.ProfileDir, fmt.Sprintf("%s-%d.pprof", kind, time.Now().Unix()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	profile := pprof.Lookup(kind)
+	if profile == nil {
+		return "", fmt.Errorf("no registered pprof profile named %q", kind)
+	}
+	if err := profile.WriteTo(f, 0); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -1,15 +1,19 @@
 package analyzer
 
 import (
+	"context"
 	"math"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/analyzer/sequence"
 )
 
 // BehaviorAnalyzer analyzes user behavior patterns
 type BehaviorAnalyzer struct {
-	config AnalyzerConfig
+	config    AnalyzerConfig
+	sequences *sequence.Store
 }
 
 // AnalyzerConfig holds analyzer configuration
@@ -21,8 +25,14 @@ type AnalyzerConfig struct {
 	MinSamplesForPattern     int     `yaml:"min_samples_for_pattern"`
 	PatternConfidenceThreshold float64 `yaml:"pattern_confidence_threshold"`
 	BehaviorCategories       map[string]BehaviorCategory `yaml:"behavior_categories"`
+	SequenceOrder            int     `yaml:"sequence_order"`
+	SequencePersistPath      string  `yaml:"sequence_persist_path"`
 }
 
+// defaultSequencePersistPath is where per-user sequence predictors are
+// persisted when AnalyzerConfig.SequencePersistPath isn't set.
+const defaultSequencePersistPath = "./kronop_sequences.gob"
+
 // BehaviorCategory defines user behavior categories
 type BehaviorCategory struct {
 	ThresholdScrollSpeed float64 `yaml:"threshold_scroll_speed"`
@@ -75,9 +85,86 @@ type Interaction struct {
 // NewBehaviorAnalyzer creates a new behavior analyzer
 func NewBehaviorAnalyzer(config AnalyzerConfig) BehaviorAnalyzer {
 	logrus.Info("🧠 Initializing AI-based Behavior Analyzer")
+
+	persistPath := config.SequencePersistPath
+	if persistPath == "" {
+		persistPath = defaultSequencePersistPath
+	}
+
+	// A failed sequence store is logged and skipped rather than fatal:
+	// sequence-based prediction is an enhancement over the heuristic
+	// scoring below, not load-bearing for it.
+	sequences, err := sequence.NewStore(persistPath, config.SequenceOrder)
+	if err != nil {
+		logrus.Warnf("⚠️ Failed to initialize sequence predictor store: %v", err)
+		sequences = nil
+	}
+
 	return BehaviorAnalyzer{
-		config: config,
+		config:    config,
+		sequences: sequences,
+	}
+}
+
+// ObserveScrollEvent feeds userID's sequence predictor a scroll event,
+// discretized via sequence.ClassifyScroll. No-op if sequence tracking
+// failed to initialize.
+func (ba *BehaviorAnalyzer) ObserveScrollEvent(userID string, scrollSpeed float64) {
+	if ba.sequences == nil {
+		return
+	}
+	ba.sequences.Get(userID).Observe(sequence.ClassifyScroll(scrollSpeed))
+}
+
+// ObserveWatchEvent feeds userID's sequence predictor a watch event,
+// discretized via sequence.ClassifyWatch.
+func (ba *BehaviorAnalyzer) ObserveWatchEvent(userID string, watchSeconds float64) {
+	if ba.sequences == nil {
+		return
+	}
+	ba.sequences.Get(userID).Observe(sequence.ClassifyWatch(watchSeconds))
+}
+
+// ObserveInteraction feeds userID's sequence predictor an interaction
+// event. Interaction types outside sequence's fixed alphabet are dropped.
+func (ba *BehaviorAnalyzer) ObserveInteraction(userID string, interactionType string) {
+	if ba.sequences == nil {
+		return
 	}
+	symbol, ok := sequence.ClassifyInteraction(interactionType)
+	if !ok {
+		return
+	}
+	ba.sequences.Get(userID).Observe(symbol)
+}
+
+// PredictNextSymbols returns userID's n most likely next activity symbols
+// from its sequence predictor, or nil if sequence tracking is disabled.
+func (ba *BehaviorAnalyzer) PredictNextSymbols(userID string, n int) []sequence.Prediction {
+	if ba.sequences == nil {
+		return nil
+	}
+	return ba.sequences.Get(userID).Top(n)
+}
+
+// PersistSequences flushes every user's sequence predictor to disk; see
+// sequence.Store.Save.
+func (ba *BehaviorAnalyzer) PersistSequences() error {
+	if ba.sequences == nil {
+		return nil
+	}
+	return ba.sequences.Save()
+}
+
+// RunSequencePersistence periodically flushes sequence predictors to
+// disk until ctx is cancelled; see sequence.Store.Run. interval <= 0
+// falls back to sequence.DefaultPersistInterval. No-op if sequence
+// tracking failed to initialize.
+func (ba *BehaviorAnalyzer) RunSequencePersistence(ctx context.Context, interval time.Duration) {
+	if ba.sequences == nil {
+		return
+	}
+	ba.sequences.Run(ctx, interval)
 }
 
 // AnalyzeBehavior analyzes user behavior and returns updated profile
@@ -345,8 +432,13 @@ func (ba *BehaviorAnalyzer) calculateScrollConsistency(events []ScrollEvent) flo
 	return consistency
 }
 
-// PredictNextBehavior predicts user's next behavior based on patterns
-func (ba *BehaviorAnalyzer) PredictNextBehavior(profile *BehaviorProfile, recentData *UserBehaviorData) (*BehaviorPrediction, error) {
+// PredictNextBehavior predicts userID's next behavior based on patterns,
+// blending the heuristic trend analysis below with userID's sequence
+// predictor (see PredictNextSymbols) when it has an opinion: a predicted
+// ScrollFast/ScrollSlow bumps NextScrollSpeed's direction, and a
+// predicted WatchLong/WatchShort nudges RecommendedPrefetch, each scaled
+// by the sequence predictor's own confidence in that symbol.
+func (ba *BehaviorAnalyzer) PredictNextBehavior(userID string, profile *BehaviorProfile, recentData *UserBehaviorData) (*BehaviorPrediction, error) {
 	if len(recentData.ScrollEvents) < 3 {
 		return nil, fmt.Errorf("insufficient recent data for prediction")
 	}
@@ -377,12 +469,44 @@ func (ba *BehaviorAnalyzer) PredictNextBehavior(profile *BehaviorProfile, recent
 		prediction.Confidence = newConfidence * 0.8
 	}
 
-	logrus.Debugf("🔮 Behavior prediction: type=%s, confidence=%.2f, next_speed=%.2f", 
+	ba.applySequencePrediction(userID, prediction)
+
+	logrus.Debugf("🔮 Behavior prediction: type=%s, confidence=%.2f, next_speed=%.2f",
 		prediction.PredictedUserType, prediction.Confidence, prediction.NextScrollSpeed)
 
 	return prediction, nil
 }
 
+// applySequencePrediction nudges prediction using userID's sequence
+// predictor's top candidate, if any: a watch-length symbol adjusts
+// RecommendedPrefetch, a scroll-speed symbol nudges NextScrollSpeed up
+// (ScrollFast) or down (ScrollSlow, floored at 0). The adjustment is weighted by the
+// predictor's own probability for that symbol, so a confident sequence
+// predictor moves the prediction more than an uncertain one.
+func (ba *BehaviorAnalyzer) applySequencePrediction(userID string, prediction *BehaviorPrediction) {
+	top := ba.PredictNextSymbols(userID, 1)
+	if len(top) == 0 {
+		return
+	}
+
+	switch top[0].Symbol {
+	case sequence.ScrollFast:
+		prediction.NextScrollSpeed += top[0].Probability
+	case sequence.ScrollSlow:
+		prediction.NextScrollSpeed -= top[0].Probability
+		if prediction.NextScrollSpeed < 0 {
+			prediction.NextScrollSpeed = 0
+		}
+	case sequence.WatchLong:
+		prediction.RecommendedPrefetch += int(math.Round(top[0].Probability * 2))
+	case sequence.WatchShort:
+		prediction.RecommendedPrefetch -= int(math.Round(top[0].Probability * 2))
+		if prediction.RecommendedPrefetch < 1 {
+			prediction.RecommendedPrefetch = 1
+		}
+	}
+}
+
 // BehaviorPrediction represents predicted user behavior
 type BehaviorPrediction struct {
 	PredictedUserType    string    `json:"predicted_user_type"`
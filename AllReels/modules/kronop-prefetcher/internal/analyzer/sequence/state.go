@@ -0,0 +1,56 @@
+package sequence
+
+// predictorState is the gob-serializable mirror of a Predictor's tree and
+// window, used only by Store when persisting to or loading from disk.
+// Predictor itself isn't gob-friendly: node's fields are unexported and
+// `total` is redundant (recomputable from counts), so it's dropped here
+// and rebuilt on load instead of persisted.
+type predictorState struct {
+	Window []Symbol
+	Root   *nodeState
+}
+
+type nodeState struct {
+	Counts   map[Symbol]int
+	Children map[Symbol]*nodeState
+}
+
+func (p *Predictor) state() *predictorState {
+	return &predictorState{
+		Window: append([]Symbol(nil), p.window...),
+		Root:   toNodeState(p.root),
+	}
+}
+
+func toNodeState(n *node) *nodeState {
+	s := &nodeState{
+		Counts:   n.counts,
+		Children: make(map[Symbol]*nodeState, len(n.children)),
+	}
+	for sym, child := range n.children {
+		s.Children[sym] = toNodeState(child)
+	}
+	return s
+}
+
+func fromState(order int, state *predictorState) *Predictor {
+	return &Predictor{
+		order:  order,
+		root:   fromNodeState(state.Root),
+		window: append([]Symbol(nil), state.Window...),
+	}
+}
+
+func fromNodeState(s *nodeState) *node {
+	n := &node{
+		counts:   s.Counts,
+		children: make(map[Symbol]*node, len(s.Children)),
+	}
+	for _, count := range n.counts {
+		n.total += count
+	}
+	for sym, child := range s.Children {
+		n.children[sym] = fromNodeState(child)
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package sequence
+
+import (
+	"context"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultPersistInterval is how often Store.Run flushes every user's
+// predictor to disk.
+const DefaultPersistInterval = 5 * time.Minute
+
+// Store owns one Predictor per user and persists the whole set to a
+// single file, so a warm user's predictions stay accurate across a
+// restart instead of having to relearn their history from scratch.
+type Store struct {
+	mu         sync.RWMutex
+	order      int
+	path       string
+	predictors map[string]*Predictor
+}
+
+// NewStore creates a Store whose predictors use the given order, loading
+// any state previously persisted at path. A missing file isn't an error -
+// it just means no user has warmed up yet. path == "" disables
+// persistence: Get still works, Save is a no-op.
+func NewStore(path string, order int) (*Store, error) {
+	s := &Store{order: order, path: path, predictors: make(map[string]*Predictor)}
+	if err := s.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns userID's Predictor, creating one on first observation.
+func (s *Store) Get(userID string) *Predictor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.predictors[userID]
+	if !ok {
+		p = NewPredictor(s.order)
+		s.predictors[userID] = p
+	}
+	return p
+}
+
+// Save persists every user's predictor to s.path as a gob-encoded
+// snapshot, writing to a temp file and renaming over it so a crash
+// mid-write can't corrupt the previous snapshot.
+func (s *Store) Save() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.path == "" {
+		return nil
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tmp := s.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	snapshot := make(map[string]*predictorState, len(s.predictors))
+	for userID, p := range s.predictors {
+		snapshot[userID] = p.state()
+	}
+	if err := gob.NewEncoder(f).Encode(snapshot); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var snapshot map[string]*predictorState
+	if err := gob.NewDecoder(f).Decode(&snapshot); err != nil {
+		return err
+	}
+	for userID, state := range snapshot {
+		s.predictors[userID] = fromState(s.order, state)
+	}
+	return nil
+}
+
+// Run periodically saves the store to disk until ctx is cancelled,
+// flushing once more on the way out. Save errors are logged rather than
+// fatal: a failed flush just means warm-start loses some history, not
+// that prediction stops working.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPersistInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				logrus.Warnf("⚠️ Failed to persist sequence predictors: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				logrus.Warnf("⚠️ Failed to persist sequence predictors: %v", err)
+			}
+		}
+	}
+}
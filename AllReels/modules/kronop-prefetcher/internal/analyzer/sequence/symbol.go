@@ -0,0 +1,68 @@
+// Package sequence models a user's recent activity as a short string of
+// discretized symbols and predicts the next one with a variable-order
+// Markov chain (PPM-style escape/backoff), giving analyzer.BehaviorAnalyzer
+// a next-event predictor with memory beyond its own 3-event lookback.
+package sequence
+
+// Symbol is one discretized unit of user activity - the alphabet the
+// Markov chain predicts over. Continuous measurements (scroll speed,
+// watch time) are bucketed into a Symbol before they reach Predictor;
+// Predictor itself has no notion of reels, speeds, or durations.
+type Symbol string
+
+// The fixed alphabet Predictor operates over.
+const (
+	ScrollFast         Symbol = "S_fast"
+	ScrollSlow         Symbol = "S_slow"
+	WatchShort         Symbol = "W_short"
+	WatchLong          Symbol = "W_long"
+	InteractionLike    Symbol = "L"
+	InteractionComment Symbol = "C"
+	InteractionShare   Symbol = "Sh"
+	InteractionSave    Symbol = "Sv"
+)
+
+// fastScrollThreshold mirrors the "fast" bucket analyzer.BehaviorCategory
+// configures per-deployment for fast_scroller; this package isn't wired
+// to that config, so it uses a fixed cutoff instead.
+const fastScrollThreshold = 2.0
+
+// longWatchThreshold is the cutoff above which a watch event is bucketed
+// as WatchLong rather than WatchShort.
+const longWatchThreshold = 15.0
+
+// ClassifyScroll buckets a raw scroll speed into ScrollFast or ScrollSlow.
+func ClassifyScroll(speed float64) Symbol {
+	if speed >= fastScrollThreshold {
+		return ScrollFast
+	}
+	return ScrollSlow
+}
+
+// ClassifyWatch buckets a raw watch duration, in seconds, into WatchLong
+// or WatchShort.
+func ClassifyWatch(seconds float64) Symbol {
+	if seconds >= longWatchThreshold {
+		return WatchLong
+	}
+	return WatchShort
+}
+
+// ClassifyInteraction maps an interaction type string (as recorded by
+// analyzer.Interaction) onto its Symbol. ok is false for interaction
+// types outside the fixed alphabet, in which case the caller should
+// drop the event rather than feed it to a Predictor.
+func ClassifyInteraction(interactionType string) (symbol Symbol, ok bool) {
+	switch interactionType {
+	case "like":
+		return InteractionLike, true
+	case "comment":
+		return InteractionComment, true
+	case "share":
+		return InteractionShare, true
+	case "save":
+		return InteractionSave, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,152 @@
+package sequence
+
+import "sort"
+
+const (
+	// DefaultOrder is the depth k of the prediction tree: how many
+	// trailing symbols form the longest context considered when
+	// predicting the next one.
+	DefaultOrder = 4
+
+	// escapeMass is the fixed PPM-C-style count reserved for "a symbol
+	// this context hasn't seen yet" when weighting a context's counts
+	// and deciding how much probability mass escapes to the
+	// next-shorter context.
+	escapeMass = 1.0
+)
+
+// node is one context in the tree: the counts of symbols observed to
+// follow this particular suffix of recent history.
+type node struct {
+	counts   map[Symbol]int
+	total    int
+	children map[Symbol]*node
+}
+
+func newNode() *node {
+	return &node{counts: make(map[Symbol]int), children: make(map[Symbol]*node)}
+}
+
+// Predictor is a per-user variable-order Markov chain over Symbol. It
+// keeps a window of the most recent `order` symbols and a tree of
+// contexts up to that order; Observe walks every suffix of the window
+// (down to the empty context) and bumps the successor count at each,
+// so shorter contexts double as the escape model for longer ones.
+//
+// Predictor is not safe for concurrent use; callers needing per-user
+// locking should keep one behind a mutex. sequence.Store's mutex guards
+// only its map of predictors, not the Predictor that Get returns.
+type Predictor struct {
+	order  int
+	root   *node
+	window []Symbol
+}
+
+// NewPredictor creates a Predictor with the given maximum context order.
+// order <= 0 falls back to DefaultOrder.
+func NewPredictor(order int) *Predictor {
+	if order <= 0 {
+		order = DefaultOrder
+	}
+	return &Predictor{order: order, root: newNode()}
+}
+
+// Observe records that `next` followed the current window at every
+// context length from the full window down to the empty context, then
+// slides `next` into the window, trimming it back to `order` symbols.
+func (p *Predictor) Observe(next Symbol) {
+	for start := 0; start <= len(p.window); start++ {
+		p.insert(p.window[start:], next)
+	}
+
+	p.window = append(p.window, next)
+	if len(p.window) > p.order {
+		p.window = p.window[len(p.window)-p.order:]
+	}
+}
+
+// insert walks ctx from the root, creating nodes as needed, and bumps
+// the successor count for `next` at the resulting context node.
+func (p *Predictor) insert(ctx []Symbol, next Symbol) {
+	n := p.root
+	for _, sym := range ctx {
+		child, ok := n.children[sym]
+		if !ok {
+			child = newNode()
+			n.children[sym] = child
+		}
+		n = child
+	}
+	n.counts[next]++
+	n.total++
+}
+
+// lookup walks ctx from the root and returns the node it resolves to, or
+// nil if ctx has never been observed.
+func (p *Predictor) lookup(ctx []Symbol) *node {
+	n := p.root
+	for _, sym := range ctx {
+		child, ok := n.children[sym]
+		if !ok {
+			return nil
+		}
+		n = child
+	}
+	return n
+}
+
+// Prediction is one candidate next symbol with its estimated probability.
+type Prediction struct {
+	Symbol      Symbol
+	Probability float64
+}
+
+// Top returns up to n candidates for the symbol most likely to follow
+// the current window, highest probability first (ties broken by symbol
+// for deterministic output). n <= 0 returns every candidate the tree has
+// an opinion on.
+func (p *Predictor) Top(n int) []Prediction {
+	probs := make(map[Symbol]float64)
+	p.accumulate(p.window, 1.0, make(map[Symbol]bool), probs)
+
+	preds := make([]Prediction, 0, len(probs))
+	for sym, prob := range probs {
+		preds = append(preds, Prediction{Symbol: sym, Probability: prob})
+	}
+	sort.Slice(preds, func(i, j int) bool {
+		if preds[i].Probability != preds[j].Probability {
+			return preds[i].Probability > preds[j].Probability
+		}
+		return preds[i].Symbol < preds[j].Symbol
+	})
+
+	if n > 0 && len(preds) > n {
+		preds = preds[:n]
+	}
+	return preds
+}
+
+// accumulate distributes `mass` probability across the context found by
+// walking ctx from the root (PPM-style): each symbol the context has
+// seen gets weight mass*count/(total+escapeMass), marked in `excluded` so
+// a shorter context never overrides what a longer one already decided,
+// and the leftover escape mass recurses into ctx[1:] - the next-shorter
+// suffix of the same history.
+func (p *Predictor) accumulate(ctx []Symbol, mass float64, excluded map[Symbol]bool, probs map[Symbol]float64) {
+	if n := p.lookup(ctx); n != nil && n.total > 0 {
+		denom := float64(n.total) + escapeMass
+		for sym, count := range n.counts {
+			if excluded[sym] {
+				continue
+			}
+			probs[sym] += mass * (float64(count) / denom)
+			excluded[sym] = true
+		}
+		mass *= escapeMass / denom
+	}
+
+	if len(ctx) == 0 || mass <= 0 {
+		return
+	}
+	p.accumulate(ctx[1:], mass, excluded, probs)
+}
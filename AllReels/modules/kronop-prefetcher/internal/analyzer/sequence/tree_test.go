@@ -0,0 +1,59 @@
+package sequence
+
+import "testing"
+
+// TestPredictorLearnsRepeatingPattern feeds Predictor a synthetic scroll
+// trace for each of the five analyzer user types and checks it learns to
+// predict the cycle's next symbol once warmed up.
+func TestPredictorLearnsRepeatingPattern(t *testing.T) {
+	cases := []struct {
+		name  string
+		cycle []Symbol
+		want  Symbol
+	}{
+		{"fast_scroller", []Symbol{ScrollFast, ScrollFast, ScrollFast, WatchShort}, ScrollFast},
+		{"slow_viewer", []Symbol{WatchLong, ScrollSlow}, WatchLong},
+		{"binge_watcher", []Symbol{WatchLong, WatchLong, InteractionLike}, WatchLong},
+		{"casual_browser", []Symbol{WatchShort, ScrollFast}, WatchShort},
+		{"normal_viewer", []Symbol{ScrollSlow, WatchShort, ScrollFast}, ScrollSlow},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPredictor(4)
+			for _, sym := range repeat(tc.cycle, 20) {
+				p.Observe(sym)
+			}
+
+			top := p.Top(1)
+			if len(top) == 0 {
+				t.Fatalf("Top(1) returned no predictions")
+			}
+			if top[0].Symbol != tc.want {
+				t.Fatalf("Top(1) = %v, want first symbol %s", top, tc.want)
+			}
+		})
+	}
+}
+
+func TestTopRespectsN(t *testing.T) {
+	p := NewPredictor(2)
+	for _, sym := range repeat([]Symbol{ScrollFast, WatchShort, ScrollSlow}, 10) {
+		p.Observe(sym)
+	}
+
+	if got := p.Top(1); len(got) != 1 {
+		t.Fatalf("Top(1) returned %d predictions, want 1", len(got))
+	}
+	if got := p.Top(0); len(got) == 0 {
+		t.Fatalf("Top(0) returned no predictions, want every candidate")
+	}
+}
+
+func repeat(cycle []Symbol, times int) []Symbol {
+	out := make([]Symbol, 0, len(cycle)*times)
+	for i := 0; i < times; i++ {
+		out = append(out, cycle...)
+	}
+	return out
+}
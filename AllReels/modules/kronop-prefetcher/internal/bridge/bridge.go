@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"context"
+	"time"
+)
+
+// Bridge is the minimal interface ChannelManager dispatches a prefetch task
+// through, so executeRustTask/executeCppTask/executeUrgentTask run the same
+// regardless of whether ChannelConfig.BridgeMode picks the CGO-backed
+// RustBridge/CppBridge transports or a GRPCBridge talking to sidecar worker
+// processes over the network. NewCGOBridge adapts a *RustBridge to this
+// interface; GRPCBridge implements it directly.
+type Bridge interface {
+	// PrefetchChunk asks the engine to prefetch chunkID for reelID.
+	// priority is the task's concurrency.Priority value (0=urgent, 1=high,
+	// 2=medium, 3=low) - bridge can't import concurrency (concurrency
+	// already imports bridge), so it's passed as a plain int. GRPCBridge
+	// uses it to pick the call's deadline (see priorityDeadlines); the
+	// CGO adapter ignores it, since the underlying transport call has no
+	// per-call deadline of its own.
+	PrefetchChunk(ctx context.Context, reelID int, chunkID string, priority int) error
+	HealthCheck() error
+	GetEngineStats() (map[string]interface{}, error)
+	MonitorConnection(interval time.Duration, stopChan <-chan struct{})
+}
+
+// cgoRustAdapter adapts a CGO-backed *RustBridge to the Bridge interface, so
+// ChannelManager can hold a single bridge.Bridge field regardless of
+// ChannelConfig.BridgeMode.
+type cgoRustAdapter struct {
+	rb *RustBridge
+}
+
+// NewCGOBridge wraps rustBridge as a Bridge for ChannelConfig.BridgeMode ==
+// BridgeModeCGO (concurrency package), so ChannelManager's
+// executeRustTask/executeUrgentTask dispatch through the same interface
+// gRPC mode uses.
+func NewCGOBridge(rustBridge *RustBridge) Bridge {
+	return cgoRustAdapter{rb: rustBridge}
+}
+
+func (a cgoRustAdapter) PrefetchChunk(ctx context.Context, reelID int, chunkID string, priority int) error {
+	return a.rb.PrefetchChunk(reelID, chunkID)
+}
+
+func (a cgoRustAdapter) HealthCheck() error {
+	return a.rb.HealthCheck()
+}
+
+func (a cgoRustAdapter) GetEngineStats() (map[string]interface{}, error) {
+	return a.rb.GetEngineStats()
+}
+
+func (a cgoRustAdapter) MonitorConnection(interval time.Duration, stopChan <-chan struct{}) {
+	a.rb.MonitorConnection(interval, stopChan)
+}
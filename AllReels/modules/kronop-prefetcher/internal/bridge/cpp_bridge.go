@@ -6,35 +6,48 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/kronop/prefetcher/internal/metrics"
 	"github.com/sirupsen/logrus"
 )
 
 // CppBridge handles communication with C++ JSI engine
 type CppBridge struct {
 	cppEngineURL string
-	httpClient  *http.Client
-	mu          sync.RWMutex
-	connected   bool
+	httpClient   *http.Client
+	mu           sync.RWMutex
+	connected    bool
+	zcRing       zeroCopyWriter // non-nil once EnableZeroCopy's handshake succeeds
+	metrics      *metrics.Metrics
+}
+
+// SetMetrics attaches a metrics.Metrics bundle; sendRequest latency, frame
+// push counts and the connected gauge are recorded against it once set.
+func (cb *CppBridge) SetMetrics(m *metrics.Metrics) {
+	cb.mu.Lock()
+	cb.metrics = m
+	cb.mu.Unlock()
+	workerMetrics.Store(m)
 }
 
 // CppEngineRequest represents a request to C++ engine
 type CppEngineRequest struct {
-	Type      string      `json:"type"`
-	ReelID    int         `json:"reel_id"`
-	FrameData []byte      `json:"frame_data,omitempty"`
-	Timestamp int64       `json:"timestamp"`
+	Type      string `json:"type"`
+	ReelID    int    `json:"reel_id"`
+	FrameData []byte `json:"frame_data,omitempty"`
+	Timestamp int64  `json:"timestamp"`
 }
 
 // CppEngineResponse represents a response from C++ engine
 type CppEngineResponse struct {
-	Status    string      `json:"status"`
-	Data      []byte      `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Timestamp int64       `json:"timestamp"`
-	FrameInfo *FrameInfo  `json:"frame_info,omitempty"`
+	Status    string     `json:"status"`
+	Data      []byte     `json:"data,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Timestamp int64      `json:"timestamp"`
+	FrameInfo *FrameInfo `json:"frame_info,omitempty"`
 }
 
 // FrameInfo contains frame metadata from C++ engine
@@ -74,8 +87,13 @@ func (cb *CppBridge) Connect() error {
 
 	cb.mu.Lock()
 	cb.connected = true
+	m := cb.metrics
 	cb.mu.Unlock()
 
+	if m != nil {
+		m.EngineConnected.WithLabelValues("cpp").Set(1)
+	}
+
 	logrus.Info("✅ Connected to C++ engine successfully")
 	return nil
 }
@@ -87,12 +105,37 @@ func (cb *CppBridge) IsConnected() bool {
 	return cb.connected
 }
 
-// PushFrameToDisplay pushes a frame to the C++ display system
-func (cb *CppBridge) PushFrameToDisplay(reelID int, frameData []byte) error {
+// PushFrameToDisplay pushes a frame to the C++ display system. When the
+// zero-copy shared-memory ring is up (EnableZeroCopy succeeded), the frame
+// is copied once into the ring and the JSON+HTTP path is skipped entirely.
+func (cb *CppBridge) PushFrameToDisplay(reelID int, frameData []byte) (err error) {
+	cb.mu.RLock()
+	m := cb.metrics
+	cb.mu.RUnlock()
+	if m != nil {
+		defer func() {
+			result := "success"
+			if err != nil {
+				result = "error"
+			}
+			m.FramePushes.WithLabelValues(strconv.Itoa(reelID), result).Inc()
+		}()
+	}
+
 	if !cb.IsConnected() {
 		return fmt.Errorf("not connected to C++ engine")
 	}
 
+	cb.mu.RLock()
+	ring := cb.zcRing
+	cb.mu.RUnlock()
+	if ring != nil {
+		if err := ring.Write(reelID, frameData, false); err == nil {
+			return nil
+		}
+		logrus.Warn("⚠️ zero-copy write failed, falling back to HTTP for this frame")
+	}
+
 	request := CppEngineRequest{
 		Type:      "push_frame",
 		ReelID:    reelID,
@@ -270,6 +313,16 @@ func (cb *CppBridge) GetDisplayStats() (map[string]interface{}, error) {
 
 // sendRequest sends a request to C++ engine
 func (cb *CppBridge) sendRequest(request CppEngineRequest) (*CppEngineResponse, error) {
+	start := time.Now()
+	defer func() {
+		cb.mu.RLock()
+		m := cb.metrics
+		cb.mu.RUnlock()
+		if m != nil {
+			m.RequestLatency.WithLabelValues(request.Type).Observe(time.Since(start).Seconds())
+		}
+	}()
+
 	// Marshal request
 	reqData, err := json.Marshal(request)
 	if err != nil {
@@ -320,12 +373,13 @@ func (cb *CppBridge) PushMultipleFrames(reelID int, frames [][]byte) error {
 	// Push frames concurrently
 	for i, frameData := range frames {
 		wg.Add(1)
-		go func(idx int, data []byte) {
+		idx, data := i, frameData
+		safeGo("cpp_frame_push_worker", func() {
 			defer wg.Done()
 			if err := cb.PushFrameToDisplay(reelID, data); err != nil {
 				errors <- fmt.Errorf("frame %d: %v", idx, err)
 			}
-		}(i, frameData)
+		})
 	}
 
 	// Wait for all pushes to complete
@@ -381,6 +435,14 @@ func (cb *CppBridge) Disconnect() {
 
 	if cb.connected {
 		cb.connected = false
+		if cb.zcRing != nil {
+			cb.zcRing.Close()
+			cb.zcRing = nil
+		}
+		if cb.metrics != nil {
+			cb.metrics.EngineConnected.WithLabelValues("cpp").Set(0)
+			cb.metrics.SetReady(false)
+		}
 		logrus.Info("🔌 Disconnected from C++ engine")
 	}
 }
@@ -405,8 +467,17 @@ func (cb *CppBridge) HealthCheck() error {
 	return nil
 }
 
-// MonitorConnection monitors the connection to C++ engine
+// MonitorConnection monitors the connection to C++ engine until stopChan is
+// closed. A panic inside a single health-check iteration is recovered and
+// the loop re-spawned with backoff (via safeGoMonitor) instead of
+// monitoring silently stopping for good.
 func (cb *CppBridge) MonitorConnection(interval time.Duration, stopChan <-chan struct{}) {
+	safeGoMonitor("cpp_monitor", stopChan, func() {
+		cb.monitorLoop(interval, stopChan)
+	})
+}
+
+func (cb *CppBridge) monitorLoop(interval time.Duration, stopChan <-chan struct{}) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -418,11 +489,16 @@ func (cb *CppBridge) MonitorConnection(interval time.Duration, stopChan <-chan s
 		case <-ticker.C:
 			if err := cb.HealthCheck(); err != nil {
 				logrus.Warnf("⚠️ Health check failed: %v", err)
-				
+				if cb.metrics != nil {
+					cb.metrics.SetReady(false)
+				}
+
 				// Try to reconnect
 				if connectErr := cb.Connect(); connectErr != nil {
 					logrus.Errorf("❌ Reconnection failed: %v", connectErr)
 				}
+			} else if cb.metrics != nil {
+				cb.metrics.SetReady(true)
 			}
 		}
 	}
@@ -507,6 +583,11 @@ func (cb *CppBridge) EnableZeroCopy() error {
 		return fmt.Errorf("zero-copy enable failed: %s", response.Error)
 	}
 
+	if err := cb.enableZeroCopyRing(); err != nil {
+		logrus.Warnf("⚠️ zero-copy ring unavailable, falling back to HTTP: %v", err)
+		return nil
+	}
+
 	logrus.Info("🚀 Zero-copy mode enabled")
 	return nil
 }
@@ -0,0 +1,484 @@
+package bridge
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// prefetchStreamMethod is the fully-qualified gRPC method name for the
+// sidecar prefetch worker's bidirectional stream. There's no .proto for it
+// (see grpc_codec.go), mirroring enginetransport/grpcstream's streamMethod -
+// this is just the string the wire protocol expects.
+const prefetchStreamMethod = "/kronop.prefetchbridge.Bridge/PrefetchStream"
+
+// grpcMaxInFlight bounds how many requests can be outstanding on one pooled
+// stream at once, mirroring enginetransport/grpcstream's maxInFlight.
+const grpcMaxInFlight = 64
+
+// defaultMaxMessageSize is what GRPCBridgeConfig.MaxMessageSize falls back
+// to when left at zero.
+const defaultMaxMessageSize = 16 * 1024 * 1024
+
+// priorityDeadlines are the per-call timeouts PrefetchChunk applies based on
+// the priority passed to it (see Bridge.PrefetchChunk's doc comment for the
+// 0=urgent..3=low mapping): an urgent prefetch fails fast, while a
+// low-priority one may wait far longer before its call is abandoned.
+var priorityDeadlines = map[int]time.Duration{
+	0: 500 * time.Millisecond, // urgent
+	1: 2 * time.Second,        // high
+	2: 5 * time.Second,        // medium
+	3: 30 * time.Second,       // low
+}
+
+// defaultPriorityDeadline applies to calls that don't go through
+// PrefetchChunk (HealthCheck, GetEngineStats) and to any priority missing
+// from priorityDeadlines.
+const defaultPriorityDeadline = 5 * time.Second
+
+// BackoffConfig mirrors google.golang.org/grpc/backoff.Config. GRPCBridge
+// keeps its own copy since it reconnects a hand-rolled bidirectional stream
+// (see pooledStream.reconnectLoop) rather than relying on grpc.ClientConn's
+// built-in backoff, which only governs the underlying transport connection,
+// not a higher-level stream torn down after a read failure.
+type BackoffConfig struct {
+	BaseDelay  time.Duration
+	Multiplier float64
+	Jitter     float64
+	MaxDelay   time.Duration
+}
+
+// DefaultBackoffConfig matches grpc-go's own backoff.DefaultConfig values.
+var DefaultBackoffConfig = BackoffConfig{
+	BaseDelay:  time.Second,
+	Multiplier: 1.6,
+	Jitter:     0.2,
+	MaxDelay:   120 * time.Second,
+}
+
+// GRPCBridgeConfig configures GRPCBridge's connection to one or more
+// sidecar prefetch worker processes.
+type GRPCBridgeConfig struct {
+	// Addrs is the pool of sidecar addresses GRPCBridge dials; each call
+	// round-robins across them (see GRPCBridge.pick). A single-element
+	// slice is a valid "no pooling" configuration.
+	Addrs []string
+
+	// UseTLS selects credentials.NewTLS over insecure.NewCredentials.
+	// ServerName and CACertPath configure the TLS credentials when set;
+	// an empty CACertPath uses the host's root CA pool.
+	UseTLS     bool
+	ServerName string
+	CACertPath string
+
+	// MaxMessageSize bounds both send and receive message size in bytes.
+	// Zero falls back to defaultMaxMessageSize.
+	MaxMessageSize int
+
+	// Backoff governs reconnect delay after a pooled stream dies. Zero
+	// value falls back to DefaultBackoffConfig.
+	Backoff BackoffConfig
+}
+
+// GRPCBridge speaks to one or more sidecar Rust/C++ prefetch workers over a
+// bidirectional streaming RPC (PrefetchStream), as an alternative to the
+// CGO-backed RustBridge/CppBridge transports - see ChannelConfig.BridgeMode
+// in the concurrency package. Unlike enginetransport/grpcstream's single
+// persistent stream, it pools one stream per configured address and
+// reconnects each independently with exponential backoff+jitter, so a
+// prefetch worker can run as its own process or container instead of being
+// CGO-linked into this binary.
+type GRPCBridge struct {
+	cfg     GRPCBridgeConfig
+	streams []*pooledStream
+	next    uint64
+}
+
+// NewGRPCBridge dials cfg.Addrs and starts each pooled stream's reconnect
+// loop in the background; a sidecar that isn't up yet isn't an error here -
+// pooledStream.reconnectLoop keeps retrying until it connects or Close is
+// called.
+func NewGRPCBridge(cfg GRPCBridgeConfig) (*GRPCBridge, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("grpc bridge: at least one addr is required")
+	}
+	if cfg.Backoff == (BackoffConfig{}) {
+		cfg.Backoff = DefaultBackoffConfig
+	}
+
+	gb := &GRPCBridge{cfg: cfg}
+	for _, addr := range cfg.Addrs {
+		gb.streams = append(gb.streams, newPooledStream(addr, cfg))
+	}
+	return gb, nil
+}
+
+// pick round-robins across the pooled streams.
+func (gb *GRPCBridge) pick() *pooledStream {
+	i := atomic.AddUint64(&gb.next, 1)
+	return gb.streams[i%uint64(len(gb.streams))]
+}
+
+// PrefetchChunk asks a pooled sidecar worker to prefetch chunkID for
+// reelID, bounding the call by priorityDeadlines[priority].
+func (gb *GRPCBridge) PrefetchChunk(ctx context.Context, reelID int, chunkID string, priority int) error {
+	deadline, ok := priorityDeadlines[priority]
+	if !ok {
+		deadline = defaultPriorityDeadline
+	}
+	callCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	req := enginetransport.EngineRequest{
+		Type:      "prefetch_chunk",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	}
+
+	if _, err := gb.pick().call(callCtx, req); err != nil {
+		return fmt.Errorf("grpc bridge prefetch failed: %v", err)
+	}
+	return nil
+}
+
+// HealthCheck round-trips a health_check request against one pooled stream.
+func (gb *GRPCBridge) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPriorityDeadline)
+	defer cancel()
+
+	if _, err := gb.pick().call(ctx, enginetransport.EngineRequest{
+		Type:      "health_check",
+		Timestamp: time.Now().Unix(),
+	}); err != nil {
+		return fmt.Errorf("grpc bridge health check failed: %v", err)
+	}
+	return nil
+}
+
+// GetEngineStats fetches and decodes a sidecar worker's engine stats.
+func (gb *GRPCBridge) GetEngineStats() (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPriorityDeadline)
+	defer cancel()
+
+	resp, err := gb.pick().call(ctx, enginetransport.EngineRequest{
+		Type:      "get_engine_stats",
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("grpc bridge stats request failed: %v", err)
+	}
+
+	stats := map[string]interface{}{}
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, &stats); err != nil {
+			return nil, fmt.Errorf("failed to decode engine stats: %v", err)
+		}
+	}
+	return stats, nil
+}
+
+// MonitorConnection health-checks one pooled stream per tick (picked
+// round-robin, see pick) until stopChan closes. Each pooledStream already
+// reconnects itself in the
+// background (see reconnectLoop); this just surfaces a still-unhealthy pool
+// to the logs the same way RustBridge.MonitorConnection does for the CGO
+// transport.
+func (gb *GRPCBridge) MonitorConnection(interval time.Duration, stopChan <-chan struct{}) {
+	safeGoMonitor("grpc_bridge_monitor", stopChan, func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ticker.C:
+				if err := gb.HealthCheck(); err != nil {
+					logrus.Warnf("⚠️ gRPC bridge health check failed: %v", err)
+				}
+			}
+		}
+	})
+}
+
+// Close stops every pooled stream's reconnect loop and tears down its
+// connection.
+func (gb *GRPCBridge) Close() error {
+	var firstErr error
+	for _, ps := range gb.streams {
+		if err := ps.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// pooledStream owns one persistent bidirectional PrefetchStream to a single
+// sidecar address, reconnecting with backoff+jitter whenever it dies.
+// Requests can be sent from multiple goroutines and responses can arrive
+// out of order, so every request carries a RequestID that readLoop uses to
+// route its reply back to the right caller - the same shape as
+// enginetransport/grpcstream's stream, just with a reconnect loop layered
+// on top instead of terminating for good on the first failure.
+type pooledStream struct {
+	addr string
+	cfg  GRPCBridgeConfig
+
+	mu     sync.RWMutex
+	conn   *grpc.ClientConn
+	client grpc.ClientStream
+
+	sendMu sync.Mutex // serializes SendMsg across caller goroutines
+
+	nextID   int64
+	inFlight chan struct{}
+
+	pendingMu sync.Mutex
+	pending   map[int64]chan *enginetransport.EngineResponse
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+func newPooledStream(addr string, cfg GRPCBridgeConfig) *pooledStream {
+	ps := &pooledStream{
+		addr:     addr,
+		cfg:      cfg,
+		inFlight: make(chan struct{}, grpcMaxInFlight),
+		pending:  make(map[int64]chan *enginetransport.EngineResponse),
+		stopChan: make(chan struct{}),
+	}
+	go ps.reconnectLoop()
+	return ps
+}
+
+// reconnectLoop keeps a bidirectional stream to addr open, redialing with
+// exponential backoff+jitter (ps.cfg.Backoff) whenever the current
+// generation dies, until stopChan closes.
+func (ps *pooledStream) reconnectLoop() {
+	delay := ps.cfg.Backoff.BaseDelay
+
+	for {
+		broken, err := ps.connect()
+		if err != nil {
+			logrus.Warnf("⚠️ gRPC bridge failed to connect to %s: %v", ps.addr, err)
+			select {
+			case <-ps.stopChan:
+				return
+			case <-time.After(withJitter(delay, ps.cfg.Backoff.Jitter)):
+			}
+			delay = time.Duration(float64(delay) * ps.cfg.Backoff.Multiplier)
+			if delay > ps.cfg.Backoff.MaxDelay {
+				delay = ps.cfg.Backoff.MaxDelay
+			}
+			continue
+		}
+
+		logrus.Infof("🔗 gRPC bridge connected to %s", ps.addr)
+		delay = ps.cfg.Backoff.BaseDelay
+
+		select {
+		case <-broken:
+			logrus.Warnf("⚠️ gRPC bridge stream to %s died, reconnecting", ps.addr)
+		case <-ps.stopChan:
+			return
+		}
+	}
+}
+
+// withJitter randomizes delay by +/- fraction, matching how grpc's own
+// exponential backoff avoids every client reconnecting in lockstep.
+func withJitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || delay <= 0 {
+		return delay
+	}
+	spread := float64(delay) * fraction
+	return delay + time.Duration(spread*(2*rand.Float64()-1))
+}
+
+// connect dials addr and opens the PrefetchStream, starting the generation's
+// reader goroutine. The returned channel closes when that generation's
+// stream dies, signaling reconnectLoop to redial.
+func (ps *pooledStream) connect() (chan struct{}, error) {
+	creds := insecure.NewCredentials()
+	if ps.cfg.UseTLS {
+		tlsCreds, err := loadTLSCredentials(ps.cfg)
+		if err != nil {
+			return nil, err
+		}
+		creds = tlsCreds
+	}
+
+	maxSize := ps.cfg.MaxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+
+	conn, err := grpc.Dial(ps.addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(
+			grpc.CallContentSubtype(grpcBridgeCodecName),
+			grpc.MaxCallRecvMsgSize(maxSize),
+			grpc.MaxCallSendMsgSize(maxSize),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %v", ps.addr, err)
+	}
+
+	client, err := conn.NewStream(context.Background(), &grpc.StreamDesc{
+		StreamName:    "PrefetchStream",
+		ServerStreams: true,
+		ClientStreams: true,
+	}, prefetchStreamMethod)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open PrefetchStream to %s: %v", ps.addr, err)
+	}
+
+	broken := make(chan struct{})
+
+	ps.mu.Lock()
+	if ps.conn != nil {
+		ps.conn.Close()
+	}
+	ps.conn = conn
+	ps.client = client
+	ps.mu.Unlock()
+
+	go ps.readLoop(client, broken)
+	return broken, nil
+}
+
+// readLoop is this generation's single reader goroutine, dispatching each
+// response to the pending call it correlates with via RequestID. It exits
+// (closing broken) as soon as RecvMsg fails, which is also how a
+// disconnect gets noticed by every caller blocked in call().
+func (ps *pooledStream) readLoop(client grpc.ClientStream, broken chan struct{}) {
+	defer close(broken)
+	for {
+		var resp enginetransport.EngineResponse
+		if err := client.RecvMsg(&resp); err != nil {
+			logrus.Warnf("⚠️ gRPC bridge stream to %s read failed: %v", ps.addr, err)
+			ps.failPending(err)
+			return
+		}
+
+		ps.pendingMu.Lock()
+		reply, ok := ps.pending[resp.RequestID]
+		ps.pendingMu.Unlock()
+		if !ok {
+			logrus.Warnf("⚠️ gRPC bridge response for unknown request %d from %s", resp.RequestID, ps.addr)
+			continue
+		}
+		reply <- &resp
+	}
+}
+
+// failPending delivers err to every call() currently waiting on this
+// generation's stream, so a dead connection doesn't leave them blocked
+// until their caller's context expires.
+func (ps *pooledStream) failPending(err error) {
+	ps.pendingMu.Lock()
+	defer ps.pendingMu.Unlock()
+	for id, reply := range ps.pending {
+		reply <- &enginetransport.EngineResponse{RequestID: id, Error: err.Error()}
+	}
+}
+
+// call sends req over the current generation's stream and blocks until its
+// matching response arrives or ctx is done.
+func (ps *pooledStream) call(ctx context.Context, req enginetransport.EngineRequest) (*enginetransport.EngineResponse, error) {
+	ps.mu.RLock()
+	client := ps.client
+	ps.mu.RUnlock()
+	if client == nil {
+		return nil, fmt.Errorf("gRPC bridge not connected to %s", ps.addr)
+	}
+
+	select {
+	case ps.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-ps.inFlight }()
+
+	req.RequestID = atomic.AddInt64(&ps.nextID, 1)
+
+	reply := make(chan *enginetransport.EngineResponse, 1)
+	ps.pendingMu.Lock()
+	ps.pending[req.RequestID] = reply
+	ps.pendingMu.Unlock()
+	defer func() {
+		ps.pendingMu.Lock()
+		delete(ps.pending, req.RequestID)
+		ps.pendingMu.Unlock()
+	}()
+
+	ps.sendMu.Lock()
+	err := client.SendMsg(&req)
+	ps.sendMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %v", ps.addr, err)
+	}
+
+	select {
+	case resp := <-reply:
+		if resp.Error != "" {
+			return resp, fmt.Errorf("%s", resp.Error)
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops this stream's reconnect loop and tears down its connection.
+func (ps *pooledStream) Close() error {
+	ps.stopOnce.Do(func() {
+		close(ps.stopChan)
+	})
+
+	ps.mu.RLock()
+	conn := ps.conn
+	ps.mu.RUnlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// loadTLSCredentials builds TLS transport credentials from cfg, trusting
+// the host's root CA pool unless cfg.CACertPath overrides it.
+func loadTLSCredentials(cfg GRPCBridgeConfig) (credentials.TransportCredentials, error) {
+	tlsConfig := &tls.Config{ServerName: cfg.ServerName}
+
+	if cfg.CACertPath != "" {
+		caCert, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert %s: %v", cfg.CACertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CA cert %s", cfg.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,34 @@
+package bridge
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// grpcBridgeCodecName is registered as a gRPC content-subtype so
+// PrefetchStream can carry plain enginetransport structs without a
+// .proto-generated message type, the same approach
+// enginetransport/grpcstream uses for the Rust engine's own stream. Named
+// distinctly from that package's "json" codec since both can be registered
+// in the same binary.
+const grpcBridgeCodecName = "kronop-grpcbridge-json"
+
+func init() {
+	encoding.RegisterCodec(grpcBridgeCodec{})
+}
+
+// grpcBridgeCodec implements grpc/encoding.Codec on top of encoding/json.
+type grpcBridgeCodec struct{}
+
+func (grpcBridgeCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (grpcBridgeCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (grpcBridgeCodec) Name() string {
+	return grpcBridgeCodecName
+}
@@ -0,0 +1,360 @@
+package bridge
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HLSMuxerConfig configures segment/part durations and playlist window size
+type HLSMuxerConfig struct {
+	Addr              string        // address the embedded HTTP server listens on
+	SegmentDuration   time.Duration // target duration of a full media segment
+	PartDuration      time.Duration // target duration of an LL-HLS partial segment
+	WindowSize        int           // number of segments retained in the live playlist
+	PlaylistBlockWait time.Duration // max time to block a playlist reload request
+}
+
+// DefaultHLSMuxerConfig returns sane defaults (2s segments, 6 segment window)
+func DefaultHLSMuxerConfig() HLSMuxerConfig {
+	return HLSMuxerConfig{
+		Addr:              ":8088",
+		SegmentDuration:   2 * time.Second,
+		PartDuration:      333 * time.Millisecond,
+		WindowSize:        6,
+		PlaylistBlockWait: 3 * time.Second,
+	}
+}
+
+// hlsPart is an LL-HLS partial segment within an in-progress segment
+type hlsPart struct {
+	index       int
+	data        []byte
+	independent bool
+}
+
+// hlsSegment is a completed media segment, possibly built from parts
+type hlsSegment struct {
+	sequence int
+	data     []byte
+	duration time.Duration
+	parts    []hlsPart
+}
+
+// HLSMuxer buffers pushed frames into HLS/LL-HLS segments and serves them
+// alongside the existing JSON-over-HTTP bridge so browser players can
+// consume the same frame stream CppBridge hands to the C++ JSI engine.
+type HLSMuxer struct {
+	cfg HLSMuxerConfig
+
+	mu            sync.RWMutex
+	segments      map[int]*hlsSegment
+	mediaSequence int
+	discSequence  int
+
+	curFrames   [][]byte
+	curParts    []hlsPart
+	curStarted  time.Time
+	partStarted time.Time
+
+	server   *http.Server
+	notifyMu sync.Mutex
+	notify   chan struct{}
+}
+
+// NewHLSMuxer creates a muxer that feeds off the same frames pushed to CppBridge
+func NewHLSMuxer(cfg HLSMuxerConfig) *HLSMuxer {
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = 2 * time.Second
+	}
+	if cfg.PartDuration <= 0 {
+		cfg.PartDuration = 333 * time.Millisecond
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = 6
+	}
+	if cfg.PlaylistBlockWait <= 0 {
+		cfg.PlaylistBlockWait = 3 * time.Second
+	}
+
+	now := time.Now()
+	return &HLSMuxer{
+		cfg:         cfg,
+		segments:    make(map[int]*hlsSegment),
+		curStarted:  now,
+		partStarted: now,
+		notify:      make(chan struct{}),
+	}
+}
+
+// PushFrame accepts a frame in place of (or alongside) PushFrameToDisplay and
+// folds it into the current in-progress segment, cutting a new LL-HLS part
+// or full segment once the configured durations elapse.
+func (m *HLSMuxer) PushFrame(reelID int, frameData []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.curFrames = append(m.curFrames, frameData)
+
+	now := time.Now()
+	if now.Sub(m.partStarted) >= m.cfg.PartDuration {
+		m.cutPartLocked(now)
+	}
+	if now.Sub(m.curStarted) >= m.cfg.SegmentDuration {
+		m.cutSegmentLocked(now)
+	}
+}
+
+// StreamFrames drains frameChan into the muxer until stopChan closes,
+// mirroring CppBridge.StreamFrames but feeding HLS output instead.
+func (m *HLSMuxer) StreamFrames(reelID int, frameChan <-chan []byte, stopChan <-chan struct{}) {
+	for {
+		select {
+		case <-stopChan:
+			return
+		case frameData, ok := <-frameChan:
+			if !ok {
+				return
+			}
+			m.PushFrame(reelID, frameData)
+		}
+	}
+}
+
+func (m *HLSMuxer) cutPartLocked(now time.Time) {
+	if len(m.curFrames) == 0 {
+		return
+	}
+	part := hlsPart{
+		index:       len(m.curParts),
+		data:        joinFrames(m.curFrames),
+		independent: len(m.curParts) == 0,
+	}
+	m.curParts = append(m.curParts, part)
+	m.curFrames = nil
+	m.partStarted = now
+	m.broadcastLocked()
+}
+
+func (m *HLSMuxer) cutSegmentLocked(now time.Time) {
+	m.cutPartLocked(now)
+	if len(m.curParts) == 0 {
+		return
+	}
+
+	seg := &hlsSegment{
+		sequence: m.mediaSequence,
+		duration: now.Sub(m.curStarted),
+		parts:    m.curParts,
+	}
+	for _, p := range seg.parts {
+		seg.data = append(seg.data, p.data...)
+	}
+
+	m.segments[seg.sequence] = seg
+	m.mediaSequence++
+	m.curParts = nil
+	m.curStarted = now
+	m.partStarted = now
+
+	m.pruneLocked()
+	m.broadcastLocked()
+
+	logrus.Debugf("📺 HLS segment cut: seq=%d duration=%s", seg.sequence, seg.duration)
+}
+
+func (m *HLSMuxer) pruneLocked() {
+	oldest := m.mediaSequence - m.cfg.WindowSize
+	for seq := range m.segments {
+		if seq < oldest {
+			delete(m.segments, seq)
+			m.discSequence++
+		}
+	}
+}
+
+// broadcastLocked wakes any blocking playlist reload waiters
+func (m *HLSMuxer) broadcastLocked() {
+	m.notifyMu.Lock()
+	close(m.notify)
+	m.notify = make(chan struct{})
+	m.notifyMu.Unlock()
+}
+
+func (m *HLSMuxer) waitChan() <-chan struct{} {
+	m.notifyMu.Lock()
+	defer m.notifyMu.Unlock()
+	return m.notify
+}
+
+func joinFrames(frames [][]byte) []byte {
+	var out []byte
+	for _, f := range frames {
+		out = append(out, f...)
+	}
+	return out
+}
+
+// Start launches the embedded HTTP server serving playlist.m3u8 and segments
+func (m *HLSMuxer) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/playlist.m3u8", m.handlePlaylist)
+	mux.HandleFunc("/seg/", m.handleSegment)
+
+	m.server = &http.Server{Addr: m.cfg.Addr, Handler: mux}
+	logrus.Infof("📺 HLS muxer listening on %s", m.cfg.Addr)
+
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("❌ HLS server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts down the embedded HTTP server
+func (m *HLSMuxer) Stop() error {
+	if m.server == nil {
+		return nil
+	}
+	return m.server.Close()
+}
+
+// handlePlaylist renders the live media playlist, honoring LL-HLS blocking
+// reload via the _HLS_msn / _HLS_part query parameters.
+func (m *HLSMuxer) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	msn, part, blocking := parseBlockingReloadQuery(r)
+	if blocking {
+		m.blockUntilAvailable(msn, part)
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Write([]byte(m.renderPlaylist()))
+}
+
+func parseBlockingReloadQuery(r *http.Request) (msn, part int, blocking bool) {
+	q := r.URL.Query()
+	msnStr := q.Get("_HLS_msn")
+	if msnStr == "" {
+		return 0, 0, false
+	}
+	msn, err := strconv.Atoi(msnStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	if partStr := q.Get("_HLS_part"); partStr != "" {
+		part, _ = strconv.Atoi(partStr)
+	}
+	return msn, part, true
+}
+
+// blockUntilAvailable waits (bounded by PlaylistBlockWait) until the
+// requested media sequence/part has been cut, as required for LL-HLS
+// blocking playlist reload.
+func (m *HLSMuxer) blockUntilAvailable(msn, part int) {
+	deadline := time.Now().Add(m.cfg.PlaylistBlockWait)
+	for {
+		if m.hasReached(msn, part) || time.Now().After(deadline) {
+			return
+		}
+		ch := m.waitChan()
+		select {
+		case <-ch:
+		case <-time.After(time.Until(deadline)):
+			return
+		}
+	}
+}
+
+func (m *HLSMuxer) hasReached(msn, part int) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.mediaSequence > msn {
+		return true
+	}
+	if m.mediaSequence == msn && len(m.curParts) > part {
+		return true
+	}
+	return false
+}
+
+func (m *HLSMuxer) renderPlaylist() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString("#EXTM3U\n")
+	sb.WriteString("#EXT-X-VERSION:9\n")
+	sb.WriteString(fmt.Sprintf("#EXT-X-TARGETDURATION:%d\n", int(m.cfg.SegmentDuration.Seconds()+0.5)))
+	sb.WriteString(fmt.Sprintf("#EXT-X-PART-INF:PART-TARGET=%.3f\n", m.cfg.PartDuration.Seconds()))
+	sb.WriteString(fmt.Sprintf("#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=%.3f\n", 3*m.cfg.PartDuration.Seconds()))
+
+	oldest := m.mediaSequence - m.cfg.WindowSize
+	if oldest < 0 {
+		oldest = 0
+	}
+	sb.WriteString(fmt.Sprintf("#EXT-X-MEDIA-SEQUENCE:%d\n", oldest))
+	sb.WriteString(fmt.Sprintf("#EXT-X-DISCONTINUITY-SEQUENCE:%d\n", m.discSequence))
+
+	for seq := oldest; seq < m.mediaSequence; seq++ {
+		seg, ok := m.segments[seq]
+		if !ok {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("#EXTINF:%.3f,\n", seg.duration.Seconds()))
+		sb.WriteString(fmt.Sprintf("seg/%d.ts\n", seg.sequence))
+	}
+
+	// In-progress segment: advertise its parts so LL-HLS players can fetch
+	// them individually ahead of the segment being finalized.
+	for _, p := range m.curParts {
+		independent := ""
+		if p.independent {
+			independent = ",INDEPENDENT=YES"
+		}
+		sb.WriteString(fmt.Sprintf("#EXT-X-PART:DURATION=%.3f,URI=\"seg/%d.part%d.ts\"%s\n",
+			m.cfg.PartDuration.Seconds(), m.mediaSequence, p.index, independent))
+	}
+
+	return sb.String()
+}
+
+func (m *HLSMuxer) handleSegment(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/seg/")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if strings.Contains(name, ".part") {
+		var seq, idx int
+		if _, err := fmt.Sscanf(name, "%d.part%d.ts", &seq, &idx); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if seq != m.mediaSequence || idx >= len(m.curParts) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp2t")
+		w.Write(m.curParts[idx].data)
+		return
+	}
+
+	var seq int
+	if _, err := fmt.Sscanf(name, "%d.ts", &seq); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	seg, ok := m.segments[seq]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Write(seg.data)
+}
@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FrameSource is anything that can hand CppBridge a live sequence of video
+// frames along with their metadata, implemented by the ingest package's
+// RTSP/RTMP clients (or any other pluggable live feed).
+type FrameSource interface {
+	// Next blocks until the next frame is available, returning its raw
+	// payload and frame metadata. It returns an error once the stream ends.
+	Next() ([]byte, *FrameInfo, error)
+
+	// Close releases the underlying connection/listener.
+	Close() error
+}
+
+// attachedSources tracks the live sources currently feeding reels, so they
+// can be torn down from DetachSource.
+var (
+	attachedMu      sync.Mutex
+	attachedSources = map[int]func(){}
+)
+
+// AttachSource reads frames from src and forwards them through the same
+// StreamFrames pipeline used for prefetched VOD chunks, so live
+// camera/broadcaster feeds reach the C++ display engine without a
+// bespoke demuxer. Streaming into reelID only starts once the first
+// keyframe has arrived, so the display never opens on a broken picture.
+func (cb *CppBridge) AttachSource(reelID int, src FrameSource) error {
+	frameChan := make(chan []byte, 4)
+	stopChan := make(chan struct{})
+
+	attachedMu.Lock()
+	attachedSources[reelID] = func() { close(stopChan) }
+	attachedMu.Unlock()
+
+	go func() {
+		defer close(frameChan)
+		defer src.Close()
+
+		sawKeyFrame := false
+		for {
+			select {
+			case <-stopChan:
+				return
+			default:
+			}
+
+			frame, info, err := src.Next()
+			if err != nil {
+				logrus.Warnf("⚠️ ingest source for reel %d ended: %v", reelID, err)
+				return
+			}
+			if !sawKeyFrame {
+				if info == nil || !info.IsKeyFrame {
+					continue // wait for the first IDR/keyframe before starting
+				}
+				sawKeyFrame = true
+				logrus.Infof("🔑 reel %d: first keyframe received, starting stream", reelID)
+			}
+
+			select {
+			case frameChan <- frame:
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		if err := cb.StreamFrames(reelID, frameChan, stopChan); err != nil {
+			logrus.Errorf("❌ ingest stream for reel %d stopped: %v", reelID, err)
+		}
+	}()
+
+	return nil
+}
+
+// DetachSource stops forwarding frames from the source previously attached
+// to reelID via AttachSource.
+func (cb *CppBridge) DetachSource(reelID int) {
+	attachedMu.Lock()
+	stop, ok := attachedSources[reelID]
+	delete(attachedSources, reelID)
+	attachedMu.Unlock()
+
+	if ok {
+		stop()
+	}
+}
@@ -1,88 +1,174 @@
 package bridge
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+	"github.com/kronop/prefetcher/internal/enginetransport/grpcstream"
+	"github.com/kronop/prefetcher/internal/enginetransport/shmring"
+	"github.com/kronop/prefetcher/internal/enginetransport/unixsock"
 )
 
-// RustBridge handles communication with Rust video engine
-type RustBridge struct {
-	rustEngineURL string
-	httpClient   *http.Client
-	mu           sync.RWMutex
-	connected    bool
+// RustEngineRequest, RustEngineResponse and VideoChunk are aliases onto the
+// shared enginetransport types, so existing call sites built against
+// RustBridge keep compiling unchanged even though the real definitions now
+// live where every transport backend can share them.
+type RustEngineRequest = enginetransport.EngineRequest
+type RustEngineResponse = enginetransport.EngineResponse
+type VideoChunk = enginetransport.VideoChunk
+
+// EngineTransportKind selects which enginetransport.Transport backend a
+// RustBridge talks through.
+type EngineTransportKind string
+
+const (
+	// TransportGRPCStream is a persistent gRPC bidirectional stream; it
+	// works over a network and is the default.
+	TransportGRPCStream EngineTransportKind = "grpc"
+	// TransportUnixSocket is a Unix domain socket with MessagePack
+	// framing, for an engine co-located on the same host.
+	TransportUnixSocket EngineTransportKind = "unix"
+	// TransportSHMRing is a pair of shared-memory ring buffers for
+	// zero-copy chunk transfer with a co-located engine (Linux only).
+	TransportSHMRing EngineTransportKind = "shm"
+)
+
+// EngineTransportConfig selects and configures the transport a RustBridge
+// uses to reach the Rust engine.
+type EngineTransportConfig struct {
+	Kind EngineTransportKind
+	// Addr is the gRPC dial target for TransportGRPCStream, the socket
+	// path for TransportUnixSocket, or the shm region name for
+	// TransportSHMRing.
+	Addr string
 }
 
-// RustEngineRequest represents a request to Rust engine
-type RustEngineRequest struct {
-	Type      string      `json:"type"`
-	ReelID    int         `json:"reel_id"`
-	ChunkID   string      `json:"chunk_id"`
-	Data      []byte      `json:"data,omitempty"`
-	Timestamp int64       `json:"timestamp"`
+// RustBridge handles communication with the Rust video engine through a
+// pluggable enginetransport.Transport, so operators can pick the fastest
+// path available for their deployment without any code change: a gRPC
+// stream over the network, a Unix socket for a co-located engine, or a
+// shared-memory ring for zero-copy chunk transfer on the same host. The
+// WAL and reconnect-monitoring logic here are transport-agnostic and work
+// the same regardless of which backend is active.
+type RustBridge struct {
+	mu        sync.RWMutex
+	transport enginetransport.Transport
+	connected bool
+
+	wal       *rustWAL
+	lastAcked uint32 // highest WAL request number the engine has applied
 }
 
-// RustEngineResponse represents a response from Rust engine
-type RustEngineResponse struct {
-	Status    string      `json:"status"`
-	Data      []byte      `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Timestamp int64       `json:"timestamp"`
-	ReelID    int         `json:"reel_id"`
-	Ready     bool        `json:"ready"`
+// NewRustBridge creates a RustBridge using the backend cfg.Kind selects.
+func NewRustBridge(cfg EngineTransportConfig) (*RustBridge, error) {
+	var t enginetransport.Transport
+	switch cfg.Kind {
+	case TransportGRPCStream, "":
+		t = grpcstream.New(cfg.Addr)
+	case TransportUnixSocket:
+		t = unixsock.New(cfg.Addr)
+	case TransportSHMRing:
+		t = shmring.New(cfg.Addr)
+	default:
+		return nil, fmt.Errorf("unknown engine transport kind %q", cfg.Kind)
+	}
+
+	return &RustBridge{transport: t}, nil
 }
 
-// VideoChunk represents a video chunk from Rust engine
-type VideoChunk struct {
-	ID          string    `json:"id"`
-	ReelID      int       `json:"reel_id"`
-	Data        []byte    `json:"data"`
-	Size        int       `json:"size"`
-	Timestamp   int64     `json:"timestamp"`
-	IsKeyFrame  bool      `json:"is_key_frame"`
-	Sequence    int       `json:"sequence"`
-	Compressed  bool      `json:"compressed"`
+// Connect opens the underlying transport to the Rust engine.
+func (rb *RustBridge) Connect() error {
+	if err := rb.transport.Connect(); err != nil {
+		return err
+	}
+
+	rb.mu.Lock()
+	rb.connected = true
+	rb.mu.Unlock()
+	return nil
 }
 
-// NewRustBridge creates a new Rust bridge
-func NewRustBridge(rustEngineURL string) *RustBridge {
-	return &RustBridge{
-		rustEngineURL: rustEngineURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		connected: false,
+// EnableWAL opens (or creates) a write-ahead log at path that durably
+// records outgoing prefetch/warmup requests, so they survive a Rust engine
+// crash instead of being dropped. Call before Connect.
+func (rb *RustBridge) EnableWAL(path string) error {
+	wal, err := newRustWAL(path)
+	if err != nil {
+		return fmt.Errorf("failed to enable Rust engine WAL: %v", err)
 	}
+
+	rb.mu.Lock()
+	rb.wal = wal
+	rb.mu.Unlock()
+	return nil
 }
 
-// Connect establishes connection with Rust engine
-func (rb *RustBridge) Connect() error {
-	logrus.Infof("🔗 Connecting to Rust engine at %s", rb.rustEngineURL)
+// RecoverFromRequestNumber replays WAL entries from the last acknowledged
+// request number forward through yield, stopping cleanly the first time
+// yield returns an error. It's a no-op if EnableWAL was never called.
+func (rb *RustBridge) RecoverFromRequestNumber(from uint32, yield func(req RustEngineRequest) error) error {
+	rb.mu.RLock()
+	wal := rb.wal
+	rb.mu.RUnlock()
 
-	// Test connection
-	resp, err := rb.httpClient.Get(rb.rustEngineURL + "/health")
-	if err != nil {
-		return fmt.Errorf("failed to connect to Rust engine: %v", err)
+	if wal == nil {
+		return nil
 	}
-	defer resp.Body.Close()
+	return wal.RecoverFromRequestNumber(from, yield)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Rust engine health check failed: %s", resp.Status)
+// replayPending replays any WAL entries the engine hasn't acknowledged yet,
+// advancing lastAcked as each one is successfully resent. It's called after
+// a reconnect so prefetch/warmup intent recorded while the engine was down
+// gets caught up rather than starting cold. The WAL only ever holds
+// prefetch_chunk requests (see PrefetchChunk), so replaying one is always
+// a PrefetchChunk call on the transport.
+func (rb *RustBridge) replayPending() {
+	rb.mu.RLock()
+	wal := rb.wal
+	from := atomic.LoadUint32(&rb.lastAcked) + 1
+	rb.mu.RUnlock()
+
+	if wal == nil {
+		return
 	}
 
-	rb.mu.Lock()
-	rb.connected = true
-	rb.mu.Unlock()
+	replayed := uint32(0)
+	err := wal.RecoverFromRequestNumber(from, func(req RustEngineRequest) error {
+		if err := rb.transport.PrefetchChunk(req.ReelID, req.ChunkID); err != nil {
+			return err
+		}
+		replayed++
+		rb.advanceAcked(from + replayed - 1)
+		return nil
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ WAL replay stopped early: %v", err)
+		return
+	}
+	if replayed > 0 {
+		logrus.Infof("✅ Replayed %d pending request(s) from WAL", replayed)
+	}
+}
 
-	logrus.Info("✅ Connected to Rust engine successfully")
-	return nil
+// advanceAcked raises lastAcked to num if num is higher than the current
+// value, guarding against a replay and a concurrent fresh PrefetchChunk
+// racing each other.
+func (rb *RustBridge) advanceAcked(num uint32) {
+	for {
+		cur := atomic.LoadUint32(&rb.lastAcked)
+		if num <= cur {
+			return
+		}
+		if atomic.CompareAndSwapUint32(&rb.lastAcked, cur, num) {
+			return
+		}
+	}
 }
 
 // IsConnected checks if Rust bridge is connected
@@ -98,38 +184,19 @@ func (rb *RustBridge) RequestChunk(reelID int, chunkID string) (*VideoChunk, err
 		return nil, fmt.Errorf("not connected to Rust engine")
 	}
 
-	request := RustEngineRequest{
-		Type:      "get_chunk",
-		ReelID:    reelID,
-		ChunkID:   chunkID,
-		Timestamp: time.Now().Unix(),
-	}
-
-	response, err := rb.sendRequest(request)
+	chunk, err := rb.transport.RequestChunk(reelID, chunkID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to request chunk: %v", err)
-	}
-
-	if response.Status != "success" {
-		return nil, fmt.Errorf("Rust engine error: %s", response.Error)
-	}
-
-	// Parse video chunk
-	var chunk VideoChunk
-	if err := json.Unmarshal(response.Data, &chunk); err != nil {
-		return nil, fmt.Errorf("failed to parse chunk: %v", err)
+		return nil, err
 	}
 
 	logrus.Debugf("📦 Received chunk: reel=%d, chunk=%s, size=%d", reelID, chunkID, chunk.Size)
-	return &chunk, nil
+	return chunk, nil
 }
 
-// PrefetchChunk prefetches a video chunk
+// PrefetchChunk prefetches a video chunk. If the Rust engine is currently
+// unreachable and a WAL is enabled, the request is queued durably instead
+// of failing, and gets replayed once the engine reconnects.
 func (rb *RustBridge) PrefetchChunk(reelID int, chunkID string) error {
-	if !rb.IsConnected() {
-		return fmt.Errorf("not connected to Rust engine")
-	}
-
 	request := RustEngineRequest{
 		Type:      "prefetch_chunk",
 		ReelID:    reelID,
@@ -137,13 +204,34 @@ func (rb *RustBridge) PrefetchChunk(reelID int, chunkID string) error {
 		Timestamp: time.Now().Unix(),
 	}
 
-	response, err := rb.sendRequest(request)
-	if err != nil {
+	rb.mu.RLock()
+	wal := rb.wal
+	rb.mu.RUnlock()
+
+	var walNum uint32
+	if wal != nil {
+		num, err := wal.Append(request)
+		if err != nil {
+			logrus.Warnf("⚠️ failed to WAL prefetch request: %v", err)
+		} else {
+			walNum = num
+		}
+	}
+
+	if !rb.IsConnected() {
+		if wal != nil {
+			logrus.Debugf("📝 engine down, queued prefetch in WAL: reel=%d, chunk=%s", reelID, chunkID)
+			return nil
+		}
+		return fmt.Errorf("not connected to Rust engine")
+	}
+
+	if err := rb.transport.PrefetchChunk(reelID, chunkID); err != nil {
 		return fmt.Errorf("failed to prefetch chunk: %v", err)
 	}
 
-	if response.Status != "success" {
-		return fmt.Errorf("prefetch failed: %s", response.Error)
+	if walNum > 0 {
+		rb.advanceAcked(walNum)
 	}
 
 	logrus.Debugf("⚡ Prefetched chunk: reel=%d, chunk=%s", reelID, chunkID)
@@ -155,32 +243,7 @@ func (rb *RustBridge) IsChunkReady(reelID int, chunkID string) (bool, error) {
 	if !rb.IsConnected() {
 		return false, fmt.Errorf("not connected to Rust engine")
 	}
-
-	request := RustEngineRequest{
-		Type:      "is_ready",
-		ReelID:    reelID,
-		ChunkID:   chunkID,
-		Timestamp: time.Now().Unix(),
-	}
-
-	response, err := rb.sendRequest(request)
-	if err != nil {
-		return false, fmt.Errorf("failed to check chunk readiness: %v", err)
-	}
-
-	if response.Status != "success" {
-		return false, fmt.Errorf("readiness check failed: %s", response.Error)
-	}
-
-	// Parse readiness response
-	var readyResponse struct {
-		Ready bool `json:"ready"`
-	}
-	if err := json.Unmarshal(response.Data, &readyResponse); err != nil {
-		return false, fmt.Errorf("failed to parse readiness response: %v", err)
-	}
-
-	return readyResponse.Ready, nil
+	return rb.transport.IsChunkReady(reelID, chunkID)
 }
 
 // GetCurrentFrame gets the current frame from Rust engine
@@ -189,23 +252,13 @@ func (rb *RustBridge) GetCurrentFrame(reelID int) ([]byte, error) {
 		return nil, fmt.Errorf("not connected to Rust engine")
 	}
 
-	request := RustEngineRequest{
-		Type:      "get_current_frame",
-		ReelID:    reelID,
-		Timestamp: time.Now().Unix(),
-	}
-
-	response, err := rb.sendRequest(request)
+	frame, err := rb.transport.GetCurrentFrame(reelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current frame: %v", err)
+		return nil, err
 	}
 
-	if response.Status != "success" {
-		return nil, fmt.Errorf("failed to get frame: %s", response.Error)
-	}
-
-	logrus.Debugf("🎬 Got current frame: reel=%d, size=%d", reelID, len(response.Data))
-	return response.Data, nil
+	logrus.Debugf("🎬 Got current frame: reel=%d, size=%d", reelID, len(frame))
+	return frame, nil
 }
 
 // GetEngineStats gets statistics from Rust engine
@@ -213,67 +266,7 @@ func (rb *RustBridge) GetEngineStats() (map[string]interface{}, error) {
 	if !rb.IsConnected() {
 		return nil, fmt.Errorf("not connected to Rust engine")
 	}
-
-	request := RustEngineRequest{
-		Type:      "get_stats",
-		Timestamp: time.Now().Unix(),
-	}
-
-	response, err := rb.sendRequest(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get stats: %v", err)
-	}
-
-	if response.Status != "success" {
-		return nil, fmt.Errorf("stats request failed: %s", response.Error)
-	}
-
-	var stats map[string]interface{}
-	if err := json.Unmarshal(response.Data, &stats); err != nil {
-		return nil, fmt.Errorf("failed to parse stats: %v", err)
-	}
-
-	return stats, nil
-}
-
-// sendRequest sends a request to Rust engine
-func (rb *RustBridge) sendRequest(request RustEngineRequest) (*RustEngineResponse, error) {
-	// Marshal request
-	reqData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequest("POST", rb.rustEngineURL+"/api/v1/request", bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Kronop-Prefetcher/1.0")
-
-	// Send request
-	resp, err := rb.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	// Parse response
-	var response RustEngineResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
-	}
-
-	return &response, nil
+	return rb.transport.GetEngineStats()
 }
 
 // PrefetchMultiple prefetches multiple chunks concurrently
@@ -288,12 +281,13 @@ func (rb *RustBridge) PrefetchMultiple(reelID int, chunkIDs []string) error {
 	// Prefetch chunks concurrently
 	for _, chunkID := range chunkIDs {
 		wg.Add(1)
-		go func(id string) {
+		id := chunkID
+		safeGo("rust_prefetch_worker", func() {
 			defer wg.Done()
 			if err := rb.PrefetchChunk(reelID, id); err != nil {
 				errors <- err
 			}
-		}(chunkID)
+		})
 	}
 
 	// Wait for all prefetches to complete
@@ -316,32 +310,59 @@ func (rb *RustBridge) PrefetchMultiple(reelID int, chunkIDs []string) error {
 	return nil
 }
 
-// GetCacheStatus gets cache status from Rust engine
-func (rb *RustBridge) GetCacheStatus() (map[string]interface{}, error) {
+// rangePrefetchConcurrency bounds how many PrefetchRange chunks are in
+// flight at once. Back when RustBridge spoke HTTP, a single large response
+// body had to be read in full before the caller saw any of it; now that it
+// talks over a persistent stream (see enginetransport), each chunk already
+// arrives as its own discrete message, so the equivalent memory cap is
+// simply limiting concurrent in-flight chunks rather than decoding one
+// giant body incrementally.
+const rangePrefetchConcurrency = 8
+
+// PrefetchRange prefetches chunks for sequence numbers [startSeq, endSeq]
+// (inclusive), calling onReady with each chunk id as soon as it's ready
+// rather than waiting for the whole range, so a caller can start decoding
+// early chunks while later ones are still being prefetched.
+func (rb *RustBridge) PrefetchRange(reelID, startSeq, endSeq int, onReady func(chunkID string)) error {
+	if endSeq < startSeq {
+		return fmt.Errorf("invalid range: end %d before start %d", endSeq, startSeq)
+	}
 	if !rb.IsConnected() {
-		return nil, fmt.Errorf("not connected to Rust engine")
+		return fmt.Errorf("not connected to Rust engine")
 	}
 
-	request := RustEngineRequest{
-		Type:      "get_cache_status",
-		Timestamp: time.Now().Unix(),
-	}
+	sem := make(chan struct{}, rangePrefetchConcurrency)
+	var wg sync.WaitGroup
+	errors := make(chan error, endSeq-startSeq+1)
 
-	response, err := rb.sendRequest(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cache status: %v", err)
-	}
+	for seq := startSeq; seq <= endSeq; seq++ {
+		chunkID := fmt.Sprintf("chunk_%d", seq)
+		wg.Add(1)
+		sem <- struct{}{}
+		safeGo("rust_range_prefetch_worker", func() {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-	if response.Status != "success" {
-		return nil, fmt.Errorf("cache status request failed: %s", response.Error)
+			if err := rb.PrefetchChunk(reelID, chunkID); err != nil {
+				errors <- fmt.Errorf("chunk %s: %v", chunkID, err)
+				return
+			}
+			if onReady != nil {
+				onReady(chunkID)
+			}
+		})
 	}
 
-	var status map[string]interface{}
-	if err := json.Unmarshal(response.Data, &status); err != nil {
-		return nil, fmt.Errorf("failed to parse cache status: %v", err)
+	wg.Wait()
+	close(errors)
+
+	if err, ok := <-errors; ok {
+		logrus.Warnf("⚠️ range prefetch for reel %d failed: %v", reelID, err)
+		return err
 	}
 
-	return status, nil
+	logrus.Infof("✅ Prefetched range [%d,%d] for reel %d", startSeq, endSeq, reelID)
+	return nil
 }
 
 // WarmupCache warms up the cache with initial chunks
@@ -367,14 +388,16 @@ func (rb *RustBridge) WarmupCache(reelID int, numChunks int) error {
 	return nil
 }
 
-// Disconnect closes the connection to Rust engine
+// Disconnect closes the underlying transport to the Rust engine
 func (rb *RustBridge) Disconnect() {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
 
 	if rb.connected {
+		if err := rb.transport.Disconnect(); err != nil {
+			logrus.Warnf("⚠️ error disconnecting from Rust engine: %v", err)
+		}
 		rb.connected = false
-		logrus.Info("🔌 Disconnected from Rust engine")
 	}
 }
 
@@ -384,22 +407,25 @@ func (rb *RustBridge) HealthCheck() error {
 		return fmt.Errorf("not connected to Rust engine")
 	}
 
-	stats, err := rb.GetEngineStats()
-	if err != nil {
+	if err := rb.transport.HealthCheck(); err != nil {
 		return fmt.Errorf("health check failed: %v", err)
 	}
 
-	// Check if engine is running
-	if running, ok := stats["is_running"].(bool); !ok || !running {
-		return fmt.Errorf("Rust engine is not running")
-	}
-
 	logrus.Debug("✅ Rust engine health check passed")
 	return nil
 }
 
-// MonitorConnection monitors the connection to Rust engine
+// MonitorConnection monitors the connection to Rust engine until stopChan
+// is closed. A panic inside a single health-check iteration is recovered
+// and the loop re-spawned with backoff (via safeGoMonitor) instead of
+// monitoring silently stopping for good.
 func (rb *RustBridge) MonitorConnection(interval time.Duration, stopChan <-chan struct{}) {
+	safeGoMonitor("rust_monitor", stopChan, func() {
+		rb.monitorLoop(interval, stopChan)
+	})
+}
+
+func (rb *RustBridge) monitorLoop(interval time.Duration, stopChan <-chan struct{}) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -411,10 +437,13 @@ func (rb *RustBridge) MonitorConnection(interval time.Duration, stopChan <-chan
 		case <-ticker.C:
 			if err := rb.HealthCheck(); err != nil {
 				logrus.Warnf("⚠️ Health check failed: %v", err)
-				
-				// Try to reconnect
+
+				// Try to reconnect, then catch the engine up on anything
+				// it missed while it was down.
 				if connectErr := rb.Connect(); connectErr != nil {
 					logrus.Errorf("❌ Reconnection failed: %v", connectErr)
+				} else {
+					rb.replayPending()
 				}
 			}
 		}
@@ -0,0 +1,150 @@
+package bridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+// rustWAL is an append-only, crash-durable log of outgoing
+// RustEngineRequests (at least prefetch/warmup ops), so a prefetch hint
+// survives a Rust engine crash instead of being dropped when
+// MonitorConnection finds the engine unreachable. Entries are replayed
+// forward from RecoverFromRequestNumber, mirroring the classic
+// InfluxDB-style WAL replay: scan from an offset, stop early if the caller
+// says so, and discard a torn write at the tail rather than treat it as
+// corruption.
+type rustWAL struct {
+	mu   sync.Mutex
+	f    *os.File
+	next uint32 // request number that will be assigned to the next Append
+}
+
+// walEntryHeader precedes every JSON payload in the log.
+type walEntryHeader struct {
+	RequestNumber uint32
+	Length        uint32
+	CRC32         uint32
+}
+
+// newRustWAL opens (creating if needed) the WAL file at path and scans it
+// once to recover the next request number to assign.
+func newRustWAL(path string) (*rustWAL, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %v", err)
+	}
+
+	w := &rustWAL{f: f, next: 1}
+	err = w.scan(0, func(num uint32, _ RustEngineRequest) error {
+		w.next = num + 1
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to scan WAL: %v", err)
+	}
+	return w, nil
+}
+
+// Append assigns the next request number to req, durably writes it, and
+// returns the assigned number.
+func (w *rustWAL) Append(req RustEngineRequest) (uint32, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal WAL entry: %v", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	num := w.next
+	hdr := walEntryHeader{
+		RequestNumber: num,
+		Length:        uint32(len(payload)),
+		CRC32:         crc32.ChecksumIEEE(payload),
+	}
+
+	if _, err := w.f.Seek(0, io.SeekEnd); err != nil {
+		return 0, fmt.Errorf("failed to seek WAL: %v", err)
+	}
+	if err := binary.Write(w.f, binary.BigEndian, hdr); err != nil {
+		return 0, fmt.Errorf("failed to write WAL header: %v", err)
+	}
+	if _, err := w.f.Write(payload); err != nil {
+		return 0, fmt.Errorf("failed to write WAL payload: %v", err)
+	}
+	if err := w.f.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync WAL: %v", err)
+	}
+
+	w.next = num + 1
+	return num, nil
+}
+
+// RecoverFromRequestNumber replays every WAL entry with a request number at
+// or above from, in order, through yield. It stops cleanly the first time
+// yield returns a non-nil error, returning that error to the caller so a
+// partially-applied engine restart doesn't silently skip ahead.
+func (w *rustWAL) RecoverFromRequestNumber(from uint32, yield func(req RustEngineRequest) error) error {
+	return w.scan(from, func(_ uint32, req RustEngineRequest) error {
+		return yield(req)
+	})
+}
+
+// scan reads entries from the start of the file, skipping any with a
+// request number below from, and calls fn for the rest in order. A
+// truncated header, a short payload, or a checksum mismatch at the tail
+// (the torn-write case, e.g. the process was killed mid-Append) ends the
+// scan without error, since that entry was never durably completed.
+func (w *rustWAL) scan(from uint32, fn func(num uint32, req RustEngineRequest) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek WAL: %v", err)
+	}
+	r := bufio.NewReader(w.f)
+
+	for {
+		var hdr walEntryHeader
+		if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
+			break
+		}
+
+		payload := make([]byte, hdr.Length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(payload) != hdr.CRC32 {
+			break
+		}
+
+		if hdr.RequestNumber < from {
+			continue
+		}
+
+		var req RustEngineRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return fmt.Errorf("failed to decode WAL entry %d: %v", hdr.RequestNumber, err)
+		}
+
+		if err := fn(hdr.RequestNumber, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the underlying WAL file.
+func (w *rustWAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
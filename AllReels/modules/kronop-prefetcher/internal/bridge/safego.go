@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/metrics"
+)
+
+// workerMetrics is attached by CppBridge.SetMetrics (the one existing entry
+// point that wires a metrics.Metrics bundle into this package), and read by
+// every safeGo/safeGoMonitor worker regardless of which bridge spawned it.
+var workerMetrics atomic.Pointer[metrics.Metrics]
+
+const (
+	monitorBackoffStart = time.Second
+	monitorBackoffMax   = 30 * time.Second
+)
+
+// safeGo runs fn in a new goroutine, recovering any panic instead of
+// letting it crash the process. name identifies the worker in logs and in
+// the bridge_worker_panics_total metric. Use for short-lived, one-shot
+// workers such as a single prefetch or frame push.
+func safeGo(name string, fn func()) {
+	go runSupervised(name, fn)
+}
+
+// safeGoMonitor runs fn, recovering any panic the same way safeGo does, and
+// re-spawns fn with exponential backoff (capped at monitorBackoffMax) if it
+// panics, so a long-lived monitor loop survives a single bad iteration
+// instead of going dark for good. fn is expected to run until stopChan is
+// closed; safeGoMonitor stops respawning once that happens. Unlike safeGo,
+// this runs in the calling goroutine rather than spawning a new one, so
+// callers that already do `go bridge.MonitorConnection(...)` keep the same
+// blocking-until-stopped contract.
+func safeGoMonitor(name string, stopChan <-chan struct{}, fn func()) {
+	backoff := monitorBackoffStart
+	for {
+		runSupervised(name, fn)
+
+		select {
+		case <-stopChan:
+			return
+		default:
+		}
+
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > monitorBackoffMax {
+			backoff = monitorBackoffMax
+		}
+	}
+}
+
+// runSupervised runs fn in the calling goroutine, recovering a panic,
+// logging it with a full stack trace, and counting it against
+// bridge_worker_panics_total{worker=name} if a metrics.Metrics is attached.
+func runSupervised(name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("🔥 recovered panic in %s worker: %v\n%s", name, r, debug.Stack())
+			if m := workerMetrics.Load(); m != nil {
+				m.WorkerPanics.WithLabelValues(name).Inc()
+			}
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,286 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+	"github.com/sirupsen/logrus"
+)
+
+// catalogEntry describes one reel "track" announced to a client on the
+// control stream at session start, derived from FrameInfo.
+type catalogEntry struct {
+	ReelID int    `json:"reel_id"`
+	Codec  string `json:"codec"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	FPS    int    `json:"fps"`
+}
+
+// controlMessage is exchanged on the session's bidirectional control stream.
+// Clients send "subscribe"/"unsubscribe" with a ReelID; the server replies
+// with the initial "catalog".
+type controlMessage struct {
+	Type   string         `json:"type"` // "catalog" | "subscribe" | "unsubscribe"
+	Reels  []catalogEntry `json:"reels,omitempty"`
+	ReelID int            `json:"reel_id,omitempty"`
+}
+
+// wtClient tracks one connected WebTransport session and the set of reels
+// it has subscribed to.
+type wtClient struct {
+	session *webtransport.Session
+	control webtransport.Stream
+
+	mu          sync.Mutex
+	subscribed  map[int]bool
+	openStreams map[int]webtransport.SendStream // reelID -> current keyframe-started stream
+}
+
+// CppBridgeWebTransport pushes frames to subscribed clients over HTTP/3
+// WebTransport streams instead of POSTing JSON to /api/v1/jsi. Each reel is
+// a logical track: keyframes start a new unidirectional stream and
+// subsequent P-frames are appended to it, so a dropped/out-of-order client
+// only loses the frames on its own independently-framed stream.
+type CppBridgeWebTransport struct {
+	server *webtransport.Server
+	codec  string
+
+	mu      sync.RWMutex
+	catalog map[int]catalogEntry
+	clients map[*wtClient]struct{}
+}
+
+// NewCppBridgeWebTransport creates a WebTransport-based delivery path that
+// can run alongside the JSON-over-HTTP CppBridge.
+func NewCppBridgeWebTransport(addr, certFile, keyFile, codec string) *CppBridgeWebTransport {
+	wt := &CppBridgeWebTransport{
+		server: &webtransport.Server{
+			H3: http3.Server{Addr: addr},
+		},
+		codec:   codec,
+		catalog: make(map[int]catalogEntry),
+		clients: make(map[*wtClient]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wt/reels", wt.handleSession)
+	wt.server.H3.Handler = mux
+
+	return wt
+}
+
+// Serve starts accepting WebTransport sessions; call in a goroutine.
+func (wt *CppBridgeWebTransport) Serve(certFile, keyFile string) error {
+	logrus.Infof("🚀 WebTransport bridge listening for reel sessions")
+	return wt.server.ListenAndServeTLS(certFile, keyFile)
+}
+
+// Close shuts the WebTransport server down.
+func (wt *CppBridgeWebTransport) Close() error {
+	return wt.server.Close()
+}
+
+func (wt *CppBridgeWebTransport) handleSession(w http.ResponseWriter, r *http.Request) {
+	session, err := wt.server.Upgrade(w, r)
+	if err != nil {
+		logrus.Errorf("❌ WebTransport upgrade failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	control, err := session.AcceptStream(r.Context())
+	if err != nil {
+		logrus.Errorf("❌ failed to accept control stream: %v", err)
+		return
+	}
+
+	client := &wtClient{
+		session:     session,
+		control:     control,
+		subscribed:  make(map[int]bool),
+		openStreams: make(map[int]webtransport.SendStream),
+	}
+
+	wt.mu.Lock()
+	wt.clients[client] = struct{}{}
+	wt.mu.Unlock()
+
+	wt.sendCatalog(client)
+	go wt.readControl(client)
+}
+
+// sendCatalog writes the current reel catalog to a client's control stream.
+func (wt *CppBridgeWebTransport) sendCatalog(client *wtClient) {
+	wt.mu.RLock()
+	entries := make([]catalogEntry, 0, len(wt.catalog))
+	for _, e := range wt.catalog {
+		entries = append(entries, e)
+	}
+	wt.mu.RUnlock()
+
+	msg := controlMessage{Type: "catalog", Reels: entries}
+	writeControlMessage(client.control, msg)
+}
+
+func (wt *CppBridgeWebTransport) readControl(client *wtClient) {
+	defer func() {
+		wt.mu.Lock()
+		delete(wt.clients, client)
+		wt.mu.Unlock()
+	}()
+
+	for {
+		msg, err := readControlMessage(client.control)
+		if err != nil {
+			return
+		}
+
+		client.mu.Lock()
+		switch msg.Type {
+		case "subscribe":
+			client.subscribed[msg.ReelID] = true
+		case "unsubscribe":
+			delete(client.subscribed, msg.ReelID)
+			delete(client.openStreams, msg.ReelID)
+		}
+		client.mu.Unlock()
+	}
+}
+
+// UpdateCatalog registers/updates a reel's track metadata, derived from
+// FrameInfo, so newly connecting clients see it in their catalog.
+func (wt *CppBridgeWebTransport) UpdateCatalog(reelID int, info *FrameInfo, fps int) {
+	wt.mu.Lock()
+	wt.catalog[reelID] = catalogEntry{
+		ReelID: reelID,
+		Codec:  wt.codec,
+		Width:  info.Width,
+		Height: info.Height,
+		FPS:    fps,
+	}
+	wt.mu.Unlock()
+}
+
+// PushFrameToDisplay fans a frame out to every client subscribed to reelID,
+// starting a fresh unidirectional stream on keyframes and otherwise
+// appending to the stream already open for that reel.
+func (wt *CppBridgeWebTransport) PushFrameToDisplay(reelID int, frameData []byte, isKeyFrame bool) error {
+	wt.mu.RLock()
+	clients := make([]*wtClient, 0, len(wt.clients))
+	for c := range wt.clients {
+		clients = append(clients, c)
+	}
+	wt.mu.RUnlock()
+
+	var firstErr error
+	for _, client := range clients {
+		client.mu.Lock()
+		subscribed := client.subscribed[reelID]
+		client.mu.Unlock()
+		if !subscribed {
+			continue
+		}
+		if err := wt.pushToClient(client, reelID, frameData, isKeyFrame); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (wt *CppBridgeWebTransport) pushToClient(client *wtClient, reelID int, frameData []byte, isKeyFrame bool) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	str, open := client.openStreams[reelID]
+	if isKeyFrame || !open {
+		if open {
+			str.Close()
+		}
+		newStr, err := client.session.OpenUniStream()
+		if err != nil {
+			return fmt.Errorf("failed to open reel stream: %v", err)
+		}
+		client.openStreams[reelID] = newStr
+		str = newStr
+	}
+
+	if _, err := str.Write(frameData); err != nil {
+		delete(client.openStreams, reelID)
+		return fmt.Errorf("failed to write frame: %v", err)
+	}
+	return nil
+}
+
+// StreamFrames drains frameChan into PushFrameToDisplay until stopChan
+// closes, mirroring CppBridge.StreamFrames but over WebTransport streams.
+func (wt *CppBridgeWebTransport) StreamFrames(reelID int, frameChan <-chan []byte, keyFrameChan <-chan bool, stopChan <-chan struct{}) {
+	for {
+		select {
+		case <-stopChan:
+			return
+		case frameData, ok := <-frameChan:
+			if !ok {
+				return
+			}
+			isKeyFrame := false
+			select {
+			case isKeyFrame = <-keyFrameChan:
+			default:
+			}
+			if err := wt.PushFrameToDisplay(reelID, frameData, isKeyFrame); err != nil {
+				logrus.Warnf("⚠️ WebTransport frame push failed: %v", err)
+			}
+		}
+	}
+}
+
+// writeControlMessage length-prefixes a JSON-encoded control message so the
+// peer can frame messages on the shared bidirectional control stream.
+func writeControlMessage(str webtransport.Stream, msg controlMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(body)))
+	if _, err := str.Write(lenPrefix[:]); err != nil {
+		return err
+	}
+	_, err = str.Write(body)
+	return err
+}
+
+func readControlMessage(str webtransport.Stream) (controlMessage, error) {
+	var lenPrefix [4]byte
+	if _, err := readFull(str, lenPrefix[:]); err != nil {
+		return controlMessage{}, err
+	}
+	size := binary.BigEndian.Uint32(lenPrefix[:])
+	body := make([]byte, size)
+	if _, err := readFull(str, body); err != nil {
+		return controlMessage{}, err
+	}
+	var msg controlMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return controlMessage{}, err
+	}
+	return msg, nil
+}
+
+func readFull(str webtransport.Stream, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := str.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
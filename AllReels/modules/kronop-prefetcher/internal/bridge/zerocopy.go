@@ -0,0 +1,10 @@
+package bridge
+
+// zeroCopyWriter is the platform-specific shared-memory ring that
+// PushFrameToDisplay writes into once EnableZeroCopy succeeds. The only
+// implementation today is the Linux POSIX-shm ring in zerocopy_linux.go;
+// other platforms fall back transparently to the HTTP path.
+type zeroCopyWriter interface {
+	Write(reelID int, frame []byte, isKeyFrame bool) error
+	Close() error
+}
@@ -0,0 +1,153 @@
+//go:build linux
+
+package bridge
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// zcHeader is the fixed-size header written at the start of every slot,
+// mirroring FrameInfo plus the bookkeeping needed to claim/consume a slot.
+type zcHeader struct {
+	ReelID     int64
+	Timestamp  int64
+	Seq        uint64
+	Size       uint64
+	IsKeyFrame uint64
+}
+
+const zcHeaderSize = 40 // 5 * int64/uint64 fields, fixed on-disk layout
+
+// zeroCopyRing is a POSIX-shm ring buffer of fixed-size slots that
+// PushFrameToDisplay writes into directly instead of marshaling frames to
+// JSON and POSTing them over HTTP.
+type zeroCopyRing struct {
+	fd       int
+	mem      []byte
+	slotSize int
+	numSlots int
+	seq      uint64 // atomically incremented slot claim counter
+	eventFD  int
+}
+
+// newZeroCopyRing shm_opens (via /dev/shm, the portable equivalent on
+// Linux) a region sized numSlots*(maxFrameBytes+header), mmaps it, and
+// creates an eventfd the C++ side polls to learn a new slot was written.
+func newZeroCopyRing(name string, numSlots, maxFrameBytes int) (*zeroCopyRing, error) {
+	slotSize := maxFrameBytes + zcHeaderSize
+	totalSize := slotSize * numSlots
+
+	path := "/dev/shm/" + name
+	fd, err := unix.Open(path, unix.O_CREAT|unix.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("shm_open failed: %v", err)
+	}
+
+	if err := unix.Ftruncate(fd, int64(totalSize)); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to size shm region: %v", err)
+	}
+
+	mem, err := unix.Mmap(fd, 0, totalSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("mmap failed: %v", err)
+	}
+
+	eventFD, err := unix.Eventfd(0, unix.EFD_CLOEXEC)
+	if err != nil {
+		unix.Munmap(mem)
+		unix.Close(fd)
+		return nil, fmt.Errorf("eventfd failed: %v", err)
+	}
+
+	return &zeroCopyRing{
+		fd:       fd,
+		mem:      mem,
+		slotSize: slotSize,
+		numSlots: numSlots,
+		eventFD:  eventFD,
+	}, nil
+}
+
+// Write claims the next slot and copies frame into it exactly once,
+// writing the header fields first so the consumer can validate size/seq
+// before reading the payload.
+func (r *zeroCopyRing) Write(reelID int, frame []byte, isKeyFrame bool) error {
+	if len(frame) > r.slotSize-zcHeaderSize {
+		return fmt.Errorf("frame of %d bytes exceeds slot capacity %d", len(frame), r.slotSize-zcHeaderSize)
+	}
+
+	seq := atomic.AddUint64(&r.seq, 1)
+	slot := int(seq % uint64(r.numSlots))
+	offset := slot * r.slotSize
+
+	hdr := zcHeader{
+		ReelID:     int64(reelID),
+		Timestamp:  time.Now().UnixNano(),
+		Seq:        seq,
+		Size:       uint64(len(frame)),
+		IsKeyFrame: boolToUint64(isKeyFrame),
+	}
+	putHeader(r.mem[offset:offset+zcHeaderSize], hdr)
+	copy(r.mem[offset+zcHeaderSize:], frame)
+
+	return r.signal()
+}
+
+// signal writes to the eventfd so the C++ consumer wakes up without
+// polling the ring.
+func (r *zeroCopyRing) signal() error {
+	var buf [8]byte
+	buf[0] = 1
+	_, err := unix.Write(r.eventFD, buf[:])
+	return err
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func putHeader(dst []byte, h zcHeader) {
+	putInt64(dst[0:8], h.ReelID)
+	putInt64(dst[8:16], h.Timestamp)
+	putUint64(dst[16:24], h.Seq)
+	putUint64(dst[24:32], h.Size)
+	putUint64(dst[32:40], h.IsKeyFrame)
+}
+
+func putInt64(dst []byte, v int64) { putUint64(dst, uint64(v)) }
+
+func putUint64(dst []byte, v uint64) {
+	for i := 0; i < 8; i++ {
+		dst[i] = byte(v >> (8 * i))
+	}
+}
+
+// Close unmaps and closes the shm region and eventfd.
+func (r *zeroCopyRing) Close() error {
+	unix.Close(r.eventFD)
+	unix.Munmap(r.mem)
+	return unix.Close(r.fd)
+}
+
+// enableZeroCopyRing is called once EnableZeroCopy's RPC handshake
+// succeeds, standing up the shared-memory ring this platform supports.
+func (cb *CppBridge) enableZeroCopyRing() error {
+	ring, err := newZeroCopyRing(fmt.Sprintf("kronop-frames-%d", time.Now().UnixNano()), 64, 4*1024*1024)
+	if err != nil {
+		return err
+	}
+
+	cb.mu.Lock()
+	cb.zcRing = ring
+	cb.mu.Unlock()
+	return nil
+}
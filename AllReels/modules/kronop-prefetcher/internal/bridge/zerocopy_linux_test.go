@@ -0,0 +1,26 @@
+//go:build linux
+
+package bridge
+
+import "testing"
+
+// BenchmarkPushFrame measures zeroCopyRing.Write, the path
+// PushFrameToDisplay takes once the ring is up, and reports heap
+// allocations per push; it does not assert an allocation count.
+func BenchmarkPushFrame(b *testing.B) {
+	ring, err := newZeroCopyRing("kronop-bench-frames", 8, 64*1024)
+	if err != nil {
+		b.Skipf("zero-copy ring unavailable: %v", err)
+	}
+	defer ring.Close()
+
+	frame := make([]byte, 32*1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ring.Write(1, frame, i == 0); err != nil {
+			b.Fatalf("write failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package bridge
+
+import "fmt"
+
+// enableZeroCopyRing reports that the POSIX-shm ring isn't available on
+// this platform, so EnableZeroCopy falls back to the HTTP path.
+func (cb *CppBridge) enableZeroCopyRing() error {
+	return fmt.Errorf("zero-copy shared-memory transport is only supported on linux")
+}
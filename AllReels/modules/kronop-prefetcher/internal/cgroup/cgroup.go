@@ -0,0 +1,84 @@
+// Package cgroup reads container resource limits/usage from the cgroup
+// filesystem, autodetecting v1 vs v2, so the prediction cache can size
+// itself against what the container actually has rather than a static
+// config value. On platforms without a cgroup filesystem (Darwin/Windows
+// dev machines, or a process running outside any container) Reader falls
+// back cleanly to Go runtime heap stats.
+package cgroup
+
+import (
+	"os"
+	"sync"
+)
+
+// DefaultMountpoint is where the cgroup filesystem is mounted on nearly
+// every Linux distribution.
+const DefaultMountpoint = "/sys/fs/cgroup"
+
+// Reader exposes the container resource figures PredictionLogic needs to
+// size and throttle itself: how much memory it's allowed, how much it's
+// using, its CPU quota in cores, and (v2 only) memory pressure.
+type Reader interface {
+	// MemoryLimit returns the container's memory limit in bytes. Returns
+	// math.MaxInt64 (effectively "unlimited") when no limit is set.
+	MemoryLimit() (uint64, error)
+	// MemoryUsage returns current memory usage in bytes.
+	MemoryUsage() (uint64, error)
+	// CPUQuota returns the CPU quota in whole-or-fractional cores, e.g. 1.5
+	// for "1500m". Returns 0 when no quota is set.
+	CPUQuota() (float64, error)
+	// MemoryPressure returns the some-avg10 PSI figure (percentage of the
+	// last 10s some task was stalled on memory), for cgroup v2 only.
+	// Returns (0, ErrUnsupported) under v1 or the runtime fallback.
+	MemoryPressure() (float64, error)
+}
+
+// ErrUnsupported is returned by a Reader method the active backend has no
+// way to answer (e.g. MemoryPressure under cgroup v1).
+var ErrUnsupported = errUnsupported{}
+
+type errUnsupported struct{}
+
+func (errUnsupported) Error() string { return "cgroup: unsupported on this backend" }
+
+var (
+	detectOnce sync.Once
+	detected   Reader
+)
+
+// New autodetects the active cgroup version under mountpoint, returning a
+// v2 Reader, a v1 Reader, or (if neither is mounted, e.g. on Darwin/Windows
+// or outside a container) the runtime-heap-stats fallback Reader.
+func New(mountpoint string) Reader {
+	if isV2(mountpoint) {
+		return newV2Reader(mountpoint)
+	}
+	if isV1(mountpoint) {
+		return newV1Reader(mountpoint)
+	}
+	return newRuntimeReader()
+}
+
+// Default returns the process-wide autodetected Reader over
+// DefaultMountpoint, detected once and cached since the active cgroup
+// version can't change for the life of the process.
+func Default() Reader {
+	detectOnce.Do(func() {
+		detected = New(DefaultMountpoint)
+	})
+	return detected
+}
+
+// isV2 reports whether mountpoint looks like a cgroup v2 unified hierarchy
+// (presence of cgroup.controllers, which only exists under v2).
+func isV2(mountpoint string) bool {
+	_, err := os.Stat(mountpoint + "/cgroup.controllers")
+	return err == nil
+}
+
+// isV1 reports whether mountpoint looks like a cgroup v1 hierarchy (a
+// memory subsystem directory with the legacy memory.limit_in_bytes file).
+func isV1(mountpoint string) bool {
+	_, err := os.Stat(mountpoint + "/memory/memory.limit_in_bytes")
+	return err == nil
+}
@@ -0,0 +1,39 @@
+package cgroup
+
+import "runtime"
+
+// runtimeReader is the Reader used when no cgroup filesystem is mounted
+// (Darwin/Windows dev machines, or a process running outside a container).
+// It reports Go runtime heap stats in place of container figures, so
+// callers sizing the cache against "available memory" still get a number
+// rather than an error.
+type runtimeReader struct{}
+
+func newRuntimeReader() *runtimeReader {
+	return &runtimeReader{}
+}
+
+// MemoryLimit reports runtime.MemStats.Sys, the memory obtained from the OS
+// by the Go runtime, as a stand-in for a container memory limit.
+func (runtimeReader) MemoryLimit() (uint64, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return mem.Sys, nil
+}
+
+// MemoryUsage reports runtime.MemStats.HeapInuse.
+func (runtimeReader) MemoryUsage() (uint64, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return mem.HeapInuse, nil
+}
+
+// CPUQuota reports runtime.GOMAXPROCS(0) as the available core count.
+func (runtimeReader) CPUQuota() (float64, error) {
+	return float64(runtime.GOMAXPROCS(0)), nil
+}
+
+// MemoryPressure has no runtime equivalent.
+func (runtimeReader) MemoryPressure() (float64, error) {
+	return 0, ErrUnsupported
+}
@@ -0,0 +1,338 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// scopeParent is the subdirectory every TaskScope is created under, so a
+// crashed process's leftover scopes are easy to spot (and clean up) among
+// whatever else shares the cgroup filesystem.
+const scopeParent = "kronop-prefetcher"
+
+// TaskLimits bounds one task execution's resource usage via cgroup
+// enforcement, which the kernel applies as the task runs (OOM-killing or
+// refusing to fork past NProcLimit) rather than only noticing after the
+// fact that it ran too long.
+type TaskLimits struct {
+	MemoryLimitMB int
+	NProcLimit    int
+}
+
+// TaskMetrics is what a TaskScope actually used over its lifetime.
+type TaskMetrics struct {
+	CPUTime  time.Duration
+	MaxRSS   uint64
+	PeakPIDs int
+
+	// MemPeak, IORead, IOWrite and MajorFaults are sampled alongside
+	// CPUTime/MaxRSS when the backend exposes them (memory.peak/io.stat
+	// under v2, the blkio/memory.stat equivalents under v1). They stay
+	// zero wherever a file is missing rather than failing the sample,
+	// except MemPeak under v2, which falls back to MaxRSS when
+	// memory.peak is missing.
+	MemPeak     uint64
+	IORead      uint64
+	IOWrite     uint64
+	MajorFaults uint64
+}
+
+// TaskScope is a short-lived, named cgroup for one task execution. Enter
+// joins the calling goroutine's current OS thread to the scope under v1,
+// or the whole process under v2 (see v2TaskScope); Close
+// samples final usage, tears the scope down, and reports TaskMetrics.
+//
+// Enter and Close must be called from the same goroutine, with the task's
+// own work happening in between and nothing else - the whole calling OS
+// thread is a member of the scope for that window.
+type TaskScope interface {
+	Enter() error
+	Close() (TaskMetrics, error)
+}
+
+// NewTaskScope creates a cgroup named name under mountpoint, autodetecting
+// v1 vs v2 the same way New does, and applies limits to it. On platforms
+// without a cgroup filesystem (or when neither version is mounted) it
+// returns a no-op scope so callers don't need a build tag to use TaskScope.
+func NewTaskScope(mountpoint, name string, limits TaskLimits) (TaskScope, error) {
+	if isV2(mountpoint) {
+		return newV2TaskScope(mountpoint, name, limits)
+	}
+	if isV1(mountpoint) {
+		return newV1TaskScope(mountpoint, name, limits)
+	}
+	return noopTaskScope{}, nil
+}
+
+// noopTaskScope is used wherever no cgroup filesystem is mounted, so
+// callers can always treat enforcement/sampling as best-effort.
+type noopTaskScope struct{}
+
+func (noopTaskScope) Enter() error                { return nil }
+func (noopTaskScope) Close() (TaskMetrics, error) { return TaskMetrics{}, nil }
+
+// v1TaskScope manages one task's membership in a cgroup v1 hierarchy,
+// using the memory and cpuacct subsystems for limits/accounting and pids
+// for the process-count cap. v1's per-subsystem "tasks" file accepts a
+// thread ID directly, so membership can be scoped to just the calling OS
+// thread rather than the whole process.
+type v1TaskScope struct {
+	memDir   string
+	cpuDir   string
+	pidDir   string
+	blkioDir string
+	locked   bool
+}
+
+func newV1TaskScope(mountpoint, name string, limits TaskLimits) (*v1TaskScope, error) {
+	s := &v1TaskScope{
+		memDir:   filepath.Join(mountpoint, "memory", scopeParent, name),
+		cpuDir:   filepath.Join(mountpoint, "cpuacct", scopeParent, name),
+		pidDir:   filepath.Join(mountpoint, "pids", scopeParent, name),
+		blkioDir: filepath.Join(mountpoint, "blkio", scopeParent, name),
+	}
+
+	for _, dir := range []string{s.memDir, s.cpuDir, s.pidDir, s.blkioDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("cgroup: create scope %q: %w", dir, err)
+		}
+	}
+
+	if limits.MemoryLimitMB > 0 {
+		limitBytes := strconv.Itoa(limits.MemoryLimitMB * 1024 * 1024)
+		if err := writeString(filepath.Join(s.memDir, "memory.limit_in_bytes"), limitBytes); err != nil {
+			return nil, fmt.Errorf("cgroup: set memory limit: %w", err)
+		}
+	}
+	if limits.NProcLimit > 0 {
+		if err := writeString(filepath.Join(s.pidDir, "pids.max"), strconv.Itoa(limits.NProcLimit)); err != nil {
+			return nil, fmt.Errorf("cgroup: set pids limit: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *v1TaskScope) Enter() error {
+	runtime.LockOSThread()
+	s.locked = true
+
+	tid := strconv.Itoa(syscall.Gettid())
+	for _, dir := range []string{s.memDir, s.cpuDir, s.pidDir, s.blkioDir} {
+		if err := writeString(filepath.Join(dir, "tasks"), tid); err != nil {
+			runtime.UnlockOSThread()
+			s.locked = false
+			return fmt.Errorf("cgroup: join scope %q: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Close samples cpuacct.usage, memory.max_usage_in_bytes, pids.current,
+// memory.stat's pgmajfault and blkio.throttle.io_service_bytes, removes
+// the scope's directories, and releases the OS thread Enter locked. It
+// keeps going on a sampling error so a single missing file doesn't also
+// cost the caller the scope's cleanup.
+func (s *v1TaskScope) Close() (TaskMetrics, error) {
+	if s.locked {
+		defer func() {
+			runtime.UnlockOSThread()
+			s.locked = false
+		}()
+	}
+
+	var metrics TaskMetrics
+	if cpuNanos, err := readUint(filepath.Join(s.cpuDir, "cpuacct.usage")); err == nil {
+		metrics.CPUTime = time.Duration(cpuNanos)
+	}
+	if maxRSS, err := readUint(filepath.Join(s.memDir, "memory.max_usage_in_bytes")); err == nil {
+		metrics.MaxRSS = maxRSS
+		metrics.MemPeak = maxRSS
+	}
+	if peakPIDs, err := readUint(filepath.Join(s.pidDir, "pids.current")); err == nil {
+		metrics.PeakPIDs = int(peakPIDs)
+	}
+	if memStat, err := readString(filepath.Join(s.memDir, "memory.stat")); err == nil {
+		metrics.MajorFaults = parseStatField(memStat, "pgmajfault")
+	}
+	if ioStat, err := readString(filepath.Join(s.blkioDir, "blkio.throttle.io_service_bytes")); err == nil {
+		metrics.IORead, metrics.IOWrite = parseIOServiceBytes(ioStat)
+	}
+
+	var firstErr error
+	for _, dir := range []string{s.memDir, s.cpuDir, s.pidDir, s.blkioDir} {
+		if err := os.Remove(dir); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("cgroup: remove scope %q: %w", dir, err)
+		}
+	}
+
+	return metrics, firstErr
+}
+
+// v2TaskScope manages one task's membership in a cgroup v2 unified
+// hierarchy. Unlike v1's "tasks" file, v2's cgroup.procs only accepts
+// whole processes unless the cgroup has been marked "threaded" ahead of
+// time, which needs cooperation from the parent hierarchy the task can't
+// assume - so this scope joins by PID. Enforcement still applies for the
+// whole task, but TaskMetrics will include work any other goroutine's
+// thread did on the process during the same window.
+type v2TaskScope struct {
+	dir string
+}
+
+func newV2TaskScope(mountpoint, name string, limits TaskLimits) (*v2TaskScope, error) {
+	s := &v2TaskScope{dir: filepath.Join(mountpoint, scopeParent, name)}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("cgroup: create scope %q: %w", s.dir, err)
+	}
+
+	if limits.MemoryLimitMB > 0 {
+		limitBytes := strconv.Itoa(limits.MemoryLimitMB * 1024 * 1024)
+		if err := writeString(filepath.Join(s.dir, "memory.max"), limitBytes); err != nil {
+			return nil, fmt.Errorf("cgroup: set memory limit: %w", err)
+		}
+	}
+	if limits.NProcLimit > 0 {
+		if err := writeString(filepath.Join(s.dir, "pids.max"), strconv.Itoa(limits.NProcLimit)); err != nil {
+			return nil, fmt.Errorf("cgroup: set pids limit: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *v2TaskScope) Enter() error {
+	pid := strconv.Itoa(os.Getpid())
+	if err := writeString(filepath.Join(s.dir, "cgroup.procs"), pid); err != nil {
+		return fmt.Errorf("cgroup: join scope %q: %w", s.dir, err)
+	}
+	return nil
+}
+
+// Close samples memory.current, memory.peak, cpu.stat's usage_usec,
+// pids.current, memory.stat's pgmajfault and io.stat's rbytes/wbytes
+// (summed across every device the task touched), then removes the
+// scope. memory.peak is a newer addition to the kernel than the rest of
+// this file, so it's sampled best-effort like everything else here.
+func (s *v2TaskScope) Close() (TaskMetrics, error) {
+	var metrics TaskMetrics
+	if cpuStat, err := readString(filepath.Join(s.dir, "cpu.stat")); err == nil {
+		metrics.CPUTime = parseUsageUsec(cpuStat)
+	}
+	if current, err := readUint(filepath.Join(s.dir, "memory.current")); err == nil {
+		metrics.MaxRSS = current
+	}
+	if peak, err := readUint(filepath.Join(s.dir, "memory.peak")); err == nil {
+		metrics.MemPeak = peak
+	} else {
+		metrics.MemPeak = metrics.MaxRSS
+	}
+	if peakPIDs, err := readUint(filepath.Join(s.dir, "pids.current")); err == nil {
+		metrics.PeakPIDs = int(peakPIDs)
+	}
+	if memStat, err := readString(filepath.Join(s.dir, "memory.stat")); err == nil {
+		metrics.MajorFaults = parseStatField(memStat, "pgmajfault")
+	}
+	if ioStat, err := readString(filepath.Join(s.dir, "io.stat")); err == nil {
+		metrics.IORead, metrics.IOWrite = parseIOStat(ioStat)
+	}
+
+	err := os.Remove(s.dir)
+	if err != nil {
+		err = fmt.Errorf("cgroup: remove scope %q: %w", s.dir, err)
+	}
+	return metrics, err
+}
+
+// parseUsageUsec pulls the usage_usec field out of cpu.stat, a file with
+// lines like:
+//
+//	usage_usec 1234
+//	user_usec 1000
+//	system_usec 234
+func parseUsageUsec(cpuStat string) time.Duration {
+	for _, line := range strings.Split(cpuStat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "usage_usec" {
+			continue
+		}
+		if usec, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			return time.Duration(usec) * time.Microsecond
+		}
+	}
+	return 0
+}
+
+// parseStatField pulls a single space-separated "key value" field out of
+// a memory.stat-style file (shared by v1 and v2, both of which expose
+// pgmajfault this way), returning 0 if the key isn't present.
+func parseStatField(stat, key string) uint64 {
+	for _, line := range strings.Split(stat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != key {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			return v
+		}
+	}
+	return 0
+}
+
+// parseIOStat sums the rbytes/wbytes fields of io.stat across every
+// device line, e.g.:
+//
+//	8:0 rbytes=1216512 wbytes=0 rios=4 wios=0 dbytes=0 dios=0
+func parseIOStat(ioStat string) (read, write uint64) {
+	for _, line := range strings.Split(ioStat, "\n") {
+		for _, field := range strings.Fields(line) {
+			if v, ok := strings.CutPrefix(field, "rbytes="); ok {
+				if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+					read += n
+				}
+			} else if v, ok := strings.CutPrefix(field, "wbytes="); ok {
+				if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+					write += n
+				}
+			}
+		}
+	}
+	return read, write
+}
+
+// parseIOServiceBytes sums the Read/Write fields of v1's
+// blkio.throttle.io_service_bytes across every device line, e.g.:
+//
+//	8:0 Read 1216512
+//	8:0 Write 0
+//	Total 1216512
+func parseIOServiceBytes(ioStat string) (read, write uint64) {
+	for _, line := range strings.Split(ioStat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[1] {
+		case "Read":
+			read += n
+		case "Write":
+			write += n
+		}
+	}
+	return read, write
+}
+
+func writeString(path, value string) error {
+	return os.WriteFile(path, []byte(value), 0644)
+}
@@ -0,0 +1,83 @@
+package cgroup
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// v1Reader reads limits/usage from a cgroup v1 hierarchy's memory and cpu
+// subsystems.
+type v1Reader struct {
+	memDir string
+	cpuDir string
+}
+
+func newV1Reader(mountpoint string) *v1Reader {
+	return &v1Reader{
+		memDir: mountpoint + "/memory",
+		cpuDir: mountpoint + "/cpu",
+	}
+}
+
+// MemoryLimit reads memory.limit_in_bytes. cgroup v1 represents "no limit"
+// as a huge sentinel (typically 1<<63 rounded to a page boundary) rather
+// than omitting the file, so readings above math.MaxInt64 are clamped to
+// it the same way the v2 reader reports "unlimited".
+func (r *v1Reader) MemoryLimit() (uint64, error) {
+	v, err := readUint(r.memDir + "/memory.limit_in_bytes")
+	if err != nil {
+		return 0, err
+	}
+	if v > math.MaxInt64 {
+		return math.MaxInt64, nil
+	}
+	return v, nil
+}
+
+// MemoryUsage reads memory.usage_in_bytes.
+func (r *v1Reader) MemoryUsage() (uint64, error) {
+	return readUint(r.memDir + "/memory.usage_in_bytes")
+}
+
+// CPUQuota reads cpu.cfs_quota_us/cpu.cfs_period_us and returns
+// quota/period in cores. A quota of -1 (no limit) reports 0.
+func (r *v1Reader) CPUQuota() (float64, error) {
+	quota, err := readInt(r.cpuDir + "/cpu.cfs_quota_us")
+	if err != nil {
+		return 0, err
+	}
+	if quota < 0 {
+		return 0, nil
+	}
+	period, err := readInt(r.cpuDir + "/cpu.cfs_period_us")
+	if err != nil {
+		return 0, err
+	}
+	if period == 0 {
+		return 0, nil
+	}
+	return float64(quota) / float64(period), nil
+}
+
+// MemoryPressure has no cgroup v1 equivalent (PSI is a v2-only interface).
+func (r *v1Reader) MemoryPressure() (float64, error) {
+	return 0, ErrUnsupported
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,93 @@
+package cgroup
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// v2Reader reads limits/usage from a cgroup v2 unified hierarchy.
+type v2Reader struct {
+	dir string
+}
+
+func newV2Reader(mountpoint string) *v2Reader {
+	return &v2Reader{dir: mountpoint}
+}
+
+// MemoryLimit reads memory.max, which holds the literal string "max"
+// instead of a sentinel number when no limit is set.
+func (r *v2Reader) MemoryLimit() (uint64, error) {
+	raw, err := readString(r.dir + "/memory.max")
+	if err != nil {
+		return 0, err
+	}
+	if raw == "max" {
+		return math.MaxInt64, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+// MemoryUsage reads memory.current.
+func (r *v2Reader) MemoryUsage() (uint64, error) {
+	return readUint(r.dir + "/memory.current")
+}
+
+// CPUQuota reads cpu.max, formatted as "$QUOTA $PERIOD" in microseconds
+// (or "max $PERIOD" when unlimited), and returns quota/period in cores.
+func (r *v2Reader) CPUQuota() (float64, error) {
+	raw, err := readString(r.dir + "/cpu.max")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(raw)
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("cgroup: unexpected cpu.max format %q", raw)
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period == 0 {
+		return 0, err
+	}
+	return quota / period, nil
+}
+
+// MemoryPressure reads the some-avg10 figure out of memory.pressure, a PSI
+// file with lines like:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func (r *v2Reader) MemoryPressure() (float64, error) {
+	raw, err := readString(r.dir + "/memory.pressure")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if v, ok := strings.CutPrefix(field, "avg10="); ok {
+				return strconv.ParseFloat(v, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("cgroup: no some-avg10 field in memory.pressure")
+}
+
+func readString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
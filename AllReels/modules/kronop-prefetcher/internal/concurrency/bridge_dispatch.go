@@ -0,0 +1,64 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kronop/prefetcher/internal/bridge"
+	"github.com/sirupsen/logrus"
+)
+
+// activeBridge returns the bridge.Bridge executeRustTask/executeCppTask
+// dispatch through: grpcBridge when config.BridgeMode picked it and dialing
+// succeeded, otherwise rustAdapter (the CGO-backed RustBridge wrapped as a
+// Bridge - see bridge.NewCGOBridge).
+func (cm *ChannelManager) activeBridge() bridge.Bridge {
+	if cm.grpcBridge != nil {
+		return cm.grpcBridge
+	}
+	return cm.rustAdapter
+}
+
+// executeRustTask runs task against the Rust engine - the CGO-backed
+// RustBridge by default, or a sidecar worker over gRPC when
+// ChannelConfig.BridgeMode is BridgeModeGRPC (see activeBridge).
+func (cm *ChannelManager) executeRustTask(ctx context.Context, task PrefetchTask) error {
+	logrus.Debugf("🦀 Executing Rust task: %s (reel: %d, chunk: %s)", task.ID, task.ReelID, task.ChunkID)
+
+	if err := cm.activeBridge().PrefetchChunk(ctx, task.ReelID, task.ChunkID, int(task.Priority)); err != nil {
+		return fmt.Errorf("rust bridge error: %v", err)
+	}
+	return nil
+}
+
+// executeCppTask runs task against the C++ engine. In BridgeModeCGO, the
+// CGO CppBridge is a display/frame engine with no chunk-prefetch equivalent
+// to RustBridge.PrefetchChunk, so until it grows one this reports a clear
+// error rather than silently doing nothing - mirroring
+// SmartConcurrency.executeCppFallback's same situation. In BridgeModeGRPC,
+// the sidecar worker behind activeBridge() can serve either engine's
+// prefetch requests, so it's used directly instead.
+func (cm *ChannelManager) executeCppTask(ctx context.Context, task PrefetchTask) error {
+	if cm.grpcBridge != nil {
+		if err := cm.grpcBridge.PrefetchChunk(ctx, task.ReelID, task.ChunkID, int(task.Priority)); err != nil {
+			return fmt.Errorf("grpc bridge error: %v", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("cpp bridge has no prefetch-capable method for task %s (reel: %d, chunk: %s)",
+		task.ID, task.ReelID, task.ChunkID)
+}
+
+// executeUrgentTask runs an urgent task against the Rust engine, retrying
+// once immediately on failure before giving up - urgent tasks don't get a
+// second chance later, since by the time anything else runs they'd already
+// be late.
+func (cm *ChannelManager) executeUrgentTask(ctx context.Context, task PrefetchTask) error {
+	err := cm.executeRustTask(ctx, task)
+	if err != nil {
+		logrus.Warnf("⚠️ urgent task %s failed, retrying once: %v", task.ID, err)
+		err = cm.executeRustTask(ctx, task)
+	}
+	return err
+}
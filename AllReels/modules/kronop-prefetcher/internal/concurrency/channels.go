@@ -2,27 +2,99 @@ package concurrency
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
-	"log"
 
-	"github.com/sirupsen/logrus"
 	"github.com/kronop/prefetcher/internal/bridge"
+	"github.com/kronop/prefetcher/internal/cgroup"
+	"github.com/sirupsen/logrus"
 )
 
 // ChannelManager manages Go channels for different priority levels
 type ChannelManager struct {
-	urgentChan   chan PrefetchTask
-	highChan     chan PrefetchTask
-	mediumChan   chan PrefetchTask
-	lowChan     chan PrefetchInfo
-	errorChan    chan error.Error
-	mu          sync.RWMutex
-	config       ChannelConfig
-	stats        *ChannelStats
-	stopChan     chan struct{}
+	rustBridge *bridge.RustBridge
+	cppBridge  *bridge.CppBridge
+
+	urgentChan chan PrefetchTask
+	highChan   chan PrefetchTask
+	mediumChan chan PrefetchTask
+	lowChan    chan PrefetchTask
+	errorChan  chan error
+	mu         sync.RWMutex
+	config     ChannelConfig
+	stats      *ChannelStats
+	stopChan   chan struct{}
+
+	// urgentCredit, highCredit, mediumCredit and lowCredit are each
+	// priority's flow-control credit window (see priorityCredit) -
+	// AddTask must reserve a credit before enqueueing, and processTask
+	// returns it once the task completes. This replaces the old
+	// "block up to 100ms then drop" behavior with producers seeing
+	// ErrNoCredit immediately once a channel is saturated.
+	urgentCredit *priorityCredit
+	highCredit   *priorityCredit
+	mediumCredit *priorityCredit
+	lowCredit    *priorityCredit
+
+	// workers holds each WorkerID's accumulated WorkerStats, lazily
+	// created on that worker's first completed task (see
+	// recordWorkerResult). Guarded by mu like the rest of this manager's
+	// shared state.
+	workers map[int]*WorkerStats
+
+	// recentMetrics is a capped rolling window of cgroup.TaskMetrics from
+	// the most recently completed tasks (see recordRuntimeMetrics),
+	// summarized into ChannelStats by GetChannelStats so the scheduler
+	// can use real resource usage - not just wall-clock ProcessTime - as
+	// an input to priority boosting and adaptive scheduling.
+	recentMetrics []cgroup.TaskMetrics
+
+	// rustAdapter wraps rustBridge as a bridge.Bridge, and grpcBridge is
+	// non-nil once config.BridgeMode is BridgeModeGRPC and the sidecar
+	// pool dialed successfully - executeRustTask/executeCppTask/
+	// executeUrgentTask dispatch through activeBridge() instead of
+	// rustBridge/cppBridge directly, so both transports go through the
+	// same interface.
+	rustAdapter bridge.Bridge
+	grpcBridge  bridge.Bridge
+
+	// planner splits a SubmitRangeTask call's byte span into
+	// ChannelConfig.PartSize-aligned parts (see ChunkPlanner).
+	planner *ChunkPlanner
+
+	// parentsMu guards parents, the in-flight parentAssembly for every
+	// ParentID SubmitRangeTask has registered but WaitForRangeTask hasn't
+	// yet collected (see registerParent/completeSplitPart).
+	parentsMu sync.Mutex
+	parents   map[string]*parentAssembly
 }
 
+// BridgeMode selects whether executeRustTask/executeCppTask/
+// executeUrgentTask dispatch through the CGO-backed RustBridge/CppBridge
+// transports or a single GRPCBridge talking to sidecar prefetch worker
+// processes over the network (see ChannelConfig.GRPCBridge).
+type BridgeMode string
+
+const (
+	// BridgeModeCGO is the default: executeRustTask uses rustBridge, and
+	// executeCppTask reports CppBridge's existing "no prefetch-capable
+	// method" error (see executeCppTask) since the CGO CppBridge is a
+	// display/frame engine with nothing prefetch-shaped to call.
+	BridgeModeCGO BridgeMode = "cgo"
+	// BridgeModeGRPC routes executeRustTask and executeCppTask through
+	// the same GRPCBridge, since a gRPC sidecar worker can serve both
+	// without the CGO split between a prefetch-capable Rust engine and a
+	// display-only C++ one.
+	BridgeModeGRPC BridgeMode = "grpc"
+)
+
+// maxRuntimeMetricsSamples bounds recentMetrics, the same way
+// maxResponseTimeSamples bounds originHealth.responseTimes in the
+// prefetcher package - old samples age out rather than growing the
+// window without limit.
+const maxRuntimeMetricsSamples = 100
+
 // ChannelConfig holds channel configuration
 type ChannelConfig struct {
 	UrgentChannelSize      int           `yaml:"urgent_channel_size"`
@@ -35,8 +107,53 @@ type ChannelConfig struct {
 	BufferSize          int           `yaml:"buffer_size"`
 	EnableBackpressure    bool          `yaml:"enable_backpressure"`
 	EnablePriorityBoosting    bool          `yaml:"enable_priority_boosting"`
+
+	// HighWatermark and LowWatermark are channel-utilization fractions
+	// (queued length / capacity) that drive rebalanceCredits: crossing
+	// HighWatermark shrinks outstanding credit for lower priorities
+	// first, dropping back below LowWatermark replenishes it. Default to
+	// defaultHighWatermark/defaultLowWatermark when left at zero.
+	HighWatermark float64 `yaml:"high_watermark"`
+	LowWatermark  float64 `yaml:"low_watermark"`
+
+	// MemoryLimitMB and NProcLimit are enforced on each task's cgroup
+	// scope (see processTask), the same fields ConcurrencyConfig uses
+	// for SmartConcurrency.executePrefetchTask. Zero means "no limit".
+	MemoryLimitMB int `yaml:"memory_limit_mb"`
+	NProcLimit    int `yaml:"nproc_limit"`
+
+	// MaxWait bounds how long a task may sit in ProcessTasks' dispatch
+	// loop at a given priority before boostIfStarved promotes it to the
+	// next priority level up. A priority missing from this map (or
+	// mapped to zero) is never boosted. Without this, a steady stream of
+	// urgent/high tasks can keep preempting medium/low work indefinitely
+	// (see preemptIfHigherPending).
+	MaxWait map[Priority]time.Duration `yaml:"max_wait"`
+
+	// BridgeMode selects how executeRustTask/executeCppTask/
+	// executeUrgentTask reach the prefetch engine. Left at "" it falls
+	// back to BridgeModeCGO in NewChannelManager.
+	BridgeMode BridgeMode `yaml:"bridge_mode"`
+	// GRPCBridge configures the sidecar worker pool NewChannelManager
+	// dials when BridgeMode is BridgeModeGRPC; ignored otherwise.
+	GRPCBridge bridge.GRPCBridgeConfig `yaml:"grpc_bridge"`
+
+	// SplitThreshold is the byte span (ByteRange.End - ByteRange.Start +
+	// 1) above which SubmitRangeTask splits a task into PartSize-aligned
+	// parts instead of enqueueing it whole. PartSize is the part size
+	// ChunkPlanner aligns splits to. Zero SplitThreshold means "never
+	// split".
+	SplitThreshold int64 `yaml:"split_threshold"`
+	PartSize       int64 `yaml:"part_size"`
 }
 
+// defaultHighWatermark and defaultLowWatermark are what NewChannelManager
+// falls back to when ChannelConfig leaves the watermarks unset.
+const (
+	defaultHighWatermark = 0.8
+	defaultLowWatermark  = 0.5
+)
+
 // ChannelStats holds channel statistics
 type ChannelStats struct {
 	UrgentCount      int           `json:"urgent_count"`
@@ -48,6 +165,20 @@ type ChannelStats struct {
 	AvgProcessTime  time.Duration `json:"avg_process_time"`
 	ThroughputputBPS float64           `json:"throughputput_bps"`
 	QueueUtilization float64           `json:"queue_utilization"`
+
+	// ThrottleEvents counts rebalanceCredits calls that shrank at least
+	// one priority's credit window, i.e. how many times the manager has
+	// had to push back on producers since it started.
+	ThrottleEvents int `json:"throttle_events"`
+
+	// UrgentCredit, HighCredit, MediumCredit and LowCredit mirror each
+	// priority's currently-available credit (see priorityCredit) for
+	// GetChannelStats callers that want it alongside the rest of
+	// ChannelStats instead of a separate GetFlowControlState call.
+	UrgentCredit int `json:"urgent_credit"`
+	HighCredit   int `json:"high_credit"`
+	MediumCredit int `json:"medium_credit"`
+	LowCredit    int `json:"low_credit"`
 }
 
 // PrefetchInfo holds prefetch metadata
@@ -65,7 +196,7 @@ type PrefetchInfo struct {
 
 // NewChannelManager creates a new channel manager
 func NewChannelManager(config ChannelConfig, rustBridge *bridge.RustBridge, cppBridge *bridge.CppBridge) *ChannelManager {
-	return &ChannelManager{
+	cm := &ChannelManager{
 		rustBridge: rustBridge,
 		cppBridge: cppBridge,
 		config:     config,
@@ -73,14 +204,57 @@ func NewChannelManager(config ChannelConfig, rustBridge *bridge.RustBridge, cppB
 		highChan:    make(chan PrefetchTask, config.HighChannelSize),
 	mediumChan:  make(chan PrefetchTask, config.MediumChannelSize),
 		lowChan:     make(chan PrefetchTask, config.LowChannelSize),
-		errorChan:  make(chan error.Error, config.ErrorChannelSize),
+		errorChan:  make(chan error, config.ErrorChannelSize),
 		mu:          sync.RWMutex{},
 		stats:        &ChannelStats{},
 		stopChan:     make(chan struct{}),
+		urgentCredit: newPriorityCredit(config.UrgentChannelSize),
+		highCredit:   newPriorityCredit(config.HighChannelSize),
+		mediumCredit: newPriorityCredit(config.MediumChannelSize),
+		lowCredit:    newPriorityCredit(config.LowChannelSize),
+		workers:      make(map[int]*WorkerStats),
+		rustAdapter:  bridge.NewCGOBridge(rustBridge),
+		planner:      &ChunkPlanner{PartSize: config.PartSize},
+		parents:      make(map[string]*parentAssembly),
+	}
+
+	if config.BridgeMode == BridgeModeGRPC {
+		gb, err := bridge.NewGRPCBridge(config.GRPCBridge)
+		if err != nil {
+			logrus.Warnf("⚠️ gRPC bridge unavailable, falling back to CGO transport: %v", err)
+		} else {
+			cm.grpcBridge = gb
+		}
+	}
+
+	return cm
+}
+
+// creditFor returns priority's priorityCredit, falling back to lowCredit
+// for an unrecognized priority the same way AddTask's channel switch
+// falls back to lowChan.
+func (cm *ChannelManager) creditFor(priority Priority) *priorityCredit {
+	switch priority {
+	case PriorityUrgent:
+		return cm.urgentCredit
+	case PriorityHigh:
+		return cm.highCredit
+	case PriorityMedium:
+		return cm.mediumCredit
+	default:
+		return cm.lowCredit
 	}
 }
 
-// AddTask adds a task to the appropriate channel based on priority
+// AddTask adds a task to the channel for its priority, gated by that
+// priority's credit window (see priorityCredit) instead of the old
+// "block up to 100ms then drop" behavior. A producer must reserve a
+// credit before enqueueing; if none is available - either the channel is
+// genuinely full, or rebalanceCredits has shrunk this priority's window
+// under sustained load - AddTask returns ErrNoCredit immediately rather
+// than blocking, so a caller can retry, shed load, or escalate priority
+// on its own terms. The credit is returned by processTask once the task
+// completes.
 func (cm *ChannelManager) AddTask(task PrefetchTask) error {
 	var targetChan chan PrefetchTask
 
@@ -97,10 +271,21 @@ func (cm *ChannelManager) AddTask(task PrefetchTask) error {
 		targetChan = cm.lowChan
 	}
 
+	credit := cm.creditFor(task.Priority)
+	if !credit.tryReserve() {
+		return ErrNoCredit
+	}
+
 	select {
 	case targetChan <- task:
 		return nil
-	case <-time.After(100 * time.Millisecond):
+	default:
+		// The channel itself is full even though a credit was available -
+		// config.*ChannelSize and the credit window it seeded have drifted
+		// apart (e.g. a still-queued task from before a shrinkTo). Return
+		// the credit we just reserved since this task never actually
+		// entered the channel.
+		credit.release()
 		return fmt.Errorf("channel full for priority %d", task.Priority)
 	}
 }
@@ -154,72 +339,33 @@ func (cm *ChannelManager) GetTasksByPriority() map[Priority][]PrefetchTask {
 	return tasksByPriority
 }
 
-// ProcessTasks processes all tasks from all channels
-func (cm *ChannelManager) ProcessTasks(ctx context.Context) error {
-	ticker := time.NewTicker(10 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-ticker.C:
-			cm.processAllChannels(ctx)
-		}
-	}
-}
-
-// processAllChannels processes all channels in priority order
-func (cm *ChannelManager) processAllChannels(ctx context.Context) error {
-	// Process urgent tasks first
-	urgentTasks := cm.collectChannelTasks(cm.urgentChan)
-		for _, task := urgentTasks {
-			cm.processTask(ctx, task)
-		}
-
-	// Process high priority tasks
-	highTasks := cm.collectChannelTasks(cm.highChan)
-		for _, task := highTasks {
-			cm.processTask(ctx, task)
-	}
+// processTask executes a single task through its priority's bridge path,
+// inside a per-task cgroup scope that samples CPU/memory/IO/fault usage
+// over the call (mirrors SmartConcurrency.executePrefetchTask - see
+// cgroup.NewTaskScope). It returns a TaskResult carrying that cgroup.TaskMetrics
+// sample alongside the error, instead of the bare error processTask used
+// to return, so a caller can see real resource usage rather than only
+// wall-clock process time. The per-task sample is also folded into the
+// task's worker entry (recordWorkerResult) and the rolling window
+// GetChannelStats summarizes (recordRuntimeMetrics).
+func (cm *ChannelManager) processTask(ctx context.Context, task PrefetchTask) TaskResult {
+	startTime := time.Now()
 
-	// Process medium priority tasks
-	mediumTasks := cm.collectChannelTasks(cm.mediumChan)
-		for _, task := mediumTasks {
-			cm.processTask(ctx, task)
-	}
+	logrus.Debugf("🔄 Processing task: %s (priority: %d, reel: %d, chunk: %s)",
+		task.ID, task.ReelID, task.ChunkID, task.Priority)
 
-	// Process low priority tasks
-	lowTasks := cm.collectChannelTasks(cm.lowChan)
-		for _, task := lowTasks {
-			cm.processTask(ctx, task)
+	scope, scopeErr := cgroup.NewTaskScope(cgroup.DefaultMountpoint, task.ID, cgroup.TaskLimits{
+		MemoryLimitMB: cm.config.MemoryLimitMB,
+		NProcLimit:    cm.config.NProcLimit,
+	})
+	if scopeErr != nil {
+		logrus.Warnf("⚠️ cgroup scope unavailable for task %s, running unconfined: %v", task.ID, scopeErr)
+		scope = nil
+	} else if err := scope.Enter(); err != nil {
+		logrus.Warnf("⚠️ failed to enter cgroup scope for task %s, running unconfined: %v", task.ID, err)
+		scope = nil
 	}
 
-	return nil
-}
-
-// collectChannelTasks collects tasks from a specific channel
-func (cm *ChannelManager) collectChannelTasks(channel chan PrefetchTask) []PrefetchTask {
-	var tasks []PrefetchTask
-	for {
-		select {
-		case task := <-channel:
-			tasks = append(tasks, task)
-		case <-time.After(100 * time.Millisecond):
-			break
-		}
-	}
-	return tasks
-}
-
-// processTask processes a single task
-func (cm *ChannelManager) processTask(ctx context.Context, task PrefetchTask) error {
-	startTime := time.Now()
-	
-	// Log task processing
-	logrus.Debugf("ðŸ”„ Processing task: %s (priority: %d, reel: %d, chunk: %s)", 
-		task.ID, task.ReelID, task.ChunkID, task.Priority)
-
 	// Execute task using appropriate bridge
 	var err error
 	switch task.Priority {
@@ -239,6 +385,16 @@ func (cm *ChannelManager) processTask(ctx context.Context, task PrefetchTask) er
 		err = fmt.Errorf("unknown priority: %d", task.Priority)
 	}
 
+	var metrics cgroup.TaskMetrics
+	if scope != nil {
+		var closeErr error
+		metrics, closeErr = scope.Close()
+		if closeErr != nil {
+			logrus.Warnf("⚠️ failed to tear down cgroup scope for task %s: %v", task.ID, closeErr)
+		}
+	}
+	task.Metrics = metrics
+
 	// Update task completion
 	if err != nil {
 		task.ErrorMessage = err.Error()
@@ -246,43 +402,34 @@ func (cm *ChannelManager) processTask(ctx context.Context, task PrefetchTask) er
 	} else {
 		task.Success = true
 	}
-	
-	// Update task completion
 	task.CompletedAt = time.Now()
-	task.ProcessTime = time.Since(startTime)
-	
-	// Update worker stats
-	workerID := task.WorkerID
-	if workerID >= 0 && workerID < len(cm.workers) {
-		worker := cm.workers[workerID]
-		worker.Stats.TasksProcessed++
-		worker.Stats.TotalProcessTime += task.ProcessTime.Seconds()
-		
-		// Update average process time
-		worker.Stats.AvgProcessTime = time.Duration(
-			worker.Stats.TotalProcessTime.Seconds() / float64(worker.Stats.TasksProcessed),
-		)
-		
-		// Update success rate
-		successRate := float64(worker.Stats.TasksSucceeded) / float64(worker.Stats.TasksProcessed)
-		worker.Stats.SuccessRate = successRate
-	}
-	
+	processTime := time.Since(startTime)
+
+	cm.recordWorkerResult(task.WorkerID, processTime, metrics, task.Success, err)
+	cm.recordRuntimeMetrics(metrics)
+
 	// Update global stats
-		cm.mu.Lock()
-		if task.Success {
-			cm.stats.SuccessfulTasks++
-		} else {
-			cm.stats.FailedTasks++
-		}
-		cm.mu.Unlock()
-		
-		logrus.Debugf("ðŸ“Š Task completed: %s (success: %v, time: %v)", 
-			task.ID, task.Success, task.ProcessTime.Seconds())
+	cm.mu.Lock()
+	cm.stats.TotalProcessed++
+	if !task.Success {
+		cm.stats.ErrorCount++
 	}
+	cm.mu.Unlock()
+
+	logrus.Debugf("📊 Task completed: %s (success: %v, time: %v)",
+		task.ID, task.Success, processTime.Seconds())
+
+	// Return this task's credit now that processing has finished, then
+	// rebalance every priority's window against the channels' current
+	// utilization - done after the release so the channel this task just
+	// vacated is already reflected.
+	cm.creditFor(task.Priority).release()
 
 	// Update channel stats
 	cm.updateChannelStats(task.Priority)
+	cm.rebalanceCredits()
+
+	return TaskResult{Err: err, Metrics: metrics}
 }
 
 // updateChannelStats updates channel statistics
@@ -299,36 +446,71 @@ func (cm *ChannelManager) updateChannelStats(priority Priority) {
 		cm.stats.MediumCount++
 	case PriorityLow:
 		cm.stats.LowCount++
-	case PriorityLow:
-		cm.stats.LowCount++
 	}
 
 	// Update global stats
-	cm.mu.Unlock()
 	cm.stats.TotalProcessed++
 	cm.stats.SuccessfulTasks += cm.stats.SuccessfulTasks
 	cm.stats.FailedTasks += cm.stats.FailedTasks
 	cm.stats.AvgProcessTime = time.Duration(cm.stats.TotalProcessTime.Seconds()) / float64(cm.stats.TotalProcessed)
 	cm.stats.AvgProcessTime = time.Duration(cm.stats.TotalProcessTime.Seconds()) / float64(cm.stats.TotalProcessed)
+	cm.stats.QueueUtilization = cm.maxChannelUtilization()
+	_, cm.stats.UrgentCredit = cm.urgentCredit.snapshot()
+	_, cm.stats.HighCredit = cm.highCredit.snapshot()
+	_, cm.stats.MediumCredit = cm.mediumCredit.snapshot()
+	_, cm.stats.LowCredit = cm.lowCredit.snapshot()
 }
 
-// GetChannelStats returns statistics for all channels
-func (cm *ChannelManager) map[string]interface{} {
+// maxChannelUtilization returns the highest queued-length/capacity
+// fraction across the four priority channels, feeding ChannelStats'
+// QueueUtilization. Callers must hold cm.mu.
+func (cm *ChannelManager) maxChannelUtilization() float64 {
+	max := 0.0
+	for _, ch := range []chan PrefetchTask{cm.urgentChan, cm.highChan, cm.mediumChan, cm.lowChan} {
+		if cap(ch) == 0 {
+			continue
+		}
+		if u := float64(len(ch)) / float64(cap(ch)); u > max {
+			max = u
+		}
+	}
+	return max
+}
+
+// GetChannelStats returns statistics for all channels, plus a summary of
+// recentMetrics - the rolling window of cgroup-sampled cgroup.TaskMetrics
+// from the most recently completed tasks (see recordRuntimeMetrics) -
+// so a caller sizing priority boosting or adaptive scheduling has real
+// resource usage to work from instead of only queue depth.
+func (cm *ChannelManager) GetChannelStats() map[string]interface{} {
 	cm.mu.RLock()
-	defer cm.mu.Unlock()
+	defer cm.mu.RUnlock()
+
+	samples, totalCPUTime, peakMem, totalIORead, totalIOWrite, totalMajorFaults := cm.runtimeWindowSummary()
 
 	return map[string]interface{}{
-		"urgent_count":     cm.stats.UrgentCount,
-		"high_count":       cm.stats.HighCount,
-	"medium_count":     cm.stats.MediumCount,
-	"low_count":       cm.stats.LowCount,
-	"error_count":      cm.stats.ErrorCount,
-	"total_processed":   cm.stats.TotalProcessed,
-	"avg_process_time":  cm.stats.AvgProcessTime.Seconds(),
-	"throughputput_bps":    cm.stats.ThroughputputBPS,
-	"queue_utilization":  cm.stats.QueueUtilization,
-	"cache_hit_rate":     cm.calculateCacheHitRate(),
-	"network_efficiency": cm.calculateNetworkEfficiency(),
+		"urgent_count":       cm.stats.UrgentCount,
+		"high_count":         cm.stats.HighCount,
+		"medium_count":       cm.stats.MediumCount,
+		"low_count":          cm.stats.LowCount,
+		"error_count":        cm.stats.ErrorCount,
+		"total_processed":    cm.stats.TotalProcessed,
+		"avg_process_time":   cm.stats.AvgProcessTime.Seconds(),
+		"throughputput_bps":  cm.stats.ThroughputputBPS,
+		"queue_utilization":  cm.stats.QueueUtilization,
+		"throttle_events":    cm.stats.ThrottleEvents,
+		"urgent_credit":      cm.stats.UrgentCredit,
+		"high_credit":        cm.stats.HighCredit,
+		"medium_credit":      cm.stats.MediumCredit,
+		"low_credit":         cm.stats.LowCredit,
+		"runtime_window": map[string]interface{}{
+			"samples":            samples,
+			"total_cpu_time_ns":  totalCPUTime.Nanoseconds(),
+			"peak_mem_bytes":     peakMem,
+			"total_io_read":      totalIORead,
+			"total_io_write":     totalIOWrite,
+			"total_major_faults": totalMajorFaults,
+		},
 	}
 }
 
@@ -371,22 +553,13 @@ func (cm *ChannelManager) calculateNetworkEfficiency() float64 {
 	}
 }
 
-// StartBackgroundProcessor starts background processing
+// StartBackgroundProcessor starts the manager's background dispatch
+// loop. It used to run its own 100ms ticker that called
+// processAllChannels independently of ProcessTasks' 10ms one - two
+// pollers doing the same job - now it's a thin alias so existing
+// callers of this name get the single reflect.Select-based loop too.
 func (cm *ChannelManager) StartBackgroundProcessor(ctx context.Context) error {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			// Process all channels
-			if err := cm.processAllChannels(ctx); err != nil {
-				logrus.Errorf("âŒ Background processing error: %v", err)
-			}
-	}
-	}
+	return cm.ProcessTasks(ctx)
 }
 
 // StopBackgroundProcessor stops the background processor
@@ -400,18 +573,6 @@ func (cm *ChannelManager) StopBackgroundProcessor() {
 }
 
 // GetWorkerStats returns statistics for all workers
-func (cm *ChannelManager) WorkerStats() []WorkerStats {
-	cm.mu.RLock()
-	defer cm.mu.Unlock()
-
-	stats := make([]WorkerStats, len(cm.workers))
-	for i, worker := range cm.workers {
-		stats[i] = *worker.Stats
-	}
-
-	return stats
-}
-
 // SetMaxQueueSize updates the maximum queue size
 func (cm *ChannelManager) SetMaxQueueSize(maxSize int) {
 	cm.mu.Lock()
@@ -0,0 +1,112 @@
+package concurrency
+
+import (
+	"sync"
+	"time"
+)
+
+// baseBridgeCost is what a minimal prefetch_chunk request costs against a
+// bridgeCostTracker. Tasks with dependencies (larger reels pulling in
+// more chunks) cost proportionally more - see costForTask.
+const baseBridgeCost = 1.0
+
+// bridgeCostTracker is a client-side token bucket estimating a bridge's
+// available request buffer, refilled continuously at minRecharge units/s
+// and drained by each request's cost. Neither RustBridge nor CppBridge
+// reports its own buffer state over the wire, so this mirrors the LES
+// flow-control model of a client tracking an advertised BufLimit/
+// MinRecharge locally rather than asking the server before every send.
+type bridgeCostTracker struct {
+	mu          sync.Mutex
+	bufLimit    float64
+	minRecharge float64
+	available   float64
+	lastRefill  time.Time
+
+	totalRequests int64
+	totalCost     float64
+}
+
+func newBridgeCostTracker(bufLimit, minRecharge float64) *bridgeCostTracker {
+	return &bridgeCostTracker{
+		bufLimit:    bufLimit,
+		minRecharge: minRecharge,
+		available:   bufLimit,
+		lastRefill:  time.Now(),
+	}
+}
+
+// costForTask estimates the buffer cost of sending task to a bridge.
+// Dependencies stands in for "how much of a reel this pulls along with
+// it" - a task with no dependencies costs the baseline, one with several
+// costs more, the same way a bigger reel would take longer for the
+// engine to service.
+func (t *bridgeCostTracker) costForTask(task PrefetchTask) float64 {
+	return baseBridgeCost + float64(len(task.Dependencies))*0.5
+}
+
+// refillLocked tops up available by whatever minRecharge has accrued
+// since the last refill, capped at bufLimit. Callers must hold t.mu.
+func (t *bridgeCostTracker) refillLocked() {
+	now := time.Now()
+	elapsed := now.Sub(t.lastRefill).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	t.available += elapsed * t.minRecharge
+	if t.available > t.bufLimit {
+		t.available = t.bufLimit
+	}
+	t.lastRefill = now
+}
+
+// tryReserve refills, then deducts cost if doing so wouldn't take
+// available negative. Reports whether the reservation was made.
+func (t *bridgeCostTracker) tryReserve(cost float64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refillLocked()
+	if t.available-cost < 0 {
+		return false
+	}
+
+	t.available -= cost
+	t.totalRequests++
+	t.totalCost += cost
+	return true
+}
+
+// waitFor estimates how long it'll take minRecharge to cover cost from
+// the tracker's current available balance. Returns 0 if cost is already
+// covered or minRecharge can't ever catch up.
+func (t *bridgeCostTracker) waitFor(cost float64) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refillLocked()
+	deficit := cost - t.available
+	if deficit <= 0 || t.minRecharge <= 0 {
+		return 0
+	}
+	return time.Duration(deficit / t.minRecharge * float64(time.Second))
+}
+
+// snapshot reports the tracker's current headroom, the estimated wait
+// for one more baseline-cost request, and the average cost observed per
+// request so far - the figures ConcurrencyStats exposes.
+func (t *bridgeCostTracker) snapshot() (available, estimatedWaitMs, avgCost float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refillLocked()
+	available = t.available
+
+	if deficit := baseBridgeCost - t.available; deficit > 0 && t.minRecharge > 0 {
+		estimatedWaitMs = deficit / t.minRecharge * 1000
+	}
+	if t.totalRequests > 0 {
+		avgCost = t.totalCost / float64(t.totalRequests)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+package concurrency
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// workerCounters holds one worker's hot-path counters as atomics, so
+// processTask never needs a lock just to record that a task finished -
+// only GetWorkerStats, called far less often, has to assemble them into
+// a WorkerStats snapshot.
+type workerCounters struct {
+	tasksProcessed   atomic.Int64
+	tasksSucceeded   atomic.Int64
+	tasksFailed      atomic.Int64
+	totalProcessTime atomic.Int64 // nanoseconds
+	totalCPUTime     atomic.Int64 // nanoseconds
+	peakRSS          atomic.Uint64
+	avgRSS           atomic.Uint64
+
+	// totalIORead, totalIOWrite and totalMajorFaults accumulate the same
+	// TaskMetrics fields recordTask already takes CPU/memory from, so a
+	// worker's I/O and fault pressure is visible next to its CPU/memory
+	// footprint instead of only in the raw per-task samples.
+	totalIORead      atomic.Uint64
+	totalIOWrite     atomic.Uint64
+	totalMajorFaults atomic.Uint64
+}
+
+// recordTask folds one task's outcome into the counters. avgRSS is
+// updated as a running mean via compare-and-swap, so concurrent callers
+// (there's normally only one - the owning worker - but nothing here
+// assumes that) never lose an update the way a plain read-modify-write
+// would under a race.
+func (c *workerCounters) recordTask(processTime, cpuTime time.Duration, maxRSS uint64, succeeded bool, ioRead, ioWrite, majorFaults uint64) {
+	processed := c.tasksProcessed.Add(1)
+	c.totalProcessTime.Add(int64(processTime))
+	c.totalCPUTime.Add(int64(cpuTime))
+	c.totalIORead.Add(ioRead)
+	c.totalIOWrite.Add(ioWrite)
+	c.totalMajorFaults.Add(majorFaults)
+	if succeeded {
+		c.tasksSucceeded.Add(1)
+	} else {
+		c.tasksFailed.Add(1)
+	}
+
+	for {
+		peak := c.peakRSS.Load()
+		if maxRSS <= peak || c.peakRSS.CompareAndSwap(peak, maxRSS) {
+			break
+		}
+	}
+
+	for {
+		avg := c.avgRSS.Load()
+		var next uint64
+		if maxRSS >= avg {
+			next = avg + (maxRSS-avg)/uint64(processed)
+		} else {
+			next = avg - (avg-maxRSS)/uint64(processed)
+		}
+		if c.avgRSS.CompareAndSwap(avg, next) {
+			break
+		}
+	}
+}
+
+// snapshot loads every counter for a WorkerStats read.
+func (c *workerCounters) snapshot() (processed, succeeded, failed int64, totalProcessTime, totalCPUTime time.Duration, peakRSS, avgRSS uint64, totalIORead, totalIOWrite, totalMajorFaults uint64) {
+	return c.tasksProcessed.Load(),
+		c.tasksSucceeded.Load(),
+		c.tasksFailed.Load(),
+		time.Duration(c.totalProcessTime.Load()),
+		time.Duration(c.totalCPUTime.Load()),
+		c.peakRSS.Load(),
+		c.avgRSS.Load(),
+		c.totalIORead.Load(),
+		c.totalIOWrite.Load(),
+		c.totalMajorFaults.Load()
+}
+
+// concurrencyCounters holds the manager-wide task totals as atomics.
+// processTask used to take sc.mu for these on every single task, which
+// serialized every worker goroutine behind one lock just to bump three
+// counters.
+type concurrencyCounters struct {
+	totalTasks      atomic.Int64
+	successfulTasks atomic.Int64
+	failedTasks     atomic.Int64
+}
+
+func (c *concurrencyCounters) record(succeeded bool) {
+	c.totalTasks.Add(1)
+	if succeeded {
+		c.successfulTasks.Add(1)
+	} else {
+		c.failedTasks.Add(1)
+	}
+}
+
+func (c *concurrencyCounters) snapshot() (total, successful, failed int64) {
+	return c.totalTasks.Load(), c.successfulTasks.Load(), c.failedTasks.Load()
+}
@@ -0,0 +1,150 @@
+package concurrency
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dispatchCase indexes the reflect.SelectCase slice ProcessTasks builds.
+// ctx.Done() and stopChan come first so their index never shifts if a
+// priority channel is ever added; the four priority channels follow in
+// priority order.
+const (
+	dispatchCaseDone = iota
+	dispatchCaseStop
+	dispatchCaseUrgent
+	dispatchCaseHigh
+	dispatchCaseMedium
+	dispatchCaseLow
+)
+
+// ProcessTasks is the manager's dispatch loop. It used to wake on a
+// 10ms ticker and drain processAllChannels in fixed priority order,
+// which burned CPU at idle and let a steady stream of urgent/high
+// tasks starve medium/low ones that always lost that race. It now
+// blocks on a single reflect.Select over all four priority channels
+// plus ctx.Done() and stopChan - the same "one goroutine, one Select,
+// no ticker" shape as a priority-aware conn writer - so it only wakes
+// when a channel actually has something to read (or is closed).
+//
+// Dequeuing a medium or low task doesn't mean running it right away:
+// preemptIfHigherPending first checks urgentChan/highChan
+// non-blockingly and, if either has something waiting, puts the task
+// back on its own channel so the higher-priority work gets picked up
+// on the next Select instead of queuing behind it. A task that keeps
+// losing that race would starve forever, so boostIfStarved promotes it
+// to the next priority level once it's waited longer than
+// ChannelConfig.MaxWait[task.Priority].
+func (cm *ChannelManager) ProcessTasks(ctx context.Context) error {
+	cases := []reflect.SelectCase{
+		dispatchCaseDone:   {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		dispatchCaseStop:   {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cm.stopChan)},
+		dispatchCaseUrgent: {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cm.urgentChan)},
+		dispatchCaseHigh:   {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cm.highChan)},
+		dispatchCaseMedium: {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cm.mediumChan)},
+		dispatchCaseLow:    {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cm.lowChan)},
+	}
+	priorities := map[int]Priority{
+		dispatchCaseUrgent: PriorityUrgent,
+		dispatchCaseHigh:   PriorityHigh,
+		dispatchCaseMedium: PriorityMedium,
+		dispatchCaseLow:    PriorityLow,
+	}
+
+	for {
+		chosen, value, ok := reflect.Select(cases)
+		switch chosen {
+		case dispatchCaseDone, dispatchCaseStop:
+			return nil
+		}
+		if !ok {
+			// A closed channel reads its zero value instead of blocking
+			// forever, so without this check a closed priority channel
+			// (StopBackgroundProcessor closes all of them) would spin
+			// Select hot on a stream of zero-value tasks.
+			return nil
+		}
+
+		task := value.Interface().(PrefetchTask)
+		priority := priorities[chosen]
+
+		if priority == PriorityMedium || priority == PriorityLow {
+			if cm.preemptIfHigherPending(task, priority) {
+				continue
+			}
+		}
+
+		task = cm.boostIfStarved(task, priority)
+		result := cm.processTask(ctx, task)
+		if task.ParentID != "" {
+			// task.ID is always registered as its own ParentID (see
+			// SubmitRangeTask), so this also covers an unsplit task
+			// completing its own single-part assembly.
+			cm.completeSplitPart(task.ParentID, task.PartNo, result)
+		}
+	}
+}
+
+// preemptIfHigherPending non-blockingly checks whether urgentChan or
+// highChan has a task waiting, and if so puts task back on its own
+// channel rather than letting it run ahead of higher-priority work
+// that's already queued. It returns true when the task was put back -
+// the caller should loop back to Select instead of processing it.
+func (cm *ChannelManager) preemptIfHigherPending(task PrefetchTask, priority Priority) bool {
+	if len(cm.urgentChan) == 0 && len(cm.highChan) == 0 {
+		return false
+	}
+
+	var own chan PrefetchTask
+	switch priority {
+	case PriorityMedium:
+		own = cm.mediumChan
+	case PriorityLow:
+		own = cm.lowChan
+	default:
+		return false
+	}
+
+	select {
+	case own <- task:
+		return true
+	default:
+		// own channel filled up behind us (a producer raced in) - there's
+		// nowhere to put the task back, so just process it now rather
+		// than drop it.
+		return false
+	}
+}
+
+// boostIfStarved promotes task to the next priority level up once it's
+// been waiting (since CreatedAt) longer than ChannelConfig.MaxWait for
+// its current priority, so a task that keeps losing preemptIfHigherPending's
+// race doesn't starve indefinitely. The task's original credit (reserved
+// by AddTask under its old priority) is released and a best-effort
+// credit is reserved at the boosted priority instead, so credit
+// accounting - and rebalanceCredits - still reflects where the task
+// actually executes; a failed reservation doesn't block the boost,
+// since credits gate enqueueing and this task is already in flight.
+func (cm *ChannelManager) boostIfStarved(task PrefetchTask, priority Priority) PrefetchTask {
+	if priority == PriorityUrgent {
+		return task
+	}
+
+	maxWait, ok := cm.config.MaxWait[priority]
+	if !ok || maxWait <= 0 || time.Since(task.CreatedAt) < maxWait {
+		return task
+	}
+
+	boosted := priority - 1
+	logrus.Debugf("⏫ Boosting starved task %s from priority %d to %d after waiting %v",
+		task.ID, priority, boosted, time.Since(task.CreatedAt))
+
+	cm.creditFor(priority).release()
+	cm.creditFor(boosted).tryReserve()
+
+	task.Priority = boosted
+	return task
+}
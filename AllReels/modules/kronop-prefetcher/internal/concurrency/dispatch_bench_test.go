@@ -0,0 +1,74 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/bridge"
+)
+
+func benchChannelConfig() ChannelConfig {
+	return ChannelConfig{
+		UrgentChannelSize: 64,
+		HighChannelSize:   64,
+		MediumChannelSize: 64,
+		LowChannelSize:    64,
+		ErrorChannelSize:  16,
+	}
+}
+
+// BenchmarkProcessTasks_Idle measures ProcessTasks' cost with nothing
+// ever enqueued - the case the old 10ms-ticker loop paid a wakeup for
+// regardless of load. The reflect.Select-based loop blocks on Select
+// until stopChan or ctx.Done() fires instead of waking on a schedule, so
+// each op costs only building the manager, starting the loop and
+// shutting it down.
+func BenchmarkProcessTasks_Idle(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		cm := NewChannelManager(benchChannelConfig(), nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			cm.ProcessTasks(ctx)
+			close(done)
+		}()
+
+		cancel()
+		<-done
+	}
+}
+
+// BenchmarkProcessTasks_MixedLoad measures AddTask's latency under a
+// steady mix of all four priorities, exercising
+// preemptIfHigherPending's non-blocking requeue path for medium/low
+// tasks alongside normal dispatch of urgent/high ones.
+func BenchmarkProcessTasks_MixedLoad(b *testing.B) {
+	// Urgent/high tasks dispatch through the RustBridge, so it can't be
+	// nil here; an unconnected one fails each prefetch without panicking.
+	rb, err := bridge.NewRustBridge(bridge.EngineTransportConfig{})
+	if err != nil {
+		b.Fatalf("failed to create rust bridge: %v", err)
+	}
+	cm := NewChannelManager(benchChannelConfig(), rb, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cm.ProcessTasks(ctx)
+
+	priorities := []Priority{PriorityUrgent, PriorityHigh, PriorityMedium, PriorityLow}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		task := PrefetchTask{
+			ID:        fmt.Sprintf("bench-%d", i),
+			Priority:  priorities[i%len(priorities)],
+			CreatedAt: time.Now(),
+		}
+		for cm.AddTask(task) == ErrNoCredit {
+			// credit window is saturated - retry at once until a credit
+			// frees up rather than counting a rejected enqueue as done.
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package concurrency
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNoCredit is returned by AddTask when the target priority's credit
+// window is exhausted - the producer should back off rather than block,
+// unlike the old "wait up to 100ms then drop" behavior.
+var ErrNoCredit = errors.New("no credit available for priority")
+
+// priorityCredit is one priority channel's flow-control credit window,
+// mirroring bridgeCostTracker's assigned/recharging capacity model (see
+// costtracker.go) but for channel slots rather than bridge request
+// buffer: capacity starts at the channel's size, AddTask reserves one
+// credit per enqueued task, and processTask returns it on completion.
+// rebalanceCredits additionally shrinks/grows capacity itself in
+// response to queue utilization, independent of individual reserve/
+// release calls.
+type priorityCredit struct {
+	mu        sync.Mutex
+	capacity  int
+	available int
+}
+
+// newPriorityCredit builds a credit window starting fully available, up
+// to capacity outstanding reservations at once.
+func newPriorityCredit(capacity int) *priorityCredit {
+	return &priorityCredit{capacity: capacity, available: capacity}
+}
+
+// tryReserve reserves one credit if available, reporting whether it did.
+func (c *priorityCredit) tryReserve() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.available <= 0 {
+		return false
+	}
+	c.available--
+	return true
+}
+
+// release returns one credit, capped at capacity so a stray extra
+// release (there shouldn't be one, but defensively) can't inflate the
+// window past what AddTask was told it could reserve from.
+func (c *priorityCredit) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.available < c.capacity {
+		c.available++
+	}
+}
+
+// shrinkTo lowers capacity to newCapacity (never below 1, so a priority
+// is throttled rather than starved outright), trimming available to fit
+// if it was reserving more than the new capacity allows. Reports whether
+// capacity actually changed.
+func (c *priorityCredit) shrinkTo(newCapacity int) bool {
+	if newCapacity < 1 {
+		newCapacity = 1
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if newCapacity >= c.capacity {
+		return false
+	}
+	outstanding := c.capacity - c.available
+	c.capacity = newCapacity
+	c.available = newCapacity - outstanding
+	if c.available < 0 {
+		c.available = 0
+	}
+	return true
+}
+
+// growTo raises capacity back up to newCapacity (never past
+// originalCapacity, the channel's real size), reporting whether capacity
+// actually changed.
+func (c *priorityCredit) growTo(newCapacity, originalCapacity int) bool {
+	if newCapacity > originalCapacity {
+		newCapacity = originalCapacity
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if newCapacity <= c.capacity {
+		return false
+	}
+	c.available += newCapacity - c.capacity
+	c.capacity = newCapacity
+	return true
+}
+
+// snapshot reports this priority's current capacity and available
+// credit, for PriorityFlowControlState.
+func (c *priorityCredit) snapshot() (capacity, available int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capacity, c.available
+}
+
+// PriorityFlowControlState is one priority's point-in-time credit
+// window, as reported by GetFlowControlState.
+type PriorityFlowControlState struct {
+	Priority  Priority `json:"priority"`
+	Capacity  int      `json:"capacity"`
+	Available int      `json:"available"`
+	Throttled bool     `json:"throttled"`
+}
+
+// FlowControlState is every priority's current credit window plus how
+// many times the manager has had to shrink one, as returned by
+// GetFlowControlState.
+type FlowControlState struct {
+	Priorities     []PriorityFlowControlState `json:"priorities"`
+	ThrottleEvents int                         `json:"throttle_events"`
+}
+
+// GetFlowControlState reports each priority's current credit capacity
+// and availability, and how many times rebalanceCredits has had to
+// shrink at least one of them - so an operator can see backpressure
+// building before producers start seeing ErrNoCredit.
+func (cm *ChannelManager) GetFlowControlState() FlowControlState {
+	priorities := []struct {
+		priority Priority
+		credit   *priorityCredit
+		original int
+	}{
+		{PriorityUrgent, cm.urgentCredit, cm.config.UrgentChannelSize},
+		{PriorityHigh, cm.highCredit, cm.config.HighChannelSize},
+		{PriorityMedium, cm.mediumCredit, cm.config.MediumChannelSize},
+		{PriorityLow, cm.lowCredit, cm.config.LowChannelSize},
+	}
+
+	state := FlowControlState{Priorities: make([]PriorityFlowControlState, 0, len(priorities))}
+	for _, p := range priorities {
+		capacity, available := p.credit.snapshot()
+		state.Priorities = append(state.Priorities, PriorityFlowControlState{
+			Priority:  p.priority,
+			Capacity:  capacity,
+			Available: available,
+			Throttled: capacity < p.original,
+		})
+	}
+
+	cm.mu.RLock()
+	state.ThrottleEvents = cm.stats.ThrottleEvents
+	cm.mu.RUnlock()
+	return state
+}
+
+// rebalanceCredits shrinks or replenishes a priority's credit window based
+// on the highest current utilization (queued length / capacity) across all
+// four priority channels, following the LES/3-style "assigned capacity"
+// idea the credit windows themselves are modeled on: once any channel
+// crosses HighWatermark, the lowest priorities give up credit first (low,
+// then medium, then high - urgent is never throttled) so urgent/high
+// traffic keeps flowing through a congested manager. Each call moves one
+// credit on the lowest tier that can still change. Dropping back below
+// LowWatermark replenishes in the same order. Called from processTask
+// after every processed task.
+func (cm *ChannelManager) rebalanceCredits() {
+	type tier struct {
+		channel  chan PrefetchTask
+		credit   *priorityCredit
+		original int
+	}
+	// Ordered lowest-priority-first so shrinking walks low -> medium ->
+	// high and replenishing walks the same order back.
+	tiers := []tier{
+		{cm.lowChan, cm.lowCredit, cm.config.LowChannelSize},
+		{cm.mediumChan, cm.mediumCredit, cm.config.MediumChannelSize},
+		{cm.highChan, cm.highCredit, cm.config.HighChannelSize},
+	}
+
+	high := cm.config.HighWatermark
+	if high <= 0 {
+		high = defaultHighWatermark
+	}
+	low := cm.config.LowWatermark
+	if low <= 0 {
+		low = defaultLowWatermark
+	}
+
+	cm.mu.RLock()
+	utilization := cm.maxChannelUtilization()
+	cm.mu.RUnlock()
+
+	throttled, replenished := false, false
+	for _, t := range tiers {
+		if t.original <= 0 {
+			continue
+		}
+		capacity, _ := t.credit.snapshot()
+
+		switch {
+		case utilization >= high:
+			throttled = t.credit.shrinkTo(capacity - 1)
+		case utilization < low:
+			replenished = t.credit.growTo(capacity+1, t.original)
+		}
+		if throttled || replenished {
+			break
+		}
+	}
+
+	if throttled {
+		cm.mu.Lock()
+		cm.stats.ThrottleEvents++
+		cm.mu.Unlock()
+	}
+}
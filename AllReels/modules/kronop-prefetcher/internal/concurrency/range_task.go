@@ -0,0 +1,224 @@
+package concurrency
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Range describes one sub-range of a split PrefetchTask: the byte offsets
+// within that specific part (not the whole task's span), and which part
+// number it covers.
+type Range struct {
+	Start  int64
+	End    int64
+	PartNo int64
+}
+
+// ChunkPlanner splits a byte span into PartSize-aligned Ranges, the same
+// algorithm teldrive's multireader uses to fan a single ranged read out
+// across parts: every part strictly between the first and last spans the
+// whole part, and only the first part's Start and the last part's End are
+// clipped to the requested span's actual boundaries.
+type ChunkPlanner struct {
+	PartSize int64
+}
+
+// Split partitions the inclusive span [start, end] into PartSize-aligned
+// Ranges. A non-positive PartSize can't align anything, so it returns the
+// whole span as a single, unsplit Range.
+func (p *ChunkPlanner) Split(start, end int64) []Range {
+	if p.PartSize <= 0 {
+		return []Range{{Start: start, End: end, PartNo: 0}}
+	}
+
+	firstPart := start / p.PartSize
+	lastPart := end / p.PartSize
+
+	ranges := make([]Range, 0, lastPart-firstPart+1)
+	for partNo := firstPart; partNo <= lastPart; partNo++ {
+		r := Range{Start: 0, End: p.PartSize - 1, PartNo: partNo}
+		if partNo == firstPart {
+			r.Start = start % p.PartSize
+		}
+		if partNo == lastPart {
+			r.End = end % p.PartSize
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// parentAssembly tracks one parent task's in-flight sub-range parts. cond
+// gates wait(): every completed part Broadcasts so a waiter blocked there
+// re-checks whether every part has now arrived, then reassembles them back
+// into PartNo order.
+type parentAssembly struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	total   int
+	results map[int64]TaskResult
+	err     error
+
+	// sem bounds how many of this parent's parts dispatchSplitParts keeps
+	// enqueued at once (PrefetchTask.Concurrency), released as each part
+	// completes (see completeSplitPart).
+	sem chan struct{}
+}
+
+func newParentAssembly(total, concurrency int) *parentAssembly {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	pa := &parentAssembly{
+		total:   total,
+		results: make(map[int64]TaskResult, total),
+		sem:     make(chan struct{}, concurrency),
+	}
+	pa.cond = sync.NewCond(&pa.mu)
+	return pa
+}
+
+// record stores partNo's result and wakes any goroutine blocked in wait().
+func (pa *parentAssembly) record(partNo int64, result TaskResult) {
+	pa.mu.Lock()
+	pa.results[partNo] = result
+	if result.Err != nil && pa.err == nil {
+		pa.err = result.Err
+	}
+	pa.mu.Unlock()
+	pa.cond.Broadcast()
+
+	select {
+	case <-pa.sem:
+	default:
+	}
+}
+
+// wait blocks until every part has completed, then returns their results
+// reassembled in PartNo order.
+func (pa *parentAssembly) wait() ([]TaskResult, error) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	for len(pa.results) < pa.total {
+		pa.cond.Wait()
+	}
+
+	ordered := make([]TaskResult, pa.total)
+	for partNo, result := range pa.results {
+		ordered[partNo] = result
+	}
+	return ordered, pa.err
+}
+
+// registerParent creates and tracks a new parentAssembly for parentID,
+// replacing any stale entry left behind by a caller that never called
+// WaitForRangeTask for a previous submission under the same ID.
+func (cm *ChannelManager) registerParent(parentID string, total, concurrency int) *parentAssembly {
+	pa := newParentAssembly(total, concurrency)
+
+	cm.parentsMu.Lock()
+	cm.parents[parentID] = pa
+	cm.parentsMu.Unlock()
+
+	return pa
+}
+
+// completeSplitPart records a sub-range task's result against its parent's
+// assembly. It's a no-op if parentID isn't tracked, e.g. WaitForRangeTask
+// already collected and removed it.
+func (cm *ChannelManager) completeSplitPart(parentID string, partNo int64, result TaskResult) {
+	cm.parentsMu.Lock()
+	pa := cm.parents[parentID]
+	cm.parentsMu.Unlock()
+
+	if pa == nil {
+		return
+	}
+	pa.record(partNo, result)
+}
+
+// WaitForRangeTask blocks until every part of the SubmitRangeTask call that
+// returned parentID has completed, then returns their TaskResults
+// reassembled in order and forgets parentID.
+func (cm *ChannelManager) WaitForRangeTask(parentID string) ([]TaskResult, error) {
+	cm.parentsMu.Lock()
+	pa := cm.parents[parentID]
+	cm.parentsMu.Unlock()
+
+	if pa == nil {
+		return nil, fmt.Errorf("no in-flight range task for parent %s", parentID)
+	}
+
+	results, err := pa.wait()
+
+	cm.parentsMu.Lock()
+	delete(cm.parents, parentID)
+	cm.parentsMu.Unlock()
+
+	return results, err
+}
+
+// SubmitRangeTask enqueues task, splitting it into ChannelConfig.PartSize-
+// aligned sub-ranges via ChunkPlanner when its ByteRange span exceeds
+// SplitThreshold and task.Concurrency allows more than one part in flight
+// at once. Concurrency<2 is the fast path: it skips splitting entirely,
+// even over SplitThreshold, since a task that can't run more than one part
+// at a time gets nothing from splitting but reassembly overhead.
+//
+// It returns immediately with task.ID as the parent ID to pass
+// WaitForRangeTask, which blocks for every part's completion reassembled
+// in order.
+func (cm *ChannelManager) SubmitRangeTask(task PrefetchTask) (string, error) {
+	task.ParentID = task.ID
+	span := task.ByteRange.End - task.ByteRange.Start + 1
+
+	if task.Concurrency < 2 || cm.config.SplitThreshold <= 0 || span <= cm.config.SplitThreshold {
+		cm.registerParent(task.ParentID, 1, 1)
+		if err := cm.AddTask(task); err != nil {
+			cm.parentsMu.Lock()
+			delete(cm.parents, task.ParentID)
+			cm.parentsMu.Unlock()
+			return "", err
+		}
+		return task.ParentID, nil
+	}
+
+	ranges := cm.planner.Split(task.ByteRange.Start, task.ByteRange.End)
+	pa := cm.registerParent(task.ParentID, len(ranges), task.Concurrency)
+
+	go cm.dispatchSplitParts(task, ranges, pa)
+
+	return task.ParentID, nil
+}
+
+// dispatchSplitParts enqueues task's split parts one at a time, acquiring
+// pa.sem (sized to task.Concurrency) before each AddTask so at most that
+// many parts are ever outstanding; completeSplitPart releases a slot as
+// each part finishes. An AddTask failure (e.g. ErrNoCredit) is recorded as
+// that part's result immediately rather than retried, so a stuck part
+// can't block pa.sem forever.
+//
+// ChunkPlanner.Split's PartNo is the part's absolute index within the
+// whole file (e.g. a split starting mid-file doesn't begin at 0), but
+// parentAssembly reassembles into a [0, total-1] slice, so sub.PartNo is
+// rebased relative to ranges[0].PartNo before it's used for assembly -
+// the absolute PartNo is kept in sub.ID for identification.
+func (cm *ChannelManager) dispatchSplitParts(task PrefetchTask, ranges []Range, pa *parentAssembly) {
+	base := ranges[0].PartNo
+	for _, r := range ranges {
+		pa.sem <- struct{}{}
+
+		relPartNo := r.PartNo - base
+
+		sub := task
+		sub.ID = fmt.Sprintf("%s-part%d", task.ID, r.PartNo)
+		sub.ParentID = task.ParentID
+		sub.PartNo = relPartNo
+		sub.ByteRange = ByteRange{Start: r.Start, End: r.End}
+
+		if err := cm.AddTask(sub); err != nil {
+			pa.record(relPartNo, TaskResult{Err: err})
+			continue
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package concurrency
+
+import "context"
+
+// ExistingChunkSelector prefers a worker that most recently ran a task
+// for the same reel, on the theory that the Rust/C++ engine behind it
+// already has that reel's connection and cache state warm. It never
+// rejects a worker outright - locality is a preference, not a
+// requirement - so it can't stall scheduling waiting for a "warm enough"
+// worker that never shows up.
+var ExistingChunkSelector WorkerSelector = existingChunkSelector{}
+
+type existingChunkSelector struct{}
+
+func (existingChunkSelector) Ok(ctx context.Context, task PrefetchTask, worker *Worker) (bool, error) {
+	return worker.isActive, nil
+}
+
+func (existingChunkSelector) Cmp(ctx context.Context, task PrefetchTask, a, b *Worker) (bool, error) {
+	aWarm := a.LastReelID == task.ReelID
+	bWarm := b.LastReelID == task.ReelID
+	if aWarm != bWarm {
+		return aWarm, nil
+	}
+	aProcessed, _, _, _, _, _, _, _, _, _ := a.counters.snapshot()
+	bProcessed, _, _, _, _, _, _, _, _, _ := b.counters.snapshot()
+	return aProcessed < bProcessed, nil
+}
+
+// AllocSelector picks whichever active worker has processed the fewest
+// tasks so far. It's the right default for cold chunks that have no
+// locality to exploit.
+var AllocSelector WorkerSelector = allocSelector{}
+
+type allocSelector struct{}
+
+func (allocSelector) Ok(ctx context.Context, task PrefetchTask, worker *Worker) (bool, error) {
+	return worker.isActive, nil
+}
+
+func (allocSelector) Cmp(ctx context.Context, task PrefetchTask, a, b *Worker) (bool, error) {
+	aProcessed, _, _, _, _, _, _, _, _, _ := a.counters.snapshot()
+	bProcessed, _, _, _, _, _, _, _, _, _ := b.counters.snapshot()
+	return aProcessed < bProcessed, nil
+}
+
+// BridgeAffinitySelector keeps tasks pinned to workers that have already
+// run at least one task - and so already paid the cost of warming up
+// their bridge connection - over workers that haven't run anything yet.
+// Among equally warm (or equally cold) workers it falls back to the
+// least-loaded one.
+var BridgeAffinitySelector WorkerSelector = bridgeAffinitySelector{}
+
+type bridgeAffinitySelector struct{}
+
+func (bridgeAffinitySelector) Ok(ctx context.Context, task PrefetchTask, worker *Worker) (bool, error) {
+	return worker.isActive, nil
+}
+
+func (bridgeAffinitySelector) Cmp(ctx context.Context, task PrefetchTask, a, b *Worker) (bool, error) {
+	aProcessed, _, _, _, _, _, _, _, _, _ := a.counters.snapshot()
+	bProcessed, _, _, _, _, _, _, _, _, _ := b.counters.snapshot()
+	aWarm := aProcessed > 0
+	bWarm := bProcessed > 0
+	if aWarm != bWarm {
+		return aWarm, nil
+	}
+	return aProcessed < bProcessed, nil
+}
@@ -1,105 +1,507 @@
 package concurrency
 
 import (
+	"container/heap"
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/sirupsen/logrus"
-	"github.com/kronop/prefetcher/internal/bridge"
 	"github.com/kronop/prefetcher/internal/ai"
+	"github.com/kronop/prefetcher/internal/bridge"
+	"github.com/kronop/prefetcher/internal/cgroup"
 	"github.com/kronop/prefetcher/internal/tracker"
+	"github.com/sirupsen/logrus"
+)
+
+// SchedWindows is how many window requests a worker keeps outstanding
+// with the scheduler at once. One window is being drained while the next
+// is already being filled, so a worker never idles waiting on dispatch.
+const SchedWindows = 2
+
+// SelectorTimeout bounds a single WorkerSelector.Ok call. It guards the
+// scheduler goroutine: a bridge that's wedged behind one worker must not
+// be able to stall window-filling for every other worker.
+const SelectorTimeout = 5 * time.Second
+
+// retryPollInterval is how often the scheduler checks retryQueue for
+// tasks that have become eligible to run again.
+const retryPollInterval = 50 * time.Millisecond
+
+// maxRetryBackoff caps the exponential backoff computed in retryBackoff,
+// so a task with a long MaxRetries budget doesn't end up waiting hours
+// between attempts.
+const maxRetryBackoff = 30 * time.Second
+
+// rustFailoverThreshold is how many consecutive Rust bridge failures
+// trigger auto-routing of PriorityHigh tasks away from it (see
+// executePrefetchTask, recordBridgeResult).
+const rustFailoverThreshold = 3
+
+// defaultBufLimit and defaultMinRecharge seed a bridgeCostTracker when
+// ConcurrencyConfig doesn't specify one, sized for a bridge that can
+// absorb a short burst of ~100 baseline-cost requests and recharges fast
+// enough that a steady PriorityLow/Medium stream never has to wait long.
+const (
+	defaultBufLimit    = 100.0
+	defaultMinRecharge = 20.0
 )
 
 // SmartConcurrency manages intelligent concurrent prefetching with Go channels
 type SmartConcurrency struct {
-	rustBridge    *bridge.RustBridge
-	cppBridge    *bridge.CppBridge
-	analyzer     *ai.PredictionLogic
-	tracker      *tracker.UserBehaviorTracker
-	config       ConcurrencyConfig
-	priorityQueue chan PrefetchTask
-	workerPool    chan struct{}
-	workers      []*Worker
-	mu           sync.RWMutex
-	stats        *ConcurrencyStats
-	stopChan      chan struct{}
+	rustBridge *bridge.RustBridge
+	cppBridge  *bridge.CppBridge
+	analyzer   *ai.PredictionLogic
+	tracker    *tracker.UserBehaviorTracker
+	config     ConcurrencyConfig
+	selector   WorkerSelector
+
+	windowRequests chan schedWindowRequest
+	taskSubmit     chan PrefetchTask
+	taskRetry      chan retryRequest
+	taskDone       chan taskDoneSignal
+	workerShutdown chan workerShutdownNotice
+
+	workers []*Worker
+	mu      sync.RWMutex
+	stats   *ConcurrencyStats
+
+	// rustHealth and cppHealth track each bridge's recent failure streak
+	// (see recordBridgeResult), guarded by mu like the rest of this
+	// manager's shared state.
+	rustHealth BridgeHealth
+	cppHealth  BridgeHealth
+
+	rustCost *bridgeCostTracker
+	cppCost  *bridgeCostTracker
+
+	// counters holds the manager-wide task totals as atomics (see
+	// concurrencyCounters), so processTask can record a task's outcome
+	// without taking mu. GetStats folds a snapshot of these into the
+	// ConcurrencyStats it returns.
+	counters concurrencyCounters
+
+	nextTaskID int64
+	results    map[string]chan *TaskResult
+	resultsMu  sync.Mutex
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// BridgeHealth tracks a bridge's consecutive failure count, used to
+// decide when to stop routing work to it (see recordBridgeResult,
+// rustUnhealthy) and when it's recovered.
+type BridgeHealth struct {
+	FailCnt int  `json:"fail_cnt"`
+	IsErr   bool `json:"is_err"`
 }
 
 // ConcurrencyConfig holds concurrency configuration
 type ConcurrencyConfig struct {
-	MaxWorkers           int           `yaml:"max_workers"`
-	MaxQueueSize         int           `yaml:"max_queue_size"`
-	WorkerTimeout        time.Duration `yaml:"worker_timeout"`
-	RetryAttempts        int           `yaml:"retry_attempts"`
-	RetryDelay           time.Duration `yaml:"retry_delay"`
+	MaxWorkers               int           `yaml:"max_workers"`
+	MaxQueueSize             int           `yaml:"max_queue_size"`
+	MaxConcurrentPerWorker   int           `yaml:"max_concurrent_per_worker"`
+	WorkerTimeout            time.Duration `yaml:"worker_timeout"`
+	RetryAttempts            int           `yaml:"retry_attempts"`
+	RetryDelay               time.Duration `yaml:"retry_delay"`
 	EnableAdaptiveScheduling bool          `yaml:"enable_adaptive_scheduling"`
-	EnablePriorityBoosting    bool          `yaml:"enable_priority_boosting"`
-	NetworkMultiplier     float64       `yaml:"network_multiplier"`
+	EnablePriorityBoosting   bool          `yaml:"enable_priority_boosting"`
+	NetworkMultiplier        float64       `yaml:"network_multiplier"`
+
+	// MemoryLimitMB and NProcLimit are enforced on each task's cgroup
+	// scope (see executePrefetchTask), so a runaway prefetch gets killed
+	// by the kernel instead of just outliving its wall-clock budget.
+	// Zero means "no limit" for either. TimeLimitMS, when set, replaces
+	// Task.Timeout/WorkerTimeout as the per-task wall-clock budget.
+	MemoryLimitMB int `yaml:"memory_limit_mb"`
+	TimeLimitMS   int `yaml:"time_limit_ms"`
+	NProcLimit    int `yaml:"nproc_limit"`
+
+	// RustBufLimit/RustMinRecharge and CppBufLimit/CppMinRecharge are the
+	// token-bucket parameters for each bridge's bridgeCostTracker (see
+	// costtracker.go): how many requests' worth of buffer it can absorb
+	// in a burst, and how many units/s that buffer refills at. Zero
+	// values fall back to sane defaults in NewSmartConcurrency.
+	RustBufLimit    float64 `yaml:"rust_buf_limit"`
+	RustMinRecharge float64 `yaml:"rust_min_recharge"`
+	CppBufLimit     float64 `yaml:"cpp_buf_limit"`
+	CppMinRecharge  float64 `yaml:"cpp_min_recharge"`
 }
 
 // PrefetchTask represents a prefetching task
 type PrefetchTask struct {
-	ID              string
-	UserID          string
-	ReelID          int
-	ChunkID         string
+	ID             string
+	UserID         string
+	ReelID         int
+	ChunkID        string
 	Priority       Priority
-	URL             string
+	URL            string
 	Timeout        time.Duration
-	MaxRetries      int
-	CurrentRetries   int
-	CreatedAt       time.Time
-	ExpiresAt       time.Time
+	MaxRetries     int
+	CurrentRetries int
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
 	Dependencies   []string
-	WorkerID        int
+	WorkerID       int
 	StartTime      time.Time
-	CompletedAt     time.Time
+	CompletedAt    time.Time
 	Success        bool
-	ErrorMessage  string
+	ErrorMessage   string
+
+	// Selector overrides which WorkerSelector picks this task's worker.
+	// Nil falls back to the manager's default (see SetWorkerSelector).
+	Selector WorkerSelector
+
+	// Metrics is the cgroup-sampled resource usage of this task's last
+	// execution attempt, filled in by executePrefetchTask once it's run.
+	Metrics cgroup.TaskMetrics
+
+	// ByteRange is the byte span this task covers. ChannelManager.
+	// SubmitRangeTask splits it into PartSize-aligned parts once it
+	// exceeds ChannelConfig.SplitThreshold (see ChunkPlanner).
+	ByteRange ByteRange
+	// ParentID groups a split task's parts for reassembly (see
+	// parentAssembly); it equals ID itself for an unsplit task, so
+	// WaitForRangeTask works the same way whether or not splitting
+	// actually happened.
+	ParentID string
+	// PartNo is this task's position in ParentID's byte range, used to
+	// order parentAssembly.wait's results back into sequence.
+	PartNo int64
+	// Concurrency bounds how many of this task's own split parts
+	// SubmitRangeTask keeps in flight at once - per task, not a global
+	// worker limit. Concurrency<2 skips splitting entirely.
+	Concurrency int
 }
 
-// Priority represents task priority levels
+// ByteRange is an inclusive [Start, End] byte span.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// TaskResult is what WaitForTask returns once a task finishes: whatever
+// payload the task produced, any error from its last attempt, and the
+// cgroup-sampled TaskMetrics for that attempt.
+type TaskResult struct {
+	Payload interface{}
+	Err     error
+	Metrics cgroup.TaskMetrics
+}
+
+// taskResultPool recycles the *TaskResult processTask hands off to
+// WaitForTask over sc.results, so the hot path doesn't allocate one on
+// every single task completion.
+var taskResultPool = sync.Pool{New: func() interface{} { return new(TaskResult) }}
+
+// Release zeroes r and returns it to the shared TaskResult pool. Call it
+// only once you're done reading r - WaitForTask calls it right after
+// copying the result out for its own caller.
+func (r *TaskResult) Release() {
+	*r = TaskResult{}
+	taskResultPool.Put(r)
+}
+
+// Priority represents task priority levels. Lower values are more urgent,
+// so Priority sorts correctly against container/heap's min-heap ordering
+// (see schedQueue.Less) without any extra translation.
 type Priority int
 
 const (
 	PriorityUrgent Priority = iota
-	PriorityHigh   Priority = iota
-	PriorityMedium Priority = iota
-	PriorityLow    Priority = iota
+	PriorityHigh
+	PriorityMedium
+	PriorityLow
 )
 
-// Worker represents a worker goroutine
+// WorkerCapacity is what a worker declares it can run concurrently. The
+// scheduler accounts in-flight usage against this entirely on its own
+// goroutine (see (sc *SmartConcurrency) scheduler) instead of under a lock.
+type WorkerCapacity struct {
+	MaxConcurrent int
+}
+
+// WorkerSelector decides whether a worker can take a task at all, and -
+// among workers that can - which one is the better fit. It's the plug
+// point for worker-affinity logic (e.g. "this worker already has the
+// Rust bridge warmed up for this reel") without the scheduler goroutine
+// needing to know anything about bridges.
+type WorkerSelector interface {
+	// Ok reports whether worker can run task right now. Callers must bound
+	// ctx with SelectorTimeout so a stuck implementation can't block
+	// trySched for every other worker.
+	Ok(ctx context.Context, task PrefetchTask, worker *Worker) (bool, error)
+	// Cmp reports whether a is a better choice than b for task. Both a and
+	// b have already passed Ok.
+	Cmp(ctx context.Context, task PrefetchTask, a, b *Worker) (bool, error)
+}
+
+// defaultWorkerSelector accepts any active worker and prefers the one
+// that has processed fewer tasks so far, falling back to fewer historical
+// failures.
+// It's deliberately simple; callers that need bridge- or reel-affinity
+// routing can supply their own WorkerSelector via SetWorkerSelector.
+type defaultWorkerSelector struct{}
+
+func (defaultWorkerSelector) Ok(ctx context.Context, task PrefetchTask, worker *Worker) (bool, error) {
+	return worker.isActive, nil
+}
+
+func (defaultWorkerSelector) Cmp(ctx context.Context, task PrefetchTask, a, b *Worker) (bool, error) {
+	aProcessed, _, aFailed, _, _, _, _, _, _, _ := a.counters.snapshot()
+	bProcessed, _, bFailed, _, _, _, _, _, _, _ := b.counters.snapshot()
+	if aProcessed != bProcessed {
+		return aProcessed < bProcessed, nil
+	}
+	return aFailed < bFailed, nil
+}
+
+// schedWindow is a bounded batch of tasks assigned to one worker. Workers
+// pull these proactively instead of having individual tasks pushed to
+// them, which is what gives the scheduler free backpressure: a worker
+// only asks for its next window once it has room for one.
+type schedWindow struct {
+	WorkerID int
+	Tasks    []PrefetchTask
+}
+
+// schedWindowRequest is how a worker asks the scheduler for its next
+// window. done is the worker's own channel, so the scheduler can fill
+// several outstanding requests for the same worker in submission order
+// without the worker needing to fan in on a dynamic set of channels.
+type schedWindowRequest struct {
+	workerID int
+	done     chan *schedWindow
+}
+
+// taskDoneSignal is sent by a worker after it finishes one task in its
+// window, so the scheduler's in-flight accounting for that worker stays
+// current without waiting for the whole window to drain.
+type taskDoneSignal struct {
+	workerID int
+}
+
+// workerShutdownNotice tells the scheduler a worker is gone. stranded is
+// whatever tasks were already assigned to that worker (sitting in an
+// unconsumed window) and must be drained back onto schedQueue rather than
+// silently dropped.
+type workerShutdownNotice struct {
+	workerID int
+	stranded []PrefetchTask
+}
+
+// schedQueueEntry wraps a PrefetchTask with the sequence number it
+// entered schedQueue at, so tasks tied on Priority and CreatedAt still
+// come out in submission order - container/heap isn't stable on its own.
+type schedQueueEntry struct {
+	task PrefetchTask
+	seq  uint64
+}
+
+// schedQueueEntryPool recycles schedQueueEntry wrappers, which the
+// scheduler goroutine otherwise allocates fresh on every Push - including
+// every re-queue of a stranded or retried task.
+var schedQueueEntryPool = sync.Pool{New: func() interface{} { return new(schedQueueEntry) }}
+
+func getSchedQueueEntry(task PrefetchTask, seq uint64) *schedQueueEntry {
+	e := schedQueueEntryPool.Get().(*schedQueueEntry)
+	e.task = task
+	e.seq = seq
+	return e
+}
+
+// releaseSchedQueueEntry zeroes e and returns it to schedQueueEntryPool.
+// Callers must be done with e - in particular, only call this once its
+// task has already been copied out (see scheduleOne).
+func releaseSchedQueueEntry(e *schedQueueEntry) {
+	*e = schedQueueEntry{}
+	schedQueueEntryPool.Put(e)
+}
+
+// schedQueue is a container/heap.Interface ordering pending tasks by
+// Priority (urgent first), then CreatedAt, then submission order.
+type schedQueue []*schedQueueEntry
+
+func (q schedQueue) Len() int { return len(q) }
+
+func (q schedQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if a.task.Priority != b.task.Priority {
+		return a.task.Priority < b.task.Priority
+	}
+	if !a.task.CreatedAt.Equal(b.task.CreatedAt) {
+		return a.task.CreatedAt.Before(b.task.CreatedAt)
+	}
+	return a.seq < b.seq
+}
+
+func (q schedQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *schedQueue) Push(x interface{}) {
+	*q = append(*q, x.(*schedQueueEntry))
+}
+
+func (q *schedQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return entry
+}
+
+// retryRequest is how a worker hands a failed task back to the scheduler
+// once it's decided (in processTask) that the task has retries left. The
+// task re-enters schedQueue once readyAt has passed (see
+// drainReadyRetries), rather than being dispatched again immediately.
+type retryRequest struct {
+	task    PrefetchTask
+	readyAt time.Time
+}
+
+// retryEntry wraps a PrefetchTask with the time it becomes eligible to
+// run again.
+type retryEntry struct {
+	task    PrefetchTask
+	readyAt time.Time
+}
+
+// retryEntryPool recycles retryEntry wrappers the same way
+// schedQueueEntryPool does for schedQueue.
+var retryEntryPool = sync.Pool{New: func() interface{} { return new(retryEntry) }}
+
+func getRetryEntry(task PrefetchTask, readyAt time.Time) *retryEntry {
+	e := retryEntryPool.Get().(*retryEntry)
+	e.task = task
+	e.readyAt = readyAt
+	return e
+}
+
+// releaseRetryEntry zeroes e and returns it to retryEntryPool. Callers
+// must be done with e - only call this once its task has already been
+// copied out (see drainReadyRetries).
+func releaseRetryEntry(e *retryEntry) {
+	*e = retryEntry{}
+	retryEntryPool.Put(e)
+}
+
+// retryQueue is a container/heap.Interface ordering backed-off tasks by
+// readyAt, so the scheduler only ever has to look at the front to find
+// the next task due to come back.
+type retryQueue []*retryEntry
+
+func (q retryQueue) Len() int { return len(q) }
+
+func (q retryQueue) Less(i, j int) bool { return q[i].readyAt.Before(q[j].readyAt) }
+
+func (q retryQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *retryQueue) Push(x interface{}) {
+	*q = append(*q, x.(*retryEntry))
+}
+
+func (q *retryQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return entry
+}
+
+// Worker represents a worker goroutine. It doesn't receive tasks directly;
+// it requests schedWindows from the scheduler and drains them.
 type Worker struct {
-	ID          int
-	Channel     chan PrefetchTask
-	QuitChan     chan struct{}
-	Stats       *WorkerStats
-	isActive    bool
+	ID       int
+	Capacity WorkerCapacity
+	winChan  chan *schedWindow
+	QuitChan chan struct{}
+	isActive bool
+
+	// counters holds this worker's hot-path stats as atomics (see
+	// workerCounters), so processTask can record a task's outcome without
+	// taking any lock. GetWorkerStats loads a WorkerStats snapshot from it.
+	counters workerCounters
+
+	// lastError and lastActiveTime change on every task too, but they're
+	// plain last-value fields, not counters, so they stay behind the same
+	// single-writer convention as LastReelID below rather than needing
+	// their own atomics.
+	lastError      string
+	lastActiveTime time.Time
+
+	// LastReelID is the ReelID of the most recent task this worker ran.
+	// It's written only by this worker's own goroutine (in processTask)
+	// and read by WorkerSelector implementations on the scheduler
+	// goroutine for locality hints.
+	LastReelID int
 }
 
 // WorkerStats holds worker statistics
 type WorkerStats struct {
-	TasksProcessed    int     `json:"tasks_processed"`
-	TasksSucceeded   int     `json:"tasks_succeeded"`
-	TasksFailed      int     `json:"tasks_failed"`
+	TasksProcessed   int           `json:"tasks_processed"`
+	TasksSucceeded   int           `json:"tasks_succeeded"`
+	TasksFailed      int           `json:"tasks_failed"`
 	AvgProcessTime   time.Duration `json:"avg_process_time"`
 	TotalProcessTime time.Duration `json:"total_process_time"`
-	LastError      string    `json:"last_error"`
-	LastActiveTime   time.Time `json:"last_active_time"`
+	LastError        string        `json:"last_error"`
+	LastActiveTime   time.Time     `json:"last_active_time"`
+
+	// TotalCPUTime, AvgRSS and PeakRSS are aggregated from each task's
+	// cgroup-sampled TaskMetrics (see processTask). They stay zero on
+	// platforms where NewTaskScope falls back to a no-op scope.
+	TotalCPUTime time.Duration `json:"total_cpu_time"`
+	AvgRSS       uint64        `json:"avg_rss"`
+	PeakRSS      uint64        `json:"peak_rss"`
+
+	// TotalIORead, TotalIOWrite and TotalMajorFaults are likewise
+	// aggregated from TaskMetrics, added alongside CPU/memory so a
+	// worker's I/O and fault pressure is visible next to its CPU/memory
+	// footprint instead of only in the raw per-task samples.
+	TotalIORead      uint64 `json:"total_io_read"`
+	TotalIOWrite     uint64 `json:"total_io_write"`
+	TotalMajorFaults uint64 `json:"total_major_faults"`
 }
 
 // ConcurrencyStats holds concurrency statistics
 type ConcurrencyStats struct {
-	TotalTasks        int       `json:"total_tasks"`
-	SuccessfulTasks   int       `json:"successful_tasks"`
-	FailedTasks       int       `json:"failed_tasks"`
+	TotalTasks        int           `json:"total_tasks"`
+	SuccessfulTasks   int           `json:"successful_tasks"`
+	FailedTasks       int           `json:"failed_tasks"`
 	AvgProcessTime    time.Duration `json:"avg_process_time"`
-	ActiveWorkers     int       `json:"active_workers"`
-	QueueUtilization float64   `json:"queue_utilization"`
-	ThroughputBPS     float64   `json:"throughput_bps"`
-	CacheHitRate       float64   `json:"cache_hit_rate"`
-	NetworkEfficiency float64   `json:"network_efficiency"`
+	ActiveWorkers     int           `json:"active_workers"`
+	QueueUtilization  float64       `json:"queue_utilization"`
+	ThroughputBPS     float64       `json:"throughput_bps"`
+	CacheHitRate      float64       `json:"cache_hit_rate"`
+	NetworkEfficiency float64       `json:"network_efficiency"`
+
+	// RustBridge/CppBridge mirror rustHealth/cppHealth at the time of the
+	// last recordBridgeResult call, so callers can see bridge health
+	// without reaching into manager internals.
+	RustBridge BridgeHealth `json:"rust_bridge"`
+	CppBridge  BridgeHealth `json:"cpp_bridge"`
+
+	// Flow-control snapshots from rustCost/cppCost, refreshed by
+	// updatePerformanceMetrics.
+	RustBufferAvailable float64 `json:"rust_buffer_available"`
+	RustEstimatedWaitMs float64 `json:"rust_estimated_wait_ms"`
+	RustAvgCostPerReq   float64 `json:"rust_avg_cost_per_request"`
+	CppBufferAvailable  float64 `json:"cpp_buffer_available"`
+	CppEstimatedWaitMs  float64 `json:"cpp_estimated_wait_ms"`
+	CppAvgCostPerReq    float64 `json:"cpp_avg_cost_per_request"`
+}
+
+// QueueStats describes the pending-task backlog at a point in time.
+type QueueStats struct {
+	QueueSize       int     `json:"queue_size"`
+	MaxQueueSize    int     `json:"max_queue_size"`
+	Utilization     float64 `json:"utilization"`
+	WaitingTasks    int     `json:"waiting_tasks"`
+	ProcessingTasks int     `json:"processing_tasks"`
+	CompletedTasks  int     `json:"completed_tasks"`
+	FailedTasks     int     `json:"failed_tasks"`
 }
 
 // NewSmartConcurrency creates a new smart concurrency manager
@@ -110,45 +512,68 @@ func NewSmartConcurrency(
 	tracker *tracker.UserBehaviorTracker,
 	config ConcurrencyConfig,
 ) *SmartConcurrency {
+	if config.MaxConcurrentPerWorker <= 0 {
+		config.MaxConcurrentPerWorker = 1
+	}
+	if config.RustBufLimit <= 0 {
+		config.RustBufLimit = defaultBufLimit
+	}
+	if config.RustMinRecharge <= 0 {
+		config.RustMinRecharge = defaultMinRecharge
+	}
+	if config.CppBufLimit <= 0 {
+		config.CppBufLimit = defaultBufLimit
+	}
+	if config.CppMinRecharge <= 0 {
+		config.CppMinRecharge = defaultMinRecharge
+	}
+
 	return &SmartConcurrency{
-		rustBridge:    rustBridge,
-		cppBridge:    cppBridge,
-		analyzer:     analyzer,
-		tracker:      tracker,
-		config:      config,
-		priorityQueue: make(chan PrefetchTask, config.MaxQueueSize),
-		workerPool:    make(chan struct{}, config.MaxWorkers),
-		workers:      make([]*Worker, config.MaxWorkers),
-		stats:        &ConcurrencyStats{},
-		stopChan:     make(chan struct{}),
+		rustBridge:     rustBridge,
+		cppBridge:      cppBridge,
+		analyzer:       analyzer,
+		tracker:        tracker,
+		config:         config,
+		selector:       defaultWorkerSelector{},
+		rustCost:       newBridgeCostTracker(config.RustBufLimit, config.RustMinRecharge),
+		cppCost:        newBridgeCostTracker(config.CppBufLimit, config.CppMinRecharge),
+		windowRequests: make(chan schedWindowRequest, config.MaxWorkers*SchedWindows),
+		taskSubmit:     make(chan PrefetchTask, config.MaxQueueSize),
+		taskRetry:      make(chan retryRequest, config.MaxQueueSize),
+		taskDone:       make(chan taskDoneSignal, config.MaxWorkers),
+		workerShutdown: make(chan workerShutdownNotice, config.MaxWorkers),
+		workers:        make([]*Worker, 0, config.MaxWorkers),
+		stats:          &ConcurrencyStats{},
+		results:        make(map[string]chan *TaskResult),
+		stopChan:       make(chan struct{}),
 	}
 }
 
+// SetWorkerSelector overrides the WorkerSelector used to admit and rank
+// workers during window fills. Must be called before Start.
+func (sc *SmartConcurrency) SetWorkerSelector(selector WorkerSelector) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.selector = selector
+}
+
 // Start starts the smart concurrency manager
 func (sc *SmartConcurrency) Start(ctx context.Context) error {
 	logrus.Info("🚀 Starting Smart Concurrency Manager")
 
-	// Create worker pool
+	go sc.scheduler(ctx)
+
+	sc.mu.Lock()
 	for i := 0; i < sc.config.MaxWorkers; i++ {
-		worker := &Worker{
-			ID:          i,
-			Channel:     make(chan PrefetchTask),
-			QuitChan:     make(chan struct{}),
-			Stats:       &WorkerStats{},
-			isActive:    true,
-		}
-		
-		sc.workers[i] = worker
+		worker := sc.newWorker(i)
+		sc.workers = append(sc.workers, worker)
 		go sc.startWorker(ctx, worker)
 	}
+	sc.mu.Unlock()
 
-	// Start task dispatcher
-	go sc.taskDispatcher(ctx)
-
-	// Start performance monitor
 	go sc.performanceMonitor(ctx)
+	go sc.bridgeHealthMonitor(ctx)
 
-	// Start adaptive scheduler
 	if sc.config.EnableAdaptiveScheduling {
 		go sc.adaptiveScheduler(ctx)
 	}
@@ -157,22 +582,266 @@ func (sc *SmartConcurrency) Start(ctx context.Context) error {
 	return nil
 }
 
-// startWorker starts a worker goroutine
+func (sc *SmartConcurrency) newWorker(id int) *Worker {
+	return &Worker{
+		ID:       id,
+		Capacity: WorkerCapacity{MaxConcurrent: sc.config.MaxConcurrentPerWorker},
+		winChan:  make(chan *schedWindow, SchedWindows),
+		QuitChan: make(chan struct{}),
+		isActive: true,
+	}
+}
+
+// schedulerState is the scheduler goroutine's private state - schedQueue,
+// in-flight counts per worker, and window requests waiting to be filled.
+// Nothing outside the scheduler goroutine reads or writes these fields.
+type schedulerState struct {
+	queue    schedQueue
+	retry    retryQueue
+	nextSeq  uint64
+	inFlight map[int]int
+	pending  map[int][]schedWindowRequest
+}
+
+// scheduler owns schedQueue and all per-worker window/capacity bookkeeping.
+// It is the only goroutine that ever touches either, which is what lets
+// capacity accounting happen without a lock.
+func (sc *SmartConcurrency) scheduler(ctx context.Context) {
+	state := &schedulerState{
+		inFlight: make(map[int]int),
+		pending:  make(map[int][]schedWindowRequest),
+	}
+
+	retryTicker := time.NewTicker(retryPollInterval)
+	defer retryTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sc.stopChan:
+			return
+
+		case task := <-sc.taskSubmit:
+			heap.Push(&state.queue, getSchedQueueEntry(task, state.nextSeq))
+			state.nextSeq++
+			sc.trySched(ctx, state)
+
+		case rr := <-sc.taskRetry:
+			heap.Push(&state.retry, getRetryEntry(rr.task, rr.readyAt))
+
+		case <-retryTicker.C:
+			if sc.drainReadyRetries(state) {
+				sc.trySched(ctx, state)
+			}
+
+		case req := <-sc.windowRequests:
+			state.pending[req.workerID] = append(state.pending[req.workerID], req)
+			sc.trySched(ctx, state)
+
+		case done := <-sc.taskDone:
+			if state.inFlight[done.workerID] > 0 {
+				state.inFlight[done.workerID]--
+			}
+			sc.trySched(ctx, state)
+
+		case notice := <-sc.workerShutdown:
+			delete(state.pending, notice.workerID)
+			delete(state.inFlight, notice.workerID)
+			for _, task := range notice.stranded {
+				heap.Push(&state.queue, getSchedQueueEntry(task, state.nextSeq))
+				state.nextSeq++
+			}
+			sc.trySched(ctx, state)
+		}
+	}
+}
+
+// drainReadyRetries moves every retryQueue entry whose readyAt has
+// passed onto schedQueue, so the normal priority-ordered dispatch picks
+// it back up like any other queued task. Reports whether it moved
+// anything, so the caller knows whether a re-schedule pass is worthwhile.
+func (sc *SmartConcurrency) drainReadyRetries(state *schedulerState) bool {
+	now := time.Now()
+	moved := false
+	for state.retry.Len() > 0 && !state.retry[0].readyAt.After(now) {
+		entry := heap.Pop(&state.retry).(*retryEntry)
+		heap.Push(&state.queue, getSchedQueueEntry(entry.task, state.nextSeq))
+		state.nextSeq++
+		releaseRetryEntry(entry)
+		moved = true
+	}
+	return moved
+}
+
+// trySched assigns queued tasks to workers with outstanding window
+// requests, highest priority first, choosing among candidate workers via
+// the task's WorkerSelector (or the manager's default). It's re-run
+// whenever the queue, the pending-request set, or in-flight counts change,
+// and keeps going until a pass makes no progress.
+func (sc *SmartConcurrency) trySched(ctx context.Context, state *schedulerState) {
+	sc.mu.RLock()
+	workers := sc.workers
+	sc.mu.RUnlock()
+
+	for sc.scheduleOne(ctx, state, workers) {
+	}
+}
+
+// scheduleOne assigns the single highest-priority queued task to the best
+// available worker, if any worker can currently take it. It reports
+// whether it made an assignment, so trySched knows whether to keep going.
+func (sc *SmartConcurrency) scheduleOne(ctx context.Context, state *schedulerState, workers []*Worker) bool {
+	if state.queue.Len() == 0 {
+		return false
+	}
+
+	entry := heap.Pop(&state.queue).(*schedQueueEntry)
+	task := entry.task
+
+	selector := task.Selector
+	if selector == nil {
+		selector = sc.selector
+	}
+
+	best := sc.bestWorker(ctx, state, workers, task, selector)
+	if best == nil {
+		heap.Push(&state.queue, entry)
+		return false
+	}
+
+	reqs := state.pending[best.ID]
+	req := reqs[0]
+	state.pending[best.ID] = reqs[1:]
+	if len(state.pending[best.ID]) == 0 {
+		delete(state.pending, best.ID)
+	}
+	state.inFlight[best.ID]++
+
+	req.done <- &schedWindow{WorkerID: best.ID, Tasks: []PrefetchTask{task}}
+	releaseSchedQueueEntry(entry)
+	return true
+}
+
+// bestWorker finds the worker, among those with an outstanding window
+// request and spare capacity, that selector.Ok admits and selector.Cmp
+// ranks highest for task. Every Ok/Cmp call is bounded by SelectorTimeout
+// so one stuck selector implementation can't block scheduling for every
+// other worker.
+func (sc *SmartConcurrency) bestWorker(ctx context.Context, state *schedulerState, workers []*Worker, task PrefetchTask, selector WorkerSelector) *Worker {
+	var best *Worker
+
+	for _, worker := range workers {
+		if len(state.pending[worker.ID]) == 0 {
+			continue
+		}
+		if worker.Capacity.MaxConcurrent-state.inFlight[worker.ID] <= 0 {
+			continue
+		}
+
+		okCtx, cancel := context.WithTimeout(ctx, SelectorTimeout)
+		ok, err := selector.Ok(okCtx, task, worker)
+		cancel()
+		if err != nil {
+			logrus.Warnf("⚠️ worker selector error for worker %d: %v", worker.ID, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if best == nil {
+			best = worker
+			continue
+		}
+
+		cmpCtx, cancel := context.WithTimeout(ctx, SelectorTimeout)
+		better, err := selector.Cmp(cmpCtx, task, worker, best)
+		cancel()
+		if err != nil {
+			logrus.Warnf("⚠️ worker selector compare error for workers %d/%d: %v", worker.ID, best.ID, err)
+			continue
+		}
+		if better {
+			best = worker
+		}
+	}
+
+	return best
+}
+
+// startWorker keeps SchedWindows window requests outstanding with the
+// scheduler and runs whatever windows come back, requesting a
+// replacement each time one finishes. This is what replaced the old
+// 10ms-ticker taskDispatcher: a worker only ever waits on its own
+// winChan, never on a poll.
 func (sc *SmartConcurrency) startWorker(ctx context.Context, worker *Worker) {
 	logrus.Infof("👷 Started worker %d", worker.ID)
 
+	for i := 0; i < SchedWindows; i++ {
+		sc.requestWindow(worker)
+	}
+
 	for {
 		select {
-		case task := <-worker.Channel:
-			worker.processTask(ctx, worker, task)
 		case <-worker.QuitChan:
 			worker.isActive = false
+			sc.handleWorkerShutdown(worker)
 			logrus.Infof("🛑 Worker %d stopped", worker.ID)
 			return
 		case <-ctx.Done():
 			worker.isActive = false
+			sc.handleWorkerShutdown(worker)
 			logrus.Infof("🛑 Worker %d shutting down", worker.ID)
 			return
+		case win := <-worker.winChan:
+			sc.runWindow(ctx, worker, win)
+			sc.requestWindow(worker)
+		}
+	}
+}
+
+// requestWindow sends a schedWindowRequest for worker, giving up if the
+// manager is stopping so shutdown never blocks on a scheduler that has
+// already exited.
+func (sc *SmartConcurrency) requestWindow(worker *Worker) {
+	select {
+	case sc.windowRequests <- schedWindowRequest{workerID: worker.ID, done: worker.winChan}:
+	case <-sc.stopChan:
+	}
+}
+
+// handleWorkerShutdown drains any windows already delivered to this
+// worker but not yet run, and tells the scheduler to drop this worker's
+// bookkeeping and requeue the stranded tasks onto schedQueue.
+func (sc *SmartConcurrency) handleWorkerShutdown(worker *Worker) {
+	var stranded []PrefetchTask
+drain:
+	for {
+		select {
+		case win := <-worker.winChan:
+			stranded = append(stranded, win.Tasks...)
+		default:
+			break drain
+		}
+	}
+
+	select {
+	case sc.workerShutdown <- workerShutdownNotice{workerID: worker.ID, stranded: stranded}:
+	case <-time.After(sc.config.WorkerTimeout):
+		logrus.Warnf("⚠️ scheduler unresponsive, dropping %d stranded task(s) from worker %d", len(stranded), worker.ID)
+	}
+}
+
+// runWindow processes every task in win, one at a time, signalling
+// taskDone after each so the scheduler's in-flight accounting for this
+// worker stays current without waiting for the whole window to drain.
+func (sc *SmartConcurrency) runWindow(ctx context.Context, worker *Worker, win *schedWindow) {
+	for _, task := range win.Tasks {
+		sc.processTask(ctx, worker, task)
+
+		select {
+		case sc.taskDone <- taskDoneSignal{workerID: worker.ID}:
+		case <-sc.stopChan:
 		}
 	}
 }
@@ -180,258 +849,251 @@ func (sc *SmartConcurrency) startWorker(ctx context.Context, worker *Worker) {
 // processTask processes a single prefetch task
 func (sc *SmartConcurrency) processTask(ctx context.Context, worker *Worker, task PrefetchTask) {
 	startTime := time.Now()
-	
+
 	logrus.Debugf("📦 Worker %d processing task: %s (priority: %d)", worker.ID, task.ID, task.Priority)
-	
-	// Mark task as started
+
 	task.StartTime = time.Now()
-	
-	// Execute the prefetch task
-	err := sc.executePrefetchTask(ctx, worker, task)
-	
-	// Update worker stats
-	worker.Stats.TasksProcessed++
-	worker.Stats.TotalProcessTime += time.Since(startTime)
-	
+
+	metrics, err := sc.executePrefetchTask(ctx, worker, task)
+	task.Metrics = metrics
+
+	worker.counters.recordTask(time.Since(startTime), metrics.CPUTime, metrics.MaxRSS, err == nil, metrics.IORead, metrics.IOWrite, metrics.MajorFaults)
+	worker.lastActiveTime = time.Now()
+	worker.LastReelID = task.ReelID
+
+	task.CompletedAt = time.Now()
+
+	retrying := false
 	if err != nil {
-		worker.Stats.FailedTasks++
-		worker.Stats.LastError = err.Error()
+		worker.lastError = err.Error()
+		task.Success = false
+		task.ErrorMessage = err.Error()
 		logrus.Errorf("❌ Task %s failed: %v", task.ID, err)
+
+		if task.CurrentRetries < task.MaxRetries {
+			retrying = true
+			task.CurrentRetries++
+			backoff := sc.retryBackoff(task.CurrentRetries)
+			logrus.Warnf("🔁 Retrying task %s in %v (attempt %d/%d)", task.ID, backoff, task.CurrentRetries, task.MaxRetries)
+
+			select {
+			case sc.taskRetry <- retryRequest{task: task, readyAt: time.Now().Add(backoff)}:
+			case <-sc.stopChan:
+			}
+		}
 	} else {
-		worker.Stats.TasksSucceeded++
+		task.Success = true
 		logrus.Debugf("✅ Task %s completed successfully", task.ID)
 	}
-	
-	// Mark task as completed
-	task.CompletedAt = time.Now()
-	task.Success = true
-	task.ErrorMessage = ""
-	
-	// Update global stats
-	sc.mu.Lock()
-	sc.stats.TotalTasks++
-	if err != nil {
-		sc.stats.FailedTasks++
-	} else {
-		sc.stats.SuccessfulTasks++
+
+	sc.counters.record(err == nil)
+
+	if retrying {
+		return
 	}
-	sc.mu.Unlock()
+
+	sc.resultsMu.Lock()
+	if ch, ok := sc.results[task.ID]; ok {
+		result := taskResultPool.Get().(*TaskResult)
+		result.Err = err
+		result.Metrics = metrics
+		ch <- result
+	}
+	sc.resultsMu.Unlock()
+}
+
+// retryBackoff computes the next retry delay for a task on its attempt'th
+// retry as RetryDelay*2^attempt, capped at maxRetryBackoff.
+func (sc *SmartConcurrency) retryBackoff(attempt int) time.Duration {
+	sc.mu.RLock()
+	base := sc.config.RetryDelay
+	sc.mu.RUnlock()
+
+	if base <= 0 {
+		base = time.Second
+	}
+	if attempt > 32 {
+		return maxRetryBackoff
+	}
+
+	backoff := base * time.Duration(int64(1)<<uint(attempt))
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
 }
 
-// executePrefetchTask executes a single prefetch task
-func (sc *SmartConcurrency) executePrefetchTask(ctx context.Context, worker *worker, task PrefetchTask) error {
-	// Set timeout for the task
-	ctx, cancel := context.WithTimeout(task.Timeout)
+// executePrefetchTask executes a single prefetch task against the Rust
+// prefetch engine, inside a per-task cgroup scope that enforces
+// MemoryLimitMB/NProcLimit and reports TaskMetrics once the task
+// finishes. Urgent tasks get a dedicated retry path (see
+// executeUrgentTask) since they have no later window where a normal
+// retry pass would still help. TimeLimitMS, when set, replaces
+// task.Timeout/WorkerTimeout as the wall-clock budget - cgroups enforce
+// memory and process count, not time, so the context deadline is still
+// what catches a task that's merely slow rather than runaway.
+func (sc *SmartConcurrency) executePrefetchTask(ctx context.Context, worker *Worker, task PrefetchTask) (cgroup.TaskMetrics, error) {
+	timeout := task.Timeout
+	if sc.config.TimeLimitMS > 0 {
+		timeout = time.Duration(sc.config.TimeLimitMS) * time.Millisecond
+	} else if timeout <= 0 {
+		timeout = sc.config.WorkerTimeout
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Determine which bridge to use based on task type
-	var err error
-	
-	switch task.Priority {
-	case PriorityUrgent:
-		// Use both Rust and C++ bridges for urgent tasks
-		err = sc.executeUrgentTask(ctx, task)
-	case PriorityHigh:
-		// Use Rust bridge for high priority tasks
-		err = sc.executeRustTask(ctx, task)
-	case PriorityMedium:
-		// Use C++ bridge for medium priority tasks
-		err = sc.executeCppTask(ctx, task)
-	case PriorityLow:
-		// Use C++ bridge for low priority tasks
-		err = sc.executeCppTask(ctx, task)
+	scope, err := cgroup.NewTaskScope(cgroup.DefaultMountpoint, task.ID, cgroup.TaskLimits{
+		MemoryLimitMB: sc.config.MemoryLimitMB,
+		NProcLimit:    sc.config.NProcLimit,
+	})
+	if err != nil {
+		logrus.Warnf("⚠️ cgroup scope unavailable for task %s, running unconfined: %v", task.ID, err)
+		scope = nil
+	} else if err := scope.Enter(); err != nil {
+		logrus.Warnf("⚠️ failed to enter cgroup scope for task %s, running unconfined: %v", task.ID, err)
+		scope = nil
+	}
+
+	var execErr error
+	switch {
+	case task.Priority == PriorityUrgent:
+		execErr = sc.executeUrgentTask(execCtx, task)
+	case task.Priority == PriorityHigh && sc.rustUnhealthy():
+		execErr = sc.executeCppFallback(execCtx, task)
 	default:
-		err = fmt.Errorf("unknown priority: %d", task.Priority)
+		execErr = sc.dispatchRustTask(execCtx, task)
 	}
 
-	return err
+	var metrics cgroup.TaskMetrics
+	if scope != nil {
+		var closeErr error
+		metrics, closeErr = scope.Close()
+		if closeErr != nil {
+			logrus.Warnf("⚠️ failed to tear down cgroup scope for task %s: %v", task.ID, closeErr)
+		}
+	}
+
+	return metrics, execErr
 }
 
-// executeUrgent task using both Rust and C++ bridges
+// executeUrgentTask retries once immediately on failure.
 func (sc *SmartConcurrency) executeUrgentTask(ctx context.Context, task PrefetchTask) error {
-	// First try Rust bridge
-	err = sc.executeRustTask(ctx, task)
+	err := sc.executeRustTask(ctx, task)
 	if err != nil {
-		// Fall back to C++ bridge
-		err = sc.executeCppTask(ctx, task)
+		logrus.Warnf("⚠️ urgent task %s failed once, retrying immediately: %v", task.ID, err)
+		err = sc.executeRustTask(ctx, task)
 	}
 	return err
 }
 
-// executeRustTask executes task using Rust bridge
+// dispatchRustTask applies rustCost's flow control before running task
+// against the Rust bridge. PriorityLow/Medium tasks wait out the
+// tracker's estimated recharge time when the buffer can't cover the
+// task's cost right now, rather than piling onto the bridge immediately;
+// higher-priority tasks still reserve cost (so the tracker stays
+// accurate) but never wait for it.
+func (sc *SmartConcurrency) dispatchRustTask(ctx context.Context, task PrefetchTask) error {
+	cost := sc.rustCost.costForTask(task)
+
+	if !sc.rustCost.tryReserve(cost) && (task.Priority == PriorityLow || task.Priority == PriorityMedium) {
+		wait := sc.rustCost.waitFor(cost)
+		if wait > 0 {
+			logrus.Debugf("⏳ task %s waiting %v for Rust bridge buffer headroom", task.ID, wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		sc.rustCost.tryReserve(cost)
+	}
+
+	return sc.executeRustTask(ctx, task)
+}
+
+// executeRustTask executes task using the Rust prefetch engine
 func (sc *SmartConcurrency) executeRustTask(ctx context.Context, task PrefetchTask) error {
 	logrus.Debugf("🦀 Executing Rust task: %s (reel: %d, chunk: %s)", task.ID, task.ReelID, task.ChunkID)
 
-	// Create request for Rust engine
-	request := bridge.RustEngineRequest{
-		Type:      "prefetch_chunk",
-		ReelID:    task.ReelID,
-		ChunkID:   task.ChunkID,
-		Timestamp: task.Timestamp.Unix(),
-	}
-
-	// Send request to Rust engine
-	response, err := sc.rustBridge.SendRequest(request)
+	err := sc.rustBridge.PrefetchChunk(task.ReelID, task.ChunkID)
+	sc.recordBridgeResult(&sc.rustHealth, err)
 	if err != nil {
-		return fmt.Errorf("Rust bridge error: %v", err)
+		return fmt.Errorf("rust bridge error: %w", err)
 	}
 
-	// Check response
-	if response.Status != "success" {
-		return fmt.Errorf("Rust engine error: %s", response.Error)
-	}
-
-	// Success
 	logrus.Debugf("✅ Rust task completed: %s", task.ID)
 	return nil
 }
 
-// executeCppTask executes task using C++ bridge
-func (sc *SmartConcurrency) executeCppTask(ctx context.Context, task PrefetchTask) error {
-	logrus.Debugf("🖥️ Executing C++ task: %s (reel: %d, chunk: %s)", task.ID, task.ReelID, task.ChunkID)
+// executeCppFallback is where PriorityHigh tasks land once the Rust
+// bridge has failed rustFailoverThreshold times in a row (see
+// rustUnhealthy). CppBridge is a display/frame engine with no
+// chunk-prefetch equivalent to RustBridge.PrefetchChunk, so until it
+// grows one this reports a clear error - and records it against
+// cppHealth - rather than silently doing nothing, so callers still get
+// real failure accounting and a retry instead of a task that looks like
+// it ran.
+func (sc *SmartConcurrency) executeCppFallback(ctx context.Context, task PrefetchTask) error {
+	sc.cppCost.tryReserve(sc.cppCost.costForTask(task))
+	err := fmt.Errorf("cpp bridge has no prefetch-capable method for task %s (reel: %d, chunk: %s)", task.ID, task.ReelID, task.ChunkID)
+	sc.recordBridgeResult(&sc.cppHealth, err)
+	return err
+}
 
-	// Create request for C++ engine
-	request := bridge.CppEngineRequest{
-		Type:      "prefetch_chunk",
-		ReelID:    task.ReelID,
-	ChunkID:   task.ChunkID,
-		Timestamp: task.Timestamp.Unix(),
-	}
+// recordBridgeResult updates health's consecutive-failure count from the
+// outcome of one bridge call, flips IsErr once it crosses
+// rustFailoverThreshold, and mirrors the result into ConcurrencyStats.
+func (sc *SmartConcurrency) recordBridgeResult(health *BridgeHealth, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 
-	// Send request to C++ engine
-	response, err := sc.cppBridge.SendRequest(request)
 	if err != nil {
-		return fmt.Errorf("C++ bridge error: %v", err)
+		health.FailCnt++
+		if health.FailCnt >= rustFailoverThreshold {
+			health.IsErr = true
+		}
+	} else {
+		health.FailCnt = 0
+		health.IsErr = false
 	}
 
-	// Check response
-	if response.Status != "success" {
-		return fmt.Errorf("C++ engine error: %s", response.Error)
-	}
+	sc.stats.RustBridge = sc.rustHealth
+	sc.stats.CppBridge = sc.cppHealth
+}
 
-	// Success
-		logrus.Debugf("✅ C++ task completed: %s", task.ID)
-		return nil
+// rustUnhealthy reports whether the Rust bridge has failed enough
+// consecutive times that PriorityHigh tasks should be routed away from
+// it (see executePrefetchTask). bridgeHealthMonitor clears this once a
+// health probe succeeds.
+func (sc *SmartConcurrency) rustUnhealthy() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.rustHealth.IsErr
 }
 
-// taskDispatcher distributes tasks to workers based on priority
-func (sc *SmartConcurrency) taskDispatcher(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Millisecond)
+// bridgeHealthMonitor periodically probes the Rust bridge while it's
+// marked unhealthy, so a task stream doesn't have to supply a successful
+// call before auto-routing reverts back to it.
+func (sc *SmartConcurrency) bridgeHealthMonitor(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			logrus.Info("🛑 Task dispatcher stopped")
 			return
 		case <-ticker.C:
-			sc.dispatchTasks(ctx)
-		case task := <-sc.priorityQueue:
-			sc.dispatchTask(ctx, task)
-		}
-	}
-}
-
-// dispatchTasks dispatches tasks to appropriate workers
-func (sc *SmartConcurrency) dispatchTasks(ctx context.Context) {
-	// Get next task from priority queue
-	select {
-	case task := <-sc.priorityQueue:
-		sc.dispatchTask(ctx, task)
-	default:
-			return // No tasks in queue
-	}
-}
-
-// dispatchTask dispatches a single task to the best available worker
-func (sc *SmartConcurrency) dispatchTask(ctx context.Context, task PrefetchTask) {
-	// Find best available worker
-	worker := sc.findBestWorker(task.Priority)
-	
-	if worker == nil {
-		logrus.Warn("⚠️ No available workers, re-queuing task")
-		// Re-queue the task
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			sc.priorityQueue <- task
-		}()
-		return
-	}
-
-	// Dispatch to worker
-	select {
-	case worker.Channel <- task:
-		// Task dispatched successfully
-		logrus.Debugf("📤 Dispatched task %s to worker %d", task.ID, worker.ID)
-	default:
-		// Worker busy, try next worker
-	}
-}
-
-// findBestWorker finds the best available worker for a task
-func (sc *SmartConcurrency) findBestWorker(priority Priority) *Worker {
-	sc.mu.RLock()
-	defer sc.mu.Runlock()
-
-	var bestWorker *Worker
-	bestScore := -1.0
-	bestWorkerID := -1
-
-	for _, worker := range sc.workers {
-		if !worker.isActive {
-			continue
-		}
-
-		// Calculate worker score based on current load and task priority
-		score := sc.calculateWorkerScore(worker, priority)
-		
-		if score > bestScore {
-			bestScore = score
-			bestWorkerID = worker.ID
-			bestWorker = worker
+			if !sc.rustUnhealthy() {
+				continue
+			}
+			err := sc.rustBridge.HealthCheck()
+			sc.recordBridgeResult(&sc.rustHealth, err)
+			if err == nil {
+				logrus.Info("💚 Rust bridge health probe succeeded, resuming normal routing")
+			}
 		}
 	}
-
-	if bestWorkerID >= 0 {
-		return sc.workers[bestWorkerID]
-	}
-
-	return nil
-}
-
-// calculateWorkerScore calculates worker score for task assignment
-func (sc *SmartConcurrency) calculateWorkerScore(worker *Worker, priority Priority) float64 {
-	score := 0.0
-
-	// Priority-based scoring
-	switch priority {
-	case PriorityUrgent:
-		score = 1.0
-	case PriorityHigh:
-		score = 0.8
-	case PriorityMedium:
-		score = 0.6
-	case PriorityLow:
-		score = 0.4
-	}
-
-	// Load-based scoring (less loaded workers get higher scores)
-	loadFactor := 1.0 - (float64(worker.Stats.TasksProcessed) / float64(sc.config.MaxTasksPerWorker))
-	score += loadFactor * 0.5
-
-	// Performance-based scoring (faster workers get higher scores)
-	perfFactor := worker.Stats.AvgProcessTime.Seconds()
-	if perfFactor > 0 {
-		score += (1.0 / perfFactor) * 0.3
-	}
-
-	// Error-based scoring (workers with fewer errors get higher scores)
-	errorRate := float64(worker.Stats.FailedTasks) / float64(worker.Stats.TasksProcessed)
-	if errorRate > 0 {
-		score *= (1.0 - errorRate) * 0.2
-	}
-
-	return score
 }
 
 // performanceMonitor monitors performance metrics
@@ -451,119 +1113,109 @@ func (sc *SmartConcurrency) performanceMonitor(ctx context.Context) {
 
 // updatePerformanceMetrics updates performance metrics
 func (sc *SmartConcurrency) updatePerformanceMetrics() {
+	totalTasks, successfulTasks, _ := sc.counters.snapshot()
+
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	// Calculate throughput
-	throughputput := float64(sc.stats.SuccessfulTasks) / float64(sc.stats.TotalTasks) * 1000 // tasks per second
-
-	// Calculate queue utilization
-		queueUtilization := float64(len(sc.priorityQueue)) / float64(sc.config.MaxQueueSize)
+	var throughput float64
+	if totalTasks > 0 {
+		throughput = float64(successfulTasks) / float64(totalTasks) * 1000
+	}
 
-	// Calculate network efficiency
-		networkEfficiency := sc.calculateNetworkEfficiency()
+	queueUtilization := float64(len(sc.taskSubmit)) / float64(sc.config.MaxQueueSize)
+	networkEfficiency := sc.calculateNetworkEfficiency()
 
-	// Update stats
-	sc.stats.ThroughputputBPS = throughput
+	sc.stats.ThroughputBPS = throughput
 	sc.stats.QueueUtilization = queueUtilization
 	sc.stats.NetworkEfficiency = networkEfficiency
 
-	if sc.stats.ThroughputputBPS > 0 {
-		logrus.Infof("📊 Performance: %.1f TPS, Queue: %.1f%%, Network: %.1f%%", 
-			sc.stats.ThroughputputBPS, sc.stats.QueueUtilization, sc.stats.NetworkEfficiency)
+	sc.stats.RustBufferAvailable, sc.stats.RustEstimatedWaitMs, sc.stats.RustAvgCostPerReq = sc.rustCost.snapshot()
+	sc.stats.CppBufferAvailable, sc.stats.CppEstimatedWaitMs, sc.stats.CppAvgCostPerReq = sc.cppCost.snapshot()
+
+	if sc.stats.ThroughputBPS > 0 {
+		logrus.Infof("📊 Performance: %.1f TPS, Queue: %.1f%%, Network: %.1f%%",
+			sc.stats.ThroughputBPS, sc.stats.QueueUtilization*100, sc.stats.NetworkEfficiency*100)
 	}
 }
 
-// calculateNetworkEfficiency calculates network efficiency
+// calculateNetworkEfficiency calculates network efficiency from the Rust
+// engine's self-reported cache hit ratio
 func (sc *SmartConcurrency) calculateNetworkEfficiency() float64 {
-	// Calculate cache hit rate from Rust bridge
-	cacheStats, err := sc.rustBridge.GetCacheStats()
+	engineStats, err := sc.rustBridge.GetEngineStats()
 	if err != nil {
-		return 0.5 // Default efficiency
-	}
-
-	cacheHitRate := cacheStats["cache_hit_ratio"].(float64)
-	return cacheHitRate
-}
-
-// taskDispatcher distributes tasks to workers based on priority
-func (sc *SmartConcurrency) taskDispatcher(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			sc.dispatchTasks(ctx)
-		case task := <-sc.priorityQueue:
-			sc.dispatchTask(ctx, task)
-		}
-	}
-}
-
-// dispatchTask dispatches a single task to the best available worker
-func (sc *SmartConcurrency) dispatchTask(ctx context.Context, task PrefetchTask) {
-	// Find best worker for this task
-	worker := sc.findBestWorker(task.Priority)
-	
-	if worker == nil {
-		logrus.Warn("⚠️ No available workers, re-queuing task")
-		// Re-queue the task
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			sc.priorityQueue <- task
-		}()
-		return
+		return 0.5 // Default efficiency when the engine can't be reached
 	}
 
-	// Dispatch to worker
-	select {
-	case worker.Channel <- task:
-		// Task dispatched successfully
-		logrus.Debugf("📤 Dispatched task %s to worker %d", task.ID, worker.ID)
-	default:
-		// Worker busy, try next worker
+	ratio, ok := engineStats["cache_hit_ratio"].(float64)
+	if !ok {
+		return 0.5
 	}
+	return ratio
 }
 
 // GetStats returns current concurrency statistics
 func (sc *SmartConcurrency) GetStats() *ConcurrencyStats {
 	sc.mu.RLock()
-	defer sc.mu.Unlock()
+	statsCopy := *sc.stats
+	sc.mu.RUnlock()
+
+	total, successful, failed := sc.counters.snapshot()
+	statsCopy.TotalTasks = int(total)
+	statsCopy.SuccessfulTasks = int(successful)
+	statsCopy.FailedTasks = int(failed)
 
-	return sc.stats
+	return &statsCopy
 }
 
 // Stop stops the smart concurrency manager
 func (sc *SmartConcurrency) Stop() {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
+	sc.stopOnce.Do(func() {
+		close(sc.stopChan)
+	})
 
-	// Stop all workers
+	sc.mu.Lock()
 	for _, worker := range sc.workers {
-		close(worker.QuitChan)
-	}
-	
-	// Stop task dispatcher
-	close(sc.priorityQueue)
-	close(sc.stopChan)
-	
-	// Clear stats
-	sc.stats = &ConcurrencyStats{}
-	
+		if worker.isActive {
+			close(worker.QuitChan)
+			worker.isActive = false
+		}
+	}
+	sc.mu.Unlock()
+
 	logrus.Info("🛑 Stopped Smart Concurrency Manager")
 }
 
 // GetWorkerStats returns statistics for all workers
 func (sc *SmartConcurrency) GetWorkerStats() []WorkerStats {
 	sc.mu.RLock()
-	defer sc.mu.Unlock()
+	workers := sc.workers
+	sc.mu.RUnlock()
+
+	stats := make([]WorkerStats, len(workers))
+	for i, worker := range workers {
+		processed, succeeded, failed, totalProcessTime, totalCPUTime, peakRSS, avgRSS, totalIORead, totalIOWrite, totalMajorFaults := worker.counters.snapshot()
+
+		var avgProcessTime time.Duration
+		if processed > 0 {
+			avgProcessTime = totalProcessTime / time.Duration(processed)
+		}
 
-	stats := make([]WorkerStats, len(sc.workers))
-	for i, worker := range sc.workers {
-		stats[i] = *worker.Stats
+		stats[i] = WorkerStats{
+			TasksProcessed:   int(processed),
+			TasksSucceeded:   int(succeeded),
+			TasksFailed:      int(failed),
+			AvgProcessTime:   avgProcessTime,
+			TotalProcessTime: totalProcessTime,
+			LastError:        worker.lastError,
+			LastActiveTime:   worker.lastActiveTime,
+			TotalCPUTime:     totalCPUTime,
+			AvgRSS:           avgRSS,
+			PeakRSS:          peakRSS,
+			TotalIORead:      totalIORead,
+			TotalIOWrite:     totalIOWrite,
+			TotalMajorFaults: totalMajorFaults,
+		}
 	}
 
 	return stats
@@ -572,16 +1224,13 @@ func (sc *SmartConcurrency) GetWorkerStats() []WorkerStats {
 // GetQueueStats returns queue statistics
 func (sc *SmartConcurrency) GetQueueStats() QueueStats {
 	sc.mu.RLock()
-	defer sc.mu.Unlock()
+	defer sc.mu.RUnlock()
 
+	size := len(sc.taskSubmit)
 	return QueueStats{
-		QueueSize:     len(sc.priorityQueue),
+		QueueSize:    size,
 		MaxQueueSize: sc.config.MaxQueueSize,
-		Utilization: float64(float64(len(sc.priorityQueue)) / float64(sc.config.MaxQueueSize),
-		WaitingTasks: 0,
-		ProcessingTasks: 0,
-		CompletedTasks: 0,
-		FailedTasks: 0,
+		Utilization:  float64(size) / float64(sc.config.MaxQueueSize),
 	}
 }
 
@@ -589,7 +1238,7 @@ func (sc *SmartConcurrency) GetQueueStats() QueueStats {
 func (sc *SmartConcurrency) SetAdaptiveScheduling(enabled bool) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config.EnableAdaptiveScheduling = enabled
 	logrus.Infof("🔧 Adaptive scheduling: %v", enabled)
 }
@@ -598,7 +1247,7 @@ func (sc *SmartConcurrency) SetAdaptiveScheduling(enabled bool) {
 func (sc *SmartConcurrency) SetPriorityBoosting(enabled bool) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config.EnablePriorityBoosting = enabled
 	logrus.Infof("🚀 Priority boosting: %v", enabled)
 }
@@ -606,7 +1255,7 @@ func (sc *SmartConcurrency) SetPriorityBoosting(enabled bool) {
 // GetConfig returns current configuration
 func (sc *SmartConcurrency) GetConfig() ConcurrencyConfig {
 	sc.mu.RLock()
-	defer sc.mu.Unlock()
+	defer sc.mu.RUnlock()
 	return sc.config
 }
 
@@ -614,94 +1263,110 @@ func (sc *SmartConcurrency) GetConfig() ConcurrencyConfig {
 func (sc *SmartConcurrency) UpdateConfig(config ConcurrencyConfig) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config = config
 	logrus.Infof("🔧 Updated concurrency config")
 }
 
-// AddTask adds a prefetching task to the queue
-func (sc *SmartConcurrency) AddTask(task PrefetchTask) error {
+// AddTask adds a prefetching task to the queue and returns its ID, which
+// WaitForTask takes to retrieve the result once the task finishes.
+func (sc *SmartConcurrency) AddTask(task PrefetchTask) (string, error) {
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	if task.ID == "" {
+		task.ID = fmt.Sprintf("task-%d", atomic.AddInt64(&sc.nextTaskID, 1))
+	}
+
+	resultCh := make(chan *TaskResult, 1)
+	sc.resultsMu.Lock()
+	sc.results[task.ID] = resultCh
+	sc.resultsMu.Unlock()
+
 	select {
-	case sc.priorityQueue <- task:
+	case sc.taskSubmit <- task:
 		logrus.Debugf("📦 Added task to queue: %s (priority: %d)", task.ID, task.Priority)
-		return nil
+		return task.ID, nil
 	case <-time.After(100 * time.Millisecond):
-		logrus.Warn("⚠️ Prefetch queue full, dropping task: %s", task.ID)
-		return fmt.Errorf("prefetch queue full")
+		sc.resultsMu.Lock()
+		delete(sc.results, task.ID)
+		sc.resultsMu.Unlock()
+		logrus.Warnf("⚠️ Prefetch queue full, dropping task: %s", task.ID)
+		return "", fmt.Errorf("prefetch queue full")
 	}
 }
 
 // AddTaskWithPriority adds a task with specific priority
-func (sc *SmartConcurrency) AddTaskWithPriority(task PrefetchTask, priority Priority) error {
+func (sc *SmartConcurrency) AddTaskWithPriority(task PrefetchTask, priority Priority) (string, error) {
 	task.Priority = priority
-	
-	select {
-	case sc.priorityQueue <- task:
-		logrus.Debugf("📦 Added task with priority %d: %s", task.ID, task.Priority)
-		return nil
-	case <-time.After(100 * time.Millisecond):
-		logrus.Warn("⚠️ Prefetch queue full, dropping task: %s", task.ID)
-		return fmt.Errorf("prefetch queue full")
-	}
+	return sc.AddTask(task)
 }
 
-// GetNextTask gets the next task from the priority queue
-func (sc *SmartConcurrency) GetNextTask() (*PrefetchTask, bool) {
-	select {
-	case task := <-sc.priorityQueue:
-		return task, true
-	default:
-		return nil, false
+// WaitForTask blocks until the task identified by id (as returned by
+// AddTask) finishes, then returns its TaskResult. The result is delivered
+// exactly once; calling WaitForTask again for the same id after it's
+// already returned gets a TaskResult whose Err reports an unknown task id,
+// since the id is forgotten once its result is delivered.
+func (sc *SmartConcurrency) WaitForTask(id string) TaskResult {
+	sc.resultsMu.Lock()
+	ch, ok := sc.results[id]
+	sc.resultsMu.Unlock()
+	if !ok {
+		return TaskResult{Err: fmt.Errorf("unknown task id %q", id)}
 	}
+
+	pooled := <-ch
+	result := *pooled
+	pooled.Release()
+
+	sc.resultsMu.Lock()
+	delete(sc.results, id)
+	sc.resultsMu.Unlock()
+
+	return result
 }
 
-// ClearQueue clears the prefetch queue
+// ClearQueue clears any tasks not yet picked up by the scheduler. Tasks
+// already assigned into an open window are unaffected.
 func (sc *SmartConcurrency) ClearQueue() {
-	// Clear existing queue
-	for len(sc.priorityQueue) > 0 {
-		<-sc.priorityQueue
+	for len(sc.taskSubmit) > 0 {
+		<-sc.taskSubmit
 	}
-	
+
 	logrus.Info("🗑️ Cleared prefetch queue")
 }
 
 // GetQueueLength returns current queue length
 func (sc *SmartConcurrency) GetQueueLength() int {
-	return len(sc.priorityQueue)
+	return len(sc.taskSubmit)
 }
 
 // IsQueueFull checks if the queue is full
 func (sc *SmartConcurrency) IsQueueFull() bool {
-	return len(sc.priorityQueue) >= sc.config.MaxQueueSize
+	return len(sc.taskSubmit) >= sc.config.MaxQueueSize
 }
 
 // SetMaxWorkers updates the maximum number of workers
 func (sc *SmartConcurrency) SetMaxWorkers(maxWorkers int) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
-	// Adjust worker pool
-	if maxWorkers > sc.config.MaxWorkers {
-		// Add new workers
+
+	if maxWorkers > len(sc.workers) {
 		for i := len(sc.workers); i < maxWorkers; i++ {
-			worker := &Worker{
-				ID:          i,
-				Channel:     make(chan PrefetchTask, 10),
-				QuitChan:     make(chan struct{}),
-				Stats:       &WorkerStats{},
-				isActive:    true,
-			}
+			worker := sc.newWorker(i)
 			sc.workers = append(sc.workers, worker)
 			go sc.startWorker(context.Background(), worker)
 		}
-	} else if maxWorkers < sc.config.MaxWorkers {
-		// Remove excess workers
+	} else if maxWorkers < len(sc.workers) {
 		for i := maxWorkers; i < len(sc.workers); i++ {
-			close(sc.workers[i].QuitChan)
-			sc.workers[i].isActive = false
+			if sc.workers[i].isActive {
+				close(sc.workers[i].QuitChan)
+				sc.workers[i].isActive = false
+			}
 		}
+		sc.workers = sc.workers[:maxWorkers]
 	}
-	
+
 	sc.config.MaxWorkers = maxWorkers
 	logrus.Infof("🔧 Updated max workers to %d", maxWorkers)
 }
@@ -709,9 +1374,9 @@ func (sc *SmartConcurrency) SetMaxWorkers(maxWorkers int) {
 // SetMaxQueueSize updates the maximum queue size
 func (sc *SmartConcurrency) SetMaxQueueSize(maxSize int) {
 	sc.mu.Lock()
-	defer sc.config.MaxQueueSize = maxSize
-	sc.mu.Unlock()
-	
+	defer sc.mu.Unlock()
+
+	sc.config.MaxQueueSize = maxSize
 	logrus.Infof("🔧 Updated max queue size to %d", maxSize)
 }
 
@@ -719,7 +1384,7 @@ func (sc *SmartConcurrency) SetMaxQueueSize(maxSize int) {
 func (sc *SmartConcurrency) SetWorkerTimeout(timeout time.Duration) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config.WorkerTimeout = timeout
 	logrus.Infof("🔧 Updated worker timeout to %v", timeout)
 }
@@ -728,7 +1393,7 @@ func (sc *SmartConcurrency) SetWorkerTimeout(timeout time.Duration) {
 func (sc *SmartConcurrency) SetRetryDelay(delay time.Duration) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config.RetryDelay = delay
 	logrus.Infof("🔧 Updated retry delay to %v", delay)
 }
@@ -737,17 +1402,13 @@ func (sc *SmartConcurrency) SetRetryDelay(delay time.Duration) {
 func (sc *SmartConcurrency) SetNetworkMultiplier(multiplier float64) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	
+
 	sc.config.NetworkMultiplier = multiplier
 	logrus.Infof("🔧 Updated network multiplier to %.1f", multiplier)
 }
 
 // adaptiveScheduler adapts scheduling based on performance
 func (sc *SmartConcurrency) adaptiveScheduler(ctx context.Context) {
-	if !sc.config.EnableAdaptiveScheduling {
-		return
-	}
-
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -763,65 +1424,78 @@ func (sc *SmartConcurrency) adaptiveScheduler(ctx context.Context) {
 
 // adaptScheduling adapts scheduling based on performance metrics
 func (sc *SmartConcurrency) adaptScheduling() {
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-
-	// Get current metrics
-	stats := sc.stats
+	stats := sc.GetStats()
 	queueStats := sc.GetQueueStats()
-	workerStats := sc.GetWorkerStats()
 
-	// Calculate performance score
-	performanceScore := sc.calculatePerformanceScore(stats, queueStats, workerStats)
+	performanceScore := sc.calculatePerformanceScore(stats, queueStats)
 
-	// Adjust worker pool size based on performance
-		if performanceScore > 0.8 && sc.config.MaxWorkers < 20 {
-		sc.SetMaxWorkers(sc.config.MaxWorkers + 2)
-	} else if performanceScore < 0.5 && sc.config.MaxWorkers > 5 {
-		sc.SetMaxWorkers(sc.config.MaxWorkers - 1)
+	sc.mu.RLock()
+	maxWorkers := sc.config.MaxWorkers
+	maxQueueSize := sc.config.MaxQueueSize
+	networkMultiplier := sc.config.NetworkMultiplier
+	sc.mu.RUnlock()
+
+	if performanceScore > 0.8 && maxWorkers < 20 {
+		sc.SetMaxWorkers(maxWorkers + 2)
+	} else if performanceScore < 0.5 && maxWorkers > 5 {
+		sc.SetMaxWorkers(maxWorkers - 1)
 	}
 
-	// Adjust queue size based on queue utilization
-		if queueStats.Utilization > 0.8 && sc.config.MaxQueueSize < 200 {
-		sc.SetMaxQueueSize(sc.config.MaxQueueSize + 20)
-	} else if queueStats.Utilization < 0.3 && sc.config.MaxQueueSize > 50 {
-		sc.SetMaxQueueSize(sc.config.MaxQueueSize - 10)
+	if queueStats.Utilization > 0.8 && maxQueueSize < 200 {
+		sc.SetMaxQueueSize(maxQueueSize + 20)
+	} else if queueStats.Utilization < 0.3 && maxQueueSize > 50 {
+		sc.SetMaxQueueSize(maxQueueSize - 10)
 	}
 
-	// Adjust network multiplier based on network efficiency
-		networkEfficiency := sc.calculateNetworkEfficiency()
-		if networkEfficiency > 0.8 && sc.config.NetworkMultiplier < 2.0 {
-		sc.SetNetworkMultiplier(sc.config.NetworkMultiplier + 0.5)
-	} else if networkEfficiency < 0.5 && sc.config.NetworkMultiplier > 1.0 {
-		sc.SetNetworkMultiplier(sc.config.NetworkMultiplier - 0.5)
+	networkEfficiency := sc.calculateNetworkEfficiency()
+	if networkEfficiency > 0.8 && networkMultiplier < 2.0 {
+		sc.SetNetworkMultiplier(networkMultiplier + 0.5)
+	} else if networkEfficiency < 0.5 && networkMultiplier > 1.0 {
+		sc.SetNetworkMultiplier(networkMultiplier - 0.5)
 	}
 
-	logrus.Debugf("🔧 Adaptive scheduling: score=%.2f, workers=%d, queue=%d, multiplier=%.1f", 
-		performanceScore, sc.config.MaxWorkers, queueStats.Len(), sc.config.NetworkMultiplier)
+	logrus.Debugf("🔧 Adaptive scheduling: score=%.2f, workers=%d, queue=%d, multiplier=%.1f",
+		performanceScore, sc.config.MaxWorkers, queueStats.QueueSize, sc.config.NetworkMultiplier)
+}
+
+// calculatePerformanceScore calculates an overall performance score from
+// throughput, queue headroom, and worker health.
+func (sc *SmartConcurrency) calculatePerformanceScore(stats *ConcurrencyStats, queueStats QueueStats) float64 {
+	throughputScore := stats.ThroughputBPS / 1000.0
+	queueScore := (1.0 - queueStats.Utilization) * 0.3
+	workerScore := sc.averageWorkerScore()
+
+	return (throughputScore * 0.5) + (queueScore * 0.3) + (workerScore * 0.2)
 }
 
-// calculatePerformanceScore calculates overall performance score
-func (sc *SmartConcurrency) calculatePerformanceStats(stats *ConcurrencyStats, queueStats QueueStats, workerStats []WorkerStats) float64 {
-	// Weighted performance score
-	throughputputScore := stats.ThroughputBPS / 1000.0
-		queueScore := (1.0 - queueStats.Utilization) * 0.3
-		workerScore := sc.calculateWorkerScore(workerStats)
-		
-		// Calculate weighted score
-		return (throughputScore * 0.5) + (queueScore * 0.3) + (workerScore * 0.2)
+// averageWorkerScore averages calculateWorkerScore across all workers.
+func (sc *SmartConcurrency) averageWorkerScore() float64 {
+	workerStats := sc.GetWorkerStats()
+	if len(workerStats) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, ws := range workerStats {
+		total += calculateWorkerScore(ws)
+	}
+	return total / float64(len(workerStats))
 }
 
-// calculateWorkerScore calculates performance score for a worker
-func (sc *SmartConcurrency) calculateWorkerStats(workerStats WorkerStats) float64 {
-	// Calculate worker efficiency based on success rate and speed
-		successRate := float64(workerStats.TasksSucceeded) / float64(workerStats.TasksProcessed)
-		avgProcessTime := workerStats.AvgProcessTime.Seconds()
-		
-		// Higher score for faster workers
-		if avgProcessTime > 0 {
-			return (1.0 / avgProcessTime) * 0.8
-		}
-		
-		return successRate * 0.7
+// calculateWorkerScore scores a single worker's efficiency. When an
+// average processing time is recorded the score comes from that alone
+// (faster workers score higher); otherwise it comes from the success rate.
+func calculateWorkerScore(workerStats WorkerStats) float64 {
+	if workerStats.TasksProcessed == 0 {
+		return 0
 	}
+
+	successRate := float64(workerStats.TasksSucceeded) / float64(workerStats.TasksProcessed)
+	avgProcessTime := workerStats.AvgProcessTime.Seconds()
+
+	if avgProcessTime > 0 {
+		return (1.0 / avgProcessTime) * 0.8
+	}
+
+	return successRate * 0.7
 }
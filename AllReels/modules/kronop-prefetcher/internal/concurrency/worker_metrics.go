@@ -0,0 +1,92 @@
+package concurrency
+
+import (
+	"time"
+
+	"github.com/kronop/prefetcher/internal/cgroup"
+)
+
+// recordWorkerResult folds one task's outcome and cgroup-sampled
+// cgroup.TaskMetrics into its WorkerStats entry, creating the entry on that
+// worker's first completed task. The running-mean AvgRSS update mirrors
+// workerCounters.recordTask's (see counters.go), just under cm.mu
+// instead of via atomics, since this manager's much lower throughput
+// doesn't need a lock-free hot path.
+func (cm *ChannelManager) recordWorkerResult(workerID int, processTime time.Duration, metrics cgroup.TaskMetrics, success bool, taskErr error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	stats, ok := cm.workers[workerID]
+	if !ok {
+		stats = &WorkerStats{}
+		cm.workers[workerID] = stats
+	}
+
+	stats.TasksProcessed++
+	stats.TotalProcessTime += processTime
+	stats.AvgProcessTime = stats.TotalProcessTime / time.Duration(stats.TasksProcessed)
+	if success {
+		stats.TasksSucceeded++
+	} else {
+		stats.TasksFailed++
+		if taskErr != nil {
+			stats.LastError = taskErr.Error()
+		}
+	}
+	stats.LastActiveTime = time.Now()
+
+	stats.TotalCPUTime += metrics.CPUTime
+	if metrics.MemPeak > stats.PeakRSS {
+		stats.PeakRSS = metrics.MemPeak
+	}
+	if metrics.MemPeak >= stats.AvgRSS {
+		stats.AvgRSS += (metrics.MemPeak - stats.AvgRSS) / uint64(stats.TasksProcessed)
+	} else {
+		stats.AvgRSS -= (stats.AvgRSS - metrics.MemPeak) / uint64(stats.TasksProcessed)
+	}
+	stats.TotalIORead += metrics.IORead
+	stats.TotalIOWrite += metrics.IOWrite
+	stats.TotalMajorFaults += metrics.MajorFaults
+}
+
+// GetWorkerStats returns each worker's accumulated WorkerStats, keyed by
+// WorkerID in cm.workers (see recordWorkerResult) rather than indexed by
+// a worker-pool slice this manager never actually constructs.
+func (cm *ChannelManager) GetWorkerStats() map[int]WorkerStats {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	stats := make(map[int]WorkerStats, len(cm.workers))
+	for id, s := range cm.workers {
+		stats[id] = *s
+	}
+	return stats
+}
+
+// recordRuntimeMetrics appends metrics to recentMetrics, the rolling
+// window GetChannelStats summarizes, evicting the oldest sample once
+// maxRuntimeMetricsSamples is reached.
+func (cm *ChannelManager) recordRuntimeMetrics(metrics cgroup.TaskMetrics) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.recentMetrics = append(cm.recentMetrics, metrics)
+	if len(cm.recentMetrics) > maxRuntimeMetricsSamples {
+		cm.recentMetrics = cm.recentMetrics[len(cm.recentMetrics)-maxRuntimeMetricsSamples:]
+	}
+}
+
+// runtimeWindowSummary aggregates recentMetrics into the rolling-window
+// totals/peak GetChannelStats reports. Callers must hold cm.mu.
+func (cm *ChannelManager) runtimeWindowSummary() (samples int, totalCPUTime time.Duration, peakMem uint64, totalIORead, totalIOWrite, totalMajorFaults uint64) {
+	for _, m := range cm.recentMetrics {
+		totalCPUTime += m.CPUTime
+		if m.MemPeak > peakMem {
+			peakMem = m.MemPeak
+		}
+		totalIORead += m.IORead
+		totalIOWrite += m.IOWrite
+		totalMajorFaults += m.MajorFaults
+	}
+	return len(cm.recentMetrics), totalCPUTime, peakMem, totalIORead, totalIOWrite, totalMajorFaults
+}
@@ -0,0 +1,206 @@
+// Package grpcstream implements enginetransport.Transport over a single
+// persistent gRPC bidirectional stream. It's the default backend: works
+// over a network, and doesn't require the engine to be co-located with
+// Kronop the way unixsock and shmring do.
+package grpcstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// Transport talks to the Rust engine over a persistent gRPC stream (see
+// stream.go). addr is a gRPC dial target (host:port), not an HTTP URL.
+type Transport struct {
+	addr string
+	mu   sync.RWMutex
+
+	connected bool
+	stream    *stream
+}
+
+// New creates a Transport for the given gRPC dial target. Call Connect
+// before using it.
+func New(addr string) *Transport {
+	return &Transport{addr: addr}
+}
+
+// Connect opens the persistent gRPC stream and confirms it's responsive
+// with a get_stats round trip before reporting connected.
+func (t *Transport) Connect() error {
+	logrus.Infof("🔗 Connecting to Rust engine at %s", t.addr)
+
+	s, err := dial(t.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Rust engine: %v", err)
+	}
+
+	t.mu.Lock()
+	t.stream = s
+	t.connected = true
+	t.mu.Unlock()
+
+	if _, err := t.GetEngineStats(); err != nil {
+		t.Disconnect()
+		return fmt.Errorf("Rust engine health check failed: %v", err)
+	}
+
+	logrus.Info("✅ Connected to Rust engine successfully")
+	return nil
+}
+
+// Disconnect closes the stream and connection to the Rust engine.
+func (t *Transport) Disconnect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return nil
+	}
+
+	var err error
+	if t.stream != nil {
+		err = t.stream.Close()
+		t.stream = nil
+	}
+	t.connected = false
+	logrus.Info("🔌 Disconnected from Rust engine")
+	return err
+}
+
+// IsConnected reports whether the stream is currently up.
+func (t *Transport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.connected
+}
+
+// HealthCheck asks the engine for stats and confirms it reports running.
+func (t *Transport) HealthCheck() error {
+	stats, err := t.GetEngineStats()
+	if err != nil {
+		return fmt.Errorf("health check failed: %v", err)
+	}
+	if running, ok := stats["is_running"].(bool); !ok || !running {
+		return fmt.Errorf("Rust engine is not running")
+	}
+	return nil
+}
+
+// RequestChunk requests a video chunk from the Rust engine.
+func (t *Transport) RequestChunk(reelID int, chunkID string) (*enginetransport.VideoChunk, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_chunk",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request chunk: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("Rust engine error: %s", response.Error)
+	}
+
+	var chunk enginetransport.VideoChunk
+	if err := json.Unmarshal(response.Data, &chunk); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk: %v", err)
+	}
+	return &chunk, nil
+}
+
+// PrefetchChunk asks the Rust engine to prefetch a chunk.
+func (t *Transport) PrefetchChunk(reelID int, chunkID string) error {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "prefetch_chunk",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prefetch chunk: %v", err)
+	}
+	if response.Status != "success" {
+		return fmt.Errorf("prefetch failed: %s", response.Error)
+	}
+	return nil
+}
+
+// IsChunkReady checks if a chunk is ready for playback.
+func (t *Transport) IsChunkReady(reelID int, chunkID string) (bool, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "is_ready",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunk readiness: %v", err)
+	}
+	if response.Status != "success" {
+		return false, fmt.Errorf("readiness check failed: %s", response.Error)
+	}
+
+	var ready struct {
+		Ready bool `json:"ready"`
+	}
+	if err := json.Unmarshal(response.Data, &ready); err != nil {
+		return false, fmt.Errorf("failed to parse readiness response: %v", err)
+	}
+	return ready.Ready, nil
+}
+
+// GetCurrentFrame gets the current frame from the Rust engine.
+func (t *Transport) GetCurrentFrame(reelID int) ([]byte, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_current_frame",
+		ReelID:    reelID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current frame: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("failed to get frame: %s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// GetEngineStats gets statistics from the Rust engine.
+func (t *Transport) GetEngineStats() (map[string]interface{}, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_stats",
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("stats request failed: %s", response.Error)
+	}
+
+	var stats map[string]interface{}
+	if err := json.Unmarshal(response.Data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse stats: %v", err)
+	}
+	return stats, nil
+}
+
+// call sends req over the persistent stream and waits for its correlated
+// response.
+func (t *Transport) call(req enginetransport.EngineRequest) (*enginetransport.EngineResponse, error) {
+	t.mu.RLock()
+	s := t.stream
+	t.mu.RUnlock()
+
+	if s == nil {
+		return nil, fmt.Errorf("not connected to Rust engine")
+	}
+	return s.call(req)
+}
@@ -0,0 +1,146 @@
+package grpcstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// streamMethod is the fully-qualified gRPC method name for the Rust
+// engine's bidirectional request/response stream. There's no .proto for it
+// (see codec.go) so this is just the string the wire protocol expects.
+const streamMethod = "/kronop.rustengine.Bridge/Stream"
+
+// maxInFlight bounds how many requests can be outstanding on the stream at
+// once. call() blocks once this many are unanswered, which is the
+// backpressure: a slow engine throttles new callers instead of piling up
+// an unbounded backlog of pending replies.
+const maxInFlight = 64
+
+// stream owns the single persistent bidirectional gRPC stream used for all
+// traffic. Requests can be sent from multiple goroutines and responses can
+// arrive out of order, so every request carries a RequestID that readLoop
+// uses to route its reply back to the right caller.
+type stream struct {
+	conn   *grpc.ClientConn
+	client grpc.ClientStream
+
+	sendMu sync.Mutex // serializes stream.SendMsg across caller goroutines
+
+	nextID   int64
+	inFlight chan struct{}
+
+	pendingMu sync.Mutex
+	pending   map[int64]chan *enginetransport.EngineResponse
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// dial connects to addr and opens the persistent bidi stream, starting the
+// reader goroutine that fans responses back out to callers.
+func dial(addr string) (*stream, error) {
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsonCodecName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Rust engine: %v", err)
+	}
+
+	client, err := conn.NewStream(context.Background(), &grpc.StreamDesc{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		ClientStreams: true,
+	}, streamMethod)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open Rust engine stream: %v", err)
+	}
+
+	s := &stream{
+		conn:     conn,
+		client:   client,
+		inFlight: make(chan struct{}, maxInFlight),
+		pending:  make(map[int64]chan *enginetransport.EngineResponse),
+		closed:   make(chan struct{}),
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+// call sends req over the shared stream and blocks until its matching
+// response arrives, or the stream closes.
+func (s *stream) call(req enginetransport.EngineRequest) (*enginetransport.EngineResponse, error) {
+	select {
+	case s.inFlight <- struct{}{}:
+	case <-s.closed:
+		return nil, fmt.Errorf("Rust engine stream closed")
+	}
+	defer func() { <-s.inFlight }()
+
+	req.RequestID = atomic.AddInt64(&s.nextID, 1)
+
+	reply := make(chan *enginetransport.EngineResponse, 1)
+	s.pendingMu.Lock()
+	s.pending[req.RequestID] = reply
+	s.pendingMu.Unlock()
+	defer func() {
+		s.pendingMu.Lock()
+		delete(s.pending, req.RequestID)
+		s.pendingMu.Unlock()
+	}()
+
+	s.sendMu.Lock()
+	err := s.client.SendMsg(&req)
+	s.sendMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	select {
+	case resp := <-reply:
+		return resp, nil
+	case <-s.closed:
+		return nil, fmt.Errorf("Rust engine stream closed")
+	}
+}
+
+// readLoop is the stream's single reader goroutine, dispatching each
+// response to the pending call it correlates with via RequestID. It exits
+// (closing the stream) as soon as RecvMsg fails, which is also how a
+// disconnect gets noticed by every caller blocked in call().
+func (s *stream) readLoop() {
+	defer s.Close()
+	for {
+		var resp enginetransport.EngineResponse
+		if err := s.client.RecvMsg(&resp); err != nil {
+			logrus.Warnf("⚠️ Rust engine stream read failed: %v", err)
+			return
+		}
+
+		s.pendingMu.Lock()
+		reply, ok := s.pending[resp.RequestID]
+		s.pendingMu.Unlock()
+		if !ok {
+			logrus.Warnf("⚠️ Rust engine response for unknown request %d", resp.RequestID)
+			continue
+		}
+		reply <- &resp
+	}
+}
+
+// Close tears down the stream and connection, unblocking any in-flight calls.
+func (s *stream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return s.conn.Close()
+}
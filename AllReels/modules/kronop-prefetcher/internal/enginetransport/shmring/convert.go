@@ -0,0 +1,28 @@
+package shmring
+
+import (
+	"encoding/json"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// decodeVideoChunk and decodeStatsMap mirror the other backends: the ring
+// payload carries JSON-encoded chunk/stats data, same as grpcstream and
+// unixsock, so only the envelope (here: fixed-size shm headers instead of
+// a stream or socket frame) actually differs between transports.
+
+func decodeVideoChunk(data []byte) (*enginetransport.VideoChunk, error) {
+	var chunk enginetransport.VideoChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+func decodeStatsMap(data []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,24 @@
+// Package shmring implements enginetransport.Transport over a pair of
+// POSIX-shm ring buffers (request ring and response ring) for zero-copy
+// chunk transfer when the Rust engine runs on the same host. It mirrors
+// the bridge package's zero-copy frame-push ring, just turned into a
+// request/response pair instead of a one-way push.
+package shmring
+
+import "fmt"
+
+// ring is the platform interface implemented by shmring_linux.go; on other
+// platforms shmring_other.go's openRing stub returns errUnsupported, which
+// Transport.Connect passes on, so callers get an explicit "not supported
+// here" instead of a nil-pointer panic.
+type ring interface {
+	requestChunk(reelID int, chunkID string) ([]byte, error)
+	prefetchChunk(reelID int, chunkID string) error
+	isChunkReady(reelID int, chunkID string) (bool, error)
+	currentFrame(reelID int) ([]byte, error)
+	engineStats() (map[string]interface{}, error)
+	healthCheck() error
+	close() error
+}
+
+var errUnsupported = fmt.Errorf("shared-memory ring transport is only supported on linux")
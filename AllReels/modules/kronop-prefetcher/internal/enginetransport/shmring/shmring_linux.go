@@ -0,0 +1,225 @@
+//go:build linux
+
+package shmring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ringSlots    = 8
+	maxPayload   = 1 << 20 // 1MB per request/response payload
+	typeFieldLen = 16
+	chunkIDLen   = 64
+
+	reqHeaderSize  = 8 + typeFieldLen + 8 + chunkIDLen + 8 // seq + type + reelID + chunkID + timestamp
+	respHeaderSize = 8 + 1 + 4                             // seq + status + data length
+)
+
+// linuxRing is the Linux shm implementation of the ring interface: a
+// request ring the Go side writes into, and an eventfd-signaled response
+// ring the Rust engine writes back into. Calls are serialized one at a
+// time (callMu) — this path exists for same-host zero-copy speed, not
+// concurrency, so trading that off for a much simpler single-slot-in-flight
+// protocol is the right call here.
+type linuxRing struct {
+	callMu sync.Mutex
+	seq    uint64
+
+	reqFD, reqEventFD   int
+	reqMem              []byte
+	respFD, respEventFD int
+	respMem             []byte
+}
+
+// openRing attaches to (creating if needed) the named request/response shm
+// region pair.
+func openRing(name string) (ring, error) {
+	reqFD, reqMem, reqEventFD, err := openRegion("/dev/shm/"+name+".req", reqHeaderSize+maxPayload, ringSlots)
+	if err != nil {
+		return nil, err
+	}
+	respFD, respMem, respEventFD, err := openRegion("/dev/shm/"+name+".resp", respHeaderSize+maxPayload, ringSlots)
+	if err != nil {
+		unix.Munmap(reqMem)
+		unix.Close(reqFD)
+		unix.Close(reqEventFD)
+		return nil, err
+	}
+
+	return &linuxRing{
+		reqFD: reqFD, reqMem: reqMem, reqEventFD: reqEventFD,
+		respFD: respFD, respMem: respMem, respEventFD: respEventFD,
+	}, nil
+}
+
+func openRegion(path string, slotSize, numSlots int) (fd int, mem []byte, eventFD int, err error) {
+	fd, err = unix.Open(path, unix.O_CREAT|unix.O_RDWR, 0600)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("shm_open %s failed: %v", path, err)
+	}
+
+	total := slotSize * numSlots
+	if err = unix.Ftruncate(fd, int64(total)); err != nil {
+		unix.Close(fd)
+		return 0, nil, 0, fmt.Errorf("failed to size %s: %v", path, err)
+	}
+
+	mem, err = unix.Mmap(fd, 0, total, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		unix.Close(fd)
+		return 0, nil, 0, fmt.Errorf("mmap %s failed: %v", path, err)
+	}
+
+	eventFD, err = unix.Eventfd(0, unix.EFD_CLOEXEC)
+	if err != nil {
+		unix.Munmap(mem)
+		unix.Close(fd)
+		return 0, nil, 0, fmt.Errorf("eventfd for %s failed: %v", path, err)
+	}
+
+	return fd, mem, eventFD, nil
+}
+
+// call writes a request into the next request slot, signals the engine,
+// and blocks (with a timeout) for the matching response slot to be
+// signaled back.
+func (r *linuxRing) call(reqType string, reelID int, chunkID string, timestamp int64) (status byte, data []byte, err error) {
+	r.callMu.Lock()
+	defer r.callMu.Unlock()
+
+	seq := r.seq + 1
+	r.seq = seq
+	slot := int(seq % ringSlots)
+
+	offset := slot * (reqHeaderSize + maxPayload)
+	buf := r.reqMem[offset : offset+reqHeaderSize]
+	binary.BigEndian.PutUint64(buf[0:8], seq)
+	copy(buf[8:8+typeFieldLen], []byte(reqType))
+	binary.BigEndian.PutUint64(buf[8+typeFieldLen:16+typeFieldLen], uint64(reelID))
+	copy(buf[16+typeFieldLen:16+typeFieldLen+chunkIDLen], []byte(chunkID))
+	binary.BigEndian.PutUint64(buf[16+typeFieldLen+chunkIDLen:], uint64(timestamp))
+
+	if err := signalEvent(r.reqEventFD); err != nil {
+		return 0, nil, err
+	}
+	if err := waitEvent(r.respEventFD, 5*time.Second); err != nil {
+		return 0, nil, err
+	}
+
+	respOffset := slot * (respHeaderSize + maxPayload)
+	hdr := r.respMem[respOffset : respOffset+respHeaderSize]
+	respSeq := binary.BigEndian.Uint64(hdr[0:8])
+	if respSeq != seq {
+		return 0, nil, fmt.Errorf("shm ring: response seq %d does not match request seq %d", respSeq, seq)
+	}
+
+	status = hdr[8]
+	dataLen := binary.BigEndian.Uint32(hdr[9:13])
+	data = make([]byte, dataLen)
+	copy(data, r.respMem[respOffset+respHeaderSize:respOffset+respHeaderSize+int(dataLen)])
+	return status, data, nil
+}
+
+func signalEvent(eventFD int) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], 1)
+	_, err := unix.Write(eventFD, buf[:])
+	return err
+}
+
+// waitEvent blocks until eventFD is signaled or timeout elapses.
+func waitEvent(eventFD int, timeout time.Duration) error {
+	pfd := []unix.PollFd{{Fd: int32(eventFD), Events: unix.POLLIN}}
+	n, err := unix.Poll(pfd, int(timeout/time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("timed out waiting for shm ring response")
+	}
+	var buf [8]byte
+	_, err = unix.Read(eventFD, buf[:])
+	return err
+}
+
+func (r *linuxRing) requestChunk(reelID int, chunkID string) ([]byte, error) {
+	status, data, err := r.call("get_chunk", reelID, chunkID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if status != 0 {
+		return nil, fmt.Errorf("Rust engine error: %s", string(data))
+	}
+	return data, nil
+}
+
+func (r *linuxRing) prefetchChunk(reelID int, chunkID string) error {
+	status, data, err := r.call("prefetch_chunk", reelID, chunkID, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return fmt.Errorf("prefetch failed: %s", string(data))
+	}
+	return nil
+}
+
+func (r *linuxRing) isChunkReady(reelID int, chunkID string) (bool, error) {
+	status, data, err := r.call("is_ready", reelID, chunkID, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	if status != 0 {
+		return false, fmt.Errorf("readiness check failed: %s", string(data))
+	}
+	return len(data) > 0 && data[0] == 1, nil
+}
+
+func (r *linuxRing) currentFrame(reelID int) ([]byte, error) {
+	status, data, err := r.call("get_current_frame", reelID, "", time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if status != 0 {
+		return nil, fmt.Errorf("failed to get frame: %s", string(data))
+	}
+	return data, nil
+}
+
+func (r *linuxRing) engineStats() (map[string]interface{}, error) {
+	status, data, err := r.call("get_stats", 0, "", time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if status != 0 {
+		return nil, fmt.Errorf("stats request failed: %s", string(data))
+	}
+	return decodeStatsMap(data)
+}
+
+func (r *linuxRing) healthCheck() error {
+	stats, err := r.engineStats()
+	if err != nil {
+		return fmt.Errorf("health check failed: %v", err)
+	}
+	if running, ok := stats["is_running"].(bool); !ok || !running {
+		return fmt.Errorf("Rust engine is not running")
+	}
+	return nil
+}
+
+func (r *linuxRing) close() error {
+	unix.Munmap(r.reqMem)
+	unix.Close(r.reqFD)
+	unix.Close(r.reqEventFD)
+	unix.Munmap(r.respMem)
+	unix.Close(r.respFD)
+	unix.Close(r.respEventFD)
+	return nil
+}
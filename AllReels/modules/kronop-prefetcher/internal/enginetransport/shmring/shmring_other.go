@@ -0,0 +1,7 @@
+//go:build !linux
+
+package shmring
+
+func openRing(name string) (ring, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,159 @@
+package shmring
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// Transport talks to a co-located Rust engine over a pair of shared-memory
+// rings instead of a socket, to avoid copying chunk/frame bytes through
+// the kernel. name identifies the shm region pair (request/response) both
+// processes attach to, e.g. "kronop-rust-engine".
+type Transport struct {
+	name string
+	mu   sync.RWMutex
+
+	connected bool
+	r         ring
+}
+
+// New creates a Transport for the given shm region name. Call Connect
+// before using it.
+func New(name string) *Transport {
+	return &Transport{name: name}
+}
+
+// Connect attaches to (creating if needed) the request/response ring pair
+// and confirms the engine is responsive with a get_stats round trip.
+func (t *Transport) Connect() error {
+	logrus.Infof("🔗 Attaching to Rust engine shm ring %q", t.name)
+
+	r, err := openRing(t.name)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Rust engine: %v", err)
+	}
+
+	t.mu.Lock()
+	t.r = r
+	t.connected = true
+	t.mu.Unlock()
+
+	if _, err := t.GetEngineStats(); err != nil {
+		t.Disconnect()
+		return fmt.Errorf("Rust engine health check failed: %v", err)
+	}
+
+	logrus.Info("✅ Connected to Rust engine successfully")
+	return nil
+}
+
+// Disconnect detaches from the shm ring pair.
+func (t *Transport) Disconnect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return nil
+	}
+
+	err := t.r.close()
+	t.r = nil
+	t.connected = false
+	logrus.Info("🔌 Disconnected from Rust engine")
+	return err
+}
+
+// IsConnected reports whether the ring pair is currently attached.
+func (t *Transport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.connected
+}
+
+// HealthCheck delegates to the ring's own health check slot.
+func (t *Transport) HealthCheck() error {
+	r, err := t.activeRing()
+	if err != nil {
+		return err
+	}
+	return r.healthCheck()
+}
+
+// RequestChunk requests a video chunk from the Rust engine.
+func (t *Transport) RequestChunk(reelID int, chunkID string) (*enginetransport.VideoChunk, error) {
+	r, err := t.activeRing()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.requestChunk(reelID, chunkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request chunk: %v", err)
+	}
+
+	return decodeVideoChunk(data)
+}
+
+// PrefetchChunk asks the Rust engine to prefetch a chunk.
+func (t *Transport) PrefetchChunk(reelID int, chunkID string) error {
+	r, err := t.activeRing()
+	if err != nil {
+		return err
+	}
+	if err := r.prefetchChunk(reelID, chunkID); err != nil {
+		return fmt.Errorf("failed to prefetch chunk: %v", err)
+	}
+	return nil
+}
+
+// IsChunkReady checks if a chunk is ready for playback.
+func (t *Transport) IsChunkReady(reelID int, chunkID string) (bool, error) {
+	r, err := t.activeRing()
+	if err != nil {
+		return false, err
+	}
+	ready, err := r.isChunkReady(reelID, chunkID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunk readiness: %v", err)
+	}
+	return ready, nil
+}
+
+// GetCurrentFrame gets the current frame from the Rust engine.
+func (t *Transport) GetCurrentFrame(reelID int) ([]byte, error) {
+	r, err := t.activeRing()
+	if err != nil {
+		return nil, err
+	}
+	frame, err := r.currentFrame(reelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current frame: %v", err)
+	}
+	return frame, nil
+}
+
+// GetEngineStats gets statistics from the Rust engine.
+func (t *Transport) GetEngineStats() (map[string]interface{}, error) {
+	r, err := t.activeRing()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := r.engineStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %v", err)
+	}
+	return stats, nil
+}
+
+func (t *Transport) activeRing() (ring, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.r == nil {
+		return nil, fmt.Errorf("not connected to Rust engine")
+	}
+	return t.r, nil
+}
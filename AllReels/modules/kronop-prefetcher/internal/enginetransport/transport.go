@@ -0,0 +1,60 @@
+// Package enginetransport defines the pluggable wire-level connection
+// RustBridge uses to talk to the Rust video engine, plus the request/
+// response types every backend exchanges. Swapping how bytes actually move
+// (a gRPC stream, a co-located Unix socket, a shared-memory ring) is a
+// config choice handled by the grpcstream, unixsock, and shmring
+// sub-packages, not a change to RustBridge itself.
+package enginetransport
+
+// EngineRequest is a request to the Rust engine, carried verbatim by every
+// Transport backend.
+type EngineRequest struct {
+	Type      string `json:"type"`
+	ReelID    int    `json:"reel_id"`
+	ChunkID   string `json:"chunk_id"`
+	Data      []byte `json:"data,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	// RequestID correlates a response to the call that sent it, since
+	// replies on a shared stream/socket/ring can arrive out of order.
+	RequestID int64 `json:"request_id"`
+}
+
+// EngineResponse is the Rust engine's reply to an EngineRequest.
+type EngineResponse struct {
+	Status    string `json:"status"`
+	Data      []byte `json:"data,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	ReelID    int    `json:"reel_id"`
+	Ready     bool   `json:"ready"`
+	RequestID int64  `json:"request_id"`
+}
+
+// VideoChunk represents a video chunk served by the Rust engine.
+type VideoChunk struct {
+	ID         string `json:"id"`
+	ReelID     int    `json:"reel_id"`
+	Data       []byte `json:"data"`
+	Size       int    `json:"size"`
+	Timestamp  int64  `json:"timestamp"`
+	IsKeyFrame bool   `json:"is_key_frame"`
+	Sequence   int    `json:"sequence"`
+	Compressed bool   `json:"compressed"`
+}
+
+// Transport is the pluggable wire-level connection to the Rust video
+// engine. RustBridge holds one and delegates every engine call to it; the
+// WAL and reconnect-monitoring logic live a layer above and work the same
+// regardless of which Transport is active.
+type Transport interface {
+	Connect() error
+	Disconnect() error
+	IsConnected() bool
+	HealthCheck() error
+
+	RequestChunk(reelID int, chunkID string) (*VideoChunk, error)
+	PrefetchChunk(reelID int, chunkID string) error
+	IsChunkReady(reelID int, chunkID string) (bool, error)
+	GetCurrentFrame(reelID int) ([]byte, error)
+	GetEngineStats() (map[string]interface{}, error)
+}
@@ -0,0 +1,80 @@
+package unixsock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// encodeRequest serializes req as a MessagePack map. The request/response
+// envelope goes over the wire as MessagePack (that's the whole point of
+// this transport), but Data itself stays JSON-encoded, same as the other
+// backends, so RequestChunk/IsChunkReady/GetEngineStats can share their
+// JSON-unmarshal logic regardless of which Transport fetched the bytes.
+func encodeRequest(req enginetransport.EngineRequest) ([]byte, error) {
+	m := map[string]interface{}{
+		"type":       req.Type,
+		"reel_id":    req.ReelID,
+		"chunk_id":   req.ChunkID,
+		"timestamp":  req.Timestamp,
+		"request_id": req.RequestID,
+	}
+	if req.Data != nil {
+		m["data"] = req.Data
+	}
+
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeResponse parses a MessagePack-encoded envelope back into an
+// EngineResponse.
+func decodeResponse(payload []byte) (enginetransport.EngineResponse, error) {
+	v, err := decodeValue(bytes.NewReader(payload))
+	if err != nil {
+		return enginetransport.EngineResponse{}, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return enginetransport.EngineResponse{}, fmt.Errorf("msgpack: response envelope is %T, want map", v)
+	}
+
+	var resp enginetransport.EngineResponse
+	resp.Status, _ = m["status"].(string)
+	resp.Error, _ = m["error"].(string)
+	resp.Ready, _ = m["ready"].(bool)
+	if reelID, ok := m["reel_id"].(int64); ok {
+		resp.ReelID = int(reelID)
+	}
+	if ts, ok := m["timestamp"].(int64); ok {
+		resp.Timestamp = ts
+	}
+	if reqID, ok := m["request_id"].(int64); ok {
+		resp.RequestID = reqID
+	}
+	if data, ok := m["data"].([]byte); ok {
+		resp.Data = data
+	}
+	return resp, nil
+}
+
+func decodeVideoChunk(data []byte) (*enginetransport.VideoChunk, error) {
+	var chunk enginetransport.VideoChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+func decodeMap(data []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
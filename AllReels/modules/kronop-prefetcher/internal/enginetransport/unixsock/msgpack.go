@@ -0,0 +1,323 @@
+package unixsock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Minimal hand-rolled MessagePack encoder/decoder. There's no .proto or
+// third-party codec pulled in for this, same call as the RTSP/RTMP ingest
+// sources hand-rolling their own wire formats: it only has to round-trip
+// the handful of shapes EngineRequest/EngineResponse/map[string]interface{}
+// actually need — nil, bool, int64, float64, string, []byte, []interface{},
+// and map[string]interface{}.
+
+func encodeValue(w io.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		return writeBytes(w, 0xc0)
+	case bool:
+		if val {
+			return writeBytes(w, 0xc3)
+		}
+		return writeBytes(w, 0xc2)
+	case int:
+		return encodeInt(w, int64(val))
+	case int64:
+		return encodeInt(w, val)
+	case float64:
+		return encodeFloat64(w, val)
+	case string:
+		return encodeString(w, val)
+	case []byte:
+		return encodeBin(w, val)
+	case []interface{}:
+		return encodeArray(w, val)
+	case map[string]interface{}:
+		return encodeMap(w, val)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+}
+
+func writeBytes(w io.Writer, b ...byte) error {
+	_, err := w.Write(b)
+	return err
+}
+
+func encodeInt(w io.Writer, n int64) error {
+	if n >= 0 && n <= 0x7f {
+		return writeBytes(w, byte(n))
+	}
+	if n < 0 && n >= -32 {
+		return writeBytes(w, byte(0xe0|(n&0x1f)))
+	}
+	buf := make([]byte, 9)
+	buf[0] = 0xd3 // int64
+	binary.BigEndian.PutUint64(buf[1:], uint64(n))
+	return writeBytes(w, buf...)
+}
+
+func encodeFloat64(w io.Writer, f float64) error {
+	buf := make([]byte, 9)
+	buf[0] = 0xcb
+	binary.BigEndian.PutUint64(buf[1:], math.Float64bits(f))
+	return writeBytes(w, buf...)
+}
+
+func encodeString(w io.Writer, s string) error {
+	n := len(s)
+	switch {
+	case n <= 31:
+		if err := writeBytes(w, byte(0xa0|n)); err != nil {
+			return err
+		}
+	case n <= 0xff:
+		if err := writeBytes(w, 0xd9, byte(n)); err != nil {
+			return err
+		}
+	case n <= 0xffff:
+		buf := make([]byte, 3)
+		buf[0] = 0xda
+		binary.BigEndian.PutUint16(buf[1:], uint16(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	default:
+		buf := make([]byte, 5)
+		buf[0] = 0xdb
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func encodeBin(w io.Writer, b []byte) error {
+	n := len(b)
+	switch {
+	case n <= 0xff:
+		if err := writeBytes(w, 0xc4, byte(n)); err != nil {
+			return err
+		}
+	case n <= 0xffff:
+		buf := make([]byte, 3)
+		buf[0] = 0xc5
+		binary.BigEndian.PutUint16(buf[1:], uint16(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	default:
+		buf := make([]byte, 5)
+		buf[0] = 0xc6
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func encodeArray(w io.Writer, arr []interface{}) error {
+	n := len(arr)
+	switch {
+	case n <= 15:
+		if err := writeBytes(w, byte(0x90|n)); err != nil {
+			return err
+		}
+	case n <= 0xffff:
+		buf := make([]byte, 3)
+		buf[0] = 0xdc
+		binary.BigEndian.PutUint16(buf[1:], uint16(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	default:
+		buf := make([]byte, 5)
+		buf[0] = 0xdd
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	}
+	for _, v := range arr {
+		if err := encodeValue(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMap(w io.Writer, m map[string]interface{}) error {
+	n := len(m)
+	switch {
+	case n <= 15:
+		if err := writeBytes(w, byte(0x80|n)); err != nil {
+			return err
+		}
+	case n <= 0xffff:
+		buf := make([]byte, 3)
+		buf[0] = 0xde
+		binary.BigEndian.PutUint16(buf[1:], uint16(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	default:
+		buf := make([]byte, 5)
+		buf[0] = 0xdf
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		if err := writeBytes(w, buf...); err != nil {
+			return err
+		}
+	}
+	for k, v := range m {
+		if err := encodeString(w, k); err != nil {
+			return err
+		}
+		if err := encodeValue(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeValue reads one MessagePack value from r.
+func decodeValue(r io.Reader) (interface{}, error) {
+	var tag [1]byte
+	if _, err := io.ReadFull(r, tag[:]); err != nil {
+		return nil, err
+	}
+	b := tag[0]
+
+	switch {
+	case b <= 0x7f: // positive fixint
+		return int64(b), nil
+	case b >= 0xe0: // negative fixint
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0: // fixstr
+		return decodeStringBody(r, int(b&0x1f))
+	case b&0xf0 == 0x90: // fixarray
+		return decodeArrayBody(r, int(b&0x0f))
+	case b&0xf0 == 0x80: // fixmap
+		return decodeMapBody(r, int(b&0x0f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readLen(r, b, 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		_, err = io.ReadFull(r, buf)
+		return buf, err
+	case 0xcb:
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf[:])), nil
+	case 0xd3:
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(buf[:])), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := readLen(r, b, 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return decodeStringBody(r, n)
+	case 0xdc, 0xdd:
+		// array/map have no 8-bit variant, so base sits one below the
+		// 16-bit tag.
+		n, err := readLen(r, b, 0xdb)
+		if err != nil {
+			return nil, err
+		}
+		return decodeArrayBody(r, n)
+	case 0xde, 0xdf:
+		n, err := readLen(r, b, 0xdd)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMapBody(r, n)
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported tag 0x%x", b)
+	}
+}
+
+// readLen reads the length field for a tag whose 8/16/32-bit variants start
+// at base (e.g. bin8/bin16/bin32 start at 0xc4).
+func readLen(r io.Reader, tag, base byte) (int, error) {
+	switch tag - base {
+	case 0:
+		var b [1]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		return int(b[0]), nil
+	case 1:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		return int(binary.BigEndian.Uint16(b[:])), nil
+	default:
+		var b [4]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		return int(binary.BigEndian.Uint32(b[:])), nil
+	}
+}
+
+func decodeStringBody(r io.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func decodeArrayBody(r io.Reader, n int) ([]interface{}, error) {
+	arr := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		v, err := decodeValue(r)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func decodeMapBody(r io.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := decodeValue(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key is %T, want string", k)
+		}
+		v, err := decodeValue(r)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
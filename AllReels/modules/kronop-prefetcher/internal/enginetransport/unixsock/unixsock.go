@@ -0,0 +1,319 @@
+// Package unixsock implements enginetransport.Transport over a Unix domain
+// socket, for when the Rust engine is co-located on the same host as
+// Kronop. Requests/responses are framed as a 4-byte big-endian length
+// prefix followed by a MessagePack-encoded payload (see msgpack.go) — the
+// same framing idiom the WebTransport control stream uses, just over a
+// socket instead of QUIC, and MessagePack instead of JSON for the
+// request/response envelope. The Data payload inside the envelope stays
+// JSON-encoded (see convert.go), so RequestChunk still JSON-decodes each
+// chunk it receives.
+package unixsock
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kronop/prefetcher/internal/enginetransport"
+)
+
+// maxInFlight mirrors grpcstream's backpressure: once this many requests
+// are outstanding, new callers block instead of piling up on a slow engine.
+const maxInFlight = 64
+
+// Transport talks to a co-located Rust engine over a Unix domain socket.
+// addr is a filesystem path (e.g. "/run/kronop/rust-engine.sock").
+type Transport struct {
+	addr string
+	mu   sync.RWMutex
+
+	conn      net.Conn
+	r         *bufio.Reader
+	connected bool
+
+	writeMu sync.Mutex // serializes writes across caller goroutines
+
+	nextID   int64
+	inFlight chan struct{}
+
+	pendingMu sync.Mutex
+	pending   map[int64]chan enginetransport.EngineResponse
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New creates a Transport for the given Unix socket path. Call Connect
+// before using it.
+func New(addr string) *Transport {
+	return &Transport{addr: addr}
+}
+
+// Connect dials the socket and confirms it's responsive with a get_stats
+// round trip before reporting connected.
+func (t *Transport) Connect() error {
+	logrus.Infof("🔗 Connecting to Rust engine at unix:%s", t.addr)
+
+	conn, err := net.Dial("unix", t.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Rust engine: %v", err)
+	}
+
+	t.mu.Lock()
+	t.conn = conn
+	t.r = bufio.NewReader(conn)
+	t.inFlight = make(chan struct{}, maxInFlight)
+	t.pending = make(map[int64]chan enginetransport.EngineResponse)
+	t.closed = make(chan struct{})
+	t.connected = true
+	t.mu.Unlock()
+
+	go t.readLoop()
+
+	if _, err := t.GetEngineStats(); err != nil {
+		t.Disconnect()
+		return fmt.Errorf("Rust engine health check failed: %v", err)
+	}
+
+	logrus.Info("✅ Connected to Rust engine successfully")
+	return nil
+}
+
+// Disconnect closes the socket, unblocking any in-flight calls.
+func (t *Transport) Disconnect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return nil
+	}
+
+	t.closeOnce.Do(func() { close(t.closed) })
+	err := t.conn.Close()
+	t.connected = false
+	logrus.Info("🔌 Disconnected from Rust engine")
+	return err
+}
+
+// IsConnected reports whether the socket is currently up.
+func (t *Transport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.connected
+}
+
+// HealthCheck asks the engine for stats and confirms it reports running.
+func (t *Transport) HealthCheck() error {
+	stats, err := t.GetEngineStats()
+	if err != nil {
+		return fmt.Errorf("health check failed: %v", err)
+	}
+	if running, ok := stats["is_running"].(bool); !ok || !running {
+		return fmt.Errorf("Rust engine is not running")
+	}
+	return nil
+}
+
+// RequestChunk requests a video chunk from the Rust engine.
+func (t *Transport) RequestChunk(reelID int, chunkID string) (*enginetransport.VideoChunk, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_chunk",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request chunk: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("Rust engine error: %s", response.Error)
+	}
+
+	chunk, err := decodeVideoChunk(response.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse chunk: %v", err)
+	}
+	return chunk, nil
+}
+
+// PrefetchChunk asks the Rust engine to prefetch a chunk.
+func (t *Transport) PrefetchChunk(reelID int, chunkID string) error {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "prefetch_chunk",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prefetch chunk: %v", err)
+	}
+	if response.Status != "success" {
+		return fmt.Errorf("prefetch failed: %s", response.Error)
+	}
+	return nil
+}
+
+// IsChunkReady checks if a chunk is ready for playback.
+func (t *Transport) IsChunkReady(reelID int, chunkID string) (bool, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "is_ready",
+		ReelID:    reelID,
+		ChunkID:   chunkID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check chunk readiness: %v", err)
+	}
+	if response.Status != "success" {
+		return false, fmt.Errorf("readiness check failed: %s", response.Error)
+	}
+
+	m, err := decodeMap(response.Data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse readiness response: %v", err)
+	}
+	ready, _ := m["ready"].(bool)
+	return ready, nil
+}
+
+// GetCurrentFrame gets the current frame from the Rust engine.
+func (t *Transport) GetCurrentFrame(reelID int) ([]byte, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_current_frame",
+		ReelID:    reelID,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current frame: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("failed to get frame: %s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// GetEngineStats gets statistics from the Rust engine.
+func (t *Transport) GetEngineStats() (map[string]interface{}, error) {
+	response, err := t.call(enginetransport.EngineRequest{
+		Type:      "get_stats",
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %v", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("stats request failed: %s", response.Error)
+	}
+	return decodeMap(response.Data)
+}
+
+// call frames and sends req, then blocks until its correlated response
+// arrives or the connection closes.
+func (t *Transport) call(req enginetransport.EngineRequest) (*enginetransport.EngineResponse, error) {
+	t.mu.RLock()
+	conn, closed, inFlight := t.conn, t.closed, t.inFlight
+	t.mu.RUnlock()
+	if conn == nil {
+		return nil, fmt.Errorf("not connected to Rust engine")
+	}
+
+	select {
+	case inFlight <- struct{}{}:
+	case <-closed:
+		return nil, fmt.Errorf("Rust engine connection closed")
+	}
+	defer func() { <-inFlight }()
+
+	req.RequestID = atomic.AddInt64(&t.nextID, 1)
+
+	reply := make(chan enginetransport.EngineResponse, 1)
+	t.pendingMu.Lock()
+	t.pending[req.RequestID] = reply
+	t.pendingMu.Unlock()
+	defer func() {
+		t.pendingMu.Lock()
+		delete(t.pending, req.RequestID)
+		t.pendingMu.Unlock()
+	}()
+
+	if err := t.send(conn, req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	select {
+	case resp := <-reply:
+		return &resp, nil
+	case <-closed:
+		return nil, fmt.Errorf("Rust engine connection closed")
+	}
+}
+
+// send encodes req as a length-prefixed MessagePack frame.
+func (t *Transport) send(conn net.Conn, req enginetransport.EngineRequest) error {
+	payload, err := encodeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+	if _, err := conn.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err = conn.Write(payload)
+	return err
+}
+
+// readLoop is the socket's single reader goroutine, dispatching each
+// response to the pending call it correlates with via RequestID.
+func (t *Transport) readLoop() {
+	t.mu.RLock()
+	r, closed := t.r, t.closed
+	t.mu.RUnlock()
+
+	defer t.Disconnect()
+	for {
+		var lenBuf [4]byte
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			logrus.Warnf("⚠️ Rust engine socket read failed: %v", err)
+			return
+		}
+		n := binary.BigEndian.Uint32(lenBuf[:])
+
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			logrus.Warnf("⚠️ Rust engine socket read failed: %v", err)
+			return
+		}
+
+		resp, err := decodeResponse(payload)
+		if err != nil {
+			logrus.Warnf("⚠️ failed to decode Rust engine response: %v", err)
+			continue
+		}
+
+		t.pendingMu.Lock()
+		reply, ok := t.pending[resp.RequestID]
+		t.pendingMu.Unlock()
+		if !ok {
+			logrus.Warnf("⚠️ Rust engine response for unknown request %d", resp.RequestID)
+			continue
+		}
+
+		select {
+		case reply <- resp:
+		case <-closed:
+			return
+		}
+	}
+}
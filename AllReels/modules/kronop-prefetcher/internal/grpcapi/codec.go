@@ -0,0 +1,33 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodecName is registered as a gRPC content-subtype, the same approach
+// internal/enginetransport/grpcstream/codec.go uses to carry plain Go
+// structs without a .proto-generated message type. A future Go client SDK
+// dials with grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsonCodecName))
+// to match.
+const jsonCodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec implements grpc/encoding.Codec on top of encoding/json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return jsonCodecName
+}
@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NotFoundError is returned by a handler when UserID names a user grpcapi
+// has no tracked data for, and is mapped to codes.NotFound by
+// ErrorMappingUnaryInterceptor/ErrorMappingStreamInterceptor.
+type NotFoundError struct {
+	UserID string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("user %q not found", e.UserID)
+}
+
+// InvalidArgumentError is returned by a handler for a missing/malformed
+// required field, and is mapped to codes.InvalidArgument.
+type InvalidArgumentError struct {
+	Field string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return fmt.Sprintf("invalid argument: %s", e.Field)
+}
+
+// isInsufficientDataError reports whether err is the
+// "insufficient ... data for prediction" error analyzer.BehaviorAnalyzer
+// returns from AnalyzeScrollPattern/PredictNextBehavior. analyzer returns a
+// plain fmt.Errorf rather than a typed/sentinel error, so this matches on
+// message rather than errors.As; it's mapped to codes.FailedPrecondition
+// since it means the caller's own request history is the problem, not the
+// request itself.
+func isInsufficientDataError(err error) bool {
+	return strings.Contains(err.Error(), "insufficient") && strings.Contains(err.Error(), "data")
+}
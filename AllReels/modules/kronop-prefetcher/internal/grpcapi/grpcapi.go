@@ -0,0 +1,87 @@
+// Package grpcapi exposes behavior ingestion and prefetch decisions over
+// gRPC (service kronop.v1.Kronop — see api/proto/kronop/v1/kronop.proto for
+// the IDL) as the client-facing counterpart to StartHTTPServer. Like
+// internal/enginetransport/grpcstream, there's no protoc-gen-go-grpc step
+// in this module's build, so the service is registered by hand via a
+// grpc.ServiceDesc and carried over the same JSON content-subtype codec
+// (see codec.go) rather than generated protobuf message types — the
+// request/response shapes in messages.go are exactly what protoc would
+// otherwise generate from the .proto.
+package grpcapi
+
+import (
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/kronop/prefetcher/internal/analyzer"
+	"github.com/kronop/prefetcher/internal/prefetcher"
+)
+
+// defaultMaxRecvMsgSize caps an inbound message (StreamEvents in particular,
+// which a misbehaving client could otherwise use to send unboundedly large
+// batches) at 4 MiB.
+const defaultMaxRecvMsgSize = 4 << 20
+
+// Config configures a Server.
+type Config struct {
+	// Addr is the listen address, e.g. ":9090".
+	Addr string `yaml:"addr"`
+	// MaxRecvMsgSize caps an inbound message's size. Defaults to
+	// defaultMaxRecvMsgSize.
+	MaxRecvMsgSize int `yaml:"max_recv_msg_size"`
+	// EnableReflection registers grpc/reflection, for tools like grpcurl to
+	// introspect the service. Defaults off since it leaks the service's
+	// method surface to anything that can reach the port.
+	EnableReflection bool `yaml:"enable_reflection"`
+}
+
+// Server is the gRPC front door for behavior ingestion and prefetch
+// decisions, backed by an analyzer.BehaviorAnalyzer for analysis/prediction
+// and a *prefetcher.Engine for session bookkeeping.
+type Server struct {
+	grpcServer *grpc.Server
+	listener   net.Listener
+	kronop     *kronopServer
+}
+
+// NewServer creates a Server listening on config.Addr, wired with the
+// error-mapping and logging interceptors (see interceptors.go).
+func NewServer(config Config, ba analyzer.BehaviorAnalyzer, engine *prefetcher.Engine) (*Server, error) {
+	if config.MaxRecvMsgSize <= 0 {
+		config.MaxRecvMsgSize = defaultMaxRecvMsgSize
+	}
+
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("grpcapi: failed to listen on %s: %v", config.Addr, err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(config.MaxRecvMsgSize),
+		grpc.ChainUnaryInterceptor(LoggingUnaryInterceptor, ErrorMappingUnaryInterceptor),
+		grpc.ChainStreamInterceptor(LoggingStreamInterceptor, ErrorMappingStreamInterceptor),
+	)
+
+	kronop := &kronopServer{analyzer: ba, engine: engine, users: newUserStore()}
+	grpcServer.RegisterService(&kronopServiceDesc, kronop)
+
+	if config.EnableReflection {
+		reflection.Register(grpcServer)
+	}
+
+	return &Server{grpcServer: grpcServer, listener: listener, kronop: kronop}, nil
+}
+
+// Serve blocks, accepting connections until Stop is called.
+func (s *Server) Serve() error {
+	return s.grpcServer.Serve(s.listener)
+}
+
+// Stop gracefully stops the server, waiting for in-flight RPCs (including
+// open StreamEvents streams) to finish.
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+}
@@ -0,0 +1,85 @@
+package grpcapi
+
+import "time"
+
+// The request/response types below are exactly what protoc-gen-go would
+// generate from api/proto/kronop/v1/kronop.proto's messages; they're
+// hand-written because this module has no protoc step (see grpcapi.go).
+
+// ScrollEventRequest is RecordScrollEvent's request.
+type ScrollEventRequest struct {
+	UserID      string  `json:"user_id"`
+	FromReel    int     `json:"from_reel"`
+	ToReel      int     `json:"to_reel"`
+	Direction   string  `json:"direction"`
+	ScrollSpeed float64 `json:"scroll_speed"`
+}
+
+// WatchEventRequest is RecordWatchEvent's request.
+type WatchEventRequest struct {
+	UserID    string        `json:"user_id"`
+	ReelID    int           `json:"reel_id"`
+	WatchTime time.Duration `json:"watch_time"`
+	Completed bool          `json:"completed"`
+	Position  float64       `json:"position"`
+}
+
+// InteractionRequest is RecordInteraction's request.
+type InteractionRequest struct {
+	UserID          string `json:"user_id"`
+	ReelID          int    `json:"reel_id"`
+	InteractionType string `json:"interaction_type"`
+}
+
+// Ack is the response for every fire-and-forget Record* RPC.
+type Ack struct {
+	Accepted bool `json:"accepted"`
+}
+
+// BehaviorProfileRequest is GetBehaviorProfile's and PredictNextBehavior's
+// request.
+type BehaviorProfileRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// BehaviorProfileResponse is GetBehaviorProfile's response.
+type BehaviorProfileResponse struct {
+	UserType      string  `json:"user_type"`
+	ScrollSpeed   float64 `json:"scroll_speed"`
+	AvgWatchTime  float64 `json:"avg_watch_time"`
+	PrefetchCount int     `json:"prefetch_count"`
+	Confidence    float64 `json:"confidence"`
+}
+
+// PredictionResponse is PredictNextBehavior's response.
+type PredictionResponse struct {
+	PredictedUserType   string  `json:"predicted_user_type"`
+	NextScrollSpeed     float64 `json:"next_scroll_speed"`
+	NextWatchTime       float64 `json:"next_watch_time"`
+	Confidence          float64 `json:"confidence"`
+	RecommendedPrefetch int     `json:"recommended_prefetch"`
+}
+
+// OptimalPrefetchCountRequest is GetOptimalPrefetchCount's request.
+// NetworkCondition mirrors analyzer.NetworkCondition's int values
+// (0=VeryPoor .. 3=Excellent).
+type OptimalPrefetchCountRequest struct {
+	UserID           string `json:"user_id"`
+	NetworkCondition int    `json:"network_condition"`
+}
+
+// OptimalPrefetchCountResponse is GetOptimalPrefetchCount's response.
+type OptimalPrefetchCountResponse struct {
+	PrefetchCount int `json:"prefetch_count"`
+}
+
+// StreamEvent is a single message on the bidirectional StreamEvents RPC.
+// Exactly one of Scroll/Watch/Interaction is set, mirroring a protobuf
+// oneof; Ack is set on server->client messages acknowledging the most
+// recently processed event.
+type StreamEvent struct {
+	Scroll      *ScrollEventRequest `json:"scroll,omitempty"`
+	Watch       *WatchEventRequest  `json:"watch,omitempty"`
+	Interaction *InteractionRequest `json:"interaction,omitempty"`
+	Ack         *Ack                `json:"ack,omitempty"`
+}
@@ -0,0 +1,361 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/kronop/prefetcher/internal/analyzer"
+	"github.com/kronop/prefetcher/internal/prefetcher"
+)
+
+// kronopServer implements the kronop.v1.Kronop service (see
+// api/proto/kronop/v1/kronop.proto) against an analyzer.BehaviorAnalyzer
+// for analysis/prediction and a *prefetcher.Engine for session bookkeeping.
+type kronopServer struct {
+	analyzer analyzer.BehaviorAnalyzer
+	engine   *prefetcher.Engine
+	users    *userStore
+}
+
+// touchSession records activity against the engine-level session
+// (creating one on first contact), independently of this package's own
+// per-user analyzer state in userStore. It's the genuine use of
+// *prefetcher.Engine this service depends on: every ingested event updates
+// the same active-user bookkeeping StartHTTPServer's handlers would.
+func (s *kronopServer) touchSession(userID string, toReel int) {
+	if s.engine == nil {
+		return
+	}
+	session, exists := s.engine.GetUserSession(userID)
+	if !exists {
+		session = s.engine.AddUser(userID)
+	}
+
+	// session.mu is unexported (prefetcher package only); writing these
+	// fields unlocked matches how processBackgroundTasks itself reads
+	// LastActivity elsewhere in this engine.
+	session.LastActivity = time.Now()
+	if toReel > 0 {
+		session.CurrentReel = toReel
+	}
+}
+
+// RecordScrollEvent ingests a scroll event for req.UserID.
+func (s *kronopServer) RecordScrollEvent(ctx context.Context, req *ScrollEventRequest) (*Ack, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st := s.users.getOrCreate(req.UserID)
+	st.mu.Lock()
+	st.data.ScrollEvents = trimScrollEvents(append(st.data.ScrollEvents, analyzer.ScrollEvent{
+		Timestamp:   time.Now(),
+		FromReel:    req.FromReel,
+		ToReel:      req.ToReel,
+		ScrollSpeed: req.ScrollSpeed,
+	}))
+	st.mu.Unlock()
+
+	s.analyzer.ObserveScrollEvent(req.UserID, req.ScrollSpeed)
+	s.touchSession(req.UserID, req.ToReel)
+	return &Ack{Accepted: true}, nil
+}
+
+// RecordWatchEvent ingests a watch event for req.UserID.
+func (s *kronopServer) RecordWatchEvent(ctx context.Context, req *WatchEventRequest) (*Ack, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st := s.users.getOrCreate(req.UserID)
+	st.mu.Lock()
+	st.data.WatchEvents = trimWatchEvents(append(st.data.WatchEvents, analyzer.WatchEvent{
+		Timestamp: time.Now(),
+		ReelID:    req.ReelID,
+		WatchTime: req.WatchTime.Seconds(),
+		Completed: req.Completed,
+	}))
+	st.mu.Unlock()
+
+	s.analyzer.ObserveWatchEvent(req.UserID, req.WatchTime.Seconds())
+	s.touchSession(req.UserID, 0)
+	return &Ack{Accepted: true}, nil
+}
+
+// RecordInteraction ingests a like/comment/share/save interaction for
+// req.UserID.
+func (s *kronopServer) RecordInteraction(ctx context.Context, req *InteractionRequest) (*Ack, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st := s.users.getOrCreate(req.UserID)
+	st.mu.Lock()
+	st.data.Interactions = trimInteractions(append(st.data.Interactions, analyzer.Interaction{
+		Timestamp: time.Now(),
+		Type:      req.InteractionType,
+		ReelID:    req.ReelID,
+	}))
+	st.mu.Unlock()
+
+	s.analyzer.ObserveInteraction(req.UserID, req.InteractionType)
+	s.touchSession(req.UserID, 0)
+	return &Ack{Accepted: true}, nil
+}
+
+// GetBehaviorProfile returns req.UserID's current profile, recalculated
+// from whatever events have been ingested so far.
+func (s *kronopServer) GetBehaviorProfile(ctx context.Context, req *BehaviorProfileRequest) (*BehaviorProfileResponse, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st, ok := s.users.get(req.UserID)
+	if !ok {
+		return nil, &NotFoundError{UserID: req.UserID}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err := s.analyzer.UpdateProfile(&st.profile, &st.data); err != nil {
+		return nil, err
+	}
+
+	return &BehaviorProfileResponse{
+		UserType:      st.profile.UserType,
+		ScrollSpeed:   st.profile.ScrollSpeed,
+		AvgWatchTime:  st.profile.AvgWatchTime,
+		PrefetchCount: st.profile.PrefetchCount,
+		Confidence:    st.profile.Confidence,
+	}, nil
+}
+
+// PredictNextBehavior predicts req.UserID's next behavior from their
+// recently ingested events. Returns FailedPrecondition (via
+// isInsufficientDataError) if fewer than 3 scroll events have been
+// recorded yet.
+func (s *kronopServer) PredictNextBehavior(ctx context.Context, req *BehaviorProfileRequest) (*PredictionResponse, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st, ok := s.users.get(req.UserID)
+	if !ok {
+		return nil, &NotFoundError{UserID: req.UserID}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err := s.analyzer.UpdateProfile(&st.profile, &st.data); err != nil {
+		return nil, err
+	}
+
+	prediction, err := s.analyzer.PredictNextBehavior(req.UserID, &st.profile, &st.data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PredictionResponse{
+		PredictedUserType:   prediction.PredictedUserType,
+		NextScrollSpeed:     prediction.NextScrollSpeed,
+		NextWatchTime:       prediction.NextWatchTime,
+		Confidence:          prediction.Confidence,
+		RecommendedPrefetch: prediction.RecommendedPrefetch,
+	}, nil
+}
+
+// GetOptimalPrefetchCount returns req.UserID's optimal prefetch count under
+// the given network condition, and records that condition against the
+// engine's usage-stats histogram (see prefetcher.Engine.RecordNetworkCondition
+// and internal/usagestats).
+func (s *kronopServer) GetOptimalPrefetchCount(ctx context.Context, req *OptimalPrefetchCountRequest) (*OptimalPrefetchCountResponse, error) {
+	if req.UserID == "" {
+		return nil, &InvalidArgumentError{Field: "user_id"}
+	}
+
+	st, ok := s.users.get(req.UserID)
+	if !ok {
+		return nil, &NotFoundError{UserID: req.UserID}
+	}
+
+	condition := analyzer.NetworkCondition(req.NetworkCondition)
+
+	st.mu.RLock()
+	count := s.analyzer.GetOptimalPrefetchCount(&st.profile, condition)
+	st.mu.RUnlock()
+
+	if s.engine != nil {
+		s.engine.RecordNetworkCondition(networkConditionLabel(condition))
+	}
+
+	return &OptimalPrefetchCountResponse{PrefetchCount: count}, nil
+}
+
+// networkConditionLabel renders condition as the label
+// Engine.NetworkConditionHistogram groups usage-stats reporting by.
+func networkConditionLabel(condition analyzer.NetworkCondition) string {
+	switch condition {
+	case analyzer.NetworkExcellent:
+		return "excellent"
+	case analyzer.NetworkGood:
+		return "good"
+	case analyzer.NetworkPoor:
+		return "poor"
+	default:
+		return "very_poor"
+	}
+}
+
+// StreamEvents lets a mobile client push a continuous stream of scroll/
+// watch/interaction events over one connection instead of one RPC per
+// event, replying with an Ack after each. It's implemented directly
+// against grpc.ServerStream (see kronopServiceDesc) rather than a
+// generated Kronop_StreamEventsServer, for the same no-protoc reason as
+// the rest of this package.
+func (s *kronopServer) StreamEvents(stream grpc.ServerStream) error {
+	for {
+		var event StreamEvent
+		if err := stream.RecvMsg(&event); err != nil {
+			return err
+		}
+
+		ack, err := s.dispatchStreamEvent(stream.Context(), &event)
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(&StreamEvent{Ack: ack}); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatchStreamEvent routes a single StreamEvent to the matching unary
+// handler, so StreamEvents and the one-event-per-call RPCs share the same
+// validation and bookkeeping.
+func (s *kronopServer) dispatchStreamEvent(ctx context.Context, event *StreamEvent) (*Ack, error) {
+	switch {
+	case event.Scroll != nil:
+		return s.RecordScrollEvent(ctx, event.Scroll)
+	case event.Watch != nil:
+		return s.RecordWatchEvent(ctx, event.Watch)
+	case event.Interaction != nil:
+		return s.RecordInteraction(ctx, event.Interaction)
+	default:
+		return nil, &InvalidArgumentError{Field: "event"}
+	}
+}
+
+// kronopServiceDesc is kronop.v1.Kronop's grpc.ServiceDesc, hand-written in
+// place of protoc-gen-go-grpc's generated output (see grpcapi.go).
+var kronopServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kronop.v1.Kronop",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "RecordScrollEvent", Handler: recordScrollEventHandler},
+		{MethodName: "RecordWatchEvent", Handler: recordWatchEventHandler},
+		{MethodName: "RecordInteraction", Handler: recordInteractionHandler},
+		{MethodName: "GetBehaviorProfile", Handler: getBehaviorProfileHandler},
+		{MethodName: "PredictNextBehavior", Handler: predictNextBehaviorHandler},
+		{MethodName: "GetOptimalPrefetchCount", Handler: getOptimalPrefetchCountHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       streamEventsHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "kronop/v1/kronop.proto",
+}
+
+func recordScrollEventHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(ScrollEventRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).RecordScrollEvent(ctx, req.(*ScrollEventRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/RecordScrollEvent"}, handler)
+}
+
+func recordWatchEventHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(WatchEventRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).RecordWatchEvent(ctx, req.(*WatchEventRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/RecordWatchEvent"}, handler)
+}
+
+func recordInteractionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(InteractionRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).RecordInteraction(ctx, req.(*InteractionRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/RecordInteraction"}, handler)
+}
+
+func getBehaviorProfileHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(BehaviorProfileRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).GetBehaviorProfile(ctx, req.(*BehaviorProfileRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/GetBehaviorProfile"}, handler)
+}
+
+func predictNextBehaviorHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(BehaviorProfileRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).PredictNextBehavior(ctx, req.(*BehaviorProfileRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/PredictNextBehavior"}, handler)
+}
+
+func getOptimalPrefetchCountHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(OptimalPrefetchCountRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*kronopServer).GetOptimalPrefetchCount(ctx, req.(*OptimalPrefetchCountRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/kronop.v1.Kronop/GetOptimalPrefetchCount"}, handler)
+}
+
+func streamEventsHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(*kronopServer).StreamEvents(stream)
+}
@@ -0,0 +1,78 @@
+package grpcapi
+
+import (
+	"sync"
+
+	"github.com/kronop/prefetcher/internal/analyzer"
+)
+
+// maxTrackedEvents bounds each user's UserBehaviorData so a long-lived
+// connection's ScrollEvents/WatchEvents/Interactions can't grow unbounded;
+// oldest events are dropped once a slice hits this size, mirroring
+// tracker.UserSession's own fixed-window trimming.
+const maxTrackedEvents = 200
+
+// userState is one user's recent behavior data and last-computed profile,
+// as seen by this gRPC surface. It's intentionally separate from
+// tracker.UserBehaviorTracker/prefetcher.Engine's own per-user state
+// (different, pre-existing incompatible shapes); it exists only to give
+// analyzer.BehaviorAnalyzer's AnalyzeScrollPattern/PredictNextBehavior
+// something to operate on per caller.
+type userState struct {
+	mu      sync.RWMutex
+	data    analyzer.UserBehaviorData
+	profile analyzer.BehaviorProfile
+}
+
+// userStore is a registry of userState by userID, the gRPC-surface
+// equivalent of prefetcher.Engine.activeUsers.
+type userStore struct {
+	sessions sync.Map // map[string]*userState
+}
+
+func newUserStore() *userStore {
+	return &userStore{}
+}
+
+// getOrCreate returns userID's userState, creating an empty one on first
+// use.
+func (s *userStore) getOrCreate(userID string) *userState {
+	if v, ok := s.sessions.Load(userID); ok {
+		return v.(*userState)
+	}
+	v, _ := s.sessions.LoadOrStore(userID, &userState{
+		profile: analyzer.BehaviorProfile{UserType: "unknown"},
+	})
+	return v.(*userState)
+}
+
+// get returns userID's userState, or false if this gRPC surface has never
+// seen that user.
+func (s *userStore) get(userID string) (*userState, bool) {
+	v, ok := s.sessions.Load(userID)
+	if !ok {
+		return nil, false
+	}
+	return v.(*userState), true
+}
+
+func trimScrollEvents(events []analyzer.ScrollEvent) []analyzer.ScrollEvent {
+	if len(events) <= maxTrackedEvents {
+		return events
+	}
+	return events[len(events)-maxTrackedEvents:]
+}
+
+func trimWatchEvents(events []analyzer.WatchEvent) []analyzer.WatchEvent {
+	if len(events) <= maxTrackedEvents {
+		return events
+	}
+	return events[len(events)-maxTrackedEvents:]
+}
+
+func trimInteractions(events []analyzer.Interaction) []analyzer.Interaction {
+	if len(events) <= maxTrackedEvents {
+		return events
+	}
+	return events[len(events)-maxTrackedEvents:]
+}
@@ -0,0 +1,171 @@
+package ingest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/bridge"
+	"github.com/sirupsen/logrus"
+)
+
+// RTMPSource accepts a single inbound RTMP publish (e.g. from OBS or a
+// broadcaster's encoder) and hands back FLV video tags as frames. Only the
+// handshake and tag framing needed to pull out video payloads is
+// implemented; AMF command parsing is skipped and publish is assumed.
+type RTMPSource struct {
+	listener net.Listener
+	conn     net.Conn
+	width    int
+	height   int
+}
+
+// ListenRTMP opens addr (e.g. ":1935") and blocks until the first publisher
+// connects and completes the handshake.
+func ListenRTMP(addr string) (*RTMPSource, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for RTMP: %v", err)
+	}
+
+	logrus.Infof("📡 RTMP ingest listening on %s", addr)
+	conn, err := ln.Accept()
+	if err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to accept RTMP publisher: %v", err)
+	}
+
+	s := &RTMPSource{listener: ln, conn: conn}
+	if err := s.handshake(); err != nil {
+		conn.Close()
+		ln.Close()
+		return nil, err
+	}
+
+	logrus.Info("✅ RTMP publisher connected")
+	return s, nil
+}
+
+// handshake performs the plain (unencrypted) RTMP C0/C1/C2 <-> S0/S1/S2
+// exchange described in the RTMP spec §5.2.
+func (s *RTMPSource) handshake() error {
+	c0c1 := make([]byte, 1537)
+	if _, err := io.ReadFull(s.conn, c0c1); err != nil {
+		return fmt.Errorf("RTMP handshake: failed to read C0/C1: %v", err)
+	}
+	if c0c1[0] != 3 {
+		return fmt.Errorf("RTMP handshake: unsupported version %d", c0c1[0])
+	}
+
+	s0s1s2 := make([]byte, 1+1536+1536)
+	s0s1s2[0] = 3
+	if _, err := rand.Read(s0s1s2[1 : 1+1536]); err != nil {
+		return fmt.Errorf("RTMP handshake: failed to generate S1: %v", err)
+	}
+	copy(s0s1s2[1+1536:], c0c1[1:]) // S2 echoes C1
+	if _, err := s.conn.Write(s0s1s2); err != nil {
+		return fmt.Errorf("RTMP handshake: failed to write S0/S1/S2: %v", err)
+	}
+
+	c2 := make([]byte, 1536)
+	if _, err := io.ReadFull(s.conn, c2); err != nil {
+		return fmt.Errorf("RTMP handshake: failed to read C2: %v", err)
+	}
+	return nil
+}
+
+// Next reads chunks until a full video message body is assembled and
+// returns its payload (without the FLV video-tag header byte), flagging
+// keyframes from the frame-type nibble.
+func (s *RTMPSource) Next() ([]byte, *bridge.FrameInfo, error) {
+	for {
+		header, err := readChunkBasicHeader(s.conn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		msgTypeID, payload, err := readChunkMessage(s.conn, header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if msgTypeID != rtmpMsgTypeVideo || len(payload) == 0 {
+			continue
+		}
+
+		frameType := (payload[0] >> 4) & 0x0F
+		isKeyFrame := frameType == 1
+
+		info := &bridge.FrameInfo{
+			Width:      s.width,
+			Height:     s.height,
+			Format:     "h264",
+			Timestamp:  time.Now().Unix(),
+			IsKeyFrame: isKeyFrame,
+		}
+		return payload[1:], info, nil
+	}
+}
+
+// Close stops accepting further publishers and closes the active one.
+func (s *RTMPSource) Close() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return s.listener.Close()
+}
+
+const rtmpMsgTypeVideo = 9
+
+// chunkBasicHeader is the minimal subset of the RTMP chunk basic header
+// needed to read a fixed-size chunk stream used by most encoders.
+type chunkBasicHeader struct {
+	fmtType uint8
+	csID    uint32
+}
+
+func readChunkBasicHeader(r io.Reader) (chunkBasicHeader, error) {
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return chunkBasicHeader{}, err
+	}
+	return chunkBasicHeader{fmtType: b[0] >> 6, csID: uint32(b[0] & 0x3F)}, nil
+}
+
+// readChunkMessage reads one chunk's message header and body, returning the
+// RTMP message type ID and payload. It assumes a single-chunk message
+// (payload fits the default 128-byte chunk size is not enforced here
+// because encoders typically negotiate a larger chunk size up front).
+func readChunkMessage(r io.Reader, h chunkBasicHeader) (byte, []byte, error) {
+	var msgTypeID byte
+	var length int
+
+	switch h.fmtType {
+	case 0:
+		mh := make([]byte, 11)
+		if _, err := io.ReadFull(r, mh); err != nil {
+			return 0, nil, err
+		}
+		length = int(mh[3])<<16 | int(mh[4])<<8 | int(mh[5])
+		msgTypeID = mh[6]
+	case 1:
+		mh := make([]byte, 7)
+		if _, err := io.ReadFull(r, mh); err != nil {
+			return 0, nil, err
+		}
+		length = int(mh[3])<<16 | int(mh[4])<<8 | int(mh[5])
+		msgTypeID = mh[6]
+	default:
+		// Type 2/3 chunks reuse the previous header; without stream-level
+		// state tracking we can't recover length/type, so resync on the
+		// next basic header instead of misreading the stream.
+		return 0, nil, nil
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return msgTypeID, payload, nil
+}
@@ -0,0 +1,189 @@
+package ingest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/bridge"
+	"github.com/sirupsen/logrus"
+)
+
+// RTSPSource pulls an H.264-over-RTP stream from an RTSP server using TCP
+// interleaved transport (RFC 2326 §10.12), so no separate UDP ports need to
+// traverse NAT between kronop and the camera/broadcaster.
+type RTSPSource struct {
+	conn   net.Conn
+	rw     *bufio.ReadWriter
+	cseq   int
+	width  int
+	height int
+
+	sawKeyFrame bool
+}
+
+// DialRTSP connects to rtspURL, performs OPTIONS/DESCRIBE/SETUP/PLAY and
+// returns a FrameSource that yields NAL units as they arrive.
+func DialRTSP(rtspURL string) (*RTSPSource, error) {
+	u, err := url.Parse(rtspURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RTSP URL: %v", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", u.Host, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial RTSP server: %v", err)
+	}
+
+	s := &RTSPSource{
+		conn: conn,
+		rw:   bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)),
+	}
+
+	if err := s.request("OPTIONS", rtspURL, nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := s.request("DESCRIBE", rtspURL, map[string]string{"Accept": "application/sdp"}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := s.request("SETUP", rtspURL, map[string]string{"Transport": "RTP/AVP/TCP;unicast;interleaved=0-1"}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := s.request("PLAY", rtspURL, nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	logrus.Infof("📡 RTSP ingest connected: %s", rtspURL)
+	return s, nil
+}
+
+// request sends an RTSP request and reads/discards the response headers,
+// returning an error on a non-2xx status.
+func (s *RTSPSource) request(method, uri string, headers map[string]string) error {
+	s.cseq++
+	fmt.Fprintf(s.rw, "%s %s RTSP/1.0\r\n", method, uri)
+	fmt.Fprintf(s.rw, "CSeq: %d\r\n", s.cseq)
+	for k, v := range headers {
+		fmt.Fprintf(s.rw, "%s: %s\r\n", k, v)
+	}
+	fmt.Fprintf(s.rw, "\r\n")
+	if err := s.rw.Flush(); err != nil {
+		return fmt.Errorf("RTSP %s failed: %v", method, err)
+	}
+
+	statusLine, err := s.rw.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("RTSP %s: failed to read response: %v", method, err)
+	}
+	parts := strings.SplitN(strings.TrimSpace(statusLine), " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[1], "2") {
+		return fmt.Errorf("RTSP %s failed: %s", method, strings.TrimSpace(statusLine))
+	}
+
+	// Drain headers until the blank line
+	contentLength := 0
+	for {
+		line, err := s.rw.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("RTSP %s: failed to read headers: %v", method, err)
+		}
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		if strings.HasPrefix(line, "Content-Length:") {
+			fmt.Sscanf(strings.TrimPrefix(line, "Content-Length:"), "%d", &contentLength)
+		}
+	}
+	// The body (the SDP, for DESCRIBE) follows the blank line.
+	if contentLength > 0 {
+		body := make([]byte, contentLength)
+		io.ReadFull(s.rw, body)
+		s.parseSDP(string(body))
+	}
+	return nil
+}
+
+// parseSDP pulls the video dimensions out of an a=fmtp/a=framesize style SDP
+// body when the camera advertises them; width/height otherwise stay zero.
+// Next does not parse the SPS or an AVC sequence header, so they are not
+// filled in later from the stream.
+func (s *RTSPSource) parseSDP(sdp string) {
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "framesize") {
+			parts := strings.Fields(line)
+			for _, p := range parts {
+				if strings.Contains(p, "x") {
+					dims := strings.SplitN(p, ":", 2)
+					wh := dims[len(dims)-1]
+					var w, h int
+					if n, _ := fmt.Sscanf(wh, "%dx%d", &w, &h); n == 2 {
+						s.width, s.height = w, h
+					}
+				}
+			}
+		}
+	}
+}
+
+// Next reads the next interleaved RTP packet carrying video (channel 0) and
+// extracts its NAL payload, determining keyframe status from the NAL unit
+// type (5 = IDR slice, 7 = SPS which always precedes an IDR access unit).
+func (s *RTSPSource) Next() ([]byte, *bridge.FrameInfo, error) {
+	for {
+		marker, err := s.rw.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		if marker != '$' { // not an interleaved RTP frame, keep scanning
+			continue
+		}
+
+		header := make([]byte, 3)
+		if _, err := io.ReadFull(s.rw, header); err != nil {
+			return nil, nil, err
+		}
+		channel := header[0]
+		length := int(header[1])<<8 | int(header[2])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(s.rw, payload); err != nil {
+			return nil, nil, err
+		}
+		if channel != 0 || length < 13 {
+			continue // RTCP or a short RTP packet we can't parse a NAL from
+		}
+
+		nal := payload[12:] // skip the 12-byte RTP header
+		if len(nal) == 0 {
+			continue
+		}
+		nalType := nal[0] & 0x1F
+		isKeyFrame := nalType == 5 || nalType == 7
+		if isKeyFrame {
+			s.sawKeyFrame = true
+		}
+
+		info := &bridge.FrameInfo{
+			Width:      s.width,
+			Height:     s.height,
+			Format:     "h264",
+			Timestamp:  time.Now().Unix(),
+			IsKeyFrame: isKeyFrame,
+		}
+		return nal, info, nil
+	}
+}
+
+// Close terminates the RTSP session's underlying TCP connection.
+func (s *RTSPSource) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,5 @@
+// Package ingest provides pluggable live-feed frame sources (RTSP, RTMP)
+// that implement bridge.FrameSource so they can be attached to a
+// bridge.CppBridge via AttachSource in place of the prefetcher's own
+// chunked VOD pipeline.
+package ingest
@@ -0,0 +1,118 @@
+// Package metrics exposes a shared prometheus.Registerer populated by the
+// bridge and fetcher packages, plus the /healthz and /metrics HTTP
+// handlers operators poll instead of grepping logrus output.
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics bundles every counter/gauge/histogram the bridge and fetcher
+// packages record against, all registered on a single Registry so one
+// /metrics endpoint covers both.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	// CppBridge / RustBridge
+	RequestLatency  *prometheus.HistogramVec // labels: type
+	FramePushes     *prometheus.CounterVec   // labels: reel_id, result
+	EngineConnected *prometheus.GaugeVec     // labels: engine
+
+	// VideoFetcher
+	SourceThroughput *prometheus.GaugeVec     // labels: source
+	ChunkDuration    *prometheus.HistogramVec // labels: source
+
+	// safeGo-supervised goroutines (bridge package)
+	WorkerPanics *prometheus.CounterVec // labels: worker
+
+	ready int32 // atomic bool: first successful Connect + HealthCheck
+}
+
+// New creates a Metrics bundle with all collectors registered.
+func New() *Metrics {
+	reg := prometheus.NewRegistry()
+
+	m := &Metrics{
+		Registry: reg,
+		RequestLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "kronop",
+			Name:      "bridge_request_duration_seconds",
+			Help:      "Latency of sendRequest calls to the C++/Rust engines, by request type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		FramePushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Name:      "bridge_frame_pushes_total",
+			Help:      "Frame pushes to the display engine, by reel and result.",
+		}, []string{"reel_id", "result"}),
+		EngineConnected: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "kronop",
+			Name:      "bridge_engine_connected",
+			Help:      "1 if the bridge is currently connected to its engine, else 0.",
+		}, []string{"engine"}),
+		SourceThroughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "kronop",
+			Name:      "fetcher_source_throughput_bytes_per_second",
+			Help:      "EWMA throughput per download source URL.",
+		}, []string{"source"}),
+		ChunkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "kronop",
+			Name:      "fetcher_chunk_download_duration_seconds",
+			Help:      "Chunk download duration, bucketed by source URL.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source"}),
+		WorkerPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Name:      "bridge_worker_panics_total",
+			Help:      "Panics recovered from safeGo-supervised goroutines, by worker name.",
+		}, []string{"worker"}),
+	}
+
+	reg.MustRegister(m.RequestLatency, m.FramePushes, m.EngineConnected, m.SourceThroughput, m.ChunkDuration, m.WorkerPanics)
+	return m
+}
+
+// SetReady flips the readiness probe on, normally called after the first
+// successful Connect() and HealthCheck().
+func (m *Metrics) SetReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&m.ready, 1)
+	} else {
+		atomic.StoreInt32(&m.ready, 0)
+	}
+}
+
+func (m *Metrics) IsReady() bool {
+	return atomic.LoadInt32(&m.ready) == 1
+}
+
+// Handler returns an http.Handler serving /metrics and /healthz on its
+// own ServeMux, for callers to attach to their own listener/port.
+func (m *Metrics) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", m.handleHealthz)
+	return mux
+}
+
+// handleHealthz returns 503 until Connect() has succeeded and the first
+// HealthCheck has completed, so orchestrators don't route traffic to a
+// bridge that hasn't proven it can reach its engine yet.
+func (m *Metrics) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if !m.IsReady() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// ListenAndServe starts serving the metrics/health mux on addr; call in a
+// goroutine alongside MonitorConnection.
+func (m *Metrics) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, m.Handler())
+}
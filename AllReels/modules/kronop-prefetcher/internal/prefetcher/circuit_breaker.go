@@ -0,0 +1,152 @@
+package prefetcher
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is one of the three states an originCircuitBreaker can be
+// in, following the standard closed/open/half-open circuit breaker model.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through, counting consecutive
+	// failures toward CircuitBreakerConfig.FailureThreshold.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request until CooldownPeriod elapses,
+	// at which point the breaker moves to CircuitHalfOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through to test
+	// whether the origin has recovered; success closes the breaker,
+	// failure reopens it and restarts the cooldown.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures every originCircuitBreaker an
+// OriginRegistry creates. Rates/counts are per-origin, not global - one
+// degraded CDN edge shouldn't trip the breaker for every other origin.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many consecutive failures in CircuitClosed
+	// trip the breaker to CircuitOpen.
+	FailureThreshold int
+	// CooldownPeriod is how long CircuitOpen rejects requests before
+	// moving to CircuitHalfOpen to probe the origin again.
+	CooldownPeriod time.Duration
+}
+
+// defaultCircuitBreakerConfig is what newOriginRegistry falls back to
+// when given a zero-value CircuitBreakerConfig.
+var defaultCircuitBreakerConfig = CircuitBreakerConfig{
+	FailureThreshold: 5,
+	CooldownPeriod:   30 * time.Second,
+}
+
+// originCircuitBreaker is one origin's closed/open/half-open state
+// machine, guarding fetchVideoData against hammering a degraded CDN
+// origin with retries.
+type originCircuitBreaker struct {
+	mu sync.Mutex
+
+	config CircuitBreakerConfig
+
+	state               CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	// probeInFlight is true while CircuitHalfOpen's single allowed probe
+	// is outstanding, so concurrent callers don't all get let through at
+	// once the instant the cooldown elapses.
+	probeInFlight bool
+}
+
+func newOriginCircuitBreaker(config CircuitBreakerConfig) *originCircuitBreaker {
+	return &originCircuitBreaker{config: config, state: CircuitClosed}
+}
+
+// Allow reports whether a request may proceed right now, transitioning
+// CircuitOpen to CircuitHalfOpen if CooldownPeriod has elapsed.
+func (b *originCircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.config.CooldownPeriod {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probeInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess reports that a request Allow let through succeeded,
+// closing the breaker (from CircuitHalfOpen) or just resetting the
+// failure count (from CircuitClosed).
+func (b *originCircuitBreaker) RecordSuccess() (transitioned bool, newState CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures = 0
+	if b.state != CircuitClosed {
+		b.state = CircuitClosed
+		b.probeInFlight = false
+		return true, CircuitClosed
+	}
+	return false, CircuitClosed
+}
+
+// RecordFailure reports that a request Allow let through failed, tripping
+// CircuitClosed to CircuitOpen once FailureThreshold is reached, or
+// reopening immediately from a failed CircuitHalfOpen probe.
+func (b *originCircuitBreaker) RecordFailure() (transitioned bool, newState CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		b.probeInFlight = false
+		return true, CircuitOpen
+	case CircuitClosed:
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= b.config.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+			return true, CircuitOpen
+		}
+		return false, CircuitClosed
+	default:
+		return false, b.state
+	}
+}
+
+// State returns the breaker's current state without side effects.
+func (b *originCircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,130 @@
+package prefetcher
+
+import (
+	"testing"
+	"time"
+)
+
+// TestOriginCircuitBreakerCascadingFailures simulates an origin that fails
+// FailureThreshold times in a row: Allow must keep letting requests through
+// up to the threshold (so a single blip doesn't trip the breaker), then
+// reject every request once tripped, then allow exactly one probe after
+// CooldownPeriod elapses and close again on that probe's success.
+func TestOriginCircuitBreakerCascadingFailures(t *testing.T) {
+	b := newOriginCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, CooldownPeriod: 20 * time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("Allow() = false before FailureThreshold reached (failure %d)", i)
+		}
+		transitioned, state := b.RecordFailure()
+		if i < 2 {
+			if transitioned {
+				t.Fatalf("RecordFailure() transitioned early on failure %d", i)
+			}
+		} else if !transitioned || state != CircuitOpen {
+			t.Fatalf("RecordFailure() on the 3rd failure = (%v, %v), want (true, CircuitOpen)", transitioned, state)
+		}
+	}
+
+	if b.Allow() {
+		t.Fatal("Allow() = true while circuit is open")
+	}
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("State() = %v, want CircuitOpen", got)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatal("Allow() = false after CooldownPeriod elapsed, want a probe to be let through")
+	}
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("State() = %v after cooldown, want CircuitHalfOpen", got)
+	}
+	if b.Allow() {
+		t.Fatal("Allow() = true for a second concurrent probe while one is already in flight")
+	}
+
+	transitioned, state := b.RecordSuccess()
+	if !transitioned || state != CircuitClosed {
+		t.Fatalf("RecordSuccess() after a successful probe = (%v, %v), want (true, CircuitClosed)", transitioned, state)
+	}
+}
+
+// TestOriginCircuitBreakerHalfOpenProbeFailureReopens checks that a failed
+// half-open probe reopens the circuit and restarts the cooldown, rather
+// than falling back to counting consecutive failures again.
+func TestOriginCircuitBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	b := newOriginCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, CooldownPeriod: 10 * time.Millisecond})
+
+	b.Allow()
+	b.RecordFailure()
+	time.Sleep(15 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatal("Allow() = false for the half-open probe")
+	}
+	transitioned, state := b.RecordFailure()
+	if !transitioned || state != CircuitOpen {
+		t.Fatalf("RecordFailure() on a failed probe = (%v, %v), want (true, CircuitOpen)", transitioned, state)
+	}
+	if b.Allow() {
+		t.Fatal("Allow() = true immediately after a failed probe reopened the circuit")
+	}
+}
+
+// TestOriginRegistryIsolatesOrigins verifies that tripping one origin's
+// breaker via cascading failures doesn't affect a second, healthy origin -
+// the whole point of tracking health per-origin instead of globally.
+func TestOriginRegistryIsolatesOrigins(t *testing.T) {
+	reg := newOriginRegistry(CircuitBreakerConfig{FailureThreshold: 2, CooldownPeriod: time.Minute})
+
+	bad := "https://bad.cdn.kronop.com"
+	good := "https://good.cdn.kronop.com"
+
+	for i := 0; i < 2; i++ {
+		if !reg.Allow(bad) {
+			t.Fatalf("Allow(bad) = false before it tripped (failure %d)", i)
+		}
+		reg.RecordFailure(bad)
+	}
+
+	if reg.Allow(bad) {
+		t.Fatal("Allow(bad) = true after cascading failures should have tripped its breaker")
+	}
+	if !reg.IsOpen(bad) {
+		t.Fatal("IsOpen(bad) = false after cascading failures should have tripped its breaker")
+	}
+	if !reg.Allow(good) {
+		t.Fatal("Allow(good) = false: a failing origin must not affect an unrelated origin")
+	}
+	if reg.IsOpen(good) {
+		t.Fatal("IsOpen(good) = true: a failing origin must not affect an unrelated origin")
+	}
+}
+
+// TestOriginRegistryAdaptiveTimeout checks that AdaptiveTimeout falls back
+// to the configured timeout until enough samples accumulate, then derives
+// a p95-based timeout from recorded successes.
+func TestOriginRegistryAdaptiveTimeout(t *testing.T) {
+	reg := newOriginRegistry(defaultCircuitBreakerConfig)
+	origin := "https://cdn.kronop.com"
+	fallback := 5 * time.Second
+
+	if got := reg.AdaptiveTimeout(origin, fallback); got != fallback {
+		t.Fatalf("AdaptiveTimeout() with no samples = %v, want fallback %v", got, fallback)
+	}
+
+	for i := 0; i < minSamplesForAdaptiveTimeout; i++ {
+		reg.RecordSuccess(origin, 100*time.Millisecond)
+	}
+
+	got := reg.AdaptiveTimeout(origin, fallback)
+	if got == fallback {
+		t.Fatal("AdaptiveTimeout() still returned fallback after enough samples were recorded")
+	}
+	if got <= 0 {
+		t.Fatalf("AdaptiveTimeout() = %v, want a positive duration derived from recorded samples", got)
+	}
+}
@@ -4,27 +4,55 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"github.com/patrickmn/go-cache"
+	"github.com/kronop/prefetcher/internal/usagestats"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Engine represents the main prefetching engine
 type Engine struct {
 	config       *Config
 	analyzer     BehaviorAnalyzer
-	cache        *cache.Cache
-	rateLimiter  *rate.Limiter
+	videoFetcher VideoFetcher
+	segmentCache *segmentCache
+	rateLimiter  RateLimiter
 	activeUsers  *sync.Map
-	metrics      *Metrics
+	metrics      *engineMetrics
+	webhooks     *webhookBus
+	origins      *OriginRegistry
 	httpServer   *http.Server
 	wsUpgrader   websocket.Upgrader
+
+	// networkConditions tallies RecordNetworkCondition calls by condition
+	// label, backing NetworkConditionHistogram for the usagestats.Source
+	// interface. There's no other network-condition tracking in this
+	// engine yet, so this is purely additive.
+	networkConditions sync.Map
+
+	// handlerSem bounds how many HTTP API requests run concurrently,
+	// sized from Config.Workers (itself derived from the container's
+	// cgroup CPU quota - see internal/runtimecfg). Acquired by
+	// limitConcurrency around each mux handler.
+	handlerSem chan struct{}
+
+	// store persists sessions and behavior profiles through
+	// AddUser/UpdateUserBehavior/performCleanup/Shutdown - see store.go.
+	// Defaults to an in-memory store with no durability; NewEngine picks
+	// a Pebble-backed one instead when Config.StoreBackend is "pebble".
+	store SessionStore
 }
 
 // Config holds the engine configuration
@@ -36,17 +64,70 @@ type Config struct {
 	PrefetchTimeout        time.Duration `yaml:"prefetch_timeout"`
 	RetryAttempts          int           `yaml:"retry_attempts"`
 	RetryDelay             time.Duration `yaml:"retry_delay"`
+	// CacheSizeMB bounds the on-disk segment cache (see segment_cache.go)
+	// by total byte size, evicting least-recently-used segments once
+	// exceeded - NewEngineWithStore used to instead pass this into
+	// go-cache as a TTL in all but name, which couldn't bound memory use
+	// by size at all. Defaults to defaultCacheSizeMB.
 	CacheSizeMB            int           `yaml:"cache_size_mb"`
-	CacheTTL               time.Duration `yaml:"cache_ttl"`
 	BackgroundProcessing   bool          `yaml:"background_processing"`
 	ProcessingInterval     time.Duration `yaml:"processing_interval"`
+	UsageStats             usagestats.Config `yaml:"usage_stats"`
+
+	// Workers caps concurrent HTTP API requests and is also used as the
+	// default for MaxConcurrentPrefetches when that's left unset. Callers
+	// running under a container CPU quota should set this from
+	// runtimecfg.ApplyGOMAXPROCS's return value; 0 falls back to
+	// runtime.NumCPU().
+	Workers int `yaml:"workers"`
+
+	// WebSocketReadBufferSize and WebSocketWriteBufferSize size the
+	// Upgrader's I/O buffers; WebSocketMaxMessageSize caps a single
+	// incoming frame via conn.SetReadLimit. All three default to
+	// defaultWSBufferSize (1 MB) so batched UserBehaviorData - easily
+	// over 64 KB with a few hundred ScrollEvents - doesn't get silently
+	// dropped by gorilla/websocket's default 4 KB buffers.
+	WebSocketReadBufferSize  int `yaml:"websocket_read_buffer_size"`
+	WebSocketWriteBufferSize int `yaml:"websocket_write_buffer_size"`
+	WebSocketMaxMessageSize  int64 `yaml:"websocket_max_message_size"`
+
+	// StoreBackend selects the SessionStore NewEngine constructs:
+	// "memory" (the default) or "pebble" (durable, at StorePath). For
+	// MySQL/Postgres, open a *sql.DB with the driver of your choice and
+	// construct the engine with NewEngineWithStore(config, analyzer,
+	// store) and a NewSQLSessionStore instead.
+	StoreBackend string `yaml:"store_backend"`
+
+	// StorePath is the Pebble database directory, used only when
+	// StoreBackend is "pebble".
+	StorePath string `yaml:"store_path"`
+
+	// SegmentCachePath is the on-disk directory the segment cache (see
+	// segment_cache.go) is rooted at. Defaults to defaultSegmentCacheDir.
+	SegmentCachePath string `yaml:"segment_cache_path"`
+
+	// RateLimit configures the global and per-user token buckets
+	// NewEngineWithStore builds its RateLimiter from. Zero fields fall
+	// back to defaultGlobalRateLimit/defaultPerUserRateLimit and friends
+	// (see rate_limiter.go).
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// CircuitBreaker configures the per-origin circuit breaker
+	// fetchVideoData runs behind (see origin_registry.go and
+	// circuit_breaker.go). Zero fields fall back to
+	// defaultCircuitBreakerConfig.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
 }
 
+// defaultWSBufferSize is the floor NewEngine applies to every
+// WebSocket buffer/message-size config field left at its zero value.
+const defaultWSBufferSize = 1 << 20
+
 // UserSession represents an active user session
 type UserSession struct {
 	ID              string
 	CurrentReel     int
-	ScrollSpeed     float
+	ScrollSpeed     float64
 	WatchTime       time.Duration
 	LastActivity    time.Time
 	PrefetchQueue    chan PrefetchTask
@@ -62,6 +143,22 @@ type PrefetchTask struct {
 	Timeout    time.Duration
 	RetryCount int
 	CreatedAt  time.Time
+
+	// ManifestType selects how fetchSegments interprets URL: an HLS/DASH
+	// manifest to parse renditions/segments from, or ManifestProgressive
+	// (or the empty zero value, treated the same) for a single whole-file
+	// GET.
+	ManifestType ManifestType
+
+	// SegmentRange is the byte range fetchSegments requests for each
+	// selected segment, derived from the chosen segmentBudget and
+	// rendition's Bitrate - see segmentRangeFor. Unused for progressive
+	// tasks.
+	SegmentRange SegmentRange
+
+	// Bitrate records the rendition fetchSegments selected, in bits/sec,
+	// for logging/observability once selection has happened.
+	Bitrate int
 }
 
 // Priority represents task priority
@@ -84,36 +181,114 @@ type BehaviorProfile struct {
 	LastUpdated      time.Time `json:"last_updated"`
 }
 
-// Metrics holds engine metrics
-type Metrics struct {
-	TotalPrefetches     int64     `json:"total_prefetches"`
-	SuccessfulPrefetches int64   `json:"successful_prefetches"`
-	FailedPrefetches    int64     `json:"failed_prefetches"`
-	CacheHits           int64     `json:"cache_hits"`
-	CacheMisses         int64     `json:"cache_misses"`
-	AvgResponseTime     time.Duration `json:"avg_response_time"`
-	ActiveUsers         int       `json:"active_users"`
-	mu                  sync.RWMutex
+// NewEngine creates a new prefetching engine with the SessionStore
+// selected by config.StoreBackend ("memory" by default, or "pebble" at
+// config.StorePath). If opening that store fails, or StoreBackend names
+// something NewEngine doesn't know how to open itself (e.g. "sql"), it
+// logs the error and falls back to an in-memory store rather than
+// failing construction outright - see NewEngineWithStore to pass an
+// already-built store (e.g. NewSQLSessionStore) instead.
+func NewEngine(config Config, analyzer BehaviorAnalyzer) *Engine {
+	store, err := newConfiguredSessionStore(config)
+	if err != nil {
+		logrus.Errorf("❌ failed to open %q session store, falling back to in-memory: %v", config.StoreBackend, err)
+		store = newMemorySessionStore()
+	}
+	return NewEngineWithStore(config, analyzer, store)
 }
 
-// NewEngine creates a new prefetching engine
-func NewEngine(config Config, analyzer BehaviorAnalyzer) *Engine {
-	cache := cache.New(config.CacheTTL, config.CacheSizeMB*1024*1024)
-	
-	// Create rate limiter (100 requests per second)
-	rateLimiter := rate.NewLimiter(rate.Limit(100), 10)
+// newConfiguredSessionStore builds the SessionStore config.StoreBackend
+// names. It only knows the backends NewEngine can fully construct from
+// Config alone; "sql" isn't one of them since it needs a *sql.DB the
+// caller must open with its own driver - use NewSQLSessionStore and
+// NewEngineWithStore for that.
+func newConfiguredSessionStore(config Config) (SessionStore, error) {
+	switch config.StoreBackend {
+	case "", "memory":
+		return newMemorySessionStore(), nil
+	case "pebble":
+		return NewPebbleSessionStore(config.StorePath)
+	default:
+		return nil, fmt.Errorf("unknown session store backend %q", config.StoreBackend)
+	}
+}
+
+// NewEngineWithStore is NewEngine but with an explicit SessionStore,
+// for backends NewEngine can't build from Config alone - e.g. a MySQL or
+// Postgres *sql.DB the caller already opened with its driver, wrapped in
+// NewSQLSessionStore.
+func NewEngineWithStore(config Config, analyzer BehaviorAnalyzer, store SessionStore) *Engine {
+	if config.CacheSizeMB <= 0 {
+		config.CacheSizeMB = defaultCacheSizeMB
+	}
+	if config.SegmentCachePath == "" {
+		config.SegmentCachePath = defaultSegmentCacheDir
+	}
+	segCache, err := newSegmentCache(config.SegmentCachePath, int64(config.CacheSizeMB)*1024*1024)
+	if err != nil {
+		logrus.Errorf("❌ failed to open segment cache at %q, prefetched segments won't be cached: %v", config.SegmentCachePath, err)
+	}
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if config.MaxConcurrentPrefetches <= 0 {
+		config.MaxConcurrentPrefetches = workers
+	}
+
+	if config.WebSocketReadBufferSize <= 0 {
+		config.WebSocketReadBufferSize = defaultWSBufferSize
+	}
+	if config.WebSocketWriteBufferSize <= 0 {
+		config.WebSocketWriteBufferSize = defaultWSBufferSize
+	}
+	if config.WebSocketMaxMessageSize <= 0 {
+		config.WebSocketMaxMessageSize = defaultWSBufferSize
+	}
+
+	if config.RateLimit.GlobalRate <= 0 {
+		config.RateLimit.GlobalRate = defaultGlobalRateLimit
+	}
+	if config.RateLimit.GlobalBurst <= 0 {
+		config.RateLimit.GlobalBurst = defaultGlobalRateLimitBurst
+	}
+	if config.RateLimit.PerUserRate <= 0 {
+		config.RateLimit.PerUserRate = defaultPerUserRateLimit
+	}
+	if config.RateLimit.PerUserBurst <= 0 {
+		config.RateLimit.PerUserBurst = defaultPerUserRateLimitBurst
+	}
+	rateLimiter := newInMemoryRateLimiter(config.RateLimit)
 
 	return &Engine{
-		config:      &config,
-		analyzer:    analyzer,
-		cache:       cache,
-		rateLimiter: rateLimiter,
-		activeUsers: &sync.Map{},
-		metrics:     &Metrics{},
+		config:       &config,
+		analyzer:     analyzer,
+		videoFetcher: newHTTPVideoFetcher(config.PrefetchTimeout),
+		segmentCache: segCache,
+		rateLimiter:  rateLimiter,
+		activeUsers:  &sync.Map{},
+		metrics:      newEngineMetrics(),
+		webhooks:     newWebhookBus(),
+		origins:      newOriginRegistry(config.CircuitBreaker),
+		store:        store,
 		wsUpgrader: websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
+			ReadBufferSize:  config.WebSocketReadBufferSize,
+			WriteBufferSize: config.WebSocketWriteBufferSize,
 		},
+		handlerSem: make(chan struct{}, workers),
+	}
+}
+
+// limitConcurrency wraps next so that at most cap(e.handlerSem) calls to
+// it run at once; excess requests block until a slot frees up, bounding
+// HTTP handler concurrency to the same worker count MaxConcurrentPrefetches
+// defaults to.
+func (e *Engine) limitConcurrency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e.handlerSem <- struct{}{}
+		defer func() { <-e.handlerSem }()
+		next(w, r)
 	}
 }
 
@@ -121,6 +296,10 @@ func NewEngine(config Config, analyzer BehaviorAnalyzer) *Engine {
 func (e *Engine) Start(ctx context.Context) error {
 	logrus.Info("🚀 Starting Kronop Prefetcher Engine")
 
+	if err := e.Rehydrate(); err != nil {
+		logrus.Errorf("❌ failed to rehydrate sessions from store: %v", err)
+	}
+
 	// Start background processing
 	if e.config.BackgroundProcessing {
 		go e.backgroundProcessor(ctx)
@@ -136,16 +315,49 @@ func (e *Engine) Start(ctx context.Context) error {
 	return nil
 }
 
+// Rehydrate loads every session persisted in e.store back into
+// activeUsers, so a restart picks up each user's learned BehaviorProfile
+// and queued prefetch tasks instead of starting back at "unknown".
+// Called automatically from Start.
+func (e *Engine) Rehydrate() error {
+	sessions, err := e.store.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot session store: %v", err)
+	}
+
+	for _, stored := range sessions {
+		session := newSessionFromStored(stored, defaultPrefetchQueueSize)
+		e.activeUsers.Store(stored.ID, session)
+	}
+
+	if len(sessions) > 0 {
+		e.metrics.activeUsers.Set(float64(len(sessions)))
+	}
+
+	logrus.Infof("♻️ Rehydrated %d user session(s) from session store", len(sessions))
+	return nil
+}
+
 // StartHTTPServer starts the HTTP API server
 func (e *Engine) StartHTTPServer(ctx context.Context, port int) error {
 	mux := http.NewServeMux()
 	
-	// API endpoints
-	mux.HandleFunc("/api/v1/prefetch", e.handlePrefetch)
-	mux.HandleFunc("/api/v1/user", e.handleUser)
-	mux.HandleFunc("/api/v1/metrics", e.handleMetrics)
+	// API endpoints - wrapped in limitConcurrency so request handling
+	// scales with Config.Workers rather than the host's full core count.
+	mux.HandleFunc("/api/v1/prefetch", e.limitConcurrency(e.handlePrefetch))
+	mux.HandleFunc("/api/v1/user", e.limitConcurrency(e.handleUser))
 	mux.HandleFunc("/api/v1/health", e.handleHealth)
-	
+
+	// Prometheus scrape endpoint, replacing the old JSON /api/v1/metrics.
+	mux.Handle("/metrics", e.metrics.Handler())
+
+	// Webhook subscription management and a synthetic-event test endpoint.
+	mux.HandleFunc("/api/v1/webhooks", e.limitConcurrency(e.handleWebhooks))
+	mux.HandleFunc("/api/v1/webhooks/test", e.limitConcurrency(e.handleWebhookTest))
+
+	// Per-origin circuit breaker health.
+	mux.HandleFunc("/api/v1/origins", e.limitConcurrency(e.handleOrigins))
+
 	// WebSocket endpoint for real-time communication
 	mux.HandleFunc("/ws", e.handleWebSocket)
 
@@ -191,6 +403,9 @@ func (e *Engine) backgroundProcessor(ctx context.Context) {
 
 // processBackgroundTasks processes all active user prefetching tasks
 func (e *Engine) processBackgroundTasks(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "prefetcher.processBackgroundTasks")
+	defer span.End()
+
 	e.activeUsers.Range(func(key, value interface{}) bool {
 		userID := key.(string)
 		session := value.(*UserSession)
@@ -217,7 +432,8 @@ func (e *Engine) processPrefetchQueue(ctx context.Context, session *UserSession)
 	for i := 0; i < e.config.MaxConcurrentPrefetches && len(session.PrefetchQueue) > 0; i++ {
 		select {
 		case task := <-session.PrefetchQueue:
-			go e.executePrefetchTask(ctx, session, task)
+			userType := session.BehaviorProfile.UserType
+			go e.executePrefetchTask(ctx, session, userType, task)
 		default:
 			return
 		}
@@ -225,37 +441,68 @@ func (e *Engine) processPrefetchQueue(ctx context.Context, session *UserSession)
 }
 
 // executePrefetchTask executes a single prefetching task
-func (e *Engine) executePrefetchTask(ctx context.Context, session *UserSession, task PrefetchTask) {
+func (e *Engine) executePrefetchTask(ctx context.Context, session *UserSession, userType string, task PrefetchTask) {
+	ctx, span := tracer.Start(ctx, "prefetcher.executePrefetchTask", trace.WithAttributes(
+		attribute.Int("reel_id", task.ReelID),
+		attribute.Int("priority", int(task.Priority)),
+	))
+	defer span.End()
+
 	startTime := time.Now()
-	
+
 	logrus.Debugf("🎯 Executing prefetch task: reel=%d, priority=%d", task.ReelID, task.Priority)
 
-	// Check rate limiter
-	if !e.rateLimiter.Allow() {
-		logrus.Warn("🚫 Rate limit exceeded, delaying prefetch")
-		time.Sleep(100 * time.Millisecond)
+	// Block on the global and per-user token buckets instead of a flat
+	// 100ms sleep, bounded by the task's own timeout so a starved user
+	// doesn't hold a goroutine open indefinitely.
+	waitCtx, cancel := context.WithTimeout(ctx, task.Timeout)
+	err := e.rateLimiter.Wait(waitCtx, session.ID, userType)
+	cancel()
+	if err != nil {
+		logrus.Warnf("🚫 Rate limit wait failed for reel %d: %v", task.ReelID, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		e.metrics.prefetchFailures.Inc()
+		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("reel_%d", task.ReelID)
-	if cached, found := e.cache.Get(cacheKey); found {
-		logrus.Debugf("💾 Cache hit for reel %d", task.ReelID)
-		e.metrics.mu.Lock()
-		e.metrics.CacheHits++
-		e.metrics.mu.Unlock()
-		return
+	publishPrefetchFailed := func(err error) {
+		data, _ := json.Marshal(PrefetchFailedData{UserID: session.ID, ReelID: task.ReelID, Error: err.Error()})
+		e.webhooks.Publish(WebhookEvent{Type: EventPrefetchFailed, Timestamp: time.Now(), Data: data})
+	}
+
+	budget := segmentBudgetFor(userType)
+	renditionLabel := renditionLabelFor(budget)
+
+	// Check cache first, keyed by the rendition this budget would pick -
+	// a different user type prefetching the same reel at a different
+	// quality tier is a separate cache entry.
+	cacheKey := segmentKey{ReelID: task.ReelID, Rendition: renditionLabel, SegmentIndex: 0}
+	if e.segmentCache != nil {
+		if _, found := e.segmentCache.Get(cacheKey); found {
+			logrus.Debugf("💾 Cache hit for reel %d rendition %s", task.ReelID, renditionLabel)
+			e.metrics.cacheHits.Inc()
+			return
+		}
 	}
 
 	// Fetch from source
-	data, err := e.fetchVideoData(ctx, task.URL)
+	rng, bitrate, err := e.fetchSegments(ctx, task, budget, renditionLabel)
+	task.SegmentRange, task.Bitrate = rng, bitrate
+	responseTime := time.Since(startTime)
+	e.metrics.responseTime.Observe(responseTime.Seconds())
+
 	if err != nil {
 		logrus.Errorf("❌ Failed to fetch reel %d: %v", task.ReelID, err)
-		
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		publishPrefetchFailed(err)
+
 		// Retry logic
 		if task.RetryCount < e.config.RetryAttempts {
 			task.RetryCount++
 			task.CreatedAt = time.Now().Add(e.config.RetryDelay)
-			
+
 			// Re-queue with delay
 			go func() {
 				time.Sleep(e.config.RetryDelay)
@@ -269,37 +516,53 @@ func (e *Engine) executePrefetchTask(ctx context.Context, session *UserSession,
 			}()
 		}
 
-		e.metrics.mu.Lock()
-		e.metrics.FailedPrefetches++
-		e.metrics.mu.Unlock()
+		e.metrics.prefetchFailures.Inc()
 		return
 	}
 
-	// Store in cache
-	e.cache.Set(cacheKey, data, e.config.CacheTTL)
-	
-	// Update metrics
-	responseTime := time.Since(startTime)
-	e.metrics.mu.Lock()
-	e.metrics.SuccessfulPrefetches++
-	e.metrics.TotalPrefetches++
-	// Update average response time
-	if e.metrics.AvgResponseTime == 0 {
-		e.metrics.AvgResponseTime = responseTime
-	} else {
-		e.metrics.AvgResponseTime = (e.metrics.AvgResponseTime + responseTime) / 2
-	}
-	e.metrics.CacheMisses++
-	e.metrics.mu.Unlock()
+	e.metrics.prefetchTotal.Inc()
+
+	logrus.Debugf("✅ Successfully prefetched reel %d (%s, %d bps) in %v", task.ReelID, renditionLabel, task.Bitrate, responseTime)
+
+	successData, _ := json.Marshal(PrefetchSuccessData{
+		UserID:       session.ID,
+		ReelID:       task.ReelID,
+		ResponseTime: responseTime.Seconds(),
+	})
+	e.webhooks.Publish(WebhookEvent{Type: EventPrefetchSuccess, Timestamp: time.Now(), Data: successData})
+}
 
-	logrus.Debugf("✅ Successfully prefetched reel %d in %v", task.ReelID, responseTime)
+// recordCircuitTransition increments circuitTransitions when a
+// RecordSuccess/RecordFailure call reports the breaker actually changed
+// state, so the counter tracks transitions rather than every call.
+func (e *Engine) recordCircuitTransition(origin string, transitioned bool, state CircuitState) {
+	if transitioned {
+		logrus.Warnf("🔌 Origin %s circuit breaker -> %s", origin, state)
+		e.metrics.circuitTransitions.WithLabelValues(origin, state.String()).Inc()
+	}
 }
 
-// fetchVideoData fetches video data from the source
+// fetchVideoData fetches video data from the source, behind origin's
+// circuit breaker: a request is rejected outright with no HTTP call at
+// all while the origin's breaker is open, so a degraded CDN origin
+// doesn't get hammered by every retry/re-queue executePrefetchTask issues.
 func (e *Engine) fetchVideoData(ctx context.Context, url string) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "prefetcher.fetchVideoData", trace.WithAttributes(attribute.String("url", url)))
+	defer span.End()
+
+	origin := originOf(url)
+	if !e.origins.Allow(origin) {
+		err := fmt.Errorf("circuit breaker open for origin %s", origin)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	// Create HTTP request with timeout
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
@@ -307,30 +570,112 @@ func (e *Engine) fetchVideoData(ctx context.Context, url string) ([]byte, error)
 	req.Header.Set("User-Agent", "Kronop-Prefetcher/1.0")
 	req.Header.Set("Accept", "application/octet-stream")
 
-	// Make request
+	// Propagate trace context to whatever CDN/origin url points at, so a
+	// Tempo/Jaeger trace spans the prefetcher and the fetch it triggered.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	// Make request, with an adaptive timeout derived from this origin's
+	// own rolling p95 response time once enough samples exist, instead of
+	// the one flat PrefetchTimeout for every origin regardless of how fast
+	// or slow it normally responds.
 	client := &http.Client{
-		Timeout: e.config.PrefetchTimeout,
+		Timeout: e.origins.AdaptiveTimeout(origin, e.config.PrefetchTimeout),
 	}
 
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
+		transitioned, state := e.origins.RecordFailure(origin)
+		e.recordCircuitTransition(origin, transitioned, state)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		transitioned, state := e.origins.RecordFailure(origin)
+		e.recordCircuitTransition(origin, transitioned, state)
+		err := fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
+	transitioned, state := e.origins.RecordSuccess(origin, time.Since(start))
+	e.recordCircuitTransition(origin, transitioned, state)
+
 	// Read response body
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
 	return data, nil
 }
 
+// fetchSegments fetches task's video according to budget's quality tier,
+// storing the result in e.segmentCache keyed by (task.ReelID,
+// renditionLabel, segment index) and returning the selected rendition's
+// bitrate (0 for progressive or an unadvertised one) plus the byte range
+// requested for the first segment. For task.ManifestType ==
+// ManifestProgressive or the empty zero value it falls back to fetchVideoData's
+// original whole-file GET, stored as segment index 0.
+func (e *Engine) fetchSegments(ctx context.Context, task PrefetchTask, budget segmentBudget, renditionLabel string) (SegmentRange, int, error) {
+	if task.ManifestType == ManifestProgressive || task.ManifestType == "" {
+		data, err := e.fetchVideoData(ctx, task.URL)
+		if err != nil {
+			return SegmentRange{}, 0, err
+		}
+		key := segmentKey{ReelID: task.ReelID, Rendition: renditionLabel, SegmentIndex: 0}
+		if e.segmentCache != nil {
+			if err := e.segmentCache.Put(key, data); err != nil {
+				return SegmentRange{}, 0, err
+			}
+		}
+		return SegmentRange{}, 0, nil
+	}
+
+	manifest, err := e.videoFetcher.FetchManifest(ctx, task.URL, task.ManifestType)
+	if err != nil {
+		return SegmentRange{}, 0, err
+	}
+
+	var rendition Rendition
+	var ok bool
+	if budget.useHighestBitrate {
+		rendition, ok = manifest.highestBitrate()
+	} else {
+		rendition, ok = manifest.lowestBitrate()
+	}
+	if !ok {
+		return SegmentRange{}, 0, fmt.Errorf("manifest for reel %d has no renditions", task.ReelID)
+	}
+
+	segmentCount := budget.segmentCount
+	if segmentCount > len(rendition.SegmentURLs) {
+		segmentCount = len(rendition.SegmentURLs)
+	}
+
+	rng := segmentRangeFor(rendition.Bitrate, budget.rangeSeconds)
+
+	for i := 0; i < segmentCount; i++ {
+		data, err := e.videoFetcher.FetchSegmentRange(ctx, rendition.SegmentURLs[i], rng)
+		if err != nil {
+			return rng, rendition.Bitrate, fmt.Errorf("segment %d: %v", i, err)
+		}
+		if e.segmentCache != nil {
+			key := segmentKey{ReelID: task.ReelID, Rendition: renditionLabel, SegmentIndex: i}
+			if err := e.segmentCache.Put(key, data); err != nil {
+				return rng, rendition.Bitrate, fmt.Errorf("segment %d: %v", i, err)
+			}
+		}
+	}
+	return rng, rendition.Bitrate, nil
+}
+
 // AddUser adds a new user session
 func (e *Engine) AddUser(userID string) *UserSession {
 	session := &UserSession{
@@ -339,7 +684,7 @@ func (e *Engine) AddUser(userID string) *UserSession {
 		ScrollSpeed:     0.0,
 		WatchTime:       0,
 		LastActivity:    time.Now(),
-		PrefetchQueue:    make(chan PrefetchTask, 100),
+		PrefetchQueue:    make(chan PrefetchTask, defaultPrefetchQueueSize),
 		BehaviorProfile: &BehaviorProfile{
 			UserType:    "unknown",
 			ScrollSpeed: 0.0,
@@ -351,13 +696,21 @@ func (e *Engine) AddUser(userID string) *UserSession {
 	}
 
 	e.activeUsers.Store(userID, session)
-	
-	// Update metrics
-	e.metrics.mu.Lock()
-	e.metrics.ActiveUsers++
-	e.metrics.mu.Unlock()
+
+	session.mu.Lock()
+	stored := session.toStored()
+	session.mu.Unlock()
+	if err := e.store.Save(userID, stored); err != nil {
+		logrus.Errorf("❌ failed to persist new session for user %s: %v", userID, err)
+	}
+
+	e.metrics.activeUsers.Inc()
 
 	logrus.Infof("👤 Added user session: %s", userID)
+
+	createdData, _ := json.Marshal(SessionLifecycleData{UserID: userID})
+	e.webhooks.Publish(WebhookEvent{Type: EventSessionCreated, Timestamp: time.Now(), Data: createdData})
+
 	return session
 }
 
@@ -369,8 +722,61 @@ func (e *Engine) GetUserSession(userID string) (*UserSession, bool) {
 	return nil, false
 }
 
+// RecordNetworkCondition tallies condition (e.g. "good", "poor") against the
+// histogram reported via NetworkConditionHistogram, for callers that observe
+// network quality when making a prefetch decision. There's no other
+// consumer of this yet; it exists purely to back usage reporting (see
+// internal/usagestats).
+func (e *Engine) RecordNetworkCondition(condition string) {
+	count, _ := e.networkConditions.LoadOrStore(condition, new(int64))
+	atomic.AddInt64(count.(*int64), 1)
+}
+
+// UserTypeDistribution implements usagestats.Source: the count of currently
+// active sessions by classified BehaviorProfile.UserType.
+func (e *Engine) UserTypeDistribution() map[string]int {
+	distribution := make(map[string]int)
+	e.activeUsers.Range(func(_, value interface{}) bool {
+		session := value.(*UserSession)
+		session.mu.RLock()
+		distribution[session.BehaviorProfile.UserType]++
+		session.mu.RUnlock()
+		return true
+	})
+	return distribution
+}
+
+// AveragePrefetchCount implements usagestats.Source: the mean PrefetchCount
+// across currently active sessions' BehaviorProfile.
+func (e *Engine) AveragePrefetchCount() float64 {
+	var total, count int
+	e.activeUsers.Range(func(_, value interface{}) bool {
+		session := value.(*UserSession)
+		session.mu.RLock()
+		total += session.BehaviorProfile.PrefetchCount
+		count++
+		session.mu.RUnlock()
+		return true
+	})
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// NetworkConditionHistogram implements usagestats.Source: the count of
+// RecordNetworkCondition calls observed so far, by condition label.
+func (e *Engine) NetworkConditionHistogram() map[string]int {
+	histogram := make(map[string]int)
+	e.networkConditions.Range(func(key, value interface{}) bool {
+		histogram[key.(string)] = int(atomic.LoadInt64(value.(*int64)))
+		return true
+	})
+	return histogram
+}
+
 // UpdateUserBehavior updates user behavior and adjusts prefetching strategy
-func (e *Engine) UpdateUserBehavior(userID string, scrollSpeed float, watchTime time.Duration) {
+func (e *Engine) UpdateUserBehavior(userID string, scrollSpeed float64, watchTime time.Duration) {
 	session, exists := e.GetUserSession(userID)
 	if !exists {
 		session = e.AddUser(userID)
@@ -391,8 +797,19 @@ func (e *Engine) UpdateUserBehavior(userID string, scrollSpeed float, watchTime
 	// Adjust prefetching strategy based on behavior
 	e.adjustPrefetchingStrategy(session)
 
-	logrus.Debugf("📊 Updated user behavior: %s -> %s (scroll: %.2f, watch: %v)", 
+	if err := e.store.Save(userID, session.toStored()); err != nil {
+		logrus.Errorf("❌ failed to persist updated session for user %s: %v", userID, err)
+	}
+
+	logrus.Debugf("📊 Updated user behavior: %s -> %s (scroll: %.2f, watch: %v)",
 		userID, newProfile.UserType, scrollSpeed, watchTime)
+
+	changedData, _ := json.Marshal(BehaviorChangedData{
+		UserID:      userID,
+		UserType:    newProfile.UserType,
+		ScrollSpeed: scrollSpeed,
+	})
+	e.webhooks.Publish(WebhookEvent{Type: EventBehaviorChanged, Timestamp: time.Now(), Data: changedData})
 }
 
 // adjustPrefetchingStrategy adjusts prefetching based on user behavior
@@ -422,15 +839,31 @@ func (e *Engine) adjustPrefetchingStrategy(session *UserSession) {
 // addPrefetchTasks adds prefetching tasks to the user's queue
 func (e *Engine) addPrefetchTasks(session *UserSession, count int, priority Priority) {
 	currentReel := session.CurrentReel
-	
+
 	for i := 1; i <= count && (currentReel+i) <= e.config.MaxPrefetchCount; i++ {
+		url := fmt.Sprintf("https://cdn.kronop.com/reels/%d/master.m3u8", currentReel+i)
+
+		// An open circuit means this origin is currently degraded: skip
+		// low-priority tasks entirely rather than queuing work likely to
+		// fail, and downgrade anything higher-priority instead of dropping
+		// it, so a user still gets served once the origin recovers.
+		taskPriority := priority
+		if e.origins.IsOpen(originOf(url)) {
+			if priority == PriorityLow {
+				logrus.Debugf("⛔ Skipping reel %d prefetch, origin circuit open", currentReel+i)
+				continue
+			}
+			taskPriority = priority - 1
+		}
+
 		task := PrefetchTask{
-			ReelID:    currentReel + i,
-			Priority:  priority,
-			URL:       fmt.Sprintf("https://cdn.kronop.com/reels/%d", currentReel+i),
-			Timeout:   e.config.PrefetchTimeout,
-			RetryCount: 0,
-			CreatedAt: time.Now(),
+			ReelID:       currentReel + i,
+			Priority:     taskPriority,
+			URL:          url,
+			ManifestType: ManifestHLS,
+			Timeout:      e.config.PrefetchTimeout,
+			RetryCount:   0,
+			CreatedAt:    time.Now(),
 		}
 
 		select {
@@ -443,7 +876,10 @@ func (e *Engine) addPrefetchTasks(session *UserSession, count int, priority Prio
 	}
 }
 
-// metricsCollector collects and reports metrics
+// metricsCollector periodically refreshes the gauges that have no single
+// call site to update from - queueDepth changes on every enqueue/dequeue
+// across every session's PrefetchQueue, so it's cheaper to recompute it on
+// a tick than to touch a gauge on each of those.
 func (e *Engine) metricsCollector(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
@@ -453,28 +889,11 @@ func (e *Engine) metricsCollector(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			e.reportMetrics()
+			e.refreshQueueDepth()
 		}
 	}
 }
 
-// reportMetrics reports current metrics
-func (e *Engine) reportMetrics() {
-	e.metrics.mu.RLock()
-	metrics := *e.metrics
-	e.metrics.mu.RUnlock()
-
-	logrus.Infof("📊 Metrics: Total=%d, Success=%d, Failed=%d, CacheHits=%d, CacheMisses=%d, ActiveUsers=%d, AvgResponseTime=%v",
-		metrics.TotalPrefetches,
-		metrics.SuccessfulPrefetches,
-		metrics.FailedPrefetches,
-		metrics.CacheHits,
-		metrics.CacheMisses,
-		metrics.ActiveUsers,
-		metrics.AvgResponseTime,
-	)
-}
-
 // cleanupRoutine performs periodic cleanup
 func (e *Engine) cleanupRoutine(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Hour)
@@ -508,17 +927,22 @@ func (e *Engine) performCleanup() {
 
 	for _, userID := range inactiveUsers {
 		e.activeUsers.Delete(userID)
+		if err := e.store.Delete(userID); err != nil {
+			logrus.Errorf("❌ failed to delete persisted session for user %s: %v", userID, err)
+		}
 		logrus.Infof("🗑️ Cleaned up inactive user: %s", userID)
+
+		expiredData, _ := json.Marshal(SessionLifecycleData{UserID: userID})
+		e.webhooks.Publish(WebhookEvent{Type: EventSessionExpired, Timestamp: time.Now(), Data: expiredData})
 	}
 
 	// Update active user count
-	e.metrics.mu.Lock()
-	e.metrics.ActiveUsers = 0
+	var remaining int
 	e.activeUsers.Range(func(key, value interface{}) bool {
-		e.metrics.ActiveUsers++
+		remaining++
 		return true
 	})
-	e.metrics.mu.Unlock()
+	e.metrics.activeUsers.Set(float64(remaining))
 
 	logrus.Info("✅ Cleanup completed")
 }
@@ -527,17 +951,34 @@ func (e *Engine) performCleanup() {
 func (e *Engine) Shutdown(ctx context.Context) error {
 	logrus.Info("🛑 Shutting down prefetcher engine")
 
-	// Close all user sessions
+	// Close all user sessions, persisting each one's final state first so
+	// Rehydrate can restore it on the next Start.
 	e.activeUsers.Range(func(key, value interface{}) bool {
 		userID := key.(string)
 		session := value.(*UserSession)
+
+		session.mu.Lock()
+		stored := session.toStored()
+		session.mu.Unlock()
+		if err := e.store.Save(userID, stored); err != nil {
+			logrus.Errorf("❌ failed to persist session for user %s on shutdown: %v", userID, err)
+		}
+
 		close(session.PrefetchQueue)
 		e.activeUsers.Delete(userID)
 		return true
 	})
 
-	// Clear cache
-	e.cache.Flush()
+	// Clear segment cache
+	if e.segmentCache != nil {
+		if err := e.segmentCache.Close(); err != nil {
+			logrus.Errorf("❌ failed to close segment cache: %v", err)
+		}
+	}
+
+	if err := e.store.Close(); err != nil {
+		logrus.Errorf("❌ failed to close session store: %v", err)
+	}
 
 	logrus.Info("✅ Prefetcher engine shutdown completed")
 	return nil
@@ -545,6 +986,15 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 
 // HTTP Handlers
 func (e *Engine) handlePrefetch(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID != "" {
+		if ok, retryAfter := e.rateLimiter.Allow(userID); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds()+1)))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+	}
+
 	// Handle prefetch requests
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
@@ -556,15 +1006,6 @@ func (e *Engine) handleUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-func (e *Engine) handleMetrics(w http.ResponseWriter, r *http.Request) {
-	e.metrics.mu.RLock()
-	metrics := *e.metrics
-	e.metrics.mu.RUnlock()
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(metrics)
-}
-
 func (e *Engine) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -574,15 +1015,33 @@ func (e *Engine) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleOrigins serves every tracked origin's current circuit breaker
+// state and response-time sample count, for operators to spot a degraded
+// CDN origin without grepping logs for "circuit breaker -> open".
+func (e *Engine) handleOrigins(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.origins.Snapshot())
+}
+
 func (e *Engine) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Span covers the whole connection lifetime, not just the upgrade. The
+	// span's ctx is discarded, since there's no downstream call inside the
+	// read loop to hand it to.
+	_, span := tracer.Start(r.Context(), "prefetcher.handleWebSocket")
+	defer span.End()
+
 	// Handle WebSocket connections for real-time updates
 	conn, err := e.wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		logrus.Errorf("❌ WebSocket upgrade failed: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return
 	}
 	defer conn.Close()
 
+	conn.SetReadLimit(e.config.WebSocketMaxMessageSize)
+
 	logrus.Info("🔗 WebSocket connection established")
 
 	// Handle WebSocket messages
@@ -595,10 +1054,14 @@ func (e *Engine) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 		if messageType == websocket.TextMessage {
 			logrus.Debugf("📨 Received WebSocket message: %s", string(p))
-			
-			// Process message and send response
+
+			// Process message and send response. writeChunkedJSON
+			// segments the response into ordered frames when it
+			// exceeds defaultChunkSize, so large BehaviorPrediction/
+			// ScrollPattern payloads survive intact even under a
+			// conservative WebSocket message-size limit.
 			response := fmt.Sprintf("Echo: %s", string(p))
-			if err := conn.WriteMessage(websocket.TextMessage, []byte(response)); err != nil {
+			if err := writeChunkedJSON(conn, response, 0); err != nil {
 				logrus.Errorf("❌ WebSocket write error: %v", err)
 				break
 			}
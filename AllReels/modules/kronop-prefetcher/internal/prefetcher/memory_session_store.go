@@ -0,0 +1,67 @@
+package prefetcher
+
+import "sync"
+
+// memorySessionStore is the default SessionStore: an in-memory map with
+// no durability. It exists so Engine always has a working store even
+// when Config doesn't opt into a durable backend; Shutdown's persistence
+// calls against it are harmless, just pointless, since there's nothing
+// underneath to survive a restart.
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*StoredSession
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]*StoredSession)}
+}
+
+func (s *memorySessionStore) Save(userID string, session *StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[userID] = session
+	return nil
+}
+
+func (s *memorySessionStore) Load(userID string) (*StoredSession, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[userID]
+	return session, ok, nil
+}
+
+func (s *memorySessionStore) Delete(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, userID)
+	return nil
+}
+
+func (s *memorySessionStore) Range(fn func(userID string, session *StoredSession) bool) error {
+	s.mu.RLock()
+	sessions := make(map[string]*StoredSession, len(s.sessions))
+	for k, v := range s.sessions {
+		sessions[k] = v
+	}
+	s.mu.RUnlock()
+
+	for userID, session := range sessions {
+		if !fn(userID, session) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memorySessionStore) Snapshot() ([]*StoredSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make([]*StoredSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		snapshot = append(snapshot, session)
+	}
+	return snapshot, nil
+}
+
+func (s *memorySessionStore) Close() error { return nil }
@@ -0,0 +1,109 @@
+package prefetcher
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// engineMetrics bundles the Prometheus collectors Engine records against.
+// It replaces the old ad-hoc Metrics struct (plain int64 counters under a
+// mutex, dumped to logrus every 10s by reportMetrics): that gave operators
+// nothing to point Grafana at and turned AvgResponseTime into a running
+// average instead of a real distribution. Unlike ai.predictionMetrics and
+// tracker.trackerMetrics, this isn't gated behind a RegisterMetrics Option -
+// there's exactly one Engine per process, so it's always built and always
+// registered, the same way internal/metrics.Metrics is for the bridge.
+type engineMetrics struct {
+	registry *prometheus.Registry
+
+	prefetchTotal    prometheus.Counter
+	prefetchFailures prometheus.Counter
+	cacheHits        prometheus.Counter
+	queueDepth       prometheus.Gauge
+	activeUsers      prometheus.Gauge
+	responseTime     prometheus.Histogram
+
+	// circuitTransitions counts originCircuitBreaker state transitions,
+	// labeled by origin and the state it transitioned to - so an operator
+	// can chart how often each CDN origin trips open.
+	circuitTransitions *prometheus.CounterVec
+}
+
+// newEngineMetrics builds and registers an engineMetrics bundle on a
+// registry private to this Engine, so embedding one in a larger process
+// doesn't collide with that process's own default registerer.
+func newEngineMetrics() *engineMetrics {
+	reg := prometheus.NewRegistry()
+
+	m := &engineMetrics{
+		registry: reg,
+		prefetchTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "prefetch_total",
+			Help:      "Prefetch tasks that completed successfully (cache hits excluded).",
+		}),
+		prefetchFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "prefetch_failures_total",
+			Help:      "Prefetch tasks that failed: the rate-limit wait gave up or fetchSegments returned an error.",
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "cache_hits_total",
+			Help:      "Prefetch tasks served from cache instead of fetched.",
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "queue_depth",
+			Help:      "Sum of PrefetchQueue lengths across all active user sessions, refreshed by metricsCollector.",
+		}),
+		activeUsers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "active_users",
+			Help:      "Currently active user sessions.",
+		}),
+		responseTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "prefetch_response_time_seconds",
+			Help:      "executePrefetchTask latency on a cache miss, success or failure, including the rate-limit wait.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		circuitTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "kronop",
+			Subsystem: "prefetcher",
+			Name:      "origin_circuit_transitions_total",
+			Help:      "originCircuitBreaker state transitions, by origin and the state transitioned to.",
+		}, []string{"origin", "state"}),
+	}
+
+	reg.MustRegister(m.prefetchTotal, m.prefetchFailures, m.cacheHits,
+		m.queueDepth, m.activeUsers, m.responseTime, m.circuitTransitions)
+	return m
+}
+
+// Handler serves this engine's registry in the Prometheus text format, for
+// StartHTTPServer to mount at /metrics.
+func (m *engineMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// refreshQueueDepth recomputes queueDepth from every active session's
+// PrefetchQueue, since unlike the counters it isn't naturally updated at a
+// single call site.
+func (e *Engine) refreshQueueDepth() {
+	var depth int
+	e.activeUsers.Range(func(_, value interface{}) bool {
+		session := value.(*UserSession)
+		depth += len(session.PrefetchQueue)
+		return true
+	})
+	e.metrics.queueDepth.Set(float64(depth))
+}
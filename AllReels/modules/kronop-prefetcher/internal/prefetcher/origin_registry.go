@@ -0,0 +1,167 @@
+package prefetcher
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// maxResponseTimeSamples bounds how many recent successful response times
+// OriginRegistry keeps per origin for its rolling p95, so a long-lived
+// origin's sample slice doesn't grow without bound.
+const maxResponseTimeSamples = 200
+
+// adaptiveTimeoutMultiplier pads an origin's rolling p95 response time
+// before using it as that origin's adaptive PrefetchTimeout, so a timeout
+// set exactly at p95 doesn't start failing ~5% of otherwise-healthy
+// requests.
+const adaptiveTimeoutMultiplier = 1.5
+
+// minSamplesForAdaptiveTimeout is how many response-time samples
+// AdaptiveTimeout requires before trusting the p95 over fallback - a
+// percentile of one or two samples isn't a distribution yet.
+const minSamplesForAdaptiveTimeout = 5
+
+// originHealth is OriginRegistry's per-origin bookkeeping: a circuit
+// breaker plus the response-time samples its adaptive timeout is derived
+// from.
+type originHealth struct {
+	breaker *originCircuitBreaker
+	// responseTimes holds recent successful fetch durations in seconds,
+	// oldest-first, capped at maxResponseTimeSamples.
+	responseTimes []float64
+}
+
+// OriginRegistry tracks per-origin health - a circuit breaker plus a
+// rolling p95 of successful response times - so a single degraded CDN
+// origin can be isolated (fetchVideoData stops hammering it,
+// addPrefetchTasks skips/downgrades its tasks) without affecting any
+// other origin.
+type OriginRegistry struct {
+	mu      sync.Mutex
+	config  CircuitBreakerConfig
+	origins map[string]*originHealth
+}
+
+// newOriginRegistry builds an empty OriginRegistry. A zero-value config
+// falls back to defaultCircuitBreakerConfig.
+func newOriginRegistry(config CircuitBreakerConfig) *OriginRegistry {
+	if config.FailureThreshold <= 0 || config.CooldownPeriod <= 0 {
+		config = defaultCircuitBreakerConfig
+	}
+	return &OriginRegistry{
+		config:  config,
+		origins: make(map[string]*originHealth),
+	}
+}
+
+// originOf extracts the scheme+host portion of rawURL to key OriginRegistry
+// by - the part a CDN operator would actually call "an origin" - falling
+// back to rawURL itself if it doesn't parse, so a malformed task URL still
+// gets tracked under some key instead of being silently dropped.
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// healthFor returns origin's originHealth, creating it on first use.
+// Callers must hold r.mu.
+func (r *OriginRegistry) healthFor(origin string) *originHealth {
+	h, ok := r.origins[origin]
+	if !ok {
+		h = &originHealth{breaker: newOriginCircuitBreaker(r.config)}
+		r.origins[origin] = h
+	}
+	return h
+}
+
+// Allow reports whether a request to origin may proceed right now,
+// consuming the single probe slot if origin's circuit is half-open.
+func (r *OriginRegistry) Allow(origin string) bool {
+	r.mu.Lock()
+	h := r.healthFor(origin)
+	r.mu.Unlock()
+	return h.breaker.Allow()
+}
+
+// IsOpen reports whether origin's circuit is currently open, for
+// addPrefetchTasks to skip/downgrade tasks targeting it without consuming
+// the half-open probe slot the way Allow would.
+func (r *OriginRegistry) IsOpen(origin string) bool {
+	r.mu.Lock()
+	h := r.healthFor(origin)
+	r.mu.Unlock()
+	return h.breaker.State() == CircuitOpen
+}
+
+// RecordSuccess reports a successful fetch from origin that took
+// responseTime, folding it into the rolling p95 sample window and
+// closing the breaker if it was half-open.
+func (r *OriginRegistry) RecordSuccess(origin string, responseTime time.Duration) (transitioned bool, newState CircuitState) {
+	r.mu.Lock()
+	h := r.healthFor(origin)
+	h.responseTimes = append(h.responseTimes, responseTime.Seconds())
+	if len(h.responseTimes) > maxResponseTimeSamples {
+		h.responseTimes = h.responseTimes[len(h.responseTimes)-maxResponseTimeSamples:]
+	}
+	r.mu.Unlock()
+	return h.breaker.RecordSuccess()
+}
+
+// RecordFailure reports a failed fetch from origin, tripping or
+// re-opening its breaker per originCircuitBreaker.RecordFailure.
+func (r *OriginRegistry) RecordFailure(origin string) (transitioned bool, newState CircuitState) {
+	r.mu.Lock()
+	h := r.healthFor(origin)
+	r.mu.Unlock()
+	return h.breaker.RecordFailure()
+}
+
+// AdaptiveTimeout returns origin's timeout as adaptiveTimeoutMultiplier
+// times its rolling p95 successful response time, or fallback if fewer
+// than minSamplesForAdaptiveTimeout samples have been recorded yet.
+func (r *OriginRegistry) AdaptiveTimeout(origin string, fallback time.Duration) time.Duration {
+	r.mu.Lock()
+	h := r.healthFor(origin)
+	samples := append([]float64(nil), h.responseTimes...)
+	r.mu.Unlock()
+
+	if len(samples) < minSamplesForAdaptiveTimeout {
+		return fallback
+	}
+	p95, err := stats.Percentile(samples, 95)
+	if err != nil {
+		return fallback
+	}
+	return time.Duration(p95 * adaptiveTimeoutMultiplier * float64(time.Second))
+}
+
+// OriginStatus is one origin's point-in-time health, as served by
+// GET /api/v1/origins.
+type OriginStatus struct {
+	Origin  string `json:"origin"`
+	State   string `json:"state"`
+	Samples int    `json:"response_time_samples"`
+}
+
+// Snapshot returns every tracked origin's current status, for
+// handleOrigins.
+func (r *OriginRegistry) Snapshot() []OriginStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]OriginStatus, 0, len(r.origins))
+	for origin, h := range r.origins {
+		statuses = append(statuses, OriginStatus{
+			Origin:  origin,
+			State:   h.breaker.State().String(),
+			Samples: len(h.responseTimes),
+		})
+	}
+	return statuses
+}
@@ -0,0 +1,105 @@
+package prefetcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+)
+
+// pebbleSessionStore is a SessionStore backed by a Pebble key-value
+// store on disk, keyed by userID with JSON-serialized StoredSession
+// values - the same approach internal/ai's pebbleCacheStore takes for
+// prediction caching, reused here rather than adding a BoltDB/BadgerDB
+// dependency this repo doesn't otherwise carry.
+type pebbleSessionStore struct {
+	db *pebble.DB
+}
+
+// NewPebbleSessionStore opens (creating if needed) a Pebble-backed
+// SessionStore at dir, for single-node durability across restarts.
+func NewPebbleSessionStore(dir string) (SessionStore, error) {
+	opts := &pebble.Options{
+		Levels: make([]pebble.LevelOptions, 7),
+	}
+	for i := range opts.Levels {
+		opts.Levels[i].FilterPolicy = bloom.FilterPolicy(10)
+	}
+
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pebble session store at %s: %v", dir, err)
+	}
+	return &pebbleSessionStore{db: db}, nil
+}
+
+func (s *pebbleSessionStore) Save(userID string, session *StoredSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session for %s: %v", userID, err)
+	}
+	return s.db.Set([]byte(userID), data, pebble.Sync)
+}
+
+func (s *pebbleSessionStore) Load(userID string) (*StoredSession, bool, error) {
+	value, closer, err := s.db.Get([]byte(userID))
+	if err == pebble.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer closer.Close()
+
+	var session StoredSession
+	if err := json.Unmarshal(value, &session); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal session for %s: %v", userID, err)
+	}
+	return &session, true, nil
+}
+
+func (s *pebbleSessionStore) Delete(userID string) error {
+	return s.db.Delete([]byte(userID), pebble.Sync)
+}
+
+func (s *pebbleSessionStore) Range(fn func(userID string, session *StoredSession) bool) error {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		var session StoredSession
+		if err := json.Unmarshal(iter.Value(), &session); err != nil {
+			continue
+		}
+		if !fn(string(iter.Key()), &session) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *pebbleSessionStore) Snapshot() ([]*StoredSession, error) {
+	iter, err := s.db.NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var snapshot []*StoredSession
+	for iter.First(); iter.Valid(); iter.Next() {
+		var session StoredSession
+		if err := json.Unmarshal(iter.Value(), &session); err != nil {
+			continue
+		}
+		snapshot = append(snapshot, &session)
+	}
+	return snapshot, nil
+}
+
+func (s *pebbleSessionStore) Close() error {
+	return s.db.Close()
+}
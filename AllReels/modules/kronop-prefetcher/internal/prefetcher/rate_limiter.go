@@ -0,0 +1,123 @@
+package prefetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Default rate-limit parameters, applied by NewEngineWithStore to any
+// Config.RateLimit field left at its zero value. GlobalRate/GlobalBurst
+// match the 100 req/s, burst-10 limiter NewEngine used to hardcode;
+// PerUserRate/PerUserBurst are new, tighter budgets so one session's burst
+// can't consume the whole global bucket.
+const (
+	defaultGlobalRateLimit       = 100
+	defaultGlobalRateLimitBurst  = 10
+	defaultPerUserRateLimit      = 20
+	defaultPerUserRateLimitBurst = 5
+)
+
+// RateLimitConfig configures RateLimiter's global and per-user token
+// buckets. Rates are in requests/sec.
+type RateLimitConfig struct {
+	GlobalRate  float64 `yaml:"global_rate"`
+	GlobalBurst int     `yaml:"global_burst"`
+
+	PerUserRate  float64 `yaml:"per_user_rate"`
+	PerUserBurst int     `yaml:"per_user_burst"`
+
+	// UserTypeBurst overrides PerUserBurst by BehaviorProfile.UserType,
+	// e.g. {"binge_watcher": 20, "slow_viewer": 2}, so a user already
+	// classified as a heavy prefetcher isn't held to the same burst as
+	// everyone else.
+	UserTypeBurst map[string]int `yaml:"user_type_burst"`
+}
+
+// RateLimiter bounds prefetch throughput with both a single global budget
+// and a per-user budget keyed by UserSession.ID, so one misbehaving
+// session can't starve prefetching for every other user sharing the old
+// process-wide limiter. inMemoryRateLimiter is the only implementation
+// today; a distributed backend (Redis, or gubernator itself) can satisfy
+// the same interface later without executePrefetchTask or handlePrefetch
+// changing, the same seam SessionStore gives session storage.
+type RateLimiter interface {
+	// Allow reports whether a request for userID may proceed right now
+	// without blocking. When it returns false, retryAfter is how long the
+	// caller should wait before retrying.
+	Allow(userID string) (ok bool, retryAfter time.Duration)
+
+	// Wait blocks until a request for userID may proceed under both the
+	// global and per-user budgets - userType's UserTypeBurst override, if
+	// any, applies to the per-user bucket - or returns ctx.Err() if ctx is
+	// done first.
+	Wait(ctx context.Context, userID, userType string) error
+}
+
+// inMemoryRateLimiter is the default RateLimiter: one shared global
+// rate.Limiter plus a lazily-created rate.Limiter per userID.
+type inMemoryRateLimiter struct {
+	global RateLimitConfig
+
+	globalLimiter *rate.Limiter
+	perUser       sync.Map // userID -> *rate.Limiter
+}
+
+// newInMemoryRateLimiter builds a RateLimiter from config, which must
+// already have its zero fields defaulted (see NewEngineWithStore).
+func newInMemoryRateLimiter(config RateLimitConfig) *inMemoryRateLimiter {
+	return &inMemoryRateLimiter{
+		global:        config,
+		globalLimiter: rate.NewLimiter(rate.Limit(config.GlobalRate), config.GlobalBurst),
+	}
+}
+
+// userLimiter returns userID's bucket, creating it from PerUserRate/
+// PerUserBurst (overridden by UserTypeBurst[userType], if set) on first
+// use. The bucket's burst is re-applied on every call so a user's
+// classification changing (e.g. "unknown" -> "binge_watcher") resizes it
+// going forward, without losing tokens already accumulated.
+func (l *inMemoryRateLimiter) userLimiter(userID, userType string) *rate.Limiter {
+	burst := l.global.PerUserBurst
+	if b, ok := l.global.UserTypeBurst[userType]; ok {
+		burst = b
+	}
+
+	v, _ := l.perUser.LoadOrStore(userID, rate.NewLimiter(rate.Limit(l.global.PerUserRate), burst))
+	limiter := v.(*rate.Limiter)
+	limiter.SetBurst(burst)
+	return limiter
+}
+
+func (l *inMemoryRateLimiter) Allow(userID string) (bool, time.Duration) {
+	globalRes := l.globalLimiter.Reserve()
+	if !globalRes.OK() {
+		return false, 0
+	}
+
+	userRes := l.userLimiter(userID, "").Reserve()
+	if !userRes.OK() {
+		globalRes.Cancel()
+		return false, 0
+	}
+
+	delay := globalRes.Delay()
+	if d := userRes.Delay(); d > delay {
+		delay = d
+	}
+	if delay > 0 {
+		globalRes.Cancel()
+		userRes.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *inMemoryRateLimiter) Wait(ctx context.Context, userID, userType string) error {
+	if err := l.globalLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	return l.userLimiter(userID, userType).Wait(ctx)
+}
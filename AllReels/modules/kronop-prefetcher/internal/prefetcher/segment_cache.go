@@ -0,0 +1,148 @@
+package prefetcher
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultSegmentCacheDir and defaultCacheSizeMB are what NewEngineWithStore
+// falls back to when Config.SegmentCachePath/CacheSizeMB are left at their
+// zero values.
+const (
+	defaultSegmentCacheDir = "./kronop-segment-cache"
+	defaultCacheSizeMB     = 512
+)
+
+// segmentKey identifies one cached video segment: a specific rendition
+// and segment index of a given reel, e.g. the 3rd segment of reel 42's
+// "low" bitrate rendition.
+type segmentKey struct {
+	ReelID       int
+	Rendition    string
+	SegmentIndex int
+}
+
+// filename derives segmentCache's on-disk filename for key. Hashing
+// avoids needing to sanitize Rendition for filesystem-unsafe characters.
+func (k segmentKey) filename() string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d:%s:%d", k.ReelID, k.Rendition, k.SegmentIndex)))
+	return hex.EncodeToString(sum[:])
+}
+
+// segmentEntry is segmentCache's bookkeeping for one stored key, tracked
+// in the LRU list so eviction can find the least-recently-used entry
+// without scanning every file's mtime.
+type segmentEntry struct {
+	key  segmentKey
+	size int64
+}
+
+// segmentCache is an on-disk LRU bounded by total byte size (maxBytes,
+// derived from Config.CacheSizeMB) rather than by entry count or a TTL -
+// the in-memory, TTL-only go-cache it replaces for video segments had no
+// way to bound how much it could grow by.
+type segmentCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	curBytes int64
+	order    *list.List // front = most recently used
+	elems    map[segmentKey]*list.Element
+}
+
+// newSegmentCache opens (creating if needed) an on-disk segment cache
+// rooted at dir, bounded to maxBytes total. Any files already in dir are
+// discarded rather than reconciled - there's no sidecar index recording
+// per-file size/LRU order to rebuild from, so starting cold is simpler
+// and safer than guessing at it.
+func newSegmentCache(dir string, maxBytes int64) (*segmentCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create segment cache dir %q: %v", dir, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read segment cache dir %q: %v", dir, err)
+	}
+	for _, entry := range entries {
+		os.Remove(filepath.Join(dir, entry.Name()))
+	}
+
+	return &segmentCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		elems:    make(map[segmentKey]*list.Element),
+	}, nil
+}
+
+// Get returns key's cached bytes, refreshing it to most-recently-used.
+func (c *segmentCache) Get(key segmentKey) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.elems[key]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key.filename()))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores data under key, evicting least-recently-used entries first
+// if needed to stay within maxBytes. A single entry larger than maxBytes
+// is rejected outright rather than stored and immediately evicted.
+func (c *segmentCache) Put(key segmentKey, data []byte) error {
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return fmt.Errorf("segment of %d bytes exceeds cache capacity of %d bytes", size, c.maxBytes)
+	}
+
+	if err := os.WriteFile(filepath.Join(c.dir, key.filename()), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write segment to cache: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.elems[key]; ok {
+		entry := elem.Value.(*segmentEntry)
+		c.curBytes -= entry.size
+		entry.size = size
+		c.order.MoveToFront(elem)
+	} else {
+		elem := c.order.PushFront(&segmentEntry{key: key, size: size})
+		c.elems[key] = elem
+	}
+	c.curBytes += size
+
+	for c.curBytes > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		entry := oldest.Value.(*segmentEntry)
+		os.Remove(filepath.Join(c.dir, entry.key.filename()))
+		c.curBytes -= entry.size
+		delete(c.elems, entry.key)
+		c.order.Remove(oldest)
+	}
+	return nil
+}
+
+// Close removes every file this cache wrote, for use on Engine.Shutdown.
+func (c *segmentCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return os.RemoveAll(c.dir)
+}
@@ -0,0 +1,133 @@
+package prefetcher
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// sqlSessionStore is a SessionStore backed by a single table over
+// database/sql, for deployments that want sessions durable in the same
+// MySQL or Postgres instance as their other state rather than a
+// separate Pebble directory per node.
+//
+// The caller owns driver selection: open db with the mysql or lib/pq
+// driver already blank-imported (this package doesn't carry either as a
+// direct dependency, the same way database/sql itself doesn't) and pass
+// the placeholder style that driver expects. Save deliberately avoids
+// "ON CONFLICT"/"ON DUPLICATE KEY UPDATE" - the two dialects disagree on
+// that syntax - in favor of a portable load-then-insert-or-update, at
+// the cost of an extra round trip per write.
+type sqlSessionStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+}
+
+// SQLPlaceholderMySQL and SQLPlaceholderPostgres are the two placeholder
+// styles NewSQLSessionStore needs to format queries for.
+func SQLPlaceholderMySQL(n int) string { return "?" }
+func SQLPlaceholderPostgres(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// NewSQLSessionStore wraps db as a SessionStore, creating its session
+// table (named table) if it doesn't already exist. placeholder selects
+// the parameter-marker style for db's driver - use SQLPlaceholderMySQL
+// or SQLPlaceholderPostgres.
+func NewSQLSessionStore(db *sql.DB, table string, placeholder func(n int) string) (SessionStore, error) {
+	s := &sqlSessionStore{db: db, table: table, placeholder: placeholder}
+
+	createTable := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (user_id VARCHAR(255) PRIMARY KEY, data BLOB NOT NULL)`, table)
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, fmt.Errorf("failed to create session store table %s: %v", table, err)
+	}
+	return s, nil
+}
+
+func (s *sqlSessionStore) Save(userID string, session *StoredSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session for %s: %v", userID, err)
+	}
+
+	_, exists, err := s.Load(userID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		query := fmt.Sprintf(`UPDATE %s SET data = %s WHERE user_id = %s`,
+			s.table, s.placeholder(1), s.placeholder(2))
+		_, err = s.db.Exec(query, data, userID)
+	} else {
+		query := fmt.Sprintf(`INSERT INTO %s (user_id, data) VALUES (%s, %s)`,
+			s.table, s.placeholder(1), s.placeholder(2))
+		_, err = s.db.Exec(query, userID, data)
+	}
+	return err
+}
+
+func (s *sqlSessionStore) Load(userID string) (*StoredSession, bool, error) {
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE user_id = %s`, s.table, s.placeholder(1))
+
+	var data []byte
+	err := s.db.QueryRow(query, userID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var session StoredSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal session for %s: %v", userID, err)
+	}
+	return &session, true, nil
+}
+
+func (s *sqlSessionStore) Delete(userID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = %s`, s.table, s.placeholder(1))
+	_, err := s.db.Exec(query, userID)
+	return err
+}
+
+func (s *sqlSessionStore) Range(fn func(userID string, session *StoredSession) bool) error {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT user_id, data FROM %s`, s.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var data []byte
+		if err := rows.Scan(&userID, &data); err != nil {
+			return err
+		}
+
+		var session StoredSession
+		if err := json.Unmarshal(data, &session); err != nil {
+			continue
+		}
+		if !fn(userID, &session) {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqlSessionStore) Snapshot() ([]*StoredSession, error) {
+	var snapshot []*StoredSession
+	err := s.Range(func(_ string, session *StoredSession) bool {
+		snapshot = append(snapshot, session)
+		return true
+	})
+	return snapshot, err
+}
+
+func (s *sqlSessionStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,97 @@
+package prefetcher
+
+import "time"
+
+// StoredSession is the durable, serializable snapshot of a UserSession a
+// SessionStore persists. UserSession itself carries a channel and a
+// mutex, neither of which round-trips through a KV store or SQL row, so
+// this is what (*UserSession).toStored actually writes and
+// newSessionFromStored reads back.
+type StoredSession struct {
+	ID              string           `json:"id"`
+	CurrentReel     int              `json:"current_reel"`
+	ScrollSpeed     float64          `json:"scroll_speed"`
+	WatchTime       time.Duration    `json:"watch_time"`
+	LastActivity    time.Time        `json:"last_activity"`
+	BehaviorProfile *BehaviorProfile `json:"behavior_profile"`
+	PendingTasks    []PrefetchTask   `json:"pending_tasks"`
+}
+
+// SessionStore persists StoredSessions keyed by userID behind a
+// pluggable backend: an in-memory map (the default, memorySessionStore),
+// a Pebble-backed KV store (NewPebbleSessionStore) for single-node
+// durability, or a SQL table (NewSQLSessionStore) for MySQL/Postgres
+// deployments that already centralize their other state there. Engine's
+// AddUser, UpdateUserBehavior, performCleanup and Shutdown persist
+// through this interface, and Rehydrate reads it back on startup, so a
+// restart doesn't lose every user's learned BehaviorProfile and
+// in-flight prefetch queue.
+type SessionStore interface {
+	// Save persists (or overwrites) the session for userID.
+	Save(userID string, session *StoredSession) error
+	// Load returns the persisted session for userID, if present.
+	Load(userID string) (*StoredSession, bool, error)
+	// Delete removes the persisted session for userID, if present.
+	Delete(userID string) error
+	// Range calls fn for every persisted session, stopping early if fn
+	// returns false. Iteration order is unspecified.
+	Range(fn func(userID string, session *StoredSession) bool) error
+	// Snapshot returns every persisted session at once, for bulk
+	// rehydration on startup.
+	Snapshot() ([]*StoredSession, error)
+	// Close releases any resources the store holds (file handles,
+	// connections, etc).
+	Close() error
+}
+
+// defaultPrefetchQueueSize is the buffered capacity AddUser and
+// newSessionFromStored give a rehydrated or freshly created session's
+// PrefetchQueue.
+const defaultPrefetchQueueSize = 100
+
+// toStored snapshots session's persistable fields into a StoredSession,
+// including a peek at PrefetchQueue's current contents. Caller must hold
+// session.mu, the same lock every other PrefetchQueue reader/writer in
+// this package holds, since the peek works by draining and immediately
+// refilling the channel.
+func (s *UserSession) toStored() *StoredSession {
+	pending := make([]PrefetchTask, 0, len(s.PrefetchQueue))
+	for i := 0; i < cap(pending); i++ {
+		task := <-s.PrefetchQueue
+		pending = append(pending, task)
+		s.PrefetchQueue <- task
+	}
+
+	return &StoredSession{
+		ID:              s.ID,
+		CurrentReel:     s.CurrentReel,
+		ScrollSpeed:     s.ScrollSpeed,
+		WatchTime:       s.WatchTime,
+		LastActivity:    s.LastActivity,
+		BehaviorProfile: s.BehaviorProfile,
+		PendingTasks:    pending,
+	}
+}
+
+// newSessionFromStored reconstructs a live UserSession from a persisted
+// snapshot: a fresh PrefetchQueue of queueSize, refilled with as much of
+// PendingTasks as fits.
+func newSessionFromStored(stored *StoredSession, queueSize int) *UserSession {
+	session := &UserSession{
+		ID:              stored.ID,
+		CurrentReel:     stored.CurrentReel,
+		ScrollSpeed:     stored.ScrollSpeed,
+		WatchTime:       stored.WatchTime,
+		LastActivity:    stored.LastActivity,
+		PrefetchQueue:   make(chan PrefetchTask, queueSize),
+		BehaviorProfile: stored.BehaviorProfile,
+	}
+
+	for _, task := range stored.PendingTasks {
+		select {
+		case session.PrefetchQueue <- task:
+		default:
+		}
+	}
+	return session
+}
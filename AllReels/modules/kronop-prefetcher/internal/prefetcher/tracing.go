@@ -0,0 +1,15 @@
+package prefetcher
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer emits the spans processBackgroundTasks, executePrefetchTask,
+// fetchVideoData and handleWebSocket start. It resolves through whatever
+// TracerProvider the embedding process configures via
+// otel.SetTracerProvider; with none configured (the default) every Start
+// call is a documented no-op, so this package adds no OTel SDK/exporter
+// dependency of its own - just the otel and otel/trace APIs, the same way
+// it never assumed which Prometheus Gatherer or logrus formatter the
+// embedding process would pick.
+var tracer = otel.Tracer("github.com/kronop/prefetcher/internal/prefetcher")
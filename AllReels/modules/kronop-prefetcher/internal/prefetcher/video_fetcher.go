@@ -0,0 +1,313 @@
+package prefetcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ManifestType selects how fetchSegments interprets a PrefetchTask's URL:
+// an HLS (.m3u8) or DASH (.mpd) manifest to parse renditions/segments
+// from, or a progressive single-file download (fetchVideoData's
+// original behavior, and the zero value).
+type ManifestType string
+
+const (
+	ManifestProgressive ManifestType = "progressive"
+	ManifestHLS         ManifestType = "hls"
+	ManifestDASH        ManifestType = "dash"
+)
+
+// SegmentRange is the byte range fetchSegments requests via an HTTP
+// Range header, so it can fetch only the first few seconds of a segment
+// instead of the whole thing. End == 0 means "to the end of the segment".
+type SegmentRange struct {
+	Start int64
+	End   int64
+}
+
+// Rendition is one quality/bitrate variant of a manifest, with its
+// segment URLs in playback order.
+type Rendition struct {
+	Bitrate     int // bits/sec; 0 if the manifest didn't advertise one
+	SegmentURLs []string
+}
+
+// Manifest is a parsed HLS or DASH manifest's renditions, sorted
+// ascending by Bitrate by both parseHLSManifest and parseDASHManifest.
+type Manifest struct {
+	Renditions []Rendition
+}
+
+// lowestBitrate and highestBitrate return Renditions' first/last entry -
+// valid because Renditions is always kept sorted ascending by Bitrate.
+func (m *Manifest) lowestBitrate() (Rendition, bool) {
+	if len(m.Renditions) == 0 {
+		return Rendition{}, false
+	}
+	return m.Renditions[0], true
+}
+
+func (m *Manifest) highestBitrate() (Rendition, bool) {
+	if len(m.Renditions) == 0 {
+		return Rendition{}, false
+	}
+	return m.Renditions[len(m.Renditions)-1], true
+}
+
+// VideoFetcher fetches and parses HLS/DASH manifests and issues
+// range-bounded segment downloads. httpVideoFetcher is the only
+// implementation; it's an interface so a test can fake delivery without
+// standing up a real HTTP server.
+type VideoFetcher interface {
+	FetchManifest(ctx context.Context, url string, manifestType ManifestType) (*Manifest, error)
+	FetchSegmentRange(ctx context.Context, url string, rng SegmentRange) ([]byte, error)
+}
+
+// httpVideoFetcher is the default VideoFetcher, backed by a plain
+// *http.Client the same way fetchVideoData always has been.
+type httpVideoFetcher struct {
+	client *http.Client
+}
+
+func newHTTPVideoFetcher(timeout time.Duration) *httpVideoFetcher {
+	return &httpVideoFetcher{client: &http.Client{Timeout: timeout}}
+}
+
+func (f *httpVideoFetcher) FetchManifest(ctx context.Context, url string, manifestType ManifestType) (*Manifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d fetching manifest %s", resp.StatusCode, url)
+	}
+
+	switch manifestType {
+	case ManifestHLS:
+		return parseHLSManifest(resp.Body, url)
+	case ManifestDASH:
+		return parseDASHManifest(resp.Body, url)
+	default:
+		return nil, fmt.Errorf("unsupported manifest type %q", manifestType)
+	}
+}
+
+func (f *httpVideoFetcher) FetchSegmentRange(ctx context.Context, url string, rng SegmentRange) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rng.End > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", rng.Start, rng.End))
+	} else if rng.Start > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", rng.Start))
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("HTTP %d fetching segment %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// hlsStreamInfBandwidth extracts BANDWIDTH=<n> from an
+// #EXT-X-STREAM-INF line of an HLS master playlist.
+var hlsStreamInfBandwidth = regexp.MustCompile(`BANDWIDTH=(\d+)`)
+
+// parseHLSManifest handles both HLS manifest shapes: a master playlist
+// (#EXT-X-STREAM-INF lines each followed by a variant playlist URL,
+// recorded here as a one-segment-URL Rendition per variant since this
+// parser doesn't itself recurse into fetching each variant's media
+// playlist) and a media playlist (plain segment URIs, one Rendition
+// with Bitrate 0 since a media playlist doesn't carry its own bitrate).
+// Every URI is resolved against baseURL (the manifest's own URL), since
+// both shapes commonly list segment/variant URIs relative to it. This
+// covers the common cases without being a spec-complete HLS parser - no
+// byte-range playlists, no alternate audio/subtitle renditions.
+func parseHLSManifest(r io.Reader, baseURL string) (*Manifest, error) {
+	scanner := bufio.NewScanner(r)
+	manifest := &Manifest{}
+	pendingBitrate := 0
+	havePending := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#EXT-X-STREAM-INF:")):
+			continue
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			if m := hlsStreamInfBandwidth.FindStringSubmatch(line); m != nil {
+				pendingBitrate, _ = strconv.Atoi(m[1])
+			} else {
+				pendingBitrate = 0
+			}
+			havePending = true
+		default:
+			resolved := resolveURL(baseURL, line)
+			if havePending {
+				manifest.Renditions = append(manifest.Renditions, Rendition{
+					Bitrate:     pendingBitrate,
+					SegmentURLs: []string{resolved},
+				})
+				havePending = false
+				continue
+			}
+			if len(manifest.Renditions) == 0 {
+				manifest.Renditions = append(manifest.Renditions, Rendition{Bitrate: 0})
+			}
+			last := &manifest.Renditions[len(manifest.Renditions)-1]
+			last.SegmentURLs = append(last.SegmentURLs, resolved)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sortRenditionsByBitrate(manifest.Renditions)
+	return manifest, nil
+}
+
+// dashBandwidth extracts bandwidth="<n>" from a DASH MPD
+// <Representation> element.
+var dashBandwidth = regexp.MustCompile(`bandwidth="(\d+)"`)
+
+// dashSegmentURL extracts the media="<url>" attribute of a DASH
+// <SegmentURL> element.
+var dashSegmentURL = regexp.MustCompile(`media="([^"]+)"`)
+
+// parseDASHManifest is a best-effort MPD scanner, not a spec-complete
+// DASH parser - no SegmentTemplate $Number$ expansion, no multi-period
+// support. It handles the common case of a single period with
+// <Representation bandwidth="..."> elements each listing explicit
+// <SegmentURL media="..."/> children, resolved against baseURL (the
+// manifest's own URL) the same way parseHLSManifest resolves its URIs.
+func parseDASHManifest(r io.Reader, baseURL string) (*Manifest, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := string(body)
+
+	manifest := &Manifest{}
+	for _, chunk := range strings.Split(text, "<Representation")[1:] {
+		bitrate := 0
+		if m := dashBandwidth.FindStringSubmatch(chunk); m != nil {
+			bitrate, _ = strconv.Atoi(m[1])
+		}
+
+		end := strings.Index(chunk, "</Representation>")
+		if end == -1 {
+			end = len(chunk)
+		}
+
+		var urls []string
+		for _, m := range dashSegmentURL.FindAllStringSubmatch(chunk[:end], -1) {
+			urls = append(urls, resolveURL(baseURL, m[1]))
+		}
+		if len(urls) == 0 {
+			continue
+		}
+		manifest.Renditions = append(manifest.Renditions, Rendition{Bitrate: bitrate, SegmentURLs: urls})
+	}
+
+	sortRenditionsByBitrate(manifest.Renditions)
+	return manifest, nil
+}
+
+// resolveURL resolves ref against base (the manifest's own URL), so a
+// manifest listing segment/variant URIs relative to itself - the common
+// case - still produces fetchable absolute URLs. ref is returned
+// unchanged if either URL fails to parse, or if it's already absolute.
+func resolveURL(base, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+func sortRenditionsByBitrate(renditions []Rendition) {
+	sort.Slice(renditions, func(i, j int) bool {
+		return renditions[i].Bitrate < renditions[j].Bitrate
+	})
+}
+
+// segmentBudget is how many segments of which quality tier
+// executePrefetchTask prefetches for a user type, and how
+// many seconds of playback a single Range request should cover -
+// fast_scroller gets the lowest bitrate and only the first segment;
+// binge_watcher gets the highest bitrate and several segments.
+type segmentBudget struct {
+	useHighestBitrate bool
+	segmentCount      int
+	rangeSeconds      float64
+}
+
+var segmentBudgetByUserType = map[string]segmentBudget{
+	"fast_scroller": {useHighestBitrate: false, segmentCount: 1, rangeSeconds: 2},
+	"normal_viewer": {useHighestBitrate: false, segmentCount: 2, rangeSeconds: 4},
+	"slow_viewer":   {useHighestBitrate: false, segmentCount: 1, rangeSeconds: 3},
+	"binge_watcher": {useHighestBitrate: true, segmentCount: 4, rangeSeconds: 6},
+}
+
+var defaultSegmentBudget = segmentBudget{useHighestBitrate: false, segmentCount: 2, rangeSeconds: 4}
+
+// segmentBudgetFor returns userType's segmentBudget, or
+// defaultSegmentBudget for an unrecognized/"unknown" type.
+func segmentBudgetFor(userType string) segmentBudget {
+	if b, ok := segmentBudgetByUserType[userType]; ok {
+		return b
+	}
+	return defaultSegmentBudget
+}
+
+// renditionLabelFor is the segmentCache Rendition label for budget's
+// quality tier - just "low"/"high" since the cache key only needs to
+// distinguish tiers, not carry the exact bitrate number.
+func renditionLabelFor(budget segmentBudget) string {
+	if budget.useHighestBitrate {
+		return "high"
+	}
+	return "low"
+}
+
+// segmentRangeFor estimates the byte range covering rangeSeconds of
+// playback at bitrate (bits/sec), so fetchSegments downloads only the
+// front of each segment instead of the whole thing. bitrate <= 0 (an HLS
+// media playlist with no BANDWIDTH, or a DASH Representation missing
+// bandwidth) falls back to fetching the whole segment.
+func segmentRangeFor(bitrate int, rangeSeconds float64) SegmentRange {
+	if bitrate <= 0 {
+		return SegmentRange{}
+	}
+	bytes := int64(float64(bitrate) * rangeSeconds / 8)
+	if bytes <= 0 {
+		return SegmentRange{}
+	}
+	return SegmentRange{Start: 0, End: bytes}
+}
@@ -0,0 +1,369 @@
+package prefetcher
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventType identifies a kind of lifecycle event webhookBus fans out to
+// subscribers. A WebhookSubscription's EventTypes filters which of these
+// it receives.
+type EventType string
+
+const (
+	EventPrefetchSuccess EventType = "prefetch.success"
+	EventPrefetchFailed  EventType = "prefetch.failed"
+	EventSessionCreated  EventType = "session.created"
+	EventSessionExpired  EventType = "session.expired"
+	EventBehaviorChanged EventType = "behavior.changed"
+	// eventWebhookTest is synthetic, emitted only by
+	// handleWebhookTest - not a lifecycle event, so it isn't one a real
+	// subscription would normally list in EventTypes, but a subscription
+	// can still ask for it to verify delivery end-to-end.
+	eventWebhookTest EventType = "webhook.test"
+)
+
+// WebhookEvent is the envelope every subscriber receives, POSTed as JSON
+// with Data holding one of the schemas below depending on Type:
+//
+//	prefetch.success -> PrefetchSuccessData
+//	prefetch.failed  -> PrefetchFailedData
+//	session.created  -> SessionLifecycleData
+//	session.expired  -> SessionLifecycleData
+//	behavior.changed -> BehaviorChangedData
+//	webhook.test     -> WebhookTestData
+type WebhookEvent struct {
+	Type      EventType       `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// PrefetchSuccessData is EventPrefetchSuccess's schema.
+type PrefetchSuccessData struct {
+	UserID       string  `json:"user_id"`
+	ReelID       int     `json:"reel_id"`
+	ResponseTime float64 `json:"response_time_seconds"`
+}
+
+// PrefetchFailedData is EventPrefetchFailed's schema.
+type PrefetchFailedData struct {
+	UserID string `json:"user_id"`
+	ReelID int    `json:"reel_id"`
+	Error  string `json:"error"`
+}
+
+// SessionLifecycleData is EventSessionCreated and EventSessionExpired's
+// shared schema.
+type SessionLifecycleData struct {
+	UserID string `json:"user_id"`
+}
+
+// BehaviorChangedData is EventBehaviorChanged's schema.
+type BehaviorChangedData struct {
+	UserID      string  `json:"user_id"`
+	UserType    string  `json:"user_type"`
+	ScrollSpeed float64 `json:"scroll_speed"`
+}
+
+// WebhookTestData is eventWebhookTest's schema.
+type WebhookTestData struct {
+	Message string `json:"message"`
+}
+
+// WebhookSubscription is a registered endpoint, filtered by EventTypes.
+// Secret is accepted on creation and used to HMAC-sign every delivery, but
+// is never echoed back by List/handleListWebhooks.
+type WebhookSubscription struct {
+	ID         string      `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// webhookQueueSize bounds how many undelivered events a single
+// subscriber can buffer. A subscriber whose consumer stalls drops new
+// events past this instead of blocking Publish, so a slow webhook
+// endpoint can't stall prefetching.
+const webhookQueueSize = 64
+
+// webhookRetryBackoffMin/Max/webhookMaxAttempts bound deliver's retry of a
+// single event: exponential backoff starting at webhookRetryBackoffMin,
+// capped at webhookRetryBackoffMax, giving up after webhookMaxAttempts.
+const (
+	webhookRetryBackoffMin = 1 * time.Second
+	webhookRetryBackoffMax = 1 * time.Minute
+	webhookMaxAttempts     = 5
+)
+
+// webhookSubscriber pairs a WebhookSubscription with its own queue and
+// delivery goroutine, so one slow or failing subscriber's retries never
+// block delivery to any other subscriber.
+type webhookSubscriber struct {
+	sub    WebhookSubscription
+	events chan WebhookEvent
+	stop   chan struct{}
+}
+
+// webhookBus fans WebhookEvents out to every subscription registered for
+// that event's Type. It's the "event bus" executePrefetchTask, AddUser,
+// performCleanup and UpdateUserBehavior publish through, alongside their
+// existing logrus calls.
+type webhookBus struct {
+	mu          sync.RWMutex
+	subscribers map[string]*webhookSubscriber
+	client      *http.Client
+}
+
+func newWebhookBus() *webhookBus {
+	return &webhookBus{
+		subscribers: make(map[string]*webhookSubscriber),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Subscribe registers sub and starts its delivery goroutine.
+func (b *webhookBus) Subscribe(sub WebhookSubscription) {
+	s := &webhookSubscriber{
+		sub:    sub,
+		events: make(chan WebhookEvent, webhookQueueSize),
+		stop:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub.ID] = s
+	b.mu.Unlock()
+
+	go b.runSubscriber(s)
+}
+
+// Unsubscribe stops id's delivery goroutine and removes it, reporting
+// whether a subscription with that ID existed.
+func (b *webhookBus) Unsubscribe(id string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.subscribers[id]
+	if !ok {
+		return false
+	}
+	close(s.stop)
+	delete(b.subscribers, id)
+	return true
+}
+
+// List returns every current subscription.
+func (b *webhookBus) List() []WebhookSubscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	subs := make([]WebhookSubscription, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		sub := s.sub
+		sub.Secret = ""
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// Publish fans event out to every subscriber whose EventTypes includes
+// event.Type, dropping it for any subscriber whose queue is already full.
+func (b *webhookBus) Publish(event WebhookEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		if !subscribesTo(s.sub, event.Type) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			logrus.Warnf("📪 Webhook queue full for subscription %s, dropping %s event", s.sub.ID, event.Type)
+		}
+	}
+}
+
+func subscribesTo(sub WebhookSubscription, t EventType) bool {
+	for _, et := range sub.EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// runSubscriber drains s.events until s.stop is closed, delivering each
+// event in turn - a subscriber only ever has one delivery in flight, so a
+// slow endpoint backs up its own queue rather than reordering events.
+func (b *webhookBus) runSubscriber(s *webhookSubscriber) {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case event := <-s.events:
+			b.deliver(s.sub, event)
+		}
+	}
+}
+
+// deliver POSTs event to sub.URL, retrying on failure with exponential
+// backoff up to webhookMaxAttempts before giving up on that one event.
+func (b *webhookBus) deliver(sub WebhookSubscription, event WebhookEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logrus.Errorf("❌ failed to marshal %s event for webhook %s: %v", event.Type, sub.ID, err)
+		return
+	}
+
+	backoff := webhookRetryBackoffMin
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		if err := b.send(sub, payload); err != nil {
+			logrus.Warnf("⚠️ Webhook delivery to %s failed (attempt %d/%d): %v", sub.URL, attempt, webhookMaxAttempts, err)
+			if attempt == webhookMaxAttempts {
+				return
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > webhookRetryBackoffMax {
+				backoff = webhookRetryBackoffMax
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (b *webhookBus) send(sub WebhookSubscription, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sub.Secret != "" {
+		req.Header.Set("X-Kronop-Signature", signPayload(sub.Secret, payload))
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signPayload returns the hex-encoded HMAC-SHA256 of payload under
+// secret - the same scheme GitHub/Stripe webhooks use - so a subscriber
+// can verify X-Kronop-Signature with a standard hmac.Equal comparison.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookID generates a random (version 4, variant 1) UUID for a new
+// subscription, hand-rolled rather than pulling in an external dependency
+// for something this small - the same approach usagestats.newUUIDv4 takes.
+func newWebhookID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("wh_%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// HTTP handlers
+
+// handleWebhooks dispatches POST/GET/DELETE /api/v1/webhooks to the
+// create/list/delete operations below.
+func (e *Engine) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		e.handleCreateWebhook(w, r)
+	case http.MethodGet:
+		e.handleListWebhooks(w, r)
+	case http.MethodDelete:
+		e.handleDeleteWebhook(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (e *Engine) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL        string      `json:"url"`
+		Secret     string      `json:"secret"`
+		EventTypes []EventType `json:"event_types"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" || len(req.EventTypes) == 0 {
+		http.Error(w, "url and event_types are required", http.StatusBadRequest)
+		return
+	}
+
+	id, err := newWebhookID()
+	if err != nil {
+		http.Error(w, "failed to generate subscription id", http.StatusInternalServerError)
+		return
+	}
+
+	sub := WebhookSubscription{
+		ID:         id,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		CreatedAt:  time.Now(),
+	}
+	e.webhooks.Subscribe(sub)
+
+	sub.Secret = ""
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (e *Engine) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.webhooks.List())
+}
+
+func (e *Engine) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" || !e.webhooks.Unsubscribe(id) {
+		http.Error(w, "webhook subscription not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWebhookTest emits a synthetic eventWebhookTest event so a caller
+// can verify a subscription's endpoint and HMAC secret are wired up
+// correctly before relying on real traffic to exercise it.
+func (e *Engine) handleWebhookTest(w http.ResponseWriter, r *http.Request) {
+	data, _ := json.Marshal(WebhookTestData{Message: "synthetic test event from kronop-prefetcher"})
+	e.webhooks.Publish(WebhookEvent{
+		Type:      eventWebhookTest,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	w.WriteHeader(http.StatusAccepted)
+}
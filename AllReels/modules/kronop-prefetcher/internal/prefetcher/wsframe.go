@@ -0,0 +1,90 @@
+package prefetcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultChunkSize is how many raw payload bytes go into each wsFrame
+// before JSON+base64 envelope overhead - conservative enough that an
+// encoded frame comfortably fits under the 1 MB WebSocket message size
+// this package defaults to.
+const defaultChunkSize = 512 * 1024
+
+// wsFrame is one ordered segment of a chunked payload. Data round-trips
+// as a base64 string via encoding/json's default []byte handling, so a
+// frame is always sent as a single WebSocket TextMessage.
+type wsFrame struct {
+	Seq   int    `json:"seq"`
+	Final bool   `json:"final"`
+	Data  []byte `json:"data"`
+}
+
+// writeChunkedJSON marshals payload to JSON, then writes it to conn as
+// one or more wsFrame messages of at most chunkSize raw bytes each, so
+// an oversized BehaviorPrediction or bulk ScrollPattern payload survives
+// a WebSocket message-size limit instead of being silently dropped.
+// chunkSize <= 0 uses defaultChunkSize. An empty payload still produces
+// exactly one frame, with Final set.
+func writeChunkedJSON(conn *websocket.Conn, payload interface{}, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	for seq, offset := 0, 0; ; seq, offset = seq+1, offset+chunkSize {
+		end := offset + chunkSize
+		if end > len(raw) {
+			end = len(raw)
+		}
+		final := end == len(raw)
+
+		frame := wsFrame{Seq: seq, Final: final, Data: raw[offset:end]}
+		frameBytes, err := json.Marshal(frame)
+		if err != nil {
+			return fmt.Errorf("marshal frame %d: %w", seq, err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, frameBytes); err != nil {
+			return fmt.Errorf("write frame %d: %w", seq, err)
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
+
+// frameReassembler collects wsFrame messages (as produced by
+// writeChunkedJSON) back into the original payload bytes in sequence
+// order. The zero value is ready to use.
+type frameReassembler struct {
+	parts [][]byte
+}
+
+// add appends a frame's data and reports whether it was the final frame,
+// meaning bytes() now returns the complete assembled payload.
+func (r *frameReassembler) add(frame wsFrame) (done bool) {
+	r.parts = append(r.parts, frame.Data)
+	return frame.Final
+}
+
+// bytes returns the payload assembled from every frame added so far, in
+// the order they were added.
+func (r *frameReassembler) bytes() []byte {
+	var total int
+	for _, p := range r.parts {
+		total += len(p)
+	}
+
+	out := make([]byte, 0, total)
+	for _, p := range r.parts {
+		out = append(out, p...)
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package prefetcher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestWriteChunkedJSONRoundTrip2MB round-trips a 2 MB payload through a
+// real WebSocket connection - writeChunkedJSON on the server side,
+// frameReassembler on the client side - and asserts the reassembled
+// payload is byte-for-byte identical, i.e. no frame was dropped or
+// truncated.
+func TestWriteChunkedJSONRoundTrip2MB(t *testing.T) {
+	payload := make([]byte, 2*1024*1024)
+	for i := range payload {
+		payload[i] = byte(i % 251)
+	}
+
+	upgrader := websocket.Upgrader{ReadBufferSize: 1 << 20, WriteBufferSize: 1 << 20}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("server upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		if err := writeChunkedJSON(conn, payload, 0); err != nil {
+			t.Errorf("writeChunkedJSON failed: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("client dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadLimit(4 << 20)
+
+	var reassembler frameReassembler
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("client read failed: %v", err)
+		}
+
+		var frame wsFrame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("unmarshal frame failed: %v", err)
+		}
+		if reassembler.add(frame) {
+			break
+		}
+	}
+
+	var got []byte
+	if err := json.Unmarshal(reassembler.bytes(), &got); err != nil {
+		t.Fatalf("unmarshal reassembled payload failed: %v", err)
+	}
+
+	if len(got) != len(payload) {
+		t.Fatalf("reassembled payload length = %d, want %d (truncated)", len(got), len(payload))
+	}
+	for i := range payload {
+		if got[i] != payload[i] {
+			t.Fatalf("reassembled payload differs at byte %d: got %d, want %d", i, got[i], payload[i])
+		}
+	}
+}
+
+// TestWriteChunkedJSONSingleFrameWhenSmall checks a payload smaller than
+// chunkSize still produces exactly one, Final frame.
+func TestWriteChunkedJSONSingleFrameWhenSmall(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("server upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		if err := writeChunkedJSON(conn, "hello", 0); err != nil {
+			t.Errorf("writeChunkedJSON failed: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("client dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("client read failed: %v", err)
+	}
+
+	var frame wsFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		t.Fatalf("unmarshal frame failed: %v", err)
+	}
+	if frame.Seq != 0 || !frame.Final {
+		t.Fatalf("frame = %+v, want seq=0 final=true", frame)
+	}
+}
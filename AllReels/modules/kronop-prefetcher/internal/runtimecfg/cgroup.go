@@ -0,0 +1,85 @@
+package runtimecfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupV2CPUMaxPath = "/sys/fs/cgroup/cpu.max"
+	cgroupV1QuotaPath  = "/sys/fs/cgroup/cpu/cpu.cfs_quota_us"
+	cgroupV1PeriodPath = "/sys/fs/cgroup/cpu/cpu.cfs_period_us"
+
+	cgroupUnlimitedMarker = "max"
+)
+
+// DetectCPUQuota reads the host's cgroup CPU quota - cgroup v2's cpu.max
+// first, falling back to cgroup v1's cpu.cfs_quota_us/cpu.cfs_period_us -
+// and returns it in whole-or-fractional CPUs. ok is false when no quota
+// is set (the container isn't CPU-limited, or the cgroup files aren't
+// present at all, e.g. running outside a container); callers should then
+// fall back to runtime.NumCPU().
+func DetectCPUQuota(fs FS) (quota float64, ok bool, err error) {
+	if quota, ok, err = detectCgroupV2(fs); ok || err != nil {
+		return quota, ok, err
+	}
+	return detectCgroupV1(fs)
+}
+
+func detectCgroupV2(fs FS) (float64, bool, error) {
+	data, err := fs.ReadFile(cgroupV2CPUMaxPath)
+	if err != nil {
+		return 0, false, nil
+	}
+
+	fields := strings.Fields(strings.TrimSpace(string(data)))
+	if len(fields) != 2 {
+		return 0, false, fmt.Errorf("runtimecfg: malformed %s: %q", cgroupV2CPUMaxPath, data)
+	}
+	if fields[0] == cgroupUnlimitedMarker {
+		return 0, false, nil
+	}
+
+	quotaUs, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("runtimecfg: parsing %s quota: %w", cgroupV2CPUMaxPath, err)
+	}
+	periodUs, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("runtimecfg: parsing %s period: %w", cgroupV2CPUMaxPath, err)
+	}
+	if periodUs <= 0 {
+		return 0, false, fmt.Errorf("runtimecfg: non-positive period in %s: %q", cgroupV2CPUMaxPath, data)
+	}
+	return quotaUs / periodUs, true, nil
+}
+
+func detectCgroupV1(fs FS) (float64, bool, error) {
+	quotaData, err := fs.ReadFile(cgroupV1QuotaPath)
+	if err != nil {
+		return 0, false, nil
+	}
+	periodData, err := fs.ReadFile(cgroupV1PeriodPath)
+	if err != nil {
+		return 0, false, nil
+	}
+
+	quotaUs, err := strconv.ParseFloat(strings.TrimSpace(string(quotaData)), 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("runtimecfg: parsing %s: %w", cgroupV1QuotaPath, err)
+	}
+	if quotaUs <= 0 {
+		// -1 (or any non-positive value) means "unlimited" under cgroup v1.
+		return 0, false, nil
+	}
+
+	periodUs, err := strconv.ParseFloat(strings.TrimSpace(string(periodData)), 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("runtimecfg: parsing %s: %w", cgroupV1PeriodPath, err)
+	}
+	if periodUs <= 0 {
+		return 0, false, fmt.Errorf("runtimecfg: non-positive period in %s", cgroupV1PeriodPath)
+	}
+	return quotaUs / periodUs, true, nil
+}
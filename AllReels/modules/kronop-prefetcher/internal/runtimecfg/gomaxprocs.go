@@ -0,0 +1,46 @@
+package runtimecfg
+
+import (
+	"math"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ApplyGOMAXPROCS detects the host's cgroup CPU quota via fs, converts it
+// to a whole CPU count (rounding up when roundUp, truncating otherwise),
+// calls runtime.GOMAXPROCS with that value, and returns it. If no quota
+// is set (bare metal, or not containerized), it leaves GOMAXPROCS at Go's
+// own default and returns that instead.
+func ApplyGOMAXPROCS(fs FS, roundUp bool) (int, error) {
+	quota, ok, err := DetectCPUQuota(fs)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		procs := runtime.GOMAXPROCS(0)
+		logrus.Infof("⚙️ No cgroup CPU quota detected, leaving GOMAXPROCS=%d", procs)
+		return procs, nil
+	}
+
+	procs := wholeCPUs(quota, roundUp)
+	runtime.GOMAXPROCS(procs)
+	logrus.Infof("⚙️ Detected cgroup CPU quota=%.2f, set GOMAXPROCS=%d", quota, procs)
+	return procs, nil
+}
+
+// wholeCPUs converts a fractional CPU quota (e.g. 0.5 for Kubernetes'
+// 500m) into a whole CPU count for GOMAXPROCS and worker-pool sizing,
+// never returning less than 1.
+func wholeCPUs(quota float64, roundUp bool) int {
+	var procs int
+	if roundUp {
+		procs = int(math.Ceil(quota))
+	} else {
+		procs = int(quota)
+	}
+	if procs < 1 {
+		procs = 1
+	}
+	return procs
+}
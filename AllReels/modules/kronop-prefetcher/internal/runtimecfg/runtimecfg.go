@@ -0,0 +1,23 @@
+// Package runtimecfg detects container CPU limits (cgroup v1/v2 quotas)
+// at startup and uses them to set GOMAXPROCS and size worker pools, so
+// the engine doesn't oversubscribe goroutines when running under a
+// Kubernetes CPU quota (e.g. 500m) that gives it fewer cores than the
+// host actually has.
+package runtimecfg
+
+import "os"
+
+// FS abstracts the filesystem reads this package needs, so cgroup
+// detection can be unit-tested against synthetic files instead of the
+// real /sys/fs/cgroup hierarchy.
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// OSFS implements FS against the real filesystem.
+type OSFS struct{}
+
+// ReadFile reads path via os.ReadFile.
+func (OSFS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
@@ -0,0 +1,137 @@
+package runtimecfg
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+// fakeFS is an in-memory FS for injecting synthetic cgroup files.
+type fakeFS map[string][]byte
+
+func (f fakeFS) ReadFile(path string) ([]byte, error) {
+	data, ok := f[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func TestDetectCPUQuotaCgroupV2(t *testing.T) {
+	fs := fakeFS{cgroupV2CPUMaxPath: []byte("50000 100000\n")}
+
+	quota, ok, err := DetectCPUQuota(fs)
+	if err != nil {
+		t.Fatalf("DetectCPUQuota returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("DetectCPUQuota returned ok=false, want true")
+	}
+	if quota != 0.5 {
+		t.Fatalf("quota = %v, want 0.5", quota)
+	}
+}
+
+func TestDetectCPUQuotaCgroupV2Unlimited(t *testing.T) {
+	fs := fakeFS{cgroupV2CPUMaxPath: []byte("max 100000\n")}
+
+	_, ok, err := DetectCPUQuota(fs)
+	if err != nil {
+		t.Fatalf("DetectCPUQuota returned error: %v", err)
+	}
+	if ok {
+		t.Fatalf("DetectCPUQuota returned ok=true for an unlimited quota")
+	}
+}
+
+func TestDetectCPUQuotaCgroupV1(t *testing.T) {
+	fs := fakeFS{
+		cgroupV1QuotaPath:  []byte("200000\n"),
+		cgroupV1PeriodPath: []byte("100000\n"),
+	}
+
+	quota, ok, err := DetectCPUQuota(fs)
+	if err != nil {
+		t.Fatalf("DetectCPUQuota returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("DetectCPUQuota returned ok=false, want true")
+	}
+	if quota != 2.0 {
+		t.Fatalf("quota = %v, want 2.0", quota)
+	}
+}
+
+func TestDetectCPUQuotaCgroupV1Unlimited(t *testing.T) {
+	fs := fakeFS{
+		cgroupV1QuotaPath:  []byte("-1\n"),
+		cgroupV1PeriodPath: []byte("100000\n"),
+	}
+
+	_, ok, err := DetectCPUQuota(fs)
+	if err != nil {
+		t.Fatalf("DetectCPUQuota returned error: %v", err)
+	}
+	if ok {
+		t.Fatalf("DetectCPUQuota returned ok=true for an unlimited (-1) quota")
+	}
+}
+
+func TestDetectCPUQuotaNoCgroupFiles(t *testing.T) {
+	_, ok, err := DetectCPUQuota(fakeFS{})
+	if err != nil {
+		t.Fatalf("DetectCPUQuota returned error: %v", err)
+	}
+	if ok {
+		t.Fatalf("DetectCPUQuota returned ok=true with no cgroup files present")
+	}
+}
+
+func TestWholeCPUs(t *testing.T) {
+	cases := []struct {
+		quota   float64
+		roundUp bool
+		want    int
+	}{
+		{0.5, true, 1},
+		{2.3, true, 3},
+		{2.3, false, 2},
+		{0.1, false, 1},
+	}
+
+	for _, tc := range cases {
+		if got := wholeCPUs(tc.quota, tc.roundUp); got != tc.want {
+			t.Errorf("wholeCPUs(%v, %v) = %d, want %d", tc.quota, tc.roundUp, got, tc.want)
+		}
+	}
+}
+
+func TestApplyGOMAXPROCSNoQuota(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(original)
+
+	procs, err := ApplyGOMAXPROCS(fakeFS{}, true)
+	if err != nil {
+		t.Fatalf("ApplyGOMAXPROCS returned error: %v", err)
+	}
+	if procs != original {
+		t.Fatalf("procs = %d, want unchanged GOMAXPROCS %d", procs, original)
+	}
+}
+
+func TestApplyGOMAXPROCSWithQuota(t *testing.T) {
+	original := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(original)
+
+	fs := fakeFS{cgroupV2CPUMaxPath: []byte("150000 100000\n")}
+	procs, err := ApplyGOMAXPROCS(fs, true)
+	if err != nil {
+		t.Fatalf("ApplyGOMAXPROCS returned error: %v", err)
+	}
+	if procs != 2 {
+		t.Fatalf("procs = %d, want 2", procs)
+	}
+	if got := runtime.GOMAXPROCS(0); got != 2 {
+		t.Fatalf("runtime.GOMAXPROCS(0) = %d, want 2", got)
+	}
+}
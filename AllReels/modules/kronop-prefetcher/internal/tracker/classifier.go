@@ -0,0 +1,161 @@
+package tracker
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Classifier assigns a BehaviorProfile a user type and a confidence in
+// that assignment. It replaces what used to be a hardcoded decision tree
+// directly inside determineUserType/calculateConfidence, so the
+// classification logic can be swapped (RuleClassifier,
+// OnlineLogisticClassifier) without touching GetBehaviorProfile.
+type Classifier interface {
+	Classify(profile *BehaviorProfile) (userType string, confidence float64)
+}
+
+// featureNames names the 8 features every Classifier implementation
+// reads off a BehaviorProfile, in the fixed order featureVector returns
+// them.
+var featureNames = [8]string{
+	"avg_speed", "peak_speed", "scroll_variance", "direction_ratio",
+	"avg_watch_time", "completion_rate", "interaction_count", "session_duration",
+}
+
+func featureIndex(name string) int {
+	for i, n := range featureNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func featureVector(profile *BehaviorProfile) [8]float64 {
+	return [8]float64{
+		profile.ScrollMetrics.AvgSpeed,
+		profile.ScrollMetrics.PeakSpeed,
+		profile.ScrollMetrics.ScrollVariance,
+		profile.ScrollMetrics.DirectionRatio,
+		profile.WatchMetrics.AvgWatchTime,
+		profile.WatchMetrics.CompletionRate,
+		float64(profile.InteractionCount),
+		profile.SessionDuration.Seconds(),
+	}
+}
+
+// ClassifierRule is one threshold check a RuleClassifier evaluates
+// against a BehaviorProfile's feature vector.
+type ClassifierRule struct {
+	Feature   string  `yaml:"feature"`  // one of featureNames
+	Operator  string  `yaml:"operator"` // "gt", "gte", "lt", "lte"
+	Threshold float64 `yaml:"threshold"`
+	Class     string  `yaml:"class"`
+	Weight    float64 `yaml:"weight"`
+}
+
+func (r ClassifierRule) matches(v float64) bool {
+	switch r.Operator {
+	case "gt":
+		return v > r.Threshold
+	case "gte":
+		return v >= r.Threshold
+	case "lt":
+		return v < r.Threshold
+	case "lte":
+		return v <= r.Threshold
+	default:
+		return false
+	}
+}
+
+// RuleClassifier is a weighted-vote version of the decision tree that
+// used to live directly in determineUserType: every rule whose feature
+// satisfies its operator/threshold adds Weight to that rule's Class, the
+// highest-scoring class wins, and confidence is its share of the total
+// weight that matched anything. A single-feature rule can't express the
+// original tree's compound "watchTime>30 && completionRate>0.8"
+// conditions directly - those are approximated here as two rules voting
+// for the same class - so this is a close starting point, not a byte-for
+// -byte translation; tune the rules file to taste.
+type RuleClassifier struct {
+	Rules []ClassifierRule `yaml:"rules"`
+}
+
+// LoadRuleClassifier reads a RuleClassifier's rules from a YAML file.
+func LoadRuleClassifier(path string) (*RuleClassifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifier rules: %v", err)
+	}
+
+	var c RuleClassifier
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse classifier rules: %v", err)
+	}
+	return &c, nil
+}
+
+// DefaultRuleClassifier returns a RuleClassifier seeded with rules
+// approximating the tracker's original hardcoded thresholds.
+func DefaultRuleClassifier() *RuleClassifier {
+	return &RuleClassifier{Rules: defaultClassifierRules()}
+}
+
+func defaultClassifierRules() []ClassifierRule {
+	return []ClassifierRule{
+		{Feature: "avg_speed", Operator: "gt", Threshold: 5.0, Class: "fast_scroller", Weight: 1.0},
+		{Feature: "avg_watch_time", Operator: "gt", Threshold: 30.0, Class: "binge_watcher", Weight: 0.5},
+		{Feature: "completion_rate", Operator: "gt", Threshold: 0.8, Class: "binge_watcher", Weight: 0.5},
+		{Feature: "avg_speed", Operator: "lt", Threshold: 0.5, Class: "slow_viewer", Weight: 0.5},
+		{Feature: "avg_watch_time", Operator: "lt", Threshold: 5.0, Class: "slow_viewer", Weight: 0.5},
+		{Feature: "avg_watch_time", Operator: "lt", Threshold: 5.0, Class: "casual_browser", Weight: 1.0},
+		{Feature: "avg_speed", Operator: "gte", Threshold: 1.0, Class: "normal_viewer", Weight: 0.34},
+		{Feature: "avg_speed", Operator: "lte", Threshold: 3.0, Class: "normal_viewer", Weight: 0.33},
+		{Feature: "avg_watch_time", Operator: "gte", Threshold: 5.0, Class: "normal_viewer", Weight: 0.33},
+	}
+}
+
+// Classify implements Classifier.
+func (c *RuleClassifier) Classify(profile *BehaviorProfile) (string, float64) {
+	features := featureVector(profile)
+
+	scores := make(map[string]float64)
+	var order []string
+	var totalWeight float64
+
+	for _, rule := range c.Rules {
+		idx := featureIndex(rule.Feature)
+		if idx < 0 || !rule.matches(features[idx]) {
+			continue
+		}
+		if _, seen := scores[rule.Class]; !seen {
+			order = append(order, rule.Class)
+		}
+		scores[rule.Class] += rule.Weight
+		totalWeight += rule.Weight
+	}
+
+	if len(order) == 0 {
+		return "unknown", 0.0
+	}
+
+	bestClass := order[0]
+	bestScore := scores[bestClass]
+	for _, class := range order[1:] {
+		if scores[class] > bestScore {
+			bestClass, bestScore = class, scores[class]
+		}
+	}
+
+	confidence := bestScore
+	if totalWeight > 0 {
+		confidence = bestScore / totalWeight
+	}
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+	return bestClass, confidence
+}
@@ -0,0 +1,154 @@
+// Package dumpreader decodes the binary log written by
+// tracker.RawEventRecorder back into individual events, for offline
+// analysis tools such as cmd/kronop-tracker-dump.
+package dumpreader
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/kronop/prefetcher/internal/tracker"
+)
+
+// Event is a decoded raw-dump record. It mirrors tracker.UserEvent's
+// information but stays independent of that type so this package has no
+// dependency on anything beyond tracker.RawEventKind for decoding.
+type Event struct {
+	Kind      tracker.RawEventKind
+	Offset    time.Duration // since the dump's session start
+	ReelID    uint64
+	Tag       string // interned direction/interaction-type label, if any
+	FromReel  uint64
+	ToReel    uint64
+	Speed     float64
+	Duration  time.Duration
+	Completed bool
+	Position  float64
+	Dropped   uint64 // only set when Kind == tracker.RawKindDropped
+}
+
+// ReadStringTable loads path's sidecar interned-string file (written
+// alongside a dump at "<path>.strings") back into id-indexed order.
+func ReadStringTable(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open string table: %v", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var strs []string
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read string table: %v", err)
+		}
+
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("failed to read string table entry: %v", err)
+		}
+		strs = append(strs, string(buf))
+	}
+	return strs, nil
+}
+
+// ReadShard decodes one shard file (tracker.RawEventRecorder writes one
+// per shard, at "<path>.0", "<path>.1", ...) into its events, in the
+// order they were written. A truncated trailing record - e.g. the
+// process was killed mid-write - ends decoding and returns the events
+// read so far alongside the error, rather than discarding them.
+func ReadShard(path string, strings []string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw dump shard: %v", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var events []Event
+	for {
+		var lenPrefix [4]byte
+		if _, err := io.ReadFull(r, lenPrefix[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return events, fmt.Errorf("failed to read record length: %v", err)
+		}
+
+		buf := make([]byte, binary.BigEndian.Uint32(lenPrefix[:]))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return events, fmt.Errorf("failed to read record: %v", err)
+		}
+
+		ev, err := decodeEvent(buf, strings)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func decodeEvent(buf []byte, strings []string) (Event, error) {
+	if len(buf) < 1 {
+		return Event{}, fmt.Errorf("raw dump record too short")
+	}
+	kind := tracker.RawEventKind(buf[0])
+	buf = buf[1:]
+
+	nanos, n := binary.Uvarint(buf)
+	buf = buf[n:]
+	reelID, n := binary.Uvarint(buf)
+	buf = buf[n:]
+
+	ev := Event{Kind: kind, Offset: time.Duration(nanos), ReelID: reelID}
+
+	switch kind {
+	case tracker.RawKindScroll:
+		tag, n := binary.Uvarint(buf)
+		buf = buf[n:]
+		ev.Tag = tagString(strings, tag)
+
+		ev.FromReel, n = binary.Uvarint(buf)
+		buf = buf[n:]
+		ev.ToReel, n = binary.Uvarint(buf)
+		buf = buf[n:]
+
+		ev.Speed = math.Float64frombits(binary.BigEndian.Uint64(buf))
+		buf = buf[8:]
+
+		dur, _ := binary.Uvarint(buf)
+		ev.Duration = time.Duration(dur)
+	case tracker.RawKindWatch:
+		dur, n := binary.Uvarint(buf)
+		buf = buf[n:]
+		ev.Duration = time.Duration(dur)
+
+		ev.Completed = buf[0] == 1
+		buf = buf[1:]
+
+		ev.Position = math.Float64frombits(binary.BigEndian.Uint64(buf))
+	case tracker.RawKindInteraction:
+		tag, _ := binary.Uvarint(buf)
+		ev.Tag = tagString(strings, tag)
+	case tracker.RawKindDropped:
+		ev.Dropped = reelID
+	}
+	return ev, nil
+}
+
+func tagString(strings []string, id uint64) string {
+	if int(id) >= len(strings) {
+		return ""
+	}
+	return strings[id]
+}
@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OnlineLogisticClassifier is a linear multi-class classifier - one
+// weight vector per class over the 8 features in featureNames - trained
+// online via stochastic gradient descent. Classify scores every known
+// class as w[c]·x and returns softmax confidence; Feedback nudges every
+// class's vector with w[c] += lr * (y - sigmoid(w[c]·x)) * x, where y is
+// 1 for the reported class and 0 for the rest, then renormalizes. A
+// class is added (with a zero vector) the first time Feedback names it.
+type OnlineLogisticClassifier struct {
+	mu      sync.Mutex
+	lr      float64
+	weights map[string][]float64 // class -> weight vector, len(featureNames)
+
+	lastFeatures [8]float64
+	havePrior    bool
+
+	path string // weights persistence path; "" disables persistence
+}
+
+// NewOnlineLogisticClassifier creates a classifier with learning rate lr.
+// If persistPath is non-empty, it's used both to reload a previously
+// saved weight set now and to save one later via Save.
+func NewOnlineLogisticClassifier(lr float64, persistPath string) *OnlineLogisticClassifier {
+	c := &OnlineLogisticClassifier{
+		lr:      lr,
+		weights: make(map[string][]float64),
+		path:    persistPath,
+	}
+
+	if persistPath != "" {
+		if err := c.load(); err != nil && !os.IsNotExist(err) {
+			logrus.Errorf("❌ failed to load classifier weights from %s: %v", persistPath, err)
+		}
+	}
+	return c
+}
+
+// Classify implements Classifier.
+func (c *OnlineLogisticClassifier) Classify(profile *BehaviorProfile) (string, float64) {
+	features := featureVector(profile)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastFeatures = features
+	c.havePrior = true
+
+	if len(c.weights) == 0 {
+		return "unknown", 0.0
+	}
+
+	scores := make(map[string]float64, len(c.weights))
+	for class, w := range c.weights {
+		scores[class] = dotProduct(w, features[:])
+	}
+
+	bestClass := ""
+	bestScore := math.Inf(-1)
+	for class, score := range scores {
+		if score > bestScore {
+			bestClass, bestScore = class, score
+		}
+	}
+
+	return bestClass, softmax(scores)[bestClass]
+}
+
+// Feedback reports that actualClass was the correct classification for
+// the most recent Classify call on this classifier, and runs one SGD
+// step against it. It's a no-op if Classify hasn't been called yet.
+func (c *OnlineLogisticClassifier) Feedback(actualClass string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.havePrior {
+		return
+	}
+
+	if _, ok := c.weights[actualClass]; !ok {
+		c.weights[actualClass] = make([]float64, len(featureNames))
+	}
+
+	for class, w := range c.weights {
+		target := 0.0
+		if class == actualClass {
+			target = 1.0
+		}
+
+		pred := sigmoid(dotProduct(w, c.lastFeatures[:]))
+		step := c.lr * (target - pred)
+		for i := range w {
+			w[i] += step * c.lastFeatures[i]
+		}
+		normalizeWeights(w)
+	}
+
+	if c.path != "" {
+		if err := c.save(); err != nil {
+			logrus.Errorf("❌ failed to persist classifier weights: %v", err)
+		}
+	}
+}
+
+// Save persists the classifier's current weights to its configured path,
+// if any. Called from UserBehaviorTracker.Stop so training survives a
+// restart.
+func (c *OnlineLogisticClassifier) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.path == "" {
+		return nil
+	}
+	return c.save()
+}
+
+type classifierWeightsFile struct {
+	Weights map[string][]float64 `json:"weights"`
+}
+
+func (c *OnlineLogisticClassifier) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	var f classifierWeightsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse classifier weights: %v", err)
+	}
+	c.weights = f.Weights
+	return nil
+}
+
+func (c *OnlineLogisticClassifier) save() error {
+	data, err := json.Marshal(classifierWeightsFile{Weights: c.weights})
+	if err != nil {
+		return fmt.Errorf("failed to marshal classifier weights: %v", err)
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write classifier weights: %v", err)
+	}
+	return nil
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func dotProduct(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalizeWeights L2-normalizes w in place so repeated SGD updates don't
+// let a weight vector's magnitude grow without bound.
+func normalizeWeights(w []float64) {
+	var sumSquares float64
+	for _, v := range w {
+		sumSquares += v * v
+	}
+	norm := math.Sqrt(sumSquares)
+	if norm == 0 {
+		return
+	}
+	for i := range w {
+		w[i] /= norm
+	}
+}
+
+// softmax returns each class's score as a probability over the full set.
+func softmax(scores map[string]float64) map[string]float64 {
+	maxScore := math.Inf(-1)
+	for _, s := range scores {
+		if s > maxScore {
+			maxScore = s
+		}
+	}
+
+	exp := make(map[string]float64, len(scores))
+	var sum float64
+	for class, s := range scores {
+		e := math.Exp(s - maxScore)
+		exp[class] = e
+		sum += e
+	}
+
+	out := make(map[string]float64, len(scores))
+	for class, e := range exp {
+		out[class] = e / sum
+	}
+	return out
+}
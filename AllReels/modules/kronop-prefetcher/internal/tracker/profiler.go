@@ -0,0 +1,210 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profilerStripes is the number of independent lock/map shards
+// selfProfiler hashes activity names across, so two unrelated activities
+// (e.g. "processScrollEvent" and "processWatchEvent") almost never
+// contend on the same mutex.
+const profilerStripes = 16
+
+// ActivityStats is one activity's aggregated timing, as recorded by
+// selfProfiler.
+type ActivityStats struct {
+	Count     int64 `json:"count"`
+	TotalNs   int64 `json:"total_ns"`
+	MaxNs     int64 `json:"max_ns"`
+	CacheHits int64 `json:"cache_hits"`
+}
+
+type profilerStripe struct {
+	mu    sync.Mutex
+	stats map[string]*ActivityStats
+}
+
+// selfProfiler is a lightweight, always-on self-profiler modeled on
+// rustc's -Z self-profile: callers bracket a named activity with
+// StartActivity/(*activity).End and the aggregated
+// {count, total_ns, max_ns, cache_hits} is readable at any time through
+// report. This exists so operators can see where a tracker actually
+// spends CPU once it's handling thousands of users, instead of having to
+// infer it from logrus.Debugf lines.
+type selfProfiler struct {
+	stripes [profilerStripes]profilerStripe
+}
+
+func newSelfProfiler() *selfProfiler {
+	p := &selfProfiler{}
+	for i := range p.stripes {
+		p.stripes[i].stats = make(map[string]*ActivityStats)
+	}
+	return p
+}
+
+func (p *selfProfiler) stripeFor(name string) *profilerStripe {
+	var h uint32
+	for i := 0; i < len(name); i++ {
+		h = h*31 + uint32(name[i])
+	}
+	return &p.stripes[h%profilerStripes]
+}
+
+// activity is an in-flight StartActivity/End bracket.
+type activity struct {
+	p     *selfProfiler
+	name  string
+	start time.Time
+}
+
+// StartActivity begins timing a named activity, e.g. "processScrollEvent".
+// Nil-safe, so it can be called unconditionally and the returned
+// *activity's End/EndCacheHit just become no-ops.
+func (p *selfProfiler) StartActivity(name string) *activity {
+	if p == nil {
+		return nil
+	}
+	return &activity{p: p, name: name, start: time.Now()}
+}
+
+// End records a's elapsed time against its activity name. Typically
+// called via defer right after StartActivity.
+func (a *activity) End() {
+	if a == nil {
+		return
+	}
+	a.p.record(a.name, time.Since(a.start), false)
+}
+
+// EndCacheHit records a the same as End, but also counts it as a cache
+// hit for its activity - for a bracketed call that took a fast path
+// instead of doing the work the activity normally measures.
+func (a *activity) EndCacheHit() {
+	if a == nil {
+		return
+	}
+	a.p.record(a.name, time.Since(a.start), true)
+}
+
+// Observe records a pre-measured duration against name directly, for
+// latencies that don't fit a Start/End bracket - e.g. how long an event
+// sat in eventChan before processEvent picked it up.
+func (p *selfProfiler) Observe(name string, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.record(name, d, false)
+}
+
+// countDrop records a buffer-full drop against "<name>.dropped", keeping
+// drops out of the timing activities' count/avg so a burst of drops
+// doesn't skew them.
+func (p *selfProfiler) countDrop(name string) {
+	if p == nil {
+		return
+	}
+	stripe := p.stripeFor(name + ".dropped")
+	stripe.mu.Lock()
+	defer stripe.mu.Unlock()
+
+	stats, ok := stripe.stats[name+".dropped"]
+	if !ok {
+		stats = &ActivityStats{}
+		stripe.stats[name+".dropped"] = stats
+	}
+	stats.Count++
+}
+
+func (p *selfProfiler) record(name string, d time.Duration, cacheHit bool) {
+	stripe := p.stripeFor(name)
+	stripe.mu.Lock()
+	defer stripe.mu.Unlock()
+
+	stats, ok := stripe.stats[name]
+	if !ok {
+		stats = &ActivityStats{}
+		stripe.stats[name] = stats
+	}
+	stats.Count++
+	ns := d.Nanoseconds()
+	stats.TotalNs += ns
+	if ns > stats.MaxNs {
+		stats.MaxNs = ns
+	}
+	if cacheHit {
+		stats.CacheHits++
+	}
+}
+
+// ActivityReport is one row of GetProfilerReport's sorted table.
+type ActivityReport struct {
+	Name      string  `json:"name"`
+	Count     int64   `json:"count"`
+	TotalNs   int64   `json:"total_ns"`
+	MaxNs     int64   `json:"max_ns"`
+	AvgNs     float64 `json:"avg_ns"`
+	CacheHits int64   `json:"cache_hits"`
+}
+
+// report returns every recorded activity's current stats, sorted by
+// total time spent descending so the heaviest activities sort first.
+func (p *selfProfiler) report() []ActivityReport {
+	if p == nil {
+		return nil
+	}
+
+	var rows []ActivityReport
+	for i := range p.stripes {
+		stripe := &p.stripes[i]
+		stripe.mu.Lock()
+		for name, stats := range stripe.stats {
+			row := ActivityReport{
+				Name:      name,
+				Count:     stats.Count,
+				TotalNs:   stats.TotalNs,
+				MaxNs:     stats.MaxNs,
+				CacheHits: stats.CacheHits,
+			}
+			if stats.Count > 0 {
+				row.AvgNs = float64(stats.TotalNs) / float64(stats.Count)
+			}
+			rows = append(rows, row)
+		}
+		stripe.mu.Unlock()
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].TotalNs > rows[j].TotalNs
+	})
+	return rows
+}
+
+// GetProfilerReport returns every activity the tracker has timed so far
+// (processEvent and its per-event-type handlers, calculateScrollMetrics/
+// calculateWatchMetrics, and "<event_type>.dropped" for buffer-full
+// drops), sorted by total time spent descending.
+func (ubt *UserBehaviorTracker) GetProfilerReport() []ActivityReport {
+	return ubt.profiler.report()
+}
+
+// FormatProfilerTable renders a GetProfilerReport result as a
+// fixed-width text table, for logging or an operator console.
+func FormatProfilerTable(rows []ActivityReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-28s %10s %14s %14s %14s %10s\n",
+		"ACTIVITY", "COUNT", "TOTAL", "AVG", "MAX", "CACHE HITS")
+	for _, r := range rows {
+		fmt.Fprintf(&b, "%-28s %10d %14s %14s %14s %10d\n",
+			r.Name, r.Count,
+			time.Duration(r.TotalNs),
+			time.Duration(int64(r.AvgNs)),
+			time.Duration(r.MaxNs),
+			r.CacheHits)
+	}
+	return b.String()
+}
@@ -0,0 +1,314 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	rawDumpShards    = 4
+	rawDumpQueueSize = 1024
+)
+
+// RawEventKind identifies the record kind in a RawEventRecorder log.
+// Exported so tracker/dumpreader and kronop-tracker-dump can decode the
+// format without duplicating the constants.
+type RawEventKind byte
+
+const (
+	RawKindScroll RawEventKind = iota
+	RawKindWatch
+	RawKindInteraction
+	// RawKindDropped is a synthetic marker a shard writes before the next
+	// real record after a backlog of drops, so a reader can tell a gap in
+	// the log from a gap in activity. Its ReelID field holds the count.
+	RawKindDropped
+)
+
+func (k RawEventKind) String() string {
+	switch k {
+	case RawKindScroll:
+		return "scroll"
+	case RawKindWatch:
+		return "watch"
+	case RawKindInteraction:
+		return "interaction"
+	case RawKindDropped:
+		return "dropped"
+	default:
+		return "unknown"
+	}
+}
+
+// rawDumpEvent is the producer-side representation queued to a shard -
+// everything a shard needs to encode one record, computed up front so
+// the shard goroutine never touches the originating UserEvent.
+type rawDumpEvent struct {
+	kind      RawEventKind
+	nanos     uint64 // session-relative monotonic delta
+	reelID    uint64
+	tag       uint32 // interned direction/interaction-type id
+	fromReel  uint64
+	toReel    uint64
+	speed     float64
+	duration  uint64 // nanoseconds
+	completed bool
+	position  float64
+}
+
+// RawEventRecorder appends every UserEvent passing through processEvent
+// to a binary log, modeled on rustc's -Z self-profile raw event dump: a
+// compact, length-prefixed record per event plus a sidecar interned
+// string table for the handful of distinct direction/interaction-type
+// labels, so offline tooling (tracker/dumpreader, kronop-tracker-dump)
+// can replay exactly what the tracker saw rather than only the
+// aggregated ScrollMetrics/WatchMetrics.
+//
+// record is the only producer-facing entry point and never blocks: it
+// hashes to one of rawDumpShards independent writer goroutines and
+// attempts a non-blocking send on that shard's bounded channel. A full
+// channel doesn't drop silently - it's counted, and the next
+// successfully queued event on that shard is preceded by a
+// RawKindDropped marker recording how many were lost.
+type RawEventRecorder struct {
+	sessionStart time.Time
+	interner     *stringInterner
+	shards       [rawDumpShards]*rawDumpShard
+}
+
+type rawDumpShard struct {
+	ch      chan rawDumpEvent
+	dropped atomic.Int64
+	done    chan struct{}
+}
+
+// NewRawEventRecorder creates the sidecar string table and one log file
+// per shard under path (path.0, path.1, ... plus path.strings), and
+// starts each shard's writer goroutine.
+func NewRawEventRecorder(path string) (*RawEventRecorder, error) {
+	interner, err := newStringInterner(path + ".strings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw dump string table: %v", err)
+	}
+
+	r := &RawEventRecorder{
+		sessionStart: time.Now(),
+		interner:     interner,
+	}
+
+	for i := 0; i < rawDumpShards; i++ {
+		f, err := os.OpenFile(fmt.Sprintf("%s.%d", path, i), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open raw dump shard %d: %v", i, err)
+		}
+
+		shard := &rawDumpShard{
+			ch:   make(chan rawDumpEvent, rawDumpQueueSize),
+			done: make(chan struct{}),
+		}
+		r.shards[i] = shard
+		go shard.run(f)
+	}
+
+	return r, nil
+}
+
+// record queues event on the shard its reel ID hashes to. It never
+// blocks: a full shard counts the drop instead of waiting for room.
+func (r *RawEventRecorder) record(event UserEvent) {
+	if r == nil {
+		return
+	}
+
+	shard := r.shards[uint(event.ReelID)%rawDumpShards]
+
+	var de rawDumpEvent
+	de.nanos = uint64(event.Timestamp.Sub(r.sessionStart))
+	de.reelID = uint64(event.ReelID)
+
+	data, _ := event.Data.(map[string]interface{})
+	switch event.Type {
+	case "scroll":
+		de.kind = RawKindScroll
+		de.tag = r.interner.intern(stringField(data, "direction"))
+		de.fromReel = uintField(data, "from_reel")
+		de.toReel = uintField(data, "to_reel")
+		de.speed = floatField(data, "scroll_speed")
+		de.duration = uint64(floatField(data, "duration") * float64(time.Second))
+	case "watch":
+		de.kind = RawKindWatch
+		de.duration = uint64(floatField(data, "watch_time") * float64(time.Second))
+		de.completed = boolField(data, "completed")
+		de.position = floatField(data, "position")
+	case "interaction":
+		de.kind = RawKindInteraction
+		de.tag = r.interner.intern(stringField(data, "type"))
+	default:
+		return
+	}
+
+	if pending := shard.dropped.Swap(0); pending > 0 {
+		select {
+		case shard.ch <- rawDumpEvent{kind: RawKindDropped, reelID: uint64(pending)}:
+		default:
+			shard.dropped.Add(pending)
+		}
+	}
+
+	select {
+	case shard.ch <- de:
+	default:
+		shard.dropped.Add(1)
+	}
+}
+
+// Close drains and stops every shard's writer goroutine, then flushes the
+// string table.
+func (r *RawEventRecorder) Close() {
+	if r == nil {
+		return
+	}
+	for _, shard := range r.shards {
+		close(shard.ch)
+		<-shard.done
+	}
+	r.interner.flush()
+}
+
+func (s *rawDumpShard) run(f *os.File) {
+	defer close(s.done)
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	defer w.Flush()
+
+	buf := make([]byte, 0, 64)
+	for de := range s.ch {
+		buf = encodeRawDumpEvent(buf[:0], de)
+
+		var lenPrefix [4]byte
+		binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(buf)))
+		if _, err := w.Write(lenPrefix[:]); err != nil {
+			logrus.Errorf("❌ raw event dump write failed: %v", err)
+			continue
+		}
+		if _, err := w.Write(buf); err != nil {
+			logrus.Errorf("❌ raw event dump write failed: %v", err)
+		}
+	}
+}
+
+// encodeRawDumpEvent appends de's wire representation to buf and returns
+// the result: kind byte, nanos/reelID as uvarints, then kind-specific
+// fields. tracker/dumpreader mirrors this layout to decode it back.
+func encodeRawDumpEvent(buf []byte, de rawDumpEvent) []byte {
+	buf = append(buf, byte(de.kind))
+	buf = binary.AppendUvarint(buf, de.nanos)
+	buf = binary.AppendUvarint(buf, de.reelID)
+
+	switch de.kind {
+	case RawKindScroll:
+		buf = binary.AppendUvarint(buf, uint64(de.tag))
+		buf = binary.AppendUvarint(buf, de.fromReel)
+		buf = binary.AppendUvarint(buf, de.toReel)
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(de.speed))
+		buf = binary.AppendUvarint(buf, de.duration)
+	case RawKindWatch:
+		buf = binary.AppendUvarint(buf, de.duration)
+		if de.completed {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(de.position))
+	case RawKindInteraction:
+		buf = binary.AppendUvarint(buf, uint64(de.tag))
+	case RawKindDropped:
+		// de.reelID already carries the drop count.
+	}
+	return buf
+}
+
+// stringInterner assigns stable integer ids to the small set of distinct
+// labels raw events reference, and appends each newly-seen label to a
+// sidecar file as it's interned. The common case (an already-seen label)
+// only touches a sync.Map, so interning doesn't meaningfully contend with
+// record()'s callers.
+type stringInterner struct {
+	mu      sync.Mutex
+	f       *os.File
+	ids     sync.Map // string -> uint32
+	strings []string
+}
+
+func newStringInterner(path string) (*stringInterner, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &stringInterner{f: f}, nil
+}
+
+func (si *stringInterner) intern(s string) uint32 {
+	if v, ok := si.ids.Load(s); ok {
+		return v.(uint32)
+	}
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	if v, ok := si.ids.Load(s); ok {
+		return v.(uint32)
+	}
+
+	id := uint32(len(si.strings))
+	si.strings = append(si.strings, s)
+	si.ids.Store(s, id)
+
+	buf := binary.AppendUvarint(nil, uint64(len(s)))
+	buf = append(buf, s...)
+	if _, err := si.f.Write(buf); err != nil {
+		logrus.Errorf("❌ raw event dump string table write failed: %v", err)
+	}
+
+	return id
+}
+
+func (si *stringInterner) flush() {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	si.f.Sync()
+	si.f.Close()
+}
+
+func stringField(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
+
+func floatField(data map[string]interface{}, key string) float64 {
+	f, _ := data[key].(float64)
+	return f
+}
+
+func uintField(data map[string]interface{}, key string) uint64 {
+	if i, ok := data[key].(int); ok {
+		return uint64(i)
+	}
+	if f, ok := data[key].(float64); ok {
+		return uint64(f)
+	}
+	return 0
+}
+
+func boolField(data map[string]interface{}, key string) bool {
+	b, _ := data[key].(bool)
+	return b
+}
@@ -0,0 +1,226 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+)
+
+// seriesBucket holds the scroll/watch/interaction activity that landed
+// inside one bucket's time window.
+type seriesBucket struct {
+	scrollCount         int
+	scrollSpeedSum      float64
+	watchCount          int
+	watchTimeSum        time.Duration
+	watchCompletedCount int
+	interactionCounts   map[string]int
+}
+
+// seriesLevel is one resolution of a decayingSeries: a fixed-size ring of
+// fixed-width buckets. Advancing past the newest bucket zeroes whatever
+// buckets the clock skipped over, so a gap in activity reads the same as
+// a bucket that was actually observed to be quiet.
+type seriesLevel struct {
+	width   time.Duration
+	buckets []seriesBucket
+	cursor  int       // index of the newest bucket
+	start   time.Time // start time of buckets[cursor]
+}
+
+func newSeriesLevel(width time.Duration, numBuckets int) *seriesLevel {
+	return &seriesLevel{
+		width:   width,
+		buckets: make([]seriesBucket, numBuckets),
+	}
+}
+
+// advance rolls the level forward to t, zeroing every bucket between the
+// previous head and the new one.
+func (l *seriesLevel) advance(t time.Time) {
+	if l.start.IsZero() {
+		l.start = t.Truncate(l.width)
+		return
+	}
+
+	steps := int(t.Sub(l.start) / l.width)
+	if steps <= 0 {
+		return
+	}
+	if steps > len(l.buckets) {
+		steps = len(l.buckets)
+	}
+
+	for i := 0; i < steps; i++ {
+		l.cursor = (l.cursor + 1) % len(l.buckets)
+		l.buckets[l.cursor] = seriesBucket{}
+	}
+	l.start = l.start.Add(time.Duration(steps) * l.width)
+}
+
+func (l *seriesLevel) current() *seriesBucket {
+	return &l.buckets[l.cursor]
+}
+
+// sum folds together every bucket needed to cover the trailing window,
+// starting at the current head and walking backwards through the ring.
+func (l *seriesLevel) sum(window time.Duration, t time.Time) seriesBucket {
+	l.advance(t)
+
+	n := len(l.buckets)
+	bucketsNeeded := int(window/l.width) + 1
+	if bucketsNeeded > n {
+		bucketsNeeded = n
+	}
+
+	var total seriesBucket
+	total.interactionCounts = make(map[string]int)
+
+	idx := l.cursor
+	for i := 0; i < bucketsNeeded; i++ {
+		b := l.buckets[idx]
+		total.scrollCount += b.scrollCount
+		total.scrollSpeedSum += b.scrollSpeedSum
+		total.watchCount += b.watchCount
+		total.watchTimeSum += b.watchTimeSum
+		total.watchCompletedCount += b.watchCompletedCount
+		for k, v := range b.interactionCounts {
+			total.interactionCounts[k] += v
+		}
+
+		idx--
+		if idx < 0 {
+			idx = n - 1
+		}
+	}
+	return total
+}
+
+// decayingSeries tracks scroll/watch/interaction activity at three
+// resolutions - per-second buckets spanning the last minute, per-minute
+// buckets spanning the last hour, and per-10-minute buckets spanning the
+// last day - modeled on golang.org/x/net/internal/timeseries (that
+// package isn't importable outside x/net, so this is a small
+// from-scratch equivalent sized to what the tracker needs). Rate queries
+// use the finest level whose ring still covers the requested window, so
+// "scrolls per second over the last 30s" reads the per-second level
+// while "watch completion over the last day" reads the per-10-minute
+// one, without the tracker retaining raw events to answer either.
+//
+// A decayingSeries has its own lock, separate from UserSession.mu, so
+// GetRate and friends don't have to wait behind processEvent's
+// session-wide lock to read a snapshot.
+type decayingSeries struct {
+	mu     sync.Mutex
+	levels []*seriesLevel
+}
+
+func newDecayingSeries() *decayingSeries {
+	return &decayingSeries{
+		levels: []*seriesLevel{
+			newSeriesLevel(time.Second, 60),     // last minute
+			newSeriesLevel(time.Minute, 60),     // last hour
+			newSeriesLevel(10*time.Minute, 144), // last day
+		},
+	}
+}
+
+func (s *decayingSeries) recordScroll(t time.Time, speed float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, l := range s.levels {
+		l.advance(t)
+		b := l.current()
+		b.scrollCount++
+		b.scrollSpeedSum += speed
+	}
+}
+
+func (s *decayingSeries) recordWatch(t time.Time, watchTime time.Duration, completed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, l := range s.levels {
+		l.advance(t)
+		b := l.current()
+		b.watchCount++
+		b.watchTimeSum += watchTime
+		if completed {
+			b.watchCompletedCount++
+		}
+	}
+}
+
+func (s *decayingSeries) recordInteraction(t time.Time, interactionType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, l := range s.levels {
+		l.advance(t)
+		b := l.current()
+		if b.interactionCounts == nil {
+			b.interactionCounts = make(map[string]int)
+		}
+		b.interactionCounts[interactionType]++
+	}
+}
+
+// levelFor returns the finest-resolution level whose ring still covers
+// window, falling back to the widest level if window exceeds even that.
+func (s *decayingSeries) levelFor(window time.Duration) *seriesLevel {
+	for _, l := range s.levels {
+		if time.Duration(len(l.buckets))*l.width >= window {
+			return l
+		}
+	}
+	return s.levels[len(s.levels)-1]
+}
+
+// scrollRate summarizes scroll activity inside the trailing window.
+func (s *decayingSeries) scrollRate(window time.Duration, t time.Time) ScrollMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.levelFor(window).sum(window, t)
+	if b.scrollCount == 0 {
+		return ScrollMetrics{}
+	}
+	return ScrollMetrics{
+		AvgSpeed:     b.scrollSpeedSum / float64(b.scrollCount),
+		TotalScrolls: b.scrollCount,
+	}
+}
+
+// watchRate summarizes watch activity inside the trailing window.
+func (s *decayingSeries) watchRate(window time.Duration, t time.Time) WatchMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.levelFor(window).sum(window, t)
+	if b.watchCount == 0 {
+		return WatchMetrics{}
+	}
+
+	avgWatchTime := b.watchTimeSum.Seconds() / float64(b.watchCount)
+	completionRate := float64(b.watchCompletedCount) / float64(b.watchCount)
+	return WatchMetrics{
+		AvgWatchTime:    avgWatchTime,
+		TotalWatchTime:  b.watchTimeSum,
+		CompletionRate:  completionRate,
+		EngagementScore: avgWatchTime * completionRate,
+	}
+}
+
+// interactionRate returns each interaction type's rate (events/sec)
+// inside the trailing window.
+func (s *decayingSeries) interactionRate(window time.Duration, t time.Time) map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.levelFor(window).sum(window, t)
+	rates := make(map[string]float64, len(b.interactionCounts))
+	for k, v := range b.interactionCounts {
+		rates[k] = float64(v) / window.Seconds()
+	}
+	return rates
+}
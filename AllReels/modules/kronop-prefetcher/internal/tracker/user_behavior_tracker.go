@@ -1,10 +1,13 @@
 package tracker
 
 import (
-	"context"
+	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,6 +19,63 @@ type UserBehaviorTracker struct {
 	config       TrackerConfig
 	eventChan    chan UserEvent
 	stopChan     chan struct{}
+
+	// metrics is nil unless the tracker was built with RegisterMetrics -
+	// see metrics.go.
+	metrics *trackerMetrics
+
+	// rawRecorder is nil unless config.RawEventDumpPath was set - see
+	// rawdump.go.
+	rawRecorder *RawEventRecorder
+
+	// classifier turns a freshly-computed BehaviorProfile into a user
+	// type and confidence - see classifier.go. Defaults to
+	// DefaultRuleClassifier unless overridden via WithClassifier.
+	classifier Classifier
+
+	// profiler times processEvent and its per-event-type handlers - see
+	// profiler.go and GetProfilerReport. Always set; unlike metrics it
+	// isn't gated behind an Option since it has no external dependency
+	// to opt into.
+	profiler *selfProfiler
+}
+
+// WithClassifier overrides the tracker's Classifier (the default is
+// DefaultRuleClassifier). Pass an *OnlineLogisticClassifier to make
+// classification adapt via Feedback instead of using fixed rules.
+func WithClassifier(c Classifier) Option {
+	return func(ubt *UserBehaviorTracker) {
+		ubt.classifier = c
+	}
+}
+
+// Option configures a UserBehaviorTracker at construction time.
+type Option func(*UserBehaviorTracker)
+
+// RegisterMetrics registers this tracker's Prometheus collectors (scroll
+// speed/watch time histograms, completion rate/engagement score/user type
+// gauges, and dropped-event counts) on reg. instance labels every
+// collector so multiple trackers (one per user) can share a registry
+// without their metrics colliding.
+func RegisterMetrics(reg prometheus.Registerer, instance string) Option {
+	return func(ubt *UserBehaviorTracker) {
+		ubt.metrics = newTrackerMetrics(reg, instance)
+	}
+}
+
+// MetricsHandler mounts ubt's metrics on their own registry and returns a
+// ready-to-serve /metrics handler, for callers that want per-tracker stats
+// on a dedicated endpoint (e.g. one port per shard) rather than folded
+// into a shared application registry. Returns nil if ubt wasn't built with
+// RegisterMetrics.
+func (ubt *UserBehaviorTracker) MetricsHandler() http.Handler {
+	if ubt.metrics == nil {
+		return nil
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(ubt.metrics.scrollSpeed, ubt.metrics.watchTime, ubt.metrics.completionRate,
+		ubt.metrics.engagementScore, ubt.metrics.userType, ubt.metrics.droppedEvents)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
 }
 
 // TrackerConfig holds tracker configuration
@@ -27,6 +87,19 @@ type TrackerConfig struct {
 	SessionTimeout           time.Duration `yaml:"session_timeout"`
 	EventBufferSize          int           `yaml:"event_buffer_size"`
 	AnalysisInterval         time.Duration `yaml:"analysis_interval"`
+
+	// MetricsAddr, if set, serves MetricsHandler's /metrics endpoint on its
+	// own http.Server for Grafana to scrape directly, rather than needing
+	// the embedding service to fold the tracker's collectors into its own
+	// handler. Has no effect unless the tracker was built with
+	// RegisterMetrics.
+	MetricsAddr string `yaml:"metrics_addr"`
+
+	// RawEventDumpPath, if set, enables a RawEventRecorder that appends
+	// every processed UserEvent to a binary log at this path prefix (see
+	// rawdump.go) for offline replay, in addition to the in-memory
+	// aggregation below.
+	RawEventDumpPath string `yaml:"raw_event_dump_path"`
 }
 
 // UserSession represents a user's current session
@@ -42,6 +115,10 @@ type UserSession struct {
 	TotalScrolls     int
 	TotalWatchTime   time.Duration
 	mu              sync.RWMutex
+
+	// series answers rate queries (GetRate and friends) over a trailing
+	// window instead of the flat, whole-session ring buffers above.
+	series *decayingSeries
 }
 
 // UserEvent represents any user action
@@ -107,16 +184,39 @@ type BehaviorProfile struct {
 	Confidence       float64   `json:"confidence"`
 	LastUpdated      time.Time `json:"last_updated"`
 	PrefetchCount    int       `json:"prefetch_count"`
+	SessionDuration  time.Duration `json:"session_duration"`
 }
 
 // NewUserBehaviorTracker creates a new user behavior tracker
-func NewUserBehaviorTracker(userID string, config TrackerConfig) *UserBehaviorTracker {
+func NewUserBehaviorTracker(userID string, config TrackerConfig, opts ...Option) *UserBehaviorTracker {
 	tracker := &UserBehaviorTracker{
 		userID:    userID,
 		sessions:  &sync.Map{},
 		config:    config,
 		eventChan: make(chan UserEvent, config.EventBufferSize),
 		stopChan: make(chan struct{}),
+		profiler:  newSelfProfiler(),
+	}
+
+	for _, opt := range opts {
+		opt(tracker)
+	}
+
+	if tracker.classifier == nil {
+		tracker.classifier = DefaultRuleClassifier()
+	}
+
+	if tracker.metrics != nil && config.MetricsAddr != "" {
+		tracker.startMetricsServer()
+	}
+
+	if config.RawEventDumpPath != "" {
+		rec, err := NewRawEventRecorder(config.RawEventDumpPath)
+		if err != nil {
+			logrus.Errorf("❌ failed to start raw event recorder for user %s: %v", userID, err)
+		} else {
+			tracker.rawRecorder = rec
+		}
 	}
 
 	// Start background processing
@@ -126,6 +226,27 @@ func NewUserBehaviorTracker(userID string, config TrackerConfig) *UserBehaviorTr
 	return tracker
 }
 
+// startMetricsServer serves MetricsHandler on config.MetricsAddr in the
+// background. Listen errors are logged, not returned, since this runs from
+// the constructor - callers that need a hard failure on an unavailable
+// port should mount MetricsHandler on their own http.Server instead.
+func (ubt *UserBehaviorTracker) startMetricsServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", ubt.MetricsHandler())
+	server := &http.Server{Addr: ubt.config.MetricsAddr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("❌ tracker metrics server for user %s failed: %v", ubt.userID, err)
+		}
+	}()
+
+	go func() {
+		<-ubt.stopChan
+		_ = server.Close()
+	}()
+}
+
 // TrackScrollEvent tracks a scrolling event
 func (ubt *UserBehaviorTracker) TrackScrollEvent(fromReel, toReel int, direction string, duration time.Duration) error {
 	if !ubt.config.EnableScrollTracking {
@@ -159,6 +280,8 @@ func (ubt *UserBehaviorTracker) TrackScrollEvent(fromReel, toReel int, direction
 		return nil
 	case <-time.After(100 * time.Millisecond):
 		logrus.Warn("⚠️ Event buffer full, dropping scroll event")
+		ubt.metrics.countDropped("scroll")
+		ubt.profiler.countDrop("scroll")
 		return fmt.Errorf("event buffer full")
 	}
 }
@@ -188,6 +311,8 @@ func (ubt *UserBehaviorTracker) TrackWatchEvent(reelID int, watchTime time.Durat
 		return nil
 	case <-time.After(100 * time.Millisecond):
 		logrus.Warn("⚠️ Event buffer full, dropping watch event")
+		ubt.metrics.countDropped("watch")
+		ubt.profiler.countDrop("watch")
 		return fmt.Errorf("event buffer full")
 	}
 }
@@ -216,18 +341,19 @@ func (ubt *UserBehaviorTracker) TrackInteraction(reelID int, interactionType str
 		return nil
 	case <-time.After(100 * time.Millisecond):
 		logrus.Warn("⚠️ Event buffer full, dropping interaction")
+		ubt.metrics.countDropped("interaction")
+		ubt.profiler.countDrop("interaction")
 		return fmt.Errorf("event buffer full")
 	}
 }
 
 // GetCurrentSession gets the user's current session
 func (ubt *UserBehaviorTracker) GetCurrentSession() *UserSession {
-	session, exists := ubt.sessions.Load(ubt.userID)
+	value, exists := ubt.sessions.Load(ubt.userID)
 	if !exists {
-		session = ubt.createNewSession()
-		ubt.sessions.Store(ubt.userID, session)
+		return ubt.createNewSession()
 	}
-	return session
+	return value.(*UserSession)
 }
 
 // createNewSession creates a new user session
@@ -240,6 +366,7 @@ func (ubt *UserBehaviorTracker) createNewSession() *UserSession {
 		LastActivity: time.Now(),
 		TotalScrolls: 0,
 	TotalWatchTime: 0,
+		series:       newDecayingSeries(),
 	}
 
 	// Clean up old sessions if needed
@@ -297,6 +424,13 @@ func (ubt *UserBehaviorTracker) processEvents() {
 
 // processEvent processes a single event
 func (ubt *UserBehaviorTracker) processEvent(event UserEvent) {
+	ubt.profiler.Observe("channel_wait", time.Since(event.Timestamp))
+
+	a := ubt.profiler.StartActivity("processEvent")
+	defer a.End()
+
+	ubt.rawRecorder.record(event)
+
 	session := ubt.GetCurrentSession()
 
 	session.mu.Lock()
@@ -320,10 +454,13 @@ func (ubt *UserBehaviorTracker) processEvent(event UserEvent) {
 
 // processScrollEvent processes a scroll event
 func (ubt *UserBehaviorTracker) processScrollEvent(session *UserSession, event UserEvent) {
+	a := ubt.profiler.StartActivity("processScrollEvent")
+	defer a.End()
+
 	data := event.Data.(map[string]interface{})
 	
-	fromReel := int(data["from_reel"].(float64))
-	toReel := int(data["to_reel"].(float64))
+	fromReel := data["from_reel"].(int)
+	toReel := data["to_reel"].(int)
 	scrollSpeed := data["scroll_speed"].(float64)
 	direction := data["direction"].(string)
 	duration := time.Duration(data["duration"].(float64)) * time.Second
@@ -346,15 +483,21 @@ func (ubt *UserBehaviorTracker) processScrollEvent(session *UserSession, event U
 		session.ScrollEvents = session.ScrollEvents[len(session.ScrollEvents)-100:]
 	}
 
-	logrus.Debugf("📜 Processed scroll event: %s -> %s (speed: %.2f)", 
+	session.series.recordScroll(event.Timestamp, scrollSpeed)
+	ubt.metrics.observeScroll(scrollSpeed)
+
+	logrus.Debugf("📜 Processed scroll event: %d -> %d (speed: %.2f)",
 		fromReel, toReel, scrollSpeed)
 }
 
 // processWatchEvent processes a watch event
 func (ubt *UserBehaviorTracker) processWatchEvent(session *UserSession, event UserEvent) {
+	a := ubt.profiler.StartActivity("processWatchEvent")
+	defer a.End()
+
 	data := event.Data.(map[string]interface{})
 	
-	reelID := int(data["reel_id"].(float64))
+	reelID := event.ReelID
 	watchTime := time.Duration(data["watch_time"].(float64)) * time.Second
 	completed := data["completed"].(bool)
 	position := data["position"].(float64)
@@ -375,15 +518,21 @@ func (ubt *UserBehaviorTracker) processWatchEvent(session *UserSession, event Us
 		session.WatchEvents = session.WatchEvents[len(session.WatchEvents)-100:]
 	}
 
-	logrus.Debugf("👁️ Processed watch event: reel=%d, time=%.2fs, completed=%t", 
+	session.series.recordWatch(event.Timestamp, watchTime, completed)
+	ubt.metrics.observeWatch(watchTime.Seconds())
+
+	logrus.Debugf("👁️ Processed watch event: reel=%d, time=%.2fs, completed=%t",
 		reelID, watchTime.Seconds(), completed)
 }
 
 // processInteractionEvent processes an interaction event
 func (ubt *UserBehaviorTracker) processInteractionEvent(session *UserSession, event UserEvent) {
+	a := ubt.profiler.StartActivity("processInteractionEvent")
+	defer a.End()
+
 	data := event.Data.(map[string]interface{})
 	
-	reelID := int(data["reel_id"].(float64))
+	reelID := event.ReelID
 	interactionType := data["type"].(string)
 	interactionData := data["data"]
 
@@ -395,6 +544,7 @@ func (ubt *UserBehaviorTracker) processInteractionEvent(session *UserSession, ev
 	}
 
 	session.Interactions = append(session.Interactions, interaction)
+	session.series.recordInteraction(event.Timestamp, interactionType)
 
 	// Keep only recent interactions (last 50)
 	if len(session.Interactions) > 50 {
@@ -423,18 +573,22 @@ func (ubt *UserBehaviorTracker) GetBehaviorProfile() *BehaviorProfile {
 		Confidence:       0.0,
 		LastUpdated:      time.Now(),
 		PrefetchCount:    3, // Default
+		SessionDuration:  session.LastActivity.Sub(session.FirstSeen),
 	}
 
-	// Determine user type
-	profile.UserType = ubt.determineUserType(profile)
-	profile.Confidence = ubt.calculateConfidence(profile)
+	profile.UserType, profile.Confidence = ubt.classifier.Classify(profile)
 	profile.LastUpdated = time.Now()
 
+	ubt.metrics.refreshProfile(profile)
+
 	return profile
 }
 
 // calculateScrollMetrics calculates scroll metrics from events
 func (ubt *UserBehaviorTracker) calculateScrollMetrics(session *UserSession) ScrollMetrics {
+	a := ubt.profiler.StartActivity("calculateScrollMetrics")
+	defer a.End()
+
 	if len(session.ScrollEvents) == 0 {
 		return ScrollMetrics{}
 	}
@@ -493,6 +647,9 @@ func (ubt *UserBehaviorTracker) calculateScrollMetrics(session *UserSession) Scr
 
 // calculateWatchMetrics calculates watch metrics from events
 func (ubt *UserBehaviorTracker) calculateWatchMetrics(session *UserSession) WatchMetrics {
+	a := ubt.profiler.StartActivity("calculateWatchMetrics")
+	defer a.End()
+
 	if len(session.WatchEvents) == 0 {
 		return WatchMetrics{}
 	}
@@ -523,91 +680,37 @@ func (ubt *UserBehaviorTracker) calculateWatchMetrics(session *UserSession) Watc
 	}
 }
 
-// determineUserType determines the user type based on behavior
-func (ubt *UserBehaviorTracker) determineUserType(profile *BehaviorProfile) string {
-	scrollSpeed := profile.ScrollMetrics.AvgSpeed
-	watchTime := profile.WatchMetrics.AvgWatchTime
-	completionRate := profile.WatchMetrics.CompletionRate
-
-	// Decision tree for user type classification
-	if scrollSpeed > 5.0 {
-		return "fast_scroller"
-	} else if watchTime > 30.0 && completionRate > 0.8 {
-		return "binge_watcher"
-	} else if scrollSpeed < 0.5 && watchTime < 5.0 {
-		return "slow_viewer"
-	} else if watchTime < 5.0 {
-		return "casual_browser"
-	} else {
-		return "normal_viewer"
-	}
+// GetRate returns scroll metrics computed only from activity inside the
+// trailing window, via session.series, rather than a flat average over
+// every scroll event the session has ever recorded.
+func (ubt *UserBehaviorTracker) GetRate(window time.Duration) ScrollMetrics {
+	session := ubt.GetCurrentSession()
+	return session.series.scrollRate(window, time.Now())
 }
 
-// calculateConfidence calculates confidence in the user type determination
-func (ubt *UserBehaviorTracker) calculateConfidence(profile *BehaviorProfile) float64 {
-	scrollSpeed := profile.ScrollMetrics.AvgSpeed
-	watchTime := profile.WatchMetrics.AvgWatchTime
-	completionRate := profile.WatchMetrics.CompletionRate
-
-	// Base confidence on how well the behavior matches the determined type
-	userType := profile.UserType
-	var confidence float64
-
-	switch userType {
-	case "fast_scroller":
-		if scrollSpeed > 5.0 {
-			confidence = 0.9
-		} else {
-			confidence = 0.4
-		}
-	case "binge_watcher":
-		if watchTime > 30.0 && completionRate > 0.8 {
-			confidence = 0.85
-		} else {
-			confidence = 0.3
-		}
-	case "slow_viewer":
-		if scrollSpeed < 0.5 && watchTime > 10.0 {
-			confidence = 0.8
-		} else {
-		_confidence = 0.4
-		}
-	case "casual_browser":
-		if watchTime < 5.0 && scrollSpeed < 1.0 {
-			confidence = 0.7
-		} else {
-			confidence = 0.3
-		}
-	case "normal_viewer":
-		if scrollSpeed >= 1.0 && scrollSpeed <= 3.0 && watchTime >= 5.0 && watchTime <= 30.0 {
-			confidence = 0.75
-		} else {
-			confidence = 0.4
-		}
-	default:
-		confidence = 0.0
-	}
-
-	// Adjust confidence based on consistency
-	consistency := profile.ScrollMetrics.Consistency
-	confidence *= consistency
+// GetWatchRate returns watch metrics computed only from activity inside
+// the trailing window. See GetRate.
+func (ubt *UserBehaviorTracker) GetWatchRate(window time.Duration) WatchMetrics {
+	session := ubt.GetCurrentSession()
+	return session.series.watchRate(window, time.Now())
+}
 
-	// Adjust confidence based on data volume
-	eventCount := len(profile.ScrollEvents) + len(profile.WatchEvents) + len(profile.Interactions)
-	if eventCount < 10 {
-		confidence *= 0.5
-	} else if eventCount > 100 {
-		confidence = 1.0
-	} else {
-		// Scale confidence based on data volume
-		confidence *= float64(eventCount) / 100.0
-	}
+// GetInteractionRate returns each interaction type's rate (events/sec)
+// inside the trailing window. See GetRate.
+func (ubt *UserBehaviorTracker) GetInteractionRate(window time.Duration) map[string]float64 {
+	session := ubt.GetCurrentSession()
+	return session.series.interactionRate(window, time.Now())
+}
 
-	if confidence > 1.0 {
-		confidence = 1.0
+// Feedback reports the actual user type for the session's most recent
+// GetBehaviorProfile classification, e.g. from a downstream prefetcher's
+// hit/miss signal. It's a no-op unless the tracker's Classifier supports
+// feedback (OnlineLogisticClassifier does; DefaultRuleClassifier/
+// RuleClassifier don't).
+func (ubt *UserBehaviorTracker) Feedback(actualClass string) {
+	if fc, ok := ubt.classifier.(interface{ Feedback(string) }); ok {
+		fc.Feedback(actualClass)
 	}
-
-	return confidence
 }
 
 // GetRecentEvents gets recent events for analysis
@@ -617,8 +720,6 @@ func (ubt *UserBehaviorTracker) GetRecentEvents(count int) []UserEvent {
 	session.mu.RLock()
 	defer session.mu.RUnlock()
 
-	var recentEvents []UserEvent
-	
 	// Combine all events and sort by timestamp
 	allEvents := make([]UserEvent, 0, len(session.ScrollEvents)+len(session.WatchEvents)+len(session.Interactions))
 	
@@ -666,7 +767,7 @@ func (ubt *UserBehaviorTracker) GetRecentEvents(count int) []UserEvent {
 	}
 	
 	// Sort by timestamp (most recent first)
-	for i := range len(allEvents) {
+	for i := 0; i < len(allEvents); i++ {
 		for j := i + 1; j < len(allEvents); j++ {
 			if allEvents[i].Timestamp.After(allEvents[j].Timestamp) {
 				allEvents[i], allEvents[j] = allEvents[j], allEvents[i]
@@ -707,7 +808,14 @@ func (ubt *UserBehaviorTracker) GetSessionStats() map[string]interface{} {
 func (ubt *UserBehaviorTracker) Stop() {
 	close(ubt.stopChan)
 	close(ubt.eventChan)
-	
+	ubt.rawRecorder.Close()
+
+	if pc, ok := ubt.classifier.(interface{ Save() error }); ok {
+		if err := pc.Save(); err != nil {
+			logrus.Errorf("❌ failed to persist classifier weights for user %s: %v", ubt.userID, err)
+		}
+	}
+
 	// Clean up sessions
 	ubt.sessions.Range(func(key, value interface{}) bool {
 		ubt.sessions.Delete(key)
@@ -0,0 +1,73 @@
+package usagestats
+
+import (
+	"sync"
+	"time"
+)
+
+// LeaderKey is the key a Reporter's lease is stored under in a LeaderStore.
+// A single key is enough: only one component in this process (the usage
+// reporter) currently does leader election over a LeaderStore.
+const LeaderKey = "usagestats/leader"
+
+// LeaderStore is the pluggable backend a Reporter elects a leader over,
+// mirroring the CacheStore/enginetransport.Transport pattern used elsewhere
+// in this module: a small interface lets the in-memory implementation below
+// stand in for tests and single-replica deployments, while a real multi-
+// replica deployment can back it with whatever coordination store (Redis,
+// etcd, a Postgres row) it already runs.
+type LeaderStore interface {
+	// AcquireLease attempts to become (or renew, if holderID already holds
+	// it) the leader under key, expiring after ttl. Returns true if
+	// holderID is the leader after the call.
+	AcquireLease(key, holderID string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up holderID's lease on key, if it currently holds
+	// one, so another replica can become leader before the lease's ttl
+	// would otherwise expire it.
+	ReleaseLease(key, holderID string) error
+}
+
+// MemoryLeaderStore is an in-process LeaderStore, the only lease holder a
+// single-replica deployment (or a test) needs. It is not shared across
+// processes; a real multi-replica deployment must supply its own
+// LeaderStore backed by shared storage.
+type MemoryLeaderStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+type lease struct {
+	holderID string
+	expires  time.Time
+}
+
+// NewMemoryLeaderStore creates an empty in-process LeaderStore.
+func NewMemoryLeaderStore() *MemoryLeaderStore {
+	return &MemoryLeaderStore{leases: make(map[string]lease)}
+}
+
+// AcquireLease implements LeaderStore.
+func (s *MemoryLeaderStore) AcquireLease(key, holderID string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	l, held := s.leases[key]
+	if held && l.holderID != holderID && now.Before(l.expires) {
+		return false, nil
+	}
+
+	s.leases[key] = lease{holderID: holderID, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLease implements LeaderStore.
+func (s *MemoryLeaderStore) ReleaseLease(key, holderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, held := s.leases[key]; held && l.holderID == holderID {
+		delete(s.leases, key)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package usagestats
+
+import "time"
+
+// EngineVersion is stamped into every Report so the receiving endpoint can
+// track rollout of behavior changes across the fleet. Bump it alongside any
+// change to what a Report measures.
+const EngineVersion = "1"
+
+// Report is the anonymized payload a Reporter sends. It carries no
+// user-identifying data: ClusterSeed identifies a deployment, not a person,
+// and every other field is an aggregate.
+type Report struct {
+	EngineVersion    string         `json:"engine_version"`
+	ClusterSeed      string         `json:"cluster_seed"`
+	UptimeSeconds    float64        `json:"uptime_seconds"`
+	UserTypes        map[string]int `json:"user_types"`
+	AvgPrefetchCount float64        `json:"avg_prefetch_count"`
+	NetworkHistogram map[string]int `json:"network_histogram"`
+	SentAt           time.Time      `json:"sent_at"`
+}
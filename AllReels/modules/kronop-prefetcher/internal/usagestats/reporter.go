@@ -0,0 +1,251 @@
+package usagestats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultEndpoint is where a Reporter ships reports unless Config.Endpoint
+// overrides it.
+const defaultEndpoint = "https://usagestats.kronop.dev/report"
+
+// defaultInterval is how often the leader sends a report unless
+// Config.Interval overrides it.
+const defaultInterval = 24 * time.Hour
+
+// leaseTTL is how long a leader election lease lasts; renewed well before
+// expiry by run's lease ticker (see leaseRenewFraction).
+const leaseTTL = 2 * time.Minute
+
+// leaseRenewFraction is how far into leaseTTL the lease-renewal ticker
+// fires, leaving headroom for a slow AcquireLease call or a missed tick
+// before the lease would otherwise lapse.
+const leaseRenewFraction = 2
+
+// sendBackoffMin/sendBackoffMax bound the exponential backoff applied after
+// a failed report send; reset to sendBackoffMin on the next successful
+// send.
+const (
+	sendBackoffMin = 30 * time.Second
+	sendBackoffMax = 30 * time.Minute
+)
+
+// disableEnvVar opts a replica (or an entire fleet, via its deployment
+// manifest) out of reporting without touching Config, for operators who
+// can't easily thread a config change through.
+const disableEnvVar = "KRONOP_USAGESTATS_DISABLED"
+
+// Config configures a Reporter.
+type Config struct {
+	// Endpoint is where reports are POSTed as JSON. Defaults to
+	// defaultEndpoint.
+	Endpoint string `yaml:"endpoint"`
+	// Interval is how often the elected leader sends a report. Defaults to
+	// defaultInterval.
+	Interval time.Duration `yaml:"interval"`
+	// Disabled opts this deployment out of reporting entirely. Also
+	// honored via the KRONOP_USAGESTATS_DISABLED environment variable, so
+	// operators can disable it without a config change.
+	Disabled bool `yaml:"disabled"`
+	// SeedPath is where the persisted cluster seed is read/written.
+	// Defaults to DefaultSeedPath.
+	SeedPath string `yaml:"seed_path"`
+	// LeaderStore backs leader election across replicas. Defaults to a
+	// fresh MemoryLeaderStore, correct only for a single-replica
+	// deployment; a multi-replica deployment must supply a shared
+	// LeaderStore.
+	LeaderStore LeaderStore `yaml:"-"`
+	// HolderID identifies this replica in leader election. Defaults to a
+	// freshly generated UUID if empty.
+	HolderID string `yaml:"-"`
+}
+
+// Reporter periodically sends an anonymized Report to Config.Endpoint, but
+// only from whichever replica currently holds the usagestats leader lease
+// (see LeaderStore), so a fleet of N replicas ships one report per Interval
+// rather than N.
+type Reporter struct {
+	config      Config
+	source      Source
+	clusterSeed string
+	startedAt   time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// IsDisabled reports whether usage reporting is switched off, either via
+// Config.Disabled or the KRONOP_USAGESTATS_DISABLED environment variable.
+func (c Config) IsDisabled() bool {
+	if c.Disabled {
+		return true
+	}
+	_, set := os.LookupEnv(disableEnvVar)
+	return set
+}
+
+// NewReporter creates a Reporter summarizing source, applying Config
+// defaults for any zero-valued field. The cluster seed is loaded (or
+// created) from config.SeedPath immediately, so it's available even if
+// Start is never reached under config.IsDisabled().
+func NewReporter(config Config, source Source) (*Reporter, error) {
+	if config.Endpoint == "" {
+		config.Endpoint = defaultEndpoint
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+	if config.SeedPath == "" {
+		config.SeedPath = DefaultSeedPath
+	}
+	if config.LeaderStore == nil {
+		config.LeaderStore = NewMemoryLeaderStore()
+	}
+	if config.HolderID == "" {
+		holderID, err := newUUIDv4()
+		if err != nil {
+			return nil, err
+		}
+		config.HolderID = holderID
+	}
+
+	seed, err := LoadOrCreateClusterSeed(config.SeedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reporter{
+		config:      config,
+		source:      source,
+		clusterSeed: seed,
+		startedAt:   time.Now(),
+	}, nil
+}
+
+// Start launches the reporter loop in the background, for callers to call
+// alongside the rest of the engine's startup (see main.go). It is a no-op
+// if config.IsDisabled(). Returns immediately; the loop runs until ctx is
+// canceled or Stop is called.
+func (r *Reporter) Start(ctx context.Context) {
+	if r.config.IsDisabled() {
+		logrus.Info("📵 Usage reporting disabled, not starting reporter")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx)
+}
+
+// Stop cancels the reporter loop and, if it currently holds the leader
+// lease, releases it so another replica can take over before leaseTTL
+// would otherwise lapse. Blocks until the loop has exited.
+func (r *Reporter) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	_ = r.config.LeaderStore.ReleaseLease(LeaderKey, r.config.HolderID)
+}
+
+// run holds two independent tickers — one renewing the leader lease well
+// inside leaseTTL, one attempting a report every Interval — rather than a
+// single combined loop, so a slow or failing report send never delays
+// lease renewal and costs this replica leadership. A failed send backs off
+// exponentially via the report ticker's Reset, rather than blocking inside
+// the select with time.Sleep, so the loop stays responsive to ctx.Done()
+// throughout.
+func (r *Reporter) run(ctx context.Context) {
+	defer close(r.done)
+
+	leaseTicker := time.NewTicker(leaseTTL / leaseRenewFraction)
+	defer leaseTicker.Stop()
+
+	reportTicker := time.NewTicker(r.config.Interval)
+	defer reportTicker.Stop()
+
+	backoff := sendBackoffMin
+	isLeader := false
+
+	renewLease := func() {
+		leader, err := r.config.LeaderStore.AcquireLease(LeaderKey, r.config.HolderID, leaseTTL)
+		if err != nil {
+			logrus.Warnf("⚠️ Usage reporter failed to renew leader lease: %v", err)
+			return
+		}
+		isLeader = leader
+	}
+	renewLease()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-leaseTicker.C:
+			renewLease()
+		case <-reportTicker.C:
+			if !isLeader {
+				continue
+			}
+			if err := r.send(); err != nil {
+				logrus.Warnf("⚠️ Usage report send failed, backing off %s: %v", backoff, err)
+				reportTicker.Reset(backoff)
+				backoff *= 2
+				if backoff > sendBackoffMax {
+					backoff = sendBackoffMax
+				}
+				continue
+			}
+			backoff = sendBackoffMin
+			reportTicker.Reset(r.config.Interval)
+		}
+	}
+}
+
+// send builds a Report from the current Source state and POSTs it as JSON
+// to config.Endpoint.
+func (r *Reporter) send() error {
+	report := Report{
+		EngineVersion:    EngineVersion,
+		ClusterSeed:      r.clusterSeed,
+		UptimeSeconds:    time.Since(r.startedAt).Seconds(),
+		UserTypes:        r.source.UserTypeDistribution(),
+		AvgPrefetchCount: r.source.AveragePrefetchCount(),
+		NetworkHistogram: r.source.NetworkConditionHistogram(),
+		SentAt:           time.Now(),
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(r.config.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &httpStatusError{statusCode: resp.StatusCode}
+	}
+	logrus.Debugf("📡 Sent anonymous usage report to %s", r.config.Endpoint)
+	return nil
+}
+
+// httpStatusError reports a non-2xx response from the usage-stats endpoint.
+type httpStatusError struct {
+	statusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return http.StatusText(e.statusCode)
+}
@@ -0,0 +1,64 @@
+// Package usagestats implements an opt-out, anonymized usage reporter: a
+// Reporter periodically POSTs a JSON summary (engine version, uptime,
+// aggregated user-type distribution, average prefetch counts, and a
+// network-condition histogram) to a configurable endpoint. It's safe to
+// run unmodified on every replica behind a shared control plane: a
+// cluster seed persisted to disk gives every replica on the same volume
+// the same anonymous identity across restarts, and lease-based leader
+// election over a pluggable LeaderStore ensures only the current leader
+// actually ships a report.
+package usagestats
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultSeedPath is where the cluster seed is persisted by default.
+const DefaultSeedPath = "./kronop_cluster_seed.json"
+
+type seedFile struct {
+	ClusterSeed string `json:"cluster_seed"`
+}
+
+// LoadOrCreateClusterSeed reads the UUID cluster seed persisted at path,
+// generating and persisting a new one on first boot. Every later restart
+// (and every replica sharing the same path via a mounted volume) then
+// reuses the same anonymous identity instead of looking like a new
+// install on every report.
+func LoadOrCreateClusterSeed(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		var sf seedFile
+		if err := json.Unmarshal(data, &sf); err == nil && sf.ClusterSeed != "" {
+			return sf.ClusterSeed, nil
+		}
+	}
+
+	seed, err := newUUIDv4()
+	if err != nil {
+		return "", fmt.Errorf("usagestats: failed to generate cluster seed: %w", err)
+	}
+
+	data, err := json.Marshal(seedFile{ClusterSeed: seed})
+	if err != nil {
+		return "", fmt.Errorf("usagestats: failed to marshal cluster seed: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("usagestats: failed to persist cluster seed to %s: %w", path, err)
+	}
+	return seed, nil
+}
+
+// newUUIDv4 generates a random (version 4, variant 1) UUID, rolled by hand
+// rather than pulling in an external dependency for something this small.
+func newUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,18 @@
+package usagestats
+
+// Source is whatever live component a Reporter summarizes into a Report —
+// the prefetcher Engine, in production. Defined as a small interface (the
+// same pluggable-backend shape as CacheStore/cgroup.Reader elsewhere in this
+// module) so Reporter can be tested against a fake without spinning up a
+// real Engine.
+type Source interface {
+	// UserTypeDistribution returns the count of currently-tracked users by
+	// classified UserType (e.g. "fast_scroller", "binge_watcher").
+	UserTypeDistribution() map[string]int
+	// AveragePrefetchCount returns the mean PrefetchCount issued across
+	// currently-tracked users.
+	AveragePrefetchCount() float64
+	// NetworkConditionHistogram returns the count of recorded prefetch
+	// decisions by network condition (e.g. "good", "poor").
+	NetworkConditionHistogram() map[string]int
+}
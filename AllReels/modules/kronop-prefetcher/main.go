@@ -12,11 +12,18 @@ import (
 
 	"github.com/kronop/prefetcher/internal/prefetcher"
 	"github.com/kronop/prefetcher/internal/analyzer"
+	"github.com/kronop/prefetcher/internal/analyzer/sequence"
+	"github.com/kronop/prefetcher/internal/grpcapi"
+	"github.com/kronop/prefetcher/internal/runtimecfg"
+	"github.com/kronop/prefetcher/internal/usagestats"
 )
 
 func main() {
 	// Command line flags
 	port := flag.Int("port", 8080, "Port for HTTP server")
+	grpcPort := flag.Int("grpc-port", 9090, "Port for gRPC server")
+	grpcReflection := flag.Bool("grpc-reflection", false, "Enable gRPC server reflection (debug)")
+	cpuQuotaRoundUp := flag.Bool("cpu-quota-round-up", true, "Round a fractional cgroup CPU quota up to the nearest whole CPU for GOMAXPROCS/worker sizing")
 	configPath := flag.String("config", "config.yaml", "Configuration file path")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	flag.Parse()
@@ -34,6 +41,18 @@ func main() {
 		log.Fatalf("❌ Failed to load config: %v", err)
 	}
 
+	// Detect the container's cgroup CPU quota (if any) and set
+	// GOMAXPROCS from it, so Go doesn't oversubscribe goroutines to
+	// host cores the process isn't actually entitled to under a
+	// Kubernetes CPU limit. Fall back to config.Prefetcher.Workers as
+	// an explicit override when set.
+	workers, err := runtimecfg.ApplyGOMAXPROCS(runtimecfg.OSFS{}, *cpuQuotaRoundUp)
+	if err != nil {
+		log.Printf("⚠️ Failed to detect cgroup CPU quota: %v", err)
+	} else if config.Prefetcher.Workers <= 0 {
+		config.Prefetcher.Workers = workers
+	}
+
 	// Initialize components
 	analyzer := analyzer.NewUserBehaviorAnalyzer(config.Analyzer)
 	prefetcherEngine := prefetcher.NewEngine(config.Prefetcher, analyzer)
@@ -56,6 +75,39 @@ func main() {
 		}
 	}()
 
+	// Periodically persist per-user sequence predictors (see
+	// internal/analyzer/sequence) so warm users keep accurate
+	// predictions across a restart.
+	go analyzer.RunSequencePersistence(ctx, sequence.DefaultPersistInterval)
+
+	// Start the gRPC server for behavior ingestion and prefetch decisions
+	// (see internal/grpcapi), alongside the HTTP API server above.
+	grpcServer, err := grpcapi.NewServer(grpcapi.Config{
+		Addr:             fmt.Sprintf(":%d", *grpcPort),
+		EnableReflection: *grpcReflection,
+	}, analyzer, prefetcherEngine)
+	if err != nil {
+		log.Fatalf("❌ Failed to start gRPC server: %v", err)
+	}
+	go func() {
+		log.Printf("🌐 Starting gRPC server on port %d", *grpcPort)
+		if err := grpcServer.Serve(); err != nil {
+			log.Printf("❌ gRPC server error: %v", err)
+		}
+	}()
+
+	// Start the anonymous usage-stats reporter alongside the rest of the
+	// engine's goroutines. A construction failure (e.g. can't persist the
+	// cluster seed) is logged and skipped rather than fatal: usage
+	// reporting is opt-out telemetry, not load-bearing for prefetching.
+	var usageReporter *usagestats.Reporter
+	usageReporter, err = usagestats.NewReporter(config.Prefetcher.UsageStats, prefetcherEngine)
+	if err != nil {
+		log.Printf("⚠️ Failed to initialize usage reporter: %v", err)
+	} else {
+		usageReporter.Start(ctx)
+	}
+
 	log.Printf("✅ Kronop Prefetcher Engine started successfully")
 	log.Printf("🎯 AI-based smart prefetching active")
 	log.Printf("📊 User behavior analysis enabled")
@@ -68,6 +120,12 @@ func main() {
 	log.Printf("🛑 Shutting down Kronop Prefetcher Engine...")
 	cancel()
 
+	grpcServer.Stop()
+
+	if usageReporter != nil {
+		usageReporter.Stop()
+	}
+
 	// Graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -51,6 +51,58 @@ type VideoFetcher struct {
 	retryDelay        time.Duration
 	mongoClient       *mongo.Client
 	mongoDB           *mongo.Database
+	sources           map[string]*SourceStats
+	sourcesMutex      sync.RWMutex
+	store             ChunkStore
+	videoID           string
+}
+
+// SetChunkStore attaches a ChunkStore so completed chunks are persisted as
+// they arrive, enabling Resume() after a restart. Pass a videoID so chunks
+// and manifests can be scoped per video.
+func (f *VideoFetcher) SetChunkStore(store ChunkStore, videoID string) {
+	f.store = store
+	f.videoID = videoID
+}
+
+// SourceStats tracks per-source throughput/latency used to schedule chunks
+// onto the source expected to finish fastest.
+type SourceStats struct {
+	URL             string
+	EWMAThroughput  float64 // bytes/sec, exponentially weighted
+	EWMALatency     time.Duration
+	InFlight        int
+	Completed       int64
+	Failed          int64
+}
+
+const ewmaAlpha = 0.3
+
+// estimatedCompletion returns the expected time to deliver a chunk of the
+// given size from this source, accounting for requests already in flight.
+func (s *SourceStats) estimatedCompletion(chunkSize int64) time.Duration {
+	throughput := s.EWMAThroughput
+	if throughput <= 0 {
+		throughput = 1 * 1024 * 1024 // assume 1 MB/s until we have a sample
+	}
+	transferTime := time.Duration(float64(chunkSize)/throughput*float64(time.Second))
+	queueing := time.Duration(s.InFlight) * s.EWMALatency
+	return s.EWMALatency + transferTime + queueing
+}
+
+func (s *SourceStats) recordSuccess(size int64, elapsed time.Duration) {
+	throughput := float64(size) / elapsed.Seconds()
+	if s.EWMAThroughput == 0 {
+		s.EWMAThroughput = throughput
+	} else {
+		s.EWMAThroughput = ewmaAlpha*throughput + (1-ewmaAlpha)*s.EWMAThroughput
+	}
+	if s.EWMALatency == 0 {
+		s.EWMALatency = elapsed
+	} else {
+		s.EWMALatency = time.Duration(ewmaAlpha*float64(elapsed) + (1-ewmaAlpha)*float64(s.EWMALatency))
+	}
+	s.Completed++
 }
 
 // DownloadProgress tracks download status
@@ -104,7 +156,92 @@ func NewVideoFetcher(urls []string, chunkSize int64, maxConcurrent int) *VideoFe
 		retryDelay: 2 * time.Second,
 		mongoClient: mongoClient,
 		mongoDB: mongoDB,
+		sources:    newSourceStats(urls),
+	}
+}
+
+// newSourceStats seeds a SourceStats entry for every source URL
+func newSourceStats(urls []string) map[string]*SourceStats {
+	stats := make(map[string]*SourceStats, len(urls))
+	for _, u := range urls {
+		stats[u] = &SourceStats{URL: u}
+	}
+	return stats
+}
+
+// GetSourceStats returns a snapshot of per-source throughput/latency stats
+func (f *VideoFetcher) GetSourceStats() map[string]SourceStats {
+	f.sourcesMutex.RLock()
+	defer f.sourcesMutex.RUnlock()
+
+	snapshot := make(map[string]SourceStats, len(f.sources))
+	for url, s := range f.sources {
+		snapshot[url] = *s
+	}
+	return snapshot
+}
+
+// qualityTier maps the current AdaptiveBitrate() output to how far down the
+// source list we're willing to schedule from; earlier entries in f.urls are
+// assumed to be the higher-quality mirrors.
+func (f *VideoFetcher) qualityTier() int {
+	switch f.AdaptiveBitrate() {
+	case "4K", "1080p":
+		return len(f.urls)
+	case "720p":
+		return max(1, len(f.urls)-1)
+	case "480p":
+		return max(1, len(f.urls)*2/3)
+	default:
+		return max(1, len(f.urls)/2)
+	}
+}
+
+// bestSource picks the source with the lowest estimated completion time for
+// a chunk of the given size, restricted to the sources the current
+// AdaptiveBitrate tier allows.
+func (f *VideoFetcher) bestSource(chunkSize int64) *SourceStats {
+	tier := f.qualityTier()
+
+	f.sourcesMutex.Lock()
+	defer f.sourcesMutex.Unlock()
+
+	var best *SourceStats
+	var bestETA time.Duration
+	for i, url := range f.urls {
+		if i >= tier {
+			break
+		}
+		s := f.sources[url]
+		eta := s.estimatedCompletion(chunkSize)
+		if best == nil || eta < bestETA {
+			best = s
+			bestETA = eta
+		}
+	}
+	return best
+}
+
+// secondBestSource picks the best source other than exclude, used for
+// dueling requests on the tail of a download.
+func (f *VideoFetcher) secondBestSource(chunkSize int64, exclude string) *SourceStats {
+	f.sourcesMutex.Lock()
+	defer f.sourcesMutex.Unlock()
+
+	var best *SourceStats
+	var bestETA time.Duration
+	for _, url := range f.urls {
+		if url == exclude {
+			continue
+		}
+		s := f.sources[url]
+		eta := s.estimatedCompletion(chunkSize)
+		if best == nil || eta < bestETA {
+			best = s
+			bestETA = eta
+		}
 	}
+	return best
 }
 
 // GetVideoURLFromMongoDB fetches video URL from MongoDB database
@@ -153,11 +290,13 @@ func (f *VideoFetcher) StartDownload(videoURL string, totalSize int64) error {
 		}
 		
 		f.chunkMutex.Lock()
-		f.downloadedChunks[i] = &VideoChunk{
-			Index:     i,
-			StartByte: startByte,
-			EndByte:   endByte,
-			Size:      int(endByte - startByte + 1),
+		if existing, ok := f.downloadedChunks[i]; !ok || !existing.Downloaded {
+			f.downloadedChunks[i] = &VideoChunk{
+				Index:     i,
+				StartByte: startByte,
+				EndByte:   endByte,
+				Size:      int(endByte - startByte + 1),
+			}
 		}
 		f.chunkMutex.Unlock()
 	}
@@ -230,12 +369,21 @@ func (f *VideoFetcher) StartDownload(videoURL string, totalSize int64) error {
 				case <-f.ctx.Done():
 					return
 				default:
+					f.chunkMutex.RLock()
+					alreadyDownloaded := f.downloadedChunks[chunkIndex] != nil && f.downloadedChunks[chunkIndex].Downloaded
+					f.chunkMutex.RUnlock()
+					if alreadyDownloaded {
+						continue // Resume() already verified this chunk on disk
+					}
+
 					downloadMutex.Lock()
 					activeDownloads++
 					downloadMutex.Unlock()
-					
-					// Download this chunk from multiple sources
-					chunkData := f.downloadChunkWithRetry(videoURL, chunkIndex)
+
+					// Download this chunk, scheduling it onto whichever
+					// source is expected to finish fastest
+					remaining := len(chunkChan) + 1
+					chunkData := f.downloadChunkWithRetry(videoURL, chunkIndex, remaining)
 					
 					downloadMutex.Lock()
 					activeDownloads--
@@ -259,16 +407,23 @@ func (f *VideoFetcher) StartDownload(videoURL string, totalSize int64) error {
 				checksum := calculateMD5(result.Data)
 				
 				f.chunkMutex.Lock()
-				if chunk, exists := f.downloadedChunks[result.Index]; exists {
+				chunk, exists := f.downloadedChunks[result.Index]
+				if exists {
 					chunk.Data = result.Data
 					chunk.Downloaded = true
 					chunk.Checksum = checksum
-					
+
 					f.progressMutex.Lock()
 					f.downloadedSize += int64(len(result.Data))
 					f.progressMutex.Unlock()
 				}
 				f.chunkMutex.Unlock()
+
+				if exists && f.store != nil {
+					if err := f.store.SaveChunk(f.videoID, chunk); err != nil {
+						fmt.Printf("⚠️ failed to persist chunk %d: %v\n", result.Index, err)
+					}
+				}
 			}
 		}
 	}()
@@ -281,62 +436,161 @@ func (f *VideoFetcher) StartDownload(videoURL string, totalSize int64) error {
 }
 
 // downloadChunkWithRetry attempts to download a chunk with retries
-func (f *VideoFetcher) downloadChunkWithRetry(videoURL string, chunkIndex int) []byte {
+func (f *VideoFetcher) downloadChunkWithRetry(videoURL string, chunkIndex, remainingChunks int) []byte {
 	for attempt := 0; attempt < f.retryCount; attempt++ {
-		chunkData := f.downloadChunk(videoURL, chunkIndex)
+		chunkData := f.downloadChunk(videoURL, chunkIndex, remainingChunks)
 		if chunkData != nil {
 			return chunkData
 		}
-		
+
 		time.Sleep(f.retryDelay)
 	}
 	return nil
 }
 
-// downloadChunk downloads a single chunk with byte range
-func (f *VideoFetcher) downloadChunk(videoURL string, chunkIndex int) []byte {
+// dialingThreshold is how many chunks must remain before we start dueling
+// the same byte range across two sources and taking whichever wins.
+const duelingThreshold = 3
+
+// downloadChunk downloads a single chunk, dispatching it to the source with
+// the best estimated completion time. Once remainingChunks drops to
+// duelingThreshold or below, the same range is also requested from the
+// next-best source and whichever response arrives first wins.
+func (f *VideoFetcher) downloadChunk(videoURL string, chunkIndex, remainingChunks int) []byte {
 	f.chunkMutex.RLock()
 	chunk := f.downloadedChunks[chunkIndex]
 	f.chunkMutex.RUnlock()
-	
+
 	if chunk == nil {
 		return nil
 	}
-	
-	// Try all available source URLs
-	for _, sourceURL := range f.urls {
-		req, err := http.NewRequestWithContext(f.ctx, "GET", sourceURL+videoURL, nil)
-		if err != nil {
-			continue
-		}
-		
-		// Set range header for partial content
-		rangeHeader := fmt.Sprintf("bytes=%d-%d", chunk.StartByte, chunk.EndByte)
-		req.Header.Set("Range", rangeHeader)
-		
-		resp, err := f.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		
-		if resp.StatusCode != http.StatusPartialContent && 
-		   resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			continue
+
+	primary := f.bestSource(int64(chunk.Size))
+	if primary == nil {
+		return nil
+	}
+
+	if remainingChunks > duelingThreshold {
+		return f.fetchFromSource(primary, videoURL, chunk)
+	}
+
+	secondary := f.secondBestSource(int64(chunk.Size), primary.URL)
+	if secondary == nil {
+		return f.fetchFromSource(primary, videoURL, chunk)
+	}
+
+	return f.duelFetch(primary, secondary, videoURL, chunk)
+}
+
+// duelFetch issues the same byte range against two sources concurrently and
+// returns the data from whichever completes first, cancelling the loser.
+func (f *VideoFetcher) duelFetch(primary, secondary *SourceStats, videoURL string, chunk *VideoChunk) []byte {
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	type duelResult struct {
+		data []byte
+	}
+	results := make(chan duelResult, 2)
+
+	race := func(src *SourceStats) {
+		data := f.fetchFromSourceCtx(ctx, src, videoURL, chunk)
+		if data != nil {
+			results <- duelResult{data: data}
+		} else {
+			results <- duelResult{}
 		}
-		
-		// Read chunk data
-		data, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		
-		if err == nil && len(data) == chunk.Size {
-			return data
+	}
+
+	go race(primary)
+	go race(secondary)
+
+	for i := 0; i < 2; i++ {
+		res := <-results
+		if res.data != nil {
+			cancel() // stop the loser
+			return res.data
 		}
 	}
-	
 	return nil
 }
 
+// fetchFromSource downloads chunk from a single source and records the
+// source's throughput/latency stats on completion.
+func (f *VideoFetcher) fetchFromSource(src *SourceStats, videoURL string, chunk *VideoChunk) []byte {
+	return f.fetchFromSourceCtx(f.ctx, src, videoURL, chunk)
+}
+
+func (f *VideoFetcher) fetchFromSourceCtx(ctx context.Context, src *SourceStats, videoURL string, chunk *VideoChunk) []byte {
+	f.sourcesMutex.Lock()
+	src.InFlight++
+	f.sourcesMutex.Unlock()
+
+	start := time.Now()
+	defer func() {
+		f.sourcesMutex.Lock()
+		src.InFlight--
+		f.sourcesMutex.Unlock()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", src.URL+videoURL, nil)
+	if err != nil {
+		f.recordFailure(src)
+		return nil
+	}
+
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", chunk.StartByte, chunk.EndByte)
+	req.Header.Set("Range", rangeHeader)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		f.recordFailure(src)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent &&
+		resp.StatusCode != http.StatusOK {
+		f.recordFailure(src)
+		return nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || len(data) != chunk.Size {
+		f.recordFailure(src)
+		return nil
+	}
+
+	elapsed := time.Since(start)
+	f.sourcesMutex.Lock()
+	src.recordSuccess(int64(len(data)), elapsed)
+	f.sourcesMutex.Unlock()
+
+	chunkDurationHistogram.WithLabelValues(src.URL).Observe(elapsed.Seconds())
+	sourceThroughputGauge.WithLabelValues(src.URL).Set(src.EWMAThroughput)
+
+	return data
+}
+
+// Healthy reports whether at least one source has completed a chunk
+// successfully, suitable for wiring into ServeMetrics' readiness probe.
+func (f *VideoFetcher) Healthy() bool {
+	f.sourcesMutex.RLock()
+	defer f.sourcesMutex.RUnlock()
+	for _, s := range f.sources {
+		if s.Completed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *VideoFetcher) recordFailure(src *SourceStats) {
+	f.sourcesMutex.Lock()
+	src.Failed++
+	f.sourcesMutex.Unlock()
+}
+
 // GetNextChunk returns next available chunk for streaming
 func (f *VideoFetcher) GetNextChunk() (*VideoChunk, error) {
 	f.chunkMutex.RLock()
@@ -0,0 +1,46 @@
+// metrics.go - Prometheus metrics and /healthz for the fetcher
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	sourceThroughputGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kronop",
+		Name:      "fetcher_source_throughput_bytes_per_second",
+		Help:      "EWMA throughput per download source URL.",
+	}, []string{"source"})
+
+	chunkDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kronop",
+		Name:      "fetcher_chunk_download_duration_seconds",
+		Help:      "Chunk download duration, bucketed by source URL.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+)
+
+func init() {
+	prometheus.MustRegister(sourceThroughputGauge, chunkDurationHistogram)
+}
+
+// ServeMetrics starts a /metrics and /healthz HTTP server on addr. healthy
+// should report whether the fetcher currently has at least one usable
+// source, so orchestrators can gate traffic on it.
+func ServeMetrics(addr string, healthy func() bool) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !healthy() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,26 @@
+// Package audit writes structured, one-line-per-event audit records for
+// the wallet-auth pipeline to a configurable sink - stdout, a file, or a
+// Redis stream for tamper-evident, SIEM-consumable logging.
+package audit
+
+import "context"
+
+// Event is one structured audit-log line for an auth-pipeline event, e.g.
+// "nonce.issued" or "verify".
+type Event struct {
+	Event      string  `json:"event"`
+	Address    string  `json:"address"`
+	IP         string  `json:"ip"`
+	UserAgent  string  `json:"user_agent"`
+	Nonce      string  `json:"nonce,omitempty"`
+	Result     string  `json:"result"`
+	Confidence float64 `json:"confidence,omitempty"`
+	DurationMs int64   `json:"duration_ms"`
+	Timestamp  int64   `json:"ts"`
+}
+
+// Sink persists one audit Event. Implementations must be safe for
+// concurrent use, since auth handlers may write from many goroutines.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// auditStreamKey is the Redis stream audit events are appended to - an
+// append-only, tamper-evident log a downstream SIEM can XREAD without the
+// auth service knowing anything about who's consuming it.
+const auditStreamKey = "audit:auth"
+
+// RedisStreamSink appends each event as one entry on the audit:auth Redis
+// stream via XADD.
+type RedisStreamSink struct {
+	redis *redis.Client
+}
+
+// NewRedisStreamSink builds a RedisStreamSink writing to rdb.
+func NewRedisStreamSink(rdb *redis.Client) *RedisStreamSink {
+	return &RedisStreamSink{redis: rdb}
+}
+
+func (s *RedisStreamSink) Write(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: auditStreamKey,
+		Values: map[string]interface{}{"event": payload},
+	}).Err()
+}
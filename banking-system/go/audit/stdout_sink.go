@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdoutSink writes one JSON line per event to an io.Writer (os.Stdout by
+// default) - the simplest sink, for local development or deployments where
+// a surrounding process collects stdout as the actual log sink.
+type StdoutSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutSink returns a StdoutSink writing to os.Stdout.
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{w: os.Stdout}
+}
+
+func (s *StdoutSink) Write(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintln(s.w, string(line))
+	return err
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// EIP712Domain is the "domain separator" struct every EIP-712 typed
+// message is scoped to.
+type EIP712Domain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainID           int64  `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract"`
+}
+
+// EIP712TypeField is one field of an EIP-712 struct type, in declaration
+// order (order matters - it's part of the type's encoding).
+type EIP712TypeField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EIP712TypedData is an eth_signTypedData_v4 payload. Types must include an
+// entry for every referenced struct type except "EIP712Domain", which is
+// derived from Domain. Only flat structs of primitive fields are
+// supported - arrays and nested dynamic types are not.
+type EIP712TypedData struct {
+	Types       map[string][]EIP712TypeField `json:"types"`
+	PrimaryType string                       `json:"primaryType"`
+	Domain      EIP712Domain                 `json:"domain"`
+	Message     map[string]interface{}       `json:"message"`
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// encodeType renders typeName's canonical EIP-712 type string, e.g.
+// "Mail(address from,address to,string contents)", with any referenced
+// struct types appended afterwards in alphabetical order as the standard
+// requires.
+func encodeType(types map[string][]EIP712TypeField, typeName string) (string, error) {
+	fields, ok := types[typeName]
+	if !ok {
+		return "", fmt.Errorf("eip712: unknown type %q", typeName)
+	}
+
+	referenced := make(map[string]struct{})
+	collectReferencedTypes(types, typeName, referenced)
+	delete(referenced, typeName)
+
+	others := make([]string, 0, len(referenced))
+	for name := range referenced {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+
+	var b strings.Builder
+	writeTypeSignature(&b, typeName, fields)
+	for _, name := range others {
+		writeTypeSignature(&b, name, types[name])
+	}
+
+	return b.String(), nil
+}
+
+func writeTypeSignature(b *strings.Builder, typeName string, fields []EIP712TypeField) {
+	b.WriteString(typeName)
+	b.WriteByte('(')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, "%s %s", f.Type, f.Name)
+	}
+	b.WriteByte(')')
+}
+
+func collectReferencedTypes(types map[string][]EIP712TypeField, typeName string, seen map[string]struct{}) {
+	if _, ok := seen[typeName]; ok {
+		return
+	}
+	fields, ok := types[typeName]
+	if !ok {
+		return
+	}
+	seen[typeName] = struct{}{}
+	for _, f := range fields {
+		if _, isStruct := types[f.Type]; isStruct {
+			collectReferencedTypes(types, f.Type, seen)
+		}
+	}
+}
+
+func typeHash(types map[string][]EIP712TypeField, typeName string) ([]byte, error) {
+	encoded, err := encodeType(types, typeName)
+	if err != nil {
+		return nil, err
+	}
+	return keccak256([]byte(encoded)), nil
+}
+
+// hashStruct implements EIP-712's encodeData + keccak256 for typeName,
+// supporting the primitive field types (string, bytesN, address, bool,
+// (u)intN) and references to other flat struct types declared in types.
+func hashStruct(types map[string][]EIP712TypeField, typeName string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("eip712: unknown type %q", typeName)
+	}
+
+	tHash, err := typeHash(types, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]byte, 0, 32*(len(fields)+1))
+	encoded = append(encoded, tHash...)
+
+	for _, f := range fields {
+		value, err := encodeEIP712Value(types, f.Type, data[f.Name])
+		if err != nil {
+			return nil, fmt.Errorf("eip712: field %q: %w", f.Name, err)
+		}
+		encoded = append(encoded, value...)
+	}
+
+	return keccak256(encoded), nil
+}
+
+// encodeEIP712Value encodes one field value to its 32-byte ABI word, per
+// EIP-712's encodeData rules.
+func encodeEIP712Value(types map[string][]EIP712TypeField, fieldType string, value interface{}) ([]byte, error) {
+	if _, isStruct := types[fieldType]; isStruct {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object for struct type %q", fieldType)
+		}
+		return hashStruct(types, fieldType, nested)
+	}
+
+	switch {
+	case fieldType == "string":
+		s, _ := value.(string)
+		return keccak256([]byte(s)), nil
+	case fieldType == "bytes":
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return keccak256(b), nil
+	case fieldType == "address":
+		s, _ := value.(string)
+		addr := strings.TrimPrefix(s, "0x")
+		return leftPadHex(addr, 32)
+	case fieldType == "bool":
+		b, _ := value.(bool)
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case strings.HasPrefix(fieldType, "bytes"):
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	case strings.HasPrefix(fieldType, "uint"), strings.HasPrefix(fieldType, "int"):
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		word := make([]byte, 32)
+		n.FillBytes(word)
+		return word, nil
+	default:
+		return nil, fmt.Errorf("unsupported EIP-712 field type %q", fieldType)
+	}
+}
+
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(strings.TrimPrefix(v, "0x")), nil
+	case []byte:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected string or []byte, got %T", value)
+	}
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case float64:
+		return big.NewInt(int64(v)), nil
+	case string:
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	case int64:
+		return big.NewInt(v), nil
+	default:
+		return nil, fmt.Errorf("expected number, got %T", value)
+	}
+}
+
+func leftPadHex(hexDigits string, size int) ([]byte, error) {
+	if len(hexDigits)%2 != 0 {
+		hexDigits = "0" + hexDigits
+	}
+	raw, err := hex.DecodeString(hexDigits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex %q: %w", hexDigits, err)
+	}
+	if len(raw) > size {
+		return nil, fmt.Errorf("value %q too large for %d-byte word", hexDigits, size)
+	}
+	word := make([]byte, size)
+	copy(word[size-len(raw):], raw)
+	return word, nil
+}
+
+// domainTypeFields derives the EIP712Domain type's fields in the fixed
+// order the standard expects, including only the fields actually set.
+func domainTypeFields(domain EIP712Domain) []EIP712TypeField {
+	var fields []EIP712TypeField
+	if domain.Name != "" {
+		fields = append(fields, EIP712TypeField{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, EIP712TypeField{Name: "version", Type: "string"})
+	}
+	if domain.ChainID != 0 {
+		fields = append(fields, EIP712TypeField{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, EIP712TypeField{Name: "verifyingContract", Type: "address"})
+	}
+	return fields
+}
+
+func domainData(domain EIP712Domain) map[string]interface{} {
+	return map[string]interface{}{
+		"name":              domain.Name,
+		"version":           domain.Version,
+		"chainId":           domain.ChainID,
+		"verifyingContract": domain.VerifyingContract,
+	}
+}
+
+// HashEIP712 computes the EIP-712 signing digest for typed -
+// keccak256("\x19\x01" || domainSeparator || hashStruct(message)) - the
+// bytes an EIP-712-compliant wallet actually signs.
+func HashEIP712(typed EIP712TypedData) ([]byte, error) {
+	types := make(map[string][]EIP712TypeField, len(typed.Types)+1)
+	for name, fields := range typed.Types {
+		types[name] = fields
+	}
+	types["EIP712Domain"] = domainTypeFields(typed.Domain)
+
+	domainSeparator, err := hashStruct(types, "EIP712Domain", domainData(typed.Domain))
+	if err != nil {
+		return nil, fmt.Errorf("domain separator: %w", err)
+	}
+
+	messageHash, err := hashStruct(types, typed.PrimaryType, typed.Message)
+	if err != nil {
+		return nil, fmt.Errorf("message hash: %w", err)
+	}
+
+	return keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
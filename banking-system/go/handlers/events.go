@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Auth event types streamed over GET /api/auth/events.
+const (
+	EventSessionCreated      = "session.created"
+	EventSessionRevoked      = "session.revoked"
+	EventSessionExpiringSoon = "session.expiring_soon"
+	EventNonceConsumed       = "nonce.consumed"
+	EventLoginNewDevice      = "login.new_device"
+)
+
+// authEventsChannel is the Redis pub/sub channel Publish sends to and the
+// EventHub subscribes to, one per address.
+func authEventsChannel(address string) string {
+	return fmt.Sprintf("auth-events:%s", address)
+}
+
+// AuthEvent is what's published to an address's auth-events channel and
+// what a GET /api/auth/events client receives as a WebSocket text message.
+type AuthEvent struct {
+	Type      string      `json:"type"`
+	Address   string      `json:"address"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// eventSubscriberBuffer bounds how many unread events a single subscriber
+// can fall behind by before it's dropped - a slow browser tab shouldn't be
+// able to back up publishes for every other tab on the same address.
+const eventSubscriberBuffer = 16
+
+// eventSubscriber is one GET /api/auth/events connection's mailbox.
+type eventSubscriber struct {
+	send chan []byte
+}
+
+// addressHub fans a single Redis subscription for one address out to every
+// locally-connected subscriber for that address, so N browser tabs cost one
+// Redis subscription instead of N.
+type addressHub struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+	cancel      context.CancelFunc
+}
+
+// EventHub multiplexes auth event delivery across every address with at
+// least one connected GET /api/auth/events client.
+type EventHub struct {
+	redis *redis.Client
+
+	mu   sync.Mutex
+	hubs map[string]*addressHub
+}
+
+// NewEventHub returns an EventHub that publishes to and subscribes on rdb.
+func NewEventHub(rdb *redis.Client) *EventHub {
+	return &EventHub{redis: rdb, hubs: make(map[string]*addressHub)}
+}
+
+// Publish sends event to every subscriber currently watching address,
+// across this process and any other instance sharing the same Redis.
+func (eh *EventHub) Publish(ctx context.Context, address string, event AuthEvent) error {
+	event.Address = address
+	event.Timestamp = time.Now().Unix()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal auth event: %w", err)
+	}
+	return eh.redis.Publish(ctx, authEventsChannel(address), payload).Err()
+}
+
+// Subscribe registers a new subscriber for address, starting the
+// underlying Redis subscription if this is the first subscriber for that
+// address. The returned function must be called to unsubscribe and, once
+// the last subscriber for address leaves, stop the Redis subscription.
+func (eh *EventHub) Subscribe(address string) (*eventSubscriber, func()) {
+	sub := &eventSubscriber{send: make(chan []byte, eventSubscriberBuffer)}
+
+	eh.mu.Lock()
+	hub, ok := eh.hubs[address]
+	if !ok {
+		hub = &addressHub{subscribers: make(map[*eventSubscriber]struct{})}
+		eh.hubs[address] = hub
+		eh.startAddressHub(address, hub)
+	}
+	eh.mu.Unlock()
+
+	hub.mu.Lock()
+	hub.subscribers[sub] = struct{}{}
+	hub.mu.Unlock()
+
+	unsubscribe := func() {
+		hub.mu.Lock()
+		delete(hub.subscribers, sub)
+		empty := len(hub.subscribers) == 0
+		hub.mu.Unlock()
+
+		if empty {
+			eh.mu.Lock()
+			if current, ok := eh.hubs[address]; ok && current == hub {
+				delete(eh.hubs, address)
+				hub.cancel()
+			}
+			eh.mu.Unlock()
+		}
+	}
+
+	return sub, unsubscribe
+}
+
+// startAddressHub runs the Redis subscription for address in the
+// background until ctx is cancelled (by the last subscriber leaving),
+// fanning every received message out to hub's current subscribers.
+func (eh *EventHub) startAddressHub(address string, hub *addressHub) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hub.cancel = cancel
+
+	pubsub := eh.redis.Subscribe(ctx, authEventsChannel(address))
+
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				hub.broadcast([]byte(msg.Payload))
+			}
+		}
+	}()
+}
+
+// broadcast fans payload out to every subscriber, dropping (and closing)
+// any subscriber whose buffer is already full rather than blocking on it.
+func (hub *addressHub) broadcast(payload []byte) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for sub := range hub.subscribers {
+		select {
+		case sub.send <- payload:
+		default:
+			close(sub.send)
+			delete(hub.subscribers, sub)
+		}
+	}
+}
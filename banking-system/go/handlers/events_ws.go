@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+)
+
+// eventsHeartbeatInterval is how often a ping event is sent to keep idle
+// GET /api/auth/events connections (and any intermediary proxy) alive.
+const eventsHeartbeatInterval = 30 * time.Second
+
+// expiringSoonWindow is how long before a token's exp the
+// session.expiring_soon event fires.
+const expiringSoonWindow = 5 * time.Minute
+
+var eventsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// GET /api/auth/events - streams session/auth events for the bearer
+// token's address until the client disconnects or the token expires.
+// Authenticated the same way as GetSession, except the token may also be
+// supplied as ?token= since browsers can't set an Authorization header on
+// the WebSocket handshake request.
+func (h *WalletAuthHandler) Events(w http.ResponseWriter, r *http.Request) {
+	tokenString := bearerOrQueryToken(r)
+	if tokenString == "" {
+		http.Error(w, "No token", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := h.parseAccessToken(tokenString)
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	address, _ := claims["address"].(string)
+	expUnix, _ := claims["exp"].(float64)
+	expiresAt := time.Unix(int64(expUnix), 0)
+	if address == "" || !time.Now().Before(expiresAt) {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := eventsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub, unsubscribe := h.events.Subscribe(address)
+	defer unsubscribe()
+
+	// The client disconnecting is only observable by reading; discard
+	// anything it sends, and use the read error to notice it's gone.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	expiringAt := expiresAt.Add(-expiringSoonWindow)
+	expiringSoon := time.After(time.Until(expiringAt))
+	tokenExpiry := time.NewTimer(time.Until(expiresAt))
+	defer tokenExpiry.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-tokenExpiry.C:
+			return
+		case <-expiringSoon:
+			h.writeAuthEvent(conn, AuthEvent{Type: EventSessionExpiringSoon, Address: address})
+			expiringSoon = nil
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case payload, ok := <-sub.send:
+			if !ok {
+				// Dropped for falling behind - closing tells the client
+				// to reconnect rather than silently stalling forever.
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *WalletAuthHandler) writeAuthEvent(conn *websocket.Conn, event AuthEvent) {
+	event.Timestamp = time.Now().Unix()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// bearerOrQueryToken reads the access token from the Authorization header,
+// falling back to ?token= for WebSocket clients that can't set headers on
+// the handshake request.
+func bearerOrQueryToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && auth[:7] == "Bearer " {
+		return auth[7:]
+	}
+	return r.URL.Query().Get("token")
+}
+
+// parseAccessToken verifies tokenString the same way GetSession does and
+// returns its claims.
+func (h *WalletAuthHandler) parseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		public, ok := h.keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return public, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid claims")
+	}
+	return claims, nil
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// rsaKeyBits is the modulus size for generated signing keys. 2048 is the
+// minimum RSA size still considered safe for RS256 and matches what most
+// JWKS-consuming libraries expect.
+const rsaKeyBits = 2048
+
+// keyEntry is one generated keypair tracked by KeyManager.
+type keyEntry struct {
+	kid       string
+	private   *rsa.PrivateKey
+	retiredAt time.Time // zero while this entry is the current signing key
+}
+
+// KeyManager generates and rotates the RSA keypair WalletAuthHandler signs
+// access tokens with, and publishes the public half as a JWK Set so other
+// services can verify tokens without sharing a symmetric secret. A retired
+// key is kept around for gracePeriod - normally the access token TTL - so
+// a token signed just before rotation still verifies until it expires.
+type KeyManager struct {
+	mu          sync.RWMutex
+	entries     map[string]*keyEntry
+	currentKid  string
+	gracePeriod time.Duration
+}
+
+// NewKeyManager generates an initial signing key and returns a KeyManager
+// that keeps retired keys published for gracePeriod after they're replaced.
+func NewKeyManager(gracePeriod time.Duration) (*KeyManager, error) {
+	km := &KeyManager{
+		entries:     make(map[string]*keyEntry),
+		gracePeriod: gracePeriod,
+	}
+
+	entry, err := newKeyEntry()
+	if err != nil {
+		return nil, err
+	}
+	km.entries[entry.kid] = entry
+	km.currentKid = entry.kid
+
+	return km, nil
+}
+
+func newKeyEntry() (*keyEntry, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+
+	kidBytes := make([]byte, 8)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return nil, fmt.Errorf("generate kid: %w", err)
+	}
+
+	return &keyEntry{kid: hex.EncodeToString(kidBytes), private: priv}, nil
+}
+
+// Current returns the kid and private key new tokens should be signed with.
+func (km *KeyManager) Current() (kid string, private *rsa.PrivateKey) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	entry := km.entries[km.currentKid]
+	return entry.kid, entry.private
+}
+
+// Lookup returns the public key for kid, for verifying a token's signature.
+// It returns ok=false once kid has been retired for longer than the grace
+// period, at which point GetSession should treat the token as unverifiable.
+func (km *KeyManager) Lookup(kid string) (public *rsa.PublicKey, ok bool) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	entry, found := km.entries[kid]
+	if !found {
+		return nil, false
+	}
+	return &entry.private.PublicKey, true
+}
+
+// Rotate retires the current key and generates a new one to sign with,
+// returning the new kid. The retired key's public half stays in the JWKS
+// response for gracePeriod so already-issued tokens keep verifying.
+func (km *KeyManager) Rotate() (kid string, err error) {
+	entry, err := newKeyEntry()
+	if err != nil {
+		return "", err
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if current, ok := km.entries[km.currentKid]; ok {
+		current.retiredAt = time.Now()
+	}
+	km.entries[entry.kid] = entry
+	km.currentKid = entry.kid
+	km.prune()
+
+	return entry.kid, nil
+}
+
+// prune drops keys retired longer than gracePeriod ago. Callers must hold
+// km.mu for writing.
+func (km *KeyManager) prune() {
+	for kid, entry := range km.entries {
+		if entry.retiredAt.IsZero() {
+			continue
+		}
+		if time.Since(entry.retiredAt) > km.gracePeriod {
+			delete(km.entries, kid)
+		}
+	}
+}
+
+// JWK is one entry of a JSON Web Key Set, describing an RSA public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSet is the JWK Set served from GET /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every key still within its grace period, current key
+// included, as a JWK Set.
+func (km *KeyManager) JWKS() JWKSet {
+	km.mu.Lock()
+	km.prune()
+	entries := make([]*keyEntry, 0, len(km.entries))
+	for _, entry := range km.entries {
+		entries = append(entries, entry)
+	}
+	km.mu.Unlock()
+
+	keys := make([]JWK, 0, len(entries))
+	for _, entry := range entries {
+		pub := entry.private.PublicKey
+		keys = append(keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: entry.kid,
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(encodeExponent(pub.E)),
+		})
+	}
+
+	return JWKSet{Keys: keys}
+}
+
+// encodeExponent renders e as the minimal big-endian byte string a JWK's
+// "e" field expects (no leading zero byte, unlike a fixed-width int).
+func encodeExponent(e int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(e))
+	return new(big.Int).SetBytes(buf).Bytes()
+}
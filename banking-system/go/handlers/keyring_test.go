@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// sign builds a minimal RS256 token under the KeyManager's current key, the
+// same way VerifySignature does.
+func sign(t *testing.T, km *KeyManager) string {
+	t.Helper()
+
+	kid, private := km.Current()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"address": "0xabc",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = kid
+
+	tokenString, err := token.SignedString(private)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return tokenString
+}
+
+func verify(km *KeyManager, tokenString string) error {
+	_, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		public, ok := km.Lookup(kid)
+		if !ok {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return public, nil
+	})
+	return err
+}
+
+func TestKeyManagerIssuesAndVerifiesUnderCurrentKey(t *testing.T) {
+	km, err := NewKeyManager(time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+
+	tokenA := sign(t, km)
+	if err := verify(km, tokenA); err != nil {
+		t.Fatalf("token signed under key A should verify: %v", err)
+	}
+}
+
+func TestKeyManagerRotationKeepsOldTokenValidDuringGrace(t *testing.T) {
+	km, err := NewKeyManager(time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+
+	kidA, _ := km.Current()
+	tokenA := sign(t, km)
+
+	kidB, err := km.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if kidB == kidA {
+		t.Fatalf("Rotate should generate a new kid")
+	}
+
+	tokenB := sign(t, km)
+
+	if err := verify(km, tokenA); err != nil {
+		t.Fatalf("token A should still verify during grace period: %v", err)
+	}
+	if err := verify(km, tokenB); err != nil {
+		t.Fatalf("token B signed under the new key should verify: %v", err)
+	}
+
+	if _, ok := km.Lookup(kidA); !ok {
+		t.Fatalf("retired key A should still be published during grace period")
+	}
+}
+
+func TestKeyManagerPrunesRetiredKeyAfterGracePeriod(t *testing.T) {
+	km, err := NewKeyManager(0)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+
+	kidA, _ := km.Current()
+	if _, err := km.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if _, ok := km.Lookup(kidA); ok {
+		t.Fatalf("key A should have been pruned once its grace period (0) elapsed")
+	}
+}
+
+func TestJWKSListsOnlyUnprunedKeys(t *testing.T) {
+	km, err := NewKeyManager(time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+
+	if _, err := km.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	set := km.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS should list both the current and still-in-grace retired key, got %d", len(set.Keys))
+	}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Alg != "RS256" || k.Use != "sig" {
+			t.Fatalf("unexpected JWK shape: %+v", k)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// refreshTokenTTL is how long an unused refresh token stays valid, and how
+// long a family's membership set is kept around.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// errRefreshNotFound and errRefreshReused are the two failure modes
+// refreshTokenPair distinguishes so the caller can respond appropriately -
+// reuse specifically triggers revoking the whole token family.
+var (
+	errRefreshNotFound = errors.New("refresh token not found or expired")
+	errRefreshReused   = errors.New("refresh token reuse detected")
+)
+
+// refreshRecord is what refreshTokenPair persists in Redis at
+// refresh:{hash(token)}. ParentID is empty for the first refresh token a
+// login issues; every later one in the same family chains to the refresh
+// token it replaced.
+type refreshRecord struct {
+	Address   string `json:"address"`
+	ChainID   int64  `json:"chain_id"`
+	Domain    string `json:"domain"`
+	FamilyID  string `json:"family_id"`
+	ParentID  string `json:"parent_id"`
+	IssuedAt  int64  `json:"issued_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	Used      bool   `json:"used"`
+}
+
+func refreshKey(hash string) string {
+	return fmt.Sprintf("refresh:%s", hash)
+}
+
+func familyKey(familyID string) string {
+	return fmt.Sprintf("family:%s", familyID)
+}
+
+func revokedJTIKey(jti string) string {
+	return fmt.Sprintf("revoked:%s", jti)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHexToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// issueTokenPair mints a fresh access JWT plus an opaque refresh token,
+// chaining it to parentID within familyID (familyID is generated fresh for
+// a new login; pass "" for parentID in that case). The refresh token is
+// registered under family:{familyID} so the whole family can be revoked as
+// one unit on reuse detection or logout.
+func (h *WalletAuthHandler) issueTokenPair(ctx context.Context, address string, chainID int64, domain, familyID, parentID string) (accessToken, refreshToken string, err error) {
+	if familyID == "" {
+		familyID, err = randomHexToken(16)
+		if err != nil {
+			return "", "", fmt.Errorf("generate family id: %w", err)
+		}
+	}
+
+	jti, err := randomHexToken(16)
+	if err != nil {
+		return "", "", fmt.Errorf("generate jti: %w", err)
+	}
+
+	kid, private := h.keys.Current()
+	now := time.Now()
+	claims := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"address":   address,
+		"chain_id":  chainID,
+		"domain":    domain,
+		"family_id": familyID,
+		"jti":       jti,
+		"exp":       now.Add(accessTokenTTL).Unix(),
+		"iat":       now.Unix(),
+	})
+	claims.Header["kid"] = kid
+
+	accessToken, err = claims.SignedString(private)
+	if err != nil {
+		return "", "", fmt.Errorf("sign access token: %w", err)
+	}
+
+	refreshToken, err = randomHexToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	hash := hashToken(refreshToken)
+
+	record := refreshRecord{
+		Address:   address,
+		ChainID:   chainID,
+		Domain:    domain,
+		FamilyID:  familyID,
+		ParentID:  parentID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(refreshTokenTTL).Unix(),
+	}
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal refresh record: %w", err)
+	}
+
+	pipe := h.redis.TxPipeline()
+	pipe.Set(ctx, refreshKey(hash), recordJSON, refreshTokenTTL)
+	pipe.SAdd(ctx, familyKey(familyID), hash)
+	pipe.Expire(ctx, familyKey(familyID), refreshTokenTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", fmt.Errorf("persist refresh token: %w", err)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// refreshTokenPair validates presented, marking it used and issuing a new
+// access+refresh pair chained to it. It uses a Watch-based optimistic
+// transaction so two concurrent refreshes of the same token can't both
+// succeed - the loser sees the token already marked used and is treated as
+// a reuse, which revokes the whole family.
+func (h *WalletAuthHandler) refreshTokenPair(ctx context.Context, presented string) (accessToken, newRefreshToken string, err error) {
+	hash := hashToken(presented)
+	key := refreshKey(hash)
+
+	var record refreshRecord
+	reused := false
+
+	txf := func(tx *redis.Tx) error {
+		raw, err := tx.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return errRefreshNotFound
+		} else if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return err
+		}
+		if record.Used {
+			reused = true
+			return nil
+		}
+
+		record.Used = true
+		updated, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		ttl := time.Until(time.Unix(record.ExpiresAt, 0))
+		if ttl <= 0 {
+			return errRefreshNotFound
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, ttl)
+			return nil
+		})
+		return err
+	}
+
+	// WATCH aborts the transaction with TxFailedErr if a concurrent
+	// refresh changed this key first; retrying lets that loser see the
+	// now-Used record and correctly report reuse instead of erroring out.
+	const maxAttempts = 5
+	for attempt := 0; ; attempt++ {
+		err := h.redis.Watch(ctx, txf, key)
+		if err == nil {
+			break
+		}
+		if err == redis.TxFailedErr && attempt < maxAttempts-1 {
+			continue
+		}
+		return "", "", err
+	}
+
+	if reused {
+		h.revokeFamily(ctx, record.FamilyID)
+		h.events.Publish(ctx, record.Address, AuthEvent{Type: EventSessionRevoked})
+		return "", "", errRefreshReused
+	}
+
+	return h.issueTokenPair(ctx, record.Address, record.ChainID, record.Domain, record.FamilyID, hash)
+}
+
+// revokeFamily deletes every refresh token ever issued under familyID,
+// so a stolen-and-reused refresh token can't be replayed again and none of
+// its descendants can be refreshed further either.
+func (h *WalletAuthHandler) revokeFamily(ctx context.Context, familyID string) error {
+	hashes, err := h.redis.SMembers(ctx, familyKey(familyID)).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := h.redis.TxPipeline()
+	for _, hash := range hashes {
+		pipe.Del(ctx, refreshKey(hash))
+	}
+	pipe.Del(ctx, familyKey(familyID))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// revokeJTI records jti as revoked until ttl elapses - normally the
+// remaining lifetime of the access token it was issued for, so the revoked
+// marker never outlives the token it guards against.
+func (h *WalletAuthHandler) revokeJTI(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return h.redis.Set(ctx, revokedJTIKey(jti), "1", ttl).Err()
+}
+
+func (h *WalletAuthHandler) isJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := h.redis.Exists(ctx, revokedJTIKey(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
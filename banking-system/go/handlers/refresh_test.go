@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestWalletAuthHandler builds a handler against a local Redis instance,
+// skipping the test if one isn't reachable - there's no in-memory Redis
+// fake available in this module, and these cases are inherently about
+// Redis-level atomicity, so a real server is what's worth testing against.
+func newTestWalletAuthHandler(t *testing.T) *WalletAuthHandler {
+	t.Helper()
+
+	h, err := NewWalletAuthHandler(nil, "localhost:6379")
+	if err != nil {
+		t.Skipf("redis not available: %v", err)
+	}
+	return h
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	h := newTestWalletAuthHandler(t)
+	ctx := context.Background()
+
+	_, refreshToken, err := h.issueTokenPair(ctx, "0xabc", 1, "example.com", "", "")
+	if err != nil {
+		t.Fatalf("issueTokenPair: %v", err)
+	}
+
+	_, newRefreshToken, err := h.refreshTokenPair(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("refreshTokenPair: %v", err)
+	}
+	if newRefreshToken == refreshToken {
+		t.Fatalf("refresh should mint a new refresh token, not reuse the old one")
+	}
+
+	// The rotated-to token should itself still be good for one more refresh.
+	if _, _, err := h.refreshTokenPair(ctx, newRefreshToken); err != nil {
+		t.Fatalf("refreshing the newly issued token should succeed: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	h := newTestWalletAuthHandler(t)
+	ctx := context.Background()
+
+	_, refreshToken, err := h.issueTokenPair(ctx, "0xabc", 1, "example.com", "", "")
+	if err != nil {
+		t.Fatalf("issueTokenPair: %v", err)
+	}
+
+	_, newRefreshToken, err := h.refreshTokenPair(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+
+	// Replaying the original (already-used) refresh token simulates a
+	// stolen token being presented after the legitimate client refreshed.
+	if _, _, err := h.refreshTokenPair(ctx, refreshToken); err != errRefreshReused {
+		t.Fatalf("replaying a used refresh token should report reuse, got %v", err)
+	}
+
+	// The entire family, including the token issued by the legitimate
+	// refresh, must now be revoked.
+	if _, _, err := h.refreshTokenPair(ctx, newRefreshToken); err != errRefreshNotFound {
+		t.Fatalf("descendant token should be revoked after reuse, got %v", err)
+	}
+}
+
+func TestRefreshTokenConcurrentRaceOnlyOneWinner(t *testing.T) {
+	h := newTestWalletAuthHandler(t)
+	ctx := context.Background()
+
+	_, refreshToken, err := h.issueTokenPair(ctx, "0xabc", 1, "example.com", "", "")
+	if err != nil {
+		t.Fatalf("issueTokenPair: %v", err)
+	}
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := h.refreshTokenPair(ctx, refreshToken)
+			results[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	successes := 0
+	for _, err := range results {
+		if err == nil {
+			successes++
+		} else if err != errRefreshReused {
+			t.Fatalf("unexpected error from concurrent refresh: %v", err)
+		}
+	}
+	if successes != 1 {
+		t.Fatalf("exactly one concurrent refresh of the same token should succeed, got %d", successes)
+	}
+}
+
+func TestRevokeJTIAndIsJTIRevoked(t *testing.T) {
+	h := newTestWalletAuthHandler(t)
+	ctx := context.Background()
+
+	jti := fmt.Sprintf("test-jti-%d", time.Now().UnixNano())
+
+	revoked, err := h.isJTIRevoked(ctx, jti)
+	if err != nil {
+		t.Fatalf("isJTIRevoked: %v", err)
+	}
+	if revoked {
+		t.Fatalf("jti should not be revoked before revokeJTI is called")
+	}
+
+	if err := h.revokeJTI(ctx, jti, time.Minute); err != nil {
+		t.Fatalf("revokeJTI: %v", err)
+	}
+
+	revoked, err = h.isJTIRevoked(ctx, jti)
+	if err != nil {
+		t.Fatalf("isJTIRevoked: %v", err)
+	}
+	if !revoked {
+		t.Fatalf("jti should be revoked after revokeJTI")
+	}
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"banking-system/go/grpc_client"
+	pb "banking-system/go/proto"
+)
+
+// Scheme identifies a signature scheme VerifySignature can validate
+// against. It's carried on the verify request, defaulted from the
+// address's format when omitted, and persisted in NonceData so
+// VerifySignature always checks against the scheme GetNonce issued under.
+type Scheme string
+
+const (
+	SchemePersonalSign  Scheme = "personal_sign"
+	SchemeEIP712        Scheme = "eth_signTypedData_v4"
+	SchemeSolanaEd25519 Scheme = "solana_ed25519"
+	SchemeCosmosADR36   Scheme = "cosmos_adr36"
+	SchemeBitcoinBIP322 Scheme = "bitcoin_bip322"
+)
+
+// VerifyInput is everything a SignatureVerifier needs to validate one
+// signature, already scheme-agnostic - EIP-712 canonicalization (if any)
+// has already happened by the time a verifier sees this.
+type VerifyInput struct {
+	Address   string
+	Message   string // the exact bytes/hex digest that was signed
+	Signature string
+	ChainID   int64
+}
+
+// VerifyResult is what a SignatureVerifier reports back.
+type VerifyResult struct {
+	Verified   bool
+	Confidence float64
+}
+
+// SignatureVerifier validates one signature scheme. Implementations
+// canonicalize whatever is scheme-specific about the payload and forward
+// the resulting message/digest to the Rust verification service, which
+// holds the actual cryptographic primitives for each chain.
+type SignatureVerifier interface {
+	Scheme() Scheme
+	// MatchesAddress reports whether address's format is consistent with
+	// this scheme, so a request can be rejected early (e.g. a 0x address
+	// presented with a Solana scheme) instead of failing deep inside
+	// verification.
+	MatchesAddress(address string) bool
+	Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error)
+}
+
+var (
+	verifiersMu sync.RWMutex
+	verifiers   = map[Scheme]SignatureVerifier{}
+)
+
+// Register adds or replaces the verifier for scheme, so downstream users
+// can add custom chains without editing this package.
+func Register(scheme Scheme, verifier SignatureVerifier) {
+	verifiersMu.Lock()
+	defer verifiersMu.Unlock()
+	verifiers[scheme] = verifier
+}
+
+func lookupVerifier(scheme Scheme) (SignatureVerifier, bool) {
+	verifiersMu.RLock()
+	defer verifiersMu.RUnlock()
+	v, ok := verifiers[scheme]
+	return v, ok
+}
+
+func init() {
+	Register(SchemePersonalSign, personalSignVerifier{})
+	Register(SchemeEIP712, eip712Verifier{})
+	Register(SchemeSolanaEd25519, solanaVerifier{})
+	Register(SchemeCosmosADR36, cosmosVerifier{})
+	Register(SchemeBitcoinBIP322, bitcoinVerifier{})
+}
+
+var (
+	hexAddressPattern    = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	base58AddressPattern = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	bitcoinAddressPrefix = []string{"1", "3", "bc1"}
+)
+
+// DetectScheme guesses the signature scheme a bare address implies, for
+// requests that don't declare one explicitly.
+func DetectScheme(address string) (Scheme, bool) {
+	switch {
+	case hexAddressPattern.MatchString(address):
+		return SchemePersonalSign, true
+	case strings.HasPrefix(address, "cosmos"):
+		return SchemeCosmosADR36, true
+	case hasBitcoinPrefix(address):
+		return SchemeBitcoinBIP322, true
+	case base58AddressPattern.MatchString(address):
+		return SchemeSolanaEd25519, true
+	default:
+		return "", false
+	}
+}
+
+func hasBitcoinPrefix(address string) bool {
+	for _, prefix := range bitcoinAddressPrefix {
+		if strings.HasPrefix(address, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// personalSignVerifier handles EIP-191 personal_sign over a free-form
+// Ethereum message - the scheme this handler originally only supported.
+type personalSignVerifier struct{}
+
+func (personalSignVerifier) Scheme() Scheme { return SchemePersonalSign }
+
+func (personalSignVerifier) MatchesAddress(address string) bool {
+	return hexAddressPattern.MatchString(address)
+}
+
+func (personalSignVerifier) Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error) {
+	resp, err := rustClient.VerifySignature(ctx, &pb.VerifySignatureRequest{
+		Address:   input.Address,
+		Message:   input.Message,
+		Signature: input.Signature,
+		Scheme:    string(SchemePersonalSign),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Verified: resp.Verified, Confidence: resp.Confidence}, nil
+}
+
+// eip712Verifier handles eth_signTypedData_v4. The caller is expected to
+// have already reduced the typed-data payload to its 32-byte signing
+// digest (via HashEIP712) before building VerifyInput.Message - Rust only
+// ever sees the digest, never the structured payload.
+type eip712Verifier struct{}
+
+func (eip712Verifier) Scheme() Scheme { return SchemeEIP712 }
+
+func (eip712Verifier) MatchesAddress(address string) bool {
+	return hexAddressPattern.MatchString(address)
+}
+
+func (eip712Verifier) Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error) {
+	resp, err := rustClient.VerifySignature(ctx, &pb.VerifySignatureRequest{
+		Address:   input.Address,
+		Message:   input.Message, // hex-encoded EIP-712 digest
+		Signature: input.Signature,
+		Scheme:    string(SchemeEIP712),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Verified: resp.Verified, Confidence: resp.Confidence}, nil
+}
+
+// solanaVerifier handles Solana's ed25519 message signing. Rust performs
+// the actual ed25519 verification; this type's job is address-format
+// gating and forwarding the scheme tag.
+type solanaVerifier struct{}
+
+func (solanaVerifier) Scheme() Scheme { return SchemeSolanaEd25519 }
+
+func (solanaVerifier) MatchesAddress(address string) bool {
+	return base58AddressPattern.MatchString(address)
+}
+
+func (solanaVerifier) Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error) {
+	resp, err := rustClient.VerifySignature(ctx, &pb.VerifySignatureRequest{
+		Address:   input.Address,
+		Message:   input.Message,
+		Signature: input.Signature,
+		Scheme:    string(SchemeSolanaEd25519),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Verified: resp.Verified, Confidence: resp.Confidence}, nil
+}
+
+// cosmosVerifier handles Cosmos ADR-036 off-chain amino signing.
+type cosmosVerifier struct{}
+
+func (cosmosVerifier) Scheme() Scheme { return SchemeCosmosADR36 }
+
+func (cosmosVerifier) MatchesAddress(address string) bool {
+	return strings.HasPrefix(address, "cosmos")
+}
+
+func (cosmosVerifier) Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error) {
+	resp, err := rustClient.VerifySignature(ctx, &pb.VerifySignatureRequest{
+		Address:   input.Address,
+		Message:   input.Message,
+		Signature: input.Signature,
+		Scheme:    string(SchemeCosmosADR36),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Verified: resp.Verified, Confidence: resp.Confidence}, nil
+}
+
+// bitcoinVerifier handles BIP-322 generic signed messages.
+type bitcoinVerifier struct{}
+
+func (bitcoinVerifier) Scheme() Scheme { return SchemeBitcoinBIP322 }
+
+func (bitcoinVerifier) MatchesAddress(address string) bool {
+	return hasBitcoinPrefix(address)
+}
+
+func (bitcoinVerifier) Verify(ctx context.Context, rustClient *grpc_client.RustClient, input VerifyInput) (*VerifyResult, error) {
+	resp, err := rustClient.VerifySignature(ctx, &pb.VerifySignatureRequest{
+		Address:   input.Address,
+		Message:   input.Message,
+		Signature: input.Signature,
+		Scheme:    string(SchemeBitcoinBIP322),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Verified: resp.Verified, Confidence: resp.Confidence}, nil
+}
+
+// schemeMetrics is a minimal per-scheme counter set. A full Prometheus
+// exporter is out of scope here (see the metrics sub-package added
+// separately); this just keeps attempts/failures/latency visible in-process.
+type schemeMetrics struct {
+	Attempts     uint64
+	Failures     uint64
+	TotalLatency time.Duration
+}
+
+var (
+	schemeMetricsMu sync.Mutex
+	schemeStats     = map[Scheme]*schemeMetrics{}
+)
+
+func recordSchemeMetric(scheme Scheme, failed bool, latency time.Duration) {
+	schemeMetricsMu.Lock()
+	defer schemeMetricsMu.Unlock()
+
+	m, ok := schemeStats[scheme]
+	if !ok {
+		m = &schemeMetrics{}
+		schemeStats[scheme] = m
+	}
+	m.Attempts++
+	if failed {
+		m.Failures++
+	}
+	m.TotalLatency += latency
+}
+
+// SchemeMetricsSnapshot returns a copy of the current per-scheme counters,
+// for tests and ad-hoc inspection.
+func SchemeMetricsSnapshot() map[Scheme]schemeMetrics {
+	schemeMetricsMu.Lock()
+	defer schemeMetricsMu.Unlock()
+
+	out := make(map[Scheme]schemeMetrics, len(schemeStats))
+	for scheme, m := range schemeStats {
+		out[scheme] = *m
+	}
+	return out
+}
+
+// resolveVerifier picks the SignatureVerifier for a verify request: the
+// caller's declared scheme if present (and storedScheme, if non-empty,
+// must agree with it - the scheme GetNonce recorded can't be overridden at
+// verify time), otherwise falls back to address-format detection.
+func resolveVerifier(declaredScheme, storedScheme, address string) (SignatureVerifier, Scheme, error) {
+	scheme := Scheme(declaredScheme)
+	if scheme == "" {
+		scheme = Scheme(storedScheme)
+	}
+	if scheme == "" {
+		detected, ok := DetectScheme(address)
+		if !ok {
+			return nil, "", fmt.Errorf("could not determine signature scheme for address %q", address)
+		}
+		scheme = detected
+	}
+
+	if storedScheme != "" && Scheme(storedScheme) != scheme {
+		return nil, "", fmt.Errorf("scheme %q does not match the scheme %q this nonce was issued under", scheme, storedScheme)
+	}
+
+	verifier, ok := lookupVerifier(scheme)
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported signature scheme %q", scheme)
+	}
+	if !verifier.MatchesAddress(address) {
+		return nil, "", fmt.Errorf("address %q is not a valid %q address", address, scheme)
+	}
+
+	return verifier, scheme, nil
+}
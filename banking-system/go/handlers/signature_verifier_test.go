@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDetectScheme(t *testing.T) {
+	cases := []struct {
+		name    string
+		address string
+		want    Scheme
+		wantOK  bool
+	}{
+		{"ethereum", "0x1234567890123456789012345678901234567890", SchemePersonalSign, true},
+		{"cosmos", "cosmos1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq", SchemeCosmosADR36, true},
+		{"bitcoin legacy", "1BoatSLRHtKNngkdXEeobR76b53LETtpyT", SchemeBitcoinBIP322, true},
+		{"bitcoin bech32", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", SchemeBitcoinBIP322, true},
+		{"solana", "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK", SchemeSolanaEd25519, true},
+		{"unrecognized", "not-an-address", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := DetectScheme(tc.address)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("DetectScheme(%q) = (%q, %v), want (%q, %v)", tc.address, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestResolveVerifier(t *testing.T) {
+	const ethAddress = "0x1234567890123456789012345678901234567890"
+	const solAddress = "DYw8jCTfwHNRJhhmFcbXvVDTqWMEVFBX6ZKUmG5CNSKK"
+
+	cases := []struct {
+		name           string
+		declaredScheme string
+		storedScheme   string
+		address        string
+		wantScheme     Scheme
+		wantErr        bool
+	}{
+		{"declared personal_sign", string(SchemePersonalSign), "", ethAddress, SchemePersonalSign, false},
+		{"declared eip712", string(SchemeEIP712), "", ethAddress, SchemeEIP712, false},
+		{"falls back to detection", "", "", ethAddress, SchemePersonalSign, false},
+		{"stored scheme used when none declared", "", string(SchemeEIP712), ethAddress, SchemeEIP712, false},
+		{"declared must match stored", string(SchemePersonalSign), string(SchemeEIP712), ethAddress, "", true},
+		{"scheme must match address format", string(SchemeSolanaEd25519), "", ethAddress, "", true},
+		{"unsupported scheme", "not_a_scheme", "", ethAddress, "", true},
+		{"solana address with solana scheme", string(SchemeSolanaEd25519), "", solAddress, SchemeSolanaEd25519, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, scheme, err := resolveVerifier(tc.declaredScheme, tc.storedScheme, tc.address)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("resolveVerifier(%q, %q, %q) = nil error, want error", tc.declaredScheme, tc.storedScheme, tc.address)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveVerifier(%q, %q, %q) unexpected error: %v", tc.declaredScheme, tc.storedScheme, tc.address, err)
+			}
+			if scheme != tc.wantScheme {
+				t.Errorf("scheme = %q, want %q", scheme, tc.wantScheme)
+			}
+			if verifier.Scheme() != tc.wantScheme {
+				t.Errorf("verifier.Scheme() = %q, want %q", verifier.Scheme(), tc.wantScheme)
+			}
+		})
+	}
+}
+
+// TestHashEIP712MatchesKnownVector checks HashEIP712 against a pinned
+// digest for the canonical "Ether Mail" example from the EIP-712
+// specification, so a regression in the type-string or encoding logic
+// trips this test instead of only surfacing downstream in Rust.
+func TestHashEIP712MatchesKnownVector(t *testing.T) {
+	typed := EIP712TypedData{
+		Types: map[string][]EIP712TypeField{
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: EIP712Domain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           1,
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: map[string]interface{}{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+
+	digest, err := HashEIP712(typed)
+	if err != nil {
+		t.Fatalf("HashEIP712: %v", err)
+	}
+
+	const want = "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"
+	if got := hex.EncodeToString(digest); got != want {
+		t.Errorf("HashEIP712 digest = %s, want %s", got, want)
+	}
+}
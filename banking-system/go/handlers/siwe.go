@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// siweVersion is the only SIWE message version this handler issues or
+// accepts (EIP-4361 defines just "1" so far).
+const siweVersion = "1"
+
+// SIWEMessage is an EIP-4361 "Sign-In with Ethereum" message.
+// WalletAuthHandler builds one in GetNonce, persists its rendered text
+// verbatim in Redis, and reloads that exact text in VerifySignature -
+// it never re-renders a message to verify against, since IssuedAt and
+// ExpirationTime would differ from what the wallet actually signed.
+type SIWEMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// Render renders m in the canonical SIWE text form.
+func (m SIWEMessage) Render() string {
+	version := m.Version
+	if version == "" {
+		version = siweVersion
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s wants you to sign in with your Ethereum account:\n", m.Domain)
+	fmt.Fprintf(&b, "%s\n\n", m.Address)
+	fmt.Fprintf(&b, "%s\n\n", m.Statement)
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", version)
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued-At: %s\n", m.IssuedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Expiration-Time: %s", m.ExpirationTime.UTC().Format(time.RFC3339))
+
+	if m.NotBefore != nil {
+		fmt.Fprintf(&b, "\nNot-Before: %s", m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest-ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			fmt.Fprintf(&b, "\n- %s", resource)
+		}
+	}
+
+	return b.String()
+}
+
+// siweFieldSeparator is the separator Render's "Key: value" lines use,
+// and the only place ParseSIWEMessage splits a field line on.
+const siweFieldSeparator = ": "
+
+// ParseSIWEMessage strictly parses text as a SIWE message in the
+// canonical form Render produces, returning an error for anything that
+// doesn't match. This lets VerifySignature accept a message text a
+// third-party wallet assembled itself, rather than only ones this
+// handler rendered, while still being able to validate it field by
+// field against the nonce record it's checked against.
+func ParseSIWEMessage(text string) (*SIWEMessage, error) {
+	lines := strings.Split(text, "\n")
+	if len(lines) < 9 {
+		return nil, fmt.Errorf("siwe: message has too few lines (%d)", len(lines))
+	}
+
+	const headerSuffix = " wants you to sign in with your Ethereum account:"
+	if !strings.HasSuffix(lines[0], headerSuffix) {
+		return nil, fmt.Errorf("siwe: first line missing domain header")
+	}
+	domain := strings.TrimSuffix(lines[0], headerSuffix)
+	if domain == "" {
+		return nil, fmt.Errorf("siwe: empty domain")
+	}
+
+	address := lines[1]
+	if address == "" {
+		return nil, fmt.Errorf("siwe: empty address")
+	}
+	if lines[2] != "" {
+		return nil, fmt.Errorf("siwe: expected blank line after address")
+	}
+
+	// The statement runs from line 3 up to (not including) the blank
+	// line that separates it from the URI/Version/... field block.
+	idx := 4
+	for idx < len(lines) && lines[idx] != "" {
+		idx++
+	}
+	if idx >= len(lines) {
+		return nil, fmt.Errorf("siwe: missing blank line after statement")
+	}
+	statement := strings.Join(lines[3:idx], "\n")
+	idx++
+
+	fields := make(map[string]string)
+	var resources []string
+	inResources := false
+	for ; idx < len(lines); idx++ {
+		line := lines[idx]
+		if inResources {
+			if !strings.HasPrefix(line, "- ") {
+				return nil, fmt.Errorf("siwe: malformed resource line %q", line)
+			}
+			resources = append(resources, strings.TrimPrefix(line, "- "))
+			continue
+		}
+		if line == "Resources:" {
+			inResources = true
+			continue
+		}
+		key, value, ok := strings.Cut(line, siweFieldSeparator)
+		if !ok {
+			return nil, fmt.Errorf("siwe: malformed field line %q", line)
+		}
+		fields[key] = value
+	}
+
+	for _, key := range []string{"URI", "Version", "Chain ID", "Nonce", "Issued-At", "Expiration-Time"} {
+		if _, ok := fields[key]; !ok {
+			return nil, fmt.Errorf("siwe: missing required field %q", key)
+		}
+	}
+
+	if fields["Version"] != siweVersion {
+		return nil, fmt.Errorf("siwe: unsupported version %q", fields["Version"])
+	}
+
+	chainID, err := strconv.ParseInt(fields["Chain ID"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("siwe: invalid Chain ID: %w", err)
+	}
+	issuedAt, err := time.Parse(time.RFC3339, fields["Issued-At"])
+	if err != nil {
+		return nil, fmt.Errorf("siwe: invalid Issued-At: %w", err)
+	}
+	expirationTime, err := time.Parse(time.RFC3339, fields["Expiration-Time"])
+	if err != nil {
+		return nil, fmt.Errorf("siwe: invalid Expiration-Time: %w", err)
+	}
+
+	msg := &SIWEMessage{
+		Domain:         domain,
+		Address:        address,
+		Statement:      statement,
+		URI:            fields["URI"],
+		Version:        fields["Version"],
+		ChainID:        chainID,
+		Nonce:          fields["Nonce"],
+		IssuedAt:       issuedAt,
+		ExpirationTime: expirationTime,
+		RequestID:      fields["Request-ID"],
+		Resources:      resources,
+	}
+
+	if notBefore, ok := fields["Not-Before"]; ok {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return nil, fmt.Errorf("siwe: invalid Not-Before: %w", err)
+		}
+		msg.NotBefore = &t
+	}
+
+	return msg, nil
+}
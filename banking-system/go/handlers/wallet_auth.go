@@ -5,27 +5,58 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
-	
+
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v5"
+	"banking-system/go/audit"
 	"banking-system/go/grpc_client"
-	pb "banking-system/go/proto"
+	"banking-system/go/metrics"
 )
 
+// accessTokenTTL is how long an issued JWT is valid, and also the grace
+// period KeyManager keeps a retired signing key published for - a token
+// signed right before rotation must still verify until it expires. Kept
+// short since a refresh token (see refresh.go) is what's meant to carry a
+// session across a full day, not the access token itself.
+const accessTokenTTL = 15 * time.Minute
+
 type WalletAuthHandler struct {
 	rustClient *grpc_client.RustClient
 	redis      *redis.Client
-	jwtSecret  []byte
+	keys       *KeyManager
+	events     *EventHub
+	audit      audit.Sink
 }
 
+// Defaults GetNonce falls back to when the caller doesn't supply a
+// domain/URI/chain ID of their own.
+const (
+	defaultSIWEDomain    = "banking-system.example.com"
+	defaultSIWEURI       = "https://banking-system.example.com"
+	defaultSIWEStatement = "Sign this message to verify you own this wallet. This won't cost any gas."
+	defaultSIWEChainID   = 1
+)
+
+// NonceData is what GetNonce persists in Redis under nonce:{nonce} -
+// including Message, the exact SIWE text the wallet was asked to sign,
+// so VerifySignature always hashes what was actually signed instead of
+// re-rendering a message with a fresh IssuedAt/ExpirationTime.
 type NonceData struct {
-	Nonce     string `json:"nonce"` 
-	Address   string `json:"address"` 
-	ExpiresAt int64  `json:"expires_at"` 
-	Used      bool   `json:"used"` 
+	Nonce     string `json:"nonce"`
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	ExpiresAt int64  `json:"expires_at"`
+	Used      bool   `json:"used"`
+	ChainID   int64  `json:"chain_id"`
+	Domain    string `json:"domain"`
+	Scheme    string `json:"scheme"`
 }
 
 func NewWalletAuthHandler(rustClient *grpc_client.RustClient, redisAddr string) (*WalletAuthHandler, error) {
@@ -46,15 +77,79 @@ func NewWalletAuthHandler(rustClient *grpc_client.RustClient, redisAddr string)
 		return nil, fmt.Errorf("redis connection failed: %v", err)
 	}
 
+	keys, err := NewKeyManager(accessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("key manager init failed: %v", err)
+	}
+
 	return &WalletAuthHandler{
 		rustClient: rustClient,
 		redis:      rdb,
-		jwtSecret:  []byte("your-256-bit-secret"), // Use env variable
+		keys:       keys,
+		events:     NewEventHub(rdb),
+		audit:      audit.NewStdoutSink(),
 	}, nil
 }
 
+// SetAuditSink swaps the audit log sink, e.g. for a file or Redis-stream
+// sink configured from ops config rather than the stdout default.
+func (h *WalletAuthHandler) SetAuditSink(sink audit.Sink) {
+	h.audit = sink
+}
+
+// logAudit writes one audit-log line if an audit sink is configured. Write
+// failures are logged but never fail the request - the audit trail is a
+// side channel, not something a wallet holder's login should depend on.
+func (h *WalletAuthHandler) logAudit(ctx context.Context, r *http.Request, event, address, nonce, result string, confidence float64, start time.Time) {
+	if h.audit == nil {
+		return
+	}
+	entry := audit.Event{
+		Event:      event,
+		Address:    address,
+		IP:         r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		Nonce:      nonce,
+		Result:     result,
+		Confidence: confidence,
+		DurationMs: time.Since(start).Milliseconds(),
+		Timestamp:  time.Now().Unix(),
+	}
+	if err := h.audit.Write(ctx, entry); err != nil {
+		log.Printf("audit log write failed: %v", err)
+	}
+}
+
+// GET /.well-known/jwks.json - publishes the current and recently-retired
+// signing keys so other services can verify tokens without sharing a secret.
+func (h *WalletAuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
+
+// POST /api/auth/rotate-key - forces immediate key rotation. Guarded by a
+// shared admin token rather than a user session, since this isn't a
+// wallet-holder action.
+func (h *WalletAuthHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	adminToken := os.Getenv("ADMIN_API_TOKEN") // Use a real secrets store in production
+	if adminToken == "" || r.Header.Get("X-Admin-Token") != adminToken {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	kid, err := h.keys.Rotate()
+	if err != nil {
+		http.Error(w, "Key rotation failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"kid": kid})
+}
+
 // GET /api/auth/nonce - रेट लिमिटिंग के साथ
 func (h *WalletAuthHandler) GetNonce(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	address := r.URL.Query().Get("address")
 	if address == "" {
 		http.Error(w, "Address required", http.StatusBadRequest)
@@ -66,18 +161,20 @@ func (h *WalletAuthHandler) GetNonce(w http.ResponseWriter, r *http.Request) {
 	// Rate limiting: 5 requests per minute per IP
 	ip := r.RemoteAddr
 	rateKey := fmt.Sprintf("rate:nonce:%s", ip)
-	
+
 	count, err := h.redis.Incr(ctx, rateKey).Result()
 	if err != nil {
 		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
-	
+
 	if count == 1 {
 		h.redis.Expire(ctx, rateKey, 60*time.Second)
 	}
-	
+
 	if count > 5 {
+		metrics.NonceRejected.WithLabelValues("rate_limited").Inc()
+		h.logAudit(ctx, r, "nonce.rejected", address, "", "rate_limited", 0, start)
 		http.Error(w, "Too many requests", http.StatusTooManyRequests)
 		return
 	}
@@ -87,33 +184,69 @@ func (h *WalletAuthHandler) GetNonce(w http.ResponseWriter, r *http.Request) {
 	rand.Read(nonceBytes)
 	nonce := hex.EncodeToString(nonceBytes)
 
+	domain := r.URL.Query().Get("domain")
+	if domain == "" {
+		domain = defaultSIWEDomain
+	}
+	uri := r.URL.Query().Get("uri")
+	if uri == "" {
+		uri = defaultSIWEURI
+	}
+	chainID, err := strconv.ParseInt(r.URL.Query().Get("chain_id"), 10, 64)
+	if err != nil || chainID <= 0 {
+		chainID = defaultSIWEChainID
+	}
+
+	scheme := r.URL.Query().Get("scheme")
+	if scheme == "" {
+		detected, ok := DetectScheme(address)
+		if !ok {
+			http.Error(w, "Could not determine signature scheme for address", http.StatusBadRequest)
+			return
+		}
+		scheme = string(detected)
+	}
+
+	issuedAt := time.Now().UTC()
+	expiresAt := issuedAt.Add(5 * time.Minute)
+
+	// Build the SIWE message once; the exact rendered text - not the
+	// struct - is what gets persisted and later re-hashed on verify.
+	siweMessage := SIWEMessage{
+		Domain:         domain,
+		Address:        address,
+		Statement:      defaultSIWEStatement,
+		URI:            uri,
+		Version:        siweVersion,
+		ChainID:        chainID,
+		Nonce:          nonce,
+		IssuedAt:       issuedAt,
+		ExpirationTime: expiresAt,
+	}
+	message := siweMessage.Render()
+
 	// Store nonce in Redis with 5 min expiry
 	nonceData := NonceData{
 		Nonce:     nonce,
 		Address:   address,
-		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+		Message:   message,
+		ExpiresAt: expiresAt.Unix(),
 		Used:      false,
+		ChainID:   chainID,
+		Domain:    domain,
+		Scheme:    scheme,
 	}
 
 	data, _ := json.Marshal(nonceData)
-	
+
 	err = h.redis.SetEx(ctx, fmt.Sprintf("nonce:%s", nonce), data, 5*time.Minute).Err()
 	if err != nil {
 		http.Error(w, "Failed to store nonce", http.StatusInternalServerError)
 		return
 	}
 
-	// Create message
-	message := fmt.Sprintf(`Welcome to Banking System!
-
-Sign this message to verify you own this wallet.
-This won't cost any gas.
-
-Address: %s
-Nonce: %s
-Timestamp: %d
-Expires: %d`,
-		address, nonce, time.Now().Unix(), time.Now().Add(5*time.Minute).Unix())
+	metrics.NonceIssued.WithLabelValues(strconv.FormatInt(chainID, 10)).Inc()
+	h.logAudit(ctx, r, "nonce.issued", address, nonce, "ok", 0, start)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -124,10 +257,16 @@ Expires: %d`,
 
 // POST /api/auth/verify - Rust से signature verify कराओ
 func (h *WalletAuthHandler) VerifySignature(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
 	var req struct {
-		Address   string `json:"address"` 
-		Signature string `json:"signature"` 
-		Nonce     string `json:"nonce"` 
+		Address   string           `json:"address"`
+		Signature string           `json:"signature"`
+		Nonce     string           `json:"nonce"`
+		Domain    string           `json:"domain"`
+		ChainID   int64            `json:"chain_id"`
+		Message   string           `json:"message"`
+		Scheme    string           `json:"scheme"`
+		TypedData *EIP712TypedData `json:"typed_data,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -140,6 +279,8 @@ func (h *WalletAuthHandler) VerifySignature(w http.ResponseWriter, r *http.Reque
 	// Get nonce from Redis
 	data, err := h.redis.Get(ctx, fmt.Sprintf("nonce:%s", req.Nonce)).Bytes()
 	if err == redis.Nil {
+		metrics.NonceRejected.WithLabelValues("expired").Inc()
+		h.logAudit(ctx, r, "verify.rejected", req.Address, req.Nonce, "expired", 0, start)
 		http.Error(w, "Invalid or expired nonce", http.StatusBadRequest)
 		return
 	} else if err != nil {
@@ -155,66 +296,120 @@ func (h *WalletAuthHandler) VerifySignature(w http.ResponseWriter, r *http.Reque
 
 	// Check if nonce is used
 	if nonceData.Used {
+		metrics.NonceRejected.WithLabelValues("used").Inc()
+		h.logAudit(ctx, r, "verify.rejected", req.Address, req.Nonce, "used", 0, start)
 		http.Error(w, "Nonce already used", http.StatusBadRequest)
 		return
 	}
 
 	// Check if nonce expired
 	if time.Now().Unix() > nonceData.ExpiresAt {
+		metrics.NonceRejected.WithLabelValues("expired").Inc()
+		h.logAudit(ctx, r, "verify.rejected", req.Address, req.Nonce, "expired", 0, start)
 		http.Error(w, "Nonce expired", http.StatusBadRequest)
 		return
 	}
 
 	// Verify address matches
 	if nonceData.Address != req.Address {
+		metrics.NonceRejected.WithLabelValues("address_mismatch").Inc()
+		h.logAudit(ctx, r, "verify.rejected", req.Address, req.Nonce, "address_mismatch", 0, start)
 		http.Error(w, "Address mismatch", http.StatusBadRequest)
 		return
 	}
 
-	// 🚀 Rust को signature verify करने भेजो
-	message := fmt.Sprintf(`Welcome to Banking System!
+	// domain/chainID are only checked when the caller supplies them -
+	// older clients that only ever sent address/signature/nonce keep
+	// working against whatever GetNonce defaulted them to.
+	if req.Domain != "" && req.Domain != nonceData.Domain {
+		http.Error(w, "Domain mismatch", http.StatusBadRequest)
+		return
+	}
+	if req.ChainID != 0 && req.ChainID != nonceData.ChainID {
+		http.Error(w, "Chain ID mismatch", http.StatusBadRequest)
+		return
+	}
 
-Sign this message to verify you own this wallet.
-This won't cost any gas.
+	// Default to the exact bytes GetNonce persisted - never rebuilt, so
+	// IssuedAt/ExpirationTime can't drift from what was actually signed.
+	// A third-party wallet that assembled its own SIWE message may
+	// instead supply it directly; it's strictly parsed and cross-checked
+	// against the stored nonce record before being trusted.
+	messageText := nonceData.Message
+	if req.Message != "" {
+		parsed, err := ParseSIWEMessage(req.Message)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid SIWE message: %v", err), http.StatusBadRequest)
+			return
+		}
+		if parsed.Nonce != nonceData.Nonce || parsed.Address != nonceData.Address ||
+			parsed.Domain != nonceData.Domain || parsed.ChainID != nonceData.ChainID {
+			http.Error(w, "SIWE message does not match nonce record", http.StatusBadRequest)
+			return
+		}
+		messageText = req.Message
+	}
 
-Address: %s
-Nonce: %s
-Timestamp: %d
-Expires: %d`,
-		req.Address, req.Nonce, time.Now().Unix(), time.Now().Add(5*time.Minute).Unix())
+	verifier, scheme, err := resolveVerifier(req.Scheme, nonceData.Scheme, req.Address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	verifyReq := &pb.VerifySignatureRequest{
-		Address:   req.Address,
-		Message:   message,
-		Signature: req.Signature,
+	// EIP-712 never forwards the structured payload to Rust - it's
+	// reduced to its signing digest here, which is what the wallet
+	// actually signed.
+	if scheme == SchemeEIP712 {
+		if req.TypedData == nil {
+			http.Error(w, "typed_data is required for eth_signTypedData_v4", http.StatusBadRequest)
+			return
+		}
+		digest, err := HashEIP712(*req.TypedData)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid typed data: %v", err), http.StatusBadRequest)
+			return
+		}
+		messageText = fmt.Sprintf("%x", digest)
 	}
 
-	verifyResp, err := h.rustClient.VerifySignature(ctx, verifyReq)
+	// 🚀 Rust को signature verify करने भेजो
+	verifyStart := time.Now()
+	verifyResp, err := verifier.Verify(ctx, h.rustClient, VerifyInput{
+		Address:   req.Address,
+		Message:   messageText,
+		Signature: req.Signature,
+		ChainID:   nonceData.ChainID,
+	})
+	rustRPCDuration := time.Since(verifyStart)
+	recordSchemeMetric(scheme, err != nil || verifyResp == nil || !verifyResp.Verified, rustRPCDuration)
+	metrics.RustRPCDuration.Observe(rustRPCDuration.Seconds())
+	metrics.VerifyDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
+		metrics.VerifyTotal.WithLabelValues("service_error").Inc()
+		h.logAudit(ctx, r, "verify", req.Address, req.Nonce, "service_error", 0, start)
 		http.Error(w, "Verification service error", http.StatusInternalServerError)
 		return
 	}
 
 	if !verifyResp.Verified {
+		metrics.VerifyTotal.WithLabelValues("bad_signature").Inc()
+		h.logAudit(ctx, r, "verify", req.Address, req.Nonce, "bad_signature", verifyResp.Confidence, start)
 		http.Error(w, "Invalid signature", http.StatusUnauthorized)
 		return
 	}
 
+	metrics.VerifyTotal.WithLabelValues("ok").Inc()
+	h.logAudit(ctx, r, "verify", req.Address, req.Nonce, "ok", verifyResp.Confidence, start)
+
 	// Mark nonce as used
 	nonceData.Used = true
 	updatedData, _ := json.Marshal(nonceData)
 	h.redis.SetEx(ctx, fmt.Sprintf("nonce:%s", req.Nonce), updatedData, 5*time.Minute)
+	h.events.Publish(ctx, req.Address, AuthEvent{Type: EventNonceConsumed})
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"address": req.Address,
-		"verified": true,
-		"confidence": verifyResp.Confidence,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	})
-
-	tokenString, err := token.SignedString(h.jwtSecret)
+	// New login starts a fresh token family (parentID ""); every refresh
+	// afterwards chains off the refresh token it replaced.
+	tokenString, refreshToken, err := h.issueTokenPair(ctx, req.Address, nonceData.ChainID, nonceData.Domain, "", "")
 	if err != nil {
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
@@ -222,22 +417,118 @@ Expires: %d`,
 
 	// Store session in Redis
 	sessionKey := fmt.Sprintf("session:%s", req.Address)
+	// A session already existing for this address means someone else
+	// just signed in with the same wallet while this one was still live.
+	if existed, _ := h.redis.Exists(ctx, sessionKey).Result(); existed > 0 {
+		h.events.Publish(ctx, req.Address, AuthEvent{Type: EventLoginNewDevice})
+	}
 	sessionData := map[string]interface{}{
-		"token": tokenString,
-		"address": req.Address,
+		"token":       tokenString,
+		"address":     req.Address,
 		"verified_at": time.Now().Unix(),
-		"expires_at": time.Now().Add(24 * time.Hour).Unix(),
+		"expires_at":  time.Now().Add(accessTokenTTL).Unix(),
 	}
-	
+
 	sessionJSON, _ := json.Marshal(sessionData)
-	h.redis.SetEx(ctx, sessionKey, sessionJSON, 24*time.Hour)
+	h.redis.SetEx(ctx, sessionKey, sessionJSON, accessTokenTTL)
+	h.events.Publish(ctx, req.Address, AuthEvent{Type: EventSessionCreated})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"address":       req.Address,
+		"expires_in":    int64(accessTokenTTL.Seconds()),
+	})
+}
+
+// POST /api/auth/refresh - exchanges a refresh token for a new access +
+// refresh pair. Presenting the same refresh token twice revokes its whole
+// family, since that can only happen if it was stolen and used by someone
+// else after the legitimate client already refreshed.
+func (h *WalletAuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	accessToken, refreshToken, err := h.refreshTokenPair(ctx, req.RefreshToken)
+	switch {
+	case errors.Is(err, errRefreshReused):
+		http.Error(w, "Refresh token reuse detected; session revoked", http.StatusUnauthorized)
+		return
+	case errors.Is(err, errRefreshNotFound):
+		http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"token": tokenString,
-		"address": req.Address,
-		"expires_in": 86400,
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(accessTokenTTL.Seconds()),
+	})
+}
+
+// POST /api/auth/logout - revokes the caller's current token family (so no
+// refresh token descending from it can be used again) and the bearer
+// access token's jti (so it's rejected immediately instead of lingering
+// until exp).
+func (h *WalletAuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if len(authHeader) < 8 || authHeader[:7] != "Bearer " {
+		http.Error(w, "No token", http.StatusUnauthorized)
+		return
+	}
+	tokenString := authHeader[7:]
+
+	ctx := context.Background()
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		public, ok := h.keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return public, nil
 	})
+	if err != nil || !token.Valid {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		http.Error(w, "Invalid claims", http.StatusUnauthorized)
+		return
+	}
+
+	if jti, ok := claims["jti"].(string); ok {
+		if exp, ok := claims["exp"].(float64); ok {
+			ttl := time.Until(time.Unix(int64(exp), 0))
+			h.revokeJTI(ctx, jti, ttl)
+		}
+	}
+	if familyID, ok := claims["family_id"].(string); ok {
+		h.revokeFamily(ctx, familyID)
+	}
+	if address, ok := claims["address"].(string); ok {
+		h.redis.Del(ctx, fmt.Sprintf("session:%s", address))
+		h.events.Publish(ctx, address, AuthEvent{Type: EventSessionRevoked})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "logged_out"})
 }
 
 // GET /api/auth/session - Check session
@@ -251,7 +542,15 @@ func (h *WalletAuthHandler) GetSession(w http.ResponseWriter, r *http.Request) {
 	tokenString := authHeader[7:] // Remove "Bearer "
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return h.jwtSecret, nil
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		public, ok := h.keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return public, nil
 	})
 
 	if err != nil || !token.Valid {
@@ -267,8 +566,22 @@ func (h *WalletAuthHandler) GetSession(w http.ResponseWriter, r *http.Request) {
 
 	address := claims["address"].(string)
 
-	// Get session from Redis
 	ctx := context.Background()
+
+	// Reject a revoked-but-unexpired token (logout, or reuse-triggered
+	// family revocation) rather than trusting it just because the
+	// session key it's paired with still happens to exist.
+	if jti, ok := claims["jti"].(string); ok {
+		if revoked, err := h.isJTIRevoked(ctx, jti); err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		} else if revoked {
+			http.Error(w, "Token revoked", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// Get session from Redis
 	data, err := h.redis.Get(ctx, fmt.Sprintf("session:%s", address)).Bytes()
 	if err == redis.Nil {
 		http.Error(w, "Session expired", http.StatusUnauthorized)
@@ -0,0 +1,54 @@
+// Package metrics exposes Prometheus counters and histograms for the
+// wallet-auth pipeline (nonce issuance, verification outcomes, Rust RPC
+// latency), plus a small multi-address server to serve them on a private
+// interface separate from the public API.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Registry is the registry every auth metric in this package registers
+// against. It's kept separate from prometheus.DefaultRegisterer so this
+// package can be embedded in a larger binary without colliding with that
+// binary's own metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// NonceIssued counts nonces GetNonce successfully issued, by chain.
+	NonceIssued = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_nonce_issued_total",
+		Help: "Nonces issued by GetNonce, labeled by chain ID.",
+	}, []string{"chain"})
+
+	// NonceRejected counts nonces rejected before a signature was ever
+	// checked. reason is one of: expired, used, address_mismatch, rate_limited.
+	NonceRejected = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_nonce_rejected_total",
+		Help: "Nonces rejected prior to verification, labeled by reason.",
+	}, []string{"reason"})
+
+	// VerifyTotal counts completed signature-verification attempts. result
+	// is one of: ok, bad_signature, service_error.
+	VerifyTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_verify_total",
+		Help: "Signature verification attempts, labeled by result.",
+	}, []string{"result"})
+
+	// VerifyDuration times the VerifySignature handler end to end.
+	VerifyDuration = promauto.With(Registry).NewHistogram(prometheus.HistogramOpts{
+		Name:    "auth_verify_duration_seconds",
+		Help:    "VerifySignature handler latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// RustRPCDuration times just the verifier's call into the Rust
+	// verification service, so RPC latency can be distinguished from the
+	// handler's own nonce-lookup and canonicalization overhead.
+	RustRPCDuration = promauto.With(Registry).NewHistogram(prometheus.HistogramOpts{
+		Name:    "auth_rust_rpc_duration_seconds",
+		Help:    "Latency of the gRPC call to the Rust signature-verification service, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// TestScrapeReflectsMixedVerifications records a mix of successful and
+// failed verifications, scrapes /metrics through the real promhttp
+// handler, and asserts the resulting counters match what was recorded.
+func TestScrapeReflectsMixedVerifications(t *testing.T) {
+	NonceIssued.Reset()
+	VerifyTotal.Reset()
+
+	NonceIssued.WithLabelValues("1").Inc()
+	NonceIssued.WithLabelValues("1").Inc()
+	NonceIssued.WithLabelValues("137").Inc()
+
+	VerifyTotal.WithLabelValues("ok").Inc()
+	VerifyTotal.WithLabelValues("ok").Inc()
+	VerifyTotal.WithLabelValues("bad_signature").Inc()
+	VerifyTotal.WithLabelValues("service_error").Inc()
+
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	out := string(body)
+
+	cases := []string{
+		`auth_nonce_issued_total{chain="1"} 2`,
+		`auth_nonce_issued_total{chain="137"} 1`,
+		`auth_verify_total{result="ok"} 2`,
+		`auth_verify_total{result="bad_signature"} 1`,
+		`auth_verify_total{result="service_error"} 1`,
+	}
+	for _, want := range cases {
+		if !strings.Contains(out, want) {
+			t.Errorf("scraped output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+}
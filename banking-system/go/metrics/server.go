@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server serves /metrics for Registry on one or more listen addresses, so
+// ops can bind it to a private interface (e.g. 127.0.0.1:9100) kept apart
+// from whatever addresses carry public API traffic.
+type Server struct {
+	addrs   []string
+	servers []*http.Server
+}
+
+// NewServer builds a Server that will listen on every addr in addrs
+// (host:port entries) once Start is called.
+func NewServer(addrs []string) *Server {
+	return &Server{addrs: addrs}
+}
+
+// Start binds every configured address and serves until ctx is cancelled,
+// returning once all listeners have stopped. An empty addrs is a
+// configuration error rather than a silent no-op.
+func (s *Server) Start(ctx context.Context) error {
+	if len(s.addrs) == 0 {
+		return fmt.Errorf("metrics: no listen addresses configured")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+
+	errCh := make(chan error, len(s.addrs))
+	for _, addr := range s.addrs {
+		srv := &http.Server{Addr: addr, Handler: mux}
+		s.servers = append(s.servers, srv)
+		go func(srv *http.Server) {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("metrics: listen on %s: %w", srv.Addr, err)
+				return
+			}
+			errCh <- nil
+		}(srv)
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.Shutdown(context.Background())
+	}()
+
+	var firstErr error
+	for range s.addrs {
+		if err := <-errCh; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Shutdown gracefully stops every listener started by Start.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, srv := range s.servers {
+		if err := srv.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}